@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/oidc"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// The tests run the real OIDC service and handler against this mock provider,
+// with in-memory stand-ins for the database and the token issuer. The stand-ins
+// stamp new rows with the context's tenant, as the tenant plugin does.
+
+type memoryIdentities struct {
+	mu         sync.Mutex
+	identities []*models.UserIdentity
+	states     map[string]*models.OIDCLoginState
+}
+
+func (r *memoryIdentities) Create(ctx context.Context, identity *models.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identity.ID = uint(len(r.identities) + 1)
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *memoryIdentities) GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryIdentities) GetByUserID(ctx context.Context, userID uint) ([]*models.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var identities []*models.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *memoryIdentities) Delete(ctx context.Context, userID uint, provider string) (bool, error) {
+	return false, nil
+}
+
+func (r *memoryIdentities) TouchLogin(ctx context.Context, id uint, email *string) error {
+	return nil
+}
+
+func (r *memoryIdentities) CreateState(ctx context.Context, state *models.OIDCLoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state.TenantID = tenant.ID(ctx)
+	r.states[state.StateHash] = state
+	return nil
+}
+
+func (r *memoryIdentities) ConsumeState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[stateHash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	delete(r.states, stateHash)
+	return state, nil
+}
+
+func (r *memoryIdentities) DeleteExpiredStates(ctx context.Context) error {
+	return nil
+}
+
+// memoryUsers implements the user lookups the OIDC service makes; any other
+// method panics through the nil embedded interface.
+type memoryUsers struct {
+	repository.UserRepository
+
+	mu    sync.Mutex
+	users []*models.User
+}
+
+func (r *memoryUsers) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user.ID = uint(len(r.users) + 1)
+	user.TenantID = tenant.ID(ctx)
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *memoryUsers) find(match func(*models.User) bool) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (r *memoryUsers) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Email == email })
+}
+
+func (r *memoryUsers) GetByNip(ctx context.Context, nip string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Nip == nip })
+}
+
+func (r *memoryUsers) Update(ctx context.Context, user *models.User) error {
+	return nil
+}
+
+type activeTenants struct {
+	repository.TenantRepository
+}
+
+func (activeTenants) GetByID(ctx context.Context, id uint) (*models.Tenant, error) {
+	return &models.Tenant{ID: id, IsActive: true}, nil
+}
+
+// tokenIssuer hands out an access token naming the user instead of a JWT.
+type tokenIssuer struct {
+	service.AuthService
+}
+
+func (tokenIssuer) CompleteLogin(ctx context.Context, user *models.User) (*models.TokenResponse, error) {
+	return &models.TokenResponse{AccessToken: "access-" + user.Nip, TokenType: "Bearer"}, nil
+}
+
+type allowAll struct {
+	service.SecurityService
+}
+
+func (allowAll) CheckLogin(ctx context.Context, user *models.User, client *models.ClientInfo) error {
+	return nil
+}
+
+func (allowAll) LogEvent(ctx context.Context, event *models.SecurityEvent) {}
+
+type harness struct {
+	t          *testing.T
+	e          *echo.Echo
+	handler    *handlers.OIDCHandler
+	identities *memoryIdentities
+	users      *memoryUsers
+	// tenantID is the tenant flows start in, none by default.
+	tenantID uint
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+
+	p, err := newProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(p.handler())
+	t.Cleanup(server.Close)
+
+	previous := *issuer
+	*issuer = server.URL
+	t.Cleanup(func() { *issuer = previous })
+
+	cfg := &config.OIDCConfig{
+		Provider:      "mock",
+		IssuerURL:     server.URL,
+		ClientID:      *clientID,
+		ClientSecret:  *clientSecret,
+		RedirectURL:   "http://app.test/api/auth/oidc/callback",
+		Scopes:        []string{"openid", "profile", "email"},
+		StateExpiry:   time.Minute,
+		NipClaim:      "nip",
+		EmailClaim:    "email",
+		NameClaim:     "name",
+		GroupsClaim:   "groups",
+		DefaultRole:   "user",
+		AutoProvision: true,
+	}
+
+	h := &harness{
+		t:          t,
+		e:          echo.New(),
+		identities: &memoryIdentities{states: make(map[string]*models.OIDCLoginState)},
+		users:      &memoryUsers{},
+	}
+	oidcService := service.NewOIDCService(oidc.NewProvider(cfg), h.identities, h.users, activeTenants{}, tokenIssuer{}, allowAll{}, cfg)
+	h.handler = handlers.NewOIDCHandler(oidcService, "")
+	return h
+}
+
+// start runs an echo handler that begins a flow and returns the
+// authorization URL and the state cookie it set.
+func (h *harness) start(begin func(echo.Context) error, userID *uint) (string, *http.Cookie) {
+	h.t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login?response=json", nil)
+	if h.tenantID != 0 {
+		req = req.WithContext(tenant.WithID(req.Context(), h.tenantID))
+	}
+	rec := httptest.NewRecorder()
+	c := h.e.NewContext(req, rec)
+	if userID != nil {
+		c.Set("user_id", *userID)
+	}
+	if err := begin(c); err != nil {
+		h.t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		h.t.Fatalf("start: status %d: %s", rec.Code, rec.Body)
+	}
+
+	var body struct {
+		Data models.OIDCAuthorizationResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		h.t.Fatal(err)
+	}
+
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == "oidc_state" {
+			if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+				h.t.Fatalf("state cookie is not HttpOnly and SameSite=Lax: %+v", cookie)
+			}
+			return body.Data.AuthorizationURL, cookie
+		}
+	}
+	h.t.Fatal("start: no state cookie set")
+	return "", nil
+}
+
+// signIn submits the mock provider's login form and returns the callback
+// query the browser is redirected with.
+func (h *harness) signIn(authURL, nip string) string {
+	h.t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	form := parsed.Query()
+	form.Set("sub", "mock-"+nip)
+	form.Set("nip", nip)
+	form.Set("email", nip+"@example.com")
+	form.Set("name", "User "+nip)
+	parsed.RawQuery = ""
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.PostForm(parsed.String(), form)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		h.t.Fatalf("sign in: status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return location.RawQuery
+}
+
+// callback delivers the provider redirect, with the state cookie when one is
+// given, and returns the response.
+func (h *harness) callback(query string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	h.t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+query, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	if err := h.handler.Callback(h.e.NewContext(req, rec)); err != nil {
+		h.t.Fatal(err)
+	}
+	return rec
+}
+
+func TestLoginCompletesInTheBrowserThatStartedIt(t *testing.T) {
+	h := newHarness(t)
+
+	authURL, cookie := h.start(h.handler.Login, nil)
+	rec := h.callback(h.signIn(authURL, "199001012020"), cookie)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("callback: status %d: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "access-199001012020") {
+		t.Fatalf("callback did not sign the user in: %s", rec.Body)
+	}
+	if len(h.users.users) != 1 {
+		t.Fatalf("provisioned %d users, want 1", len(h.users.users))
+	}
+}
+
+// The provider redirects to the one callback URL without the tenant header a
+// frontend may have started the flow with.
+func TestLoginCompletesInTheTenantThatStartedIt(t *testing.T) {
+	h := newHarness(t)
+	h.tenantID = 2
+
+	authURL, cookie := h.start(h.handler.Login, nil)
+	rec := h.callback(h.signIn(authURL, "199001012020"), cookie)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("callback: status %d: %s", rec.Code, rec.Body)
+	}
+	if len(h.users.users) != 1 || h.users.users[0].TenantID != 2 {
+		t.Fatalf("provisioned %+v, want one user in tenant 2", h.users.users)
+	}
+}
+
+func TestLoginRejectsACallbackFromAnotherBrowser(t *testing.T) {
+	h := newHarness(t)
+
+	// The attacker signs in to their own account and hands the callback URL
+	// to the victim, whose browser has no cookie or one of its own flow.
+	attackerURL, _ := h.start(h.handler.Login, nil)
+	attackerQuery := h.signIn(attackerURL, "111111111111")
+	_, victimCookie := h.start(h.handler.Login, nil)
+
+	for name, cookie := range map[string]*http.Cookie{"no cookie": nil, "other flow": victimCookie} {
+		rec := h.callback(attackerQuery, cookie)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: status %d, want %d: %s", name, rec.Code, http.StatusUnauthorized, rec.Body)
+		}
+	}
+	if len(h.users.users) != 0 {
+		t.Fatalf("provisioned %d users from a forged callback", len(h.users.users))
+	}
+}
+
+func TestLinkRejectsACallbackFromAnotherBrowser(t *testing.T) {
+	h := newHarness(t)
+	victim := &models.User{Nip: "222222222222", Email: "victim@example.com", IsActive: true}
+	if err := h.users.Create(context.Background(), victim); err != nil {
+		t.Fatal(err)
+	}
+
+	// The attacker starts linking on their own session, signs in with the
+	// identity they control and sends the callback to the victim.
+	attacker := uint(99)
+	attackerURL, _ := h.start(h.handler.Link, &attacker)
+	attackerQuery := h.signIn(attackerURL, "333333333333")
+
+	if rec := h.callback(attackerQuery, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("forged link callback: status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	linkURL, cookie := h.start(h.handler.Link, &victim.ID)
+	rec := h.callback(h.signIn(linkURL, victim.Nip), cookie)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("link callback: status %d: %s", rec.Code, rec.Body)
+	}
+	identities, _ := h.identities.GetByUserID(context.Background(), victim.ID)
+	if len(identities) != 1 || identities[0].Subject != "mock-"+victim.Nip {
+		t.Fatalf("linked identities = %+v", identities)
+	}
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 
 	"github.com/bobchopperz/bahrululum/internal/config"
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"github.com/bobchopperz/bahrululum/internal/init/database"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
 	"gorm.io/gorm"
 )
 
@@ -16,6 +18,7 @@ var (
 	chapters = flag.Bool("chapters", false, "Seed course chapters only")
 	contents = flag.Bool("contents", false, "Seed course contents only")
 	clean    = flag.Bool("clean", false, "Clean all seeded data before seeding")
+	slug     = flag.String("tenant", "", "Slug of the tenant to seed; the configured default when empty")
 )
 
 func main() {
@@ -42,9 +45,19 @@ func main() {
 		log.Fatalf("Failed to migrate models: %v", err)
 	}
 
+	// Scope everything below to the tenant
+	if *slug == "" {
+		*slug = cfg.TenantConfig.Default
+	}
+	var t models.Tenant
+	if err := db.Where("slug = ?", *slug).First(&t).Error; err != nil {
+		log.Fatalf("Failed to find tenant %q: %v", *slug, err)
+	}
+	db = db.WithContext(tenant.WithID(context.Background(), t.ID))
+
 	// Clean data if requested
 	if *clean {
-		cleanData(db)
+		cleanData(db, t.ID)
 	}
 
 	// Determine what to seed
@@ -71,19 +84,19 @@ func main() {
 	}
 }
 
-func cleanData(db *gorm.DB) {
+func cleanData(db *gorm.DB, tenantID uint) {
 	log.Println("Cleaning existing seeded data...")
 
 	// Delete in reverse order of dependencies
-	if err := db.Exec("DELETE FROM course_contents").Error; err != nil {
+	if err := db.Exec("DELETE FROM course_contents WHERE tenant_id = ?", tenantID).Error; err != nil {
 		log.Printf("Warning: Failed to clean course_contents: %v", err)
 	}
 
-	if err := db.Exec("DELETE FROM course_chapters").Error; err != nil {
+	if err := db.Exec("DELETE FROM course_chapters WHERE tenant_id = ?", tenantID).Error; err != nil {
 		log.Printf("Warning: Failed to clean course_chapters: %v", err)
 	}
 
-	if err := db.Exec("DELETE FROM courses").Error; err != nil {
+	if err := db.Exec("DELETE FROM courses WHERE tenant_id = ?", tenantID).Error; err != nil {
 		log.Printf("Warning: Failed to clean courses: %v", err)
 	}
 
@@ -16,6 +16,9 @@ import (
 	"github.com/bobchopperz/bahrululum/internal/domain/repository"
 	"github.com/bobchopperz/bahrululum/internal/domain/service"
 	"github.com/bobchopperz/bahrululum/internal/init/database"
+	"github.com/bobchopperz/bahrululum/internal/jwtkeys"
+	"github.com/bobchopperz/bahrululum/internal/mailer"
+	"github.com/bobchopperz/bahrululum/internal/oidc"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -34,42 +37,144 @@ func main() {
 	e := echo.New()
 	e.Validator = validators.NewValidator()
 	e.HideBanner = true
-	configureMiddleware(e)
+	configureMiddleware(e, cfg)
 
 	userRepository := repository.NewUserRepository(db)
 	courseRepository := repository.NewCourseRepository(db)
 	enrollmentRepository := repository.NewEnrollmentRepository(db)
 	chapterRepository := repository.NewCourseChapterRepository(db)
 	contentRepository := repository.NewCourseContentRepository(db)
+	bulkJobRepository := repository.NewBulkEnrollmentJobRepository(db)
+	notificationRepository := repository.NewNotificationRepository(db)
+	learningPathRepository := repository.NewLearningPathRepository(db)
+	certificateRepository := repository.NewCertificateRepository(db)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+	passwordResetRepository := repository.NewPasswordResetRepository(db)
+	emailVerificationRepository := repository.NewEmailVerificationRepository(db)
+	mfaRepository := repository.NewMFARepository(db)
+	securityRepository := repository.NewSecurityRepository(db)
+	userIdentityRepository := repository.NewUserIdentityRepository(db)
+	apiKeyRepository := repository.NewAPIKeyRepository(db)
+	userImportRepository := repository.NewUserImportRepository(db)
+	orgUnitRepository := repository.NewOrgUnitRepository(db)
+	tenantRepository := repository.NewTenantRepository(db)
+	cohortRepository := repository.NewCohortRepository(db)
+	attendanceRepository := repository.NewAttendanceRepository(db)
+	calendarFeedRepository := repository.NewCalendarFeedRepository(db)
+	hafalanRepository := repository.NewHafalanRepository(db)
+
+	mail, err := mailer.New(&cfg.MailConfig)
+	if err != nil {
+		log.Fatalf("Failed to setup mailer: %v", err)
+	}
+
+	jwtKeys, err := jwtkeys.Load(&cfg.JWTConfig)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
 
+	tenantService := service.NewTenantService(tenantRepository, &cfg.TenantConfig)
 	userService := service.NewUserService(userRepository)
-	authService := service.NewAuthService(userRepository, &cfg.JWTConfig)
+	mfaService := service.NewMFAService(mfaRepository, userRepository, &cfg.MFAConfig)
+	securityService := service.NewSecurityService(securityRepository, userRepository, &cfg.SecurityConfig)
+	var authenticators []service.Authenticator
+	var localRoles []string
+	if cfg.LDAPConfig.Enabled {
+		authenticators = append(authenticators, service.NewLDAPAuthenticator(userRepository, &cfg.LDAPConfig))
+		localRoles = cfg.LDAPConfig.LocalFallbackRoles
+	}
+	authenticators = append(authenticators, service.NewLocalAuthenticator(localRoles))
+	authService := service.NewAuthService(userRepository, refreshTokenRepository, mfaService, securityService, authenticators, jwtKeys, &cfg.JWTConfig, &cfg.EmailVerificationConfig, &cfg.MFAConfig)
+	passwordResetService := service.NewPasswordResetService(passwordResetRepository, userRepository, authService, mail, &cfg.PasswordResetConfig)
+	emailVerificationService := service.NewEmailVerificationService(emailVerificationRepository, userRepository, mail, &cfg.EmailVerificationConfig)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository, securityService)
+	userAdminService := service.NewUserAdminService(userRepository, userService, authService, emailVerificationService, securityService)
+	userImportService := service.NewUserImportService(userImportRepository, userRepository, authService, securityService)
+	sessionService := service.NewSessionService(refreshTokenRepository, userRepository, securityService)
+	profileService := service.NewProfileService(userRepository, emailVerificationService, authService)
+	oidcService := service.NewOIDCService(oidc.NewProvider(&cfg.OIDCConfig), userIdentityRepository, userRepository, tenantRepository, authService, securityService, &cfg.OIDCConfig)
 	courseService := service.NewCourseService(courseRepository)
-	enrollmentService := service.NewEnrollmentService(enrollmentRepository)
+	enrollmentService := service.NewEnrollmentService(enrollmentRepository, contentRepository, courseRepository, attendanceRepository)
+	bulkEnrollmentService := service.NewBulkEnrollmentService(enrollmentRepository, courseRepository, userRepository, bulkJobRepository)
+	orgUnitService := service.NewOrgUnitService(orgUnitRepository, userRepository, courseRepository, enrollmentRepository, bulkEnrollmentService, securityService)
 	chapterService := service.NewCourseChapterService(chapterRepository)
-	contentService := service.NewCourseContentService(contentRepository)
+	contentService := service.NewCourseContentService(contentRepository, hafalanRepository)
+	notificationService := service.NewNotificationService(notificationRepository)
+	learningPathService := service.NewLearningPathService(learningPathRepository, courseRepository, enrollmentRepository, certificateRepository)
+	cohortService := service.NewCohortService(cohortRepository, userRepository, courseRepository, enrollmentRepository, notificationService)
+	attendanceService := service.NewAttendanceService(attendanceRepository, chapterRepository, courseRepository, enrollmentRepository, enrollmentService, &cfg.AttendanceConfig)
+	calendarService := service.NewCalendarService(calendarFeedRepository, userRepository, enrollmentRepository, attendanceRepository, tenantRepository, &cfg.CalendarConfig)
+	hafalanService := service.NewHafalanService(hafalanRepository, contentRepository, courseRepository, enrollmentRepository, enrollmentService)
+	reminderService := service.NewReminderService(enrollmentRepository, notificationService, &cfg.ReminderConfig)
+
+	e.Use(mymiddleware.Tenant(tenantService, &cfg.TenantConfig))
+	e.Use(mymiddleware.Calendar())
 
 	routes.SetupHealthRoutes(e)
+	routes.SetupJWKSRoutes(e, jwtKeys)
+	routes.SetupTenantRoutes(e, tenantService, authService, userService)
 
 	opts := routes.AuthRoutesOpts{
-		AuthService: authService,
-		UserService: userService,
+		AuthService:          authService,
+		UserService:          userService,
+		PasswordResetService: passwordResetService,
+		VerificationService:  emailVerificationService,
+		RequireVerifiedLogin: cfg.EmailVerificationConfig.RequireForLogin,
 	}
 	routes.SetupAuthRoutes(e, opts)
 	routes.SetupUsersRoutes(e, userService)
-	routes.SetupCoursesRoutes(e, courseService)
-	routes.SetupEnrollmentRoutes(e, enrollmentService, authService)
+	routes.SetupUserAdminRoutes(e, userAdminService, authService, userService)
+	routes.SetupUserImportRoutes(e, userImportService, authService, userService)
+	routes.SetupOrgUnitRoutes(e, orgUnitService, authService, userService)
+	routes.SetupProfileRoutes(e, profileService, authService)
+	routes.SetupMFARoutes(e, mfaService, authService)
+	routes.SetupSecurityRoutes(e, securityService, authService, userService)
+	routes.SetupSessionRoutes(e, sessionService, authService, userService)
+	routes.SetupAPIKeyRoutes(e, apiKeyService, authService, userService)
+	if cfg.OIDCConfig.Enabled {
+		routes.SetupOIDCRoutes(e, oidcService, authService, cfg.OIDCConfig.FrontendURL)
+	}
+	routes.SetupCoursesRoutes(e, courseService, authService, userService)
+	routes.SetupEnrollmentRoutes(e, routes.EnrollmentRoutesOpts{
+		EnrollmentService:    enrollmentService,
+		BulkService:          bulkEnrollmentService,
+		AuthService:          authService,
+		APIKeyService:        apiKeyService,
+		UserService:          userService,
+		RequireVerifiedEmail: cfg.EmailVerificationConfig.RequireForEnrollment,
+	})
 	routes.SetupCourseChapterRoutes(e, chapterService)
 	routes.SetupCourseContentRoutes(e, contentService)
+	routes.SetupNotificationRoutes(e, notificationService, authService)
+	routes.SetupLearningPathRoutes(e, learningPathService, authService, userService, cfg.EmailVerificationConfig.RequireForEnrollment)
+	routes.SetupCohortRoutes(e, cohortService, authService, userService)
+	routes.SetupAttendanceRoutes(e, attendanceService, authService, userService)
+	routes.SetupCalendarRoutes(e, calendarService, authService)
+	routes.SetupHafalanRoutes(e, hafalanService, authService, userService)
+
+	// Jobs cannot outlive the process that ran them. The context carries no
+	// tenant, so jobs of every tenant are covered.
+	if err := bulkEnrollmentService.FailInterruptedJobs(context.Background()); err != nil {
+		log.Printf("Failed to mark interrupted bulk enrollment jobs: %v", err)
+	}
+
+	go reminderService.Run(context.Background())
 
 	startServer(e, cfg)
 }
 
-func configureMiddleware(e *echo.Echo) {
+func configureMiddleware(e *echo.Echo, cfg *config.Config) {
+	ipExtractor, err := mymiddleware.IPExtractor(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+	e.IPExtractor = ipExtractor
+
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(mymiddleware.CORS())
+	e.Use(mymiddleware.CORS(cfg.TenantConfig.Header))
 	e.Use(middleware.RequestID())
+	e.Use(mymiddleware.ClientInfo())
 }
 
 func startServer(e *echo.Echo, cfg *config.Config) {
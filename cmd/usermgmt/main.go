@@ -0,0 +1,491 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/init/database"
+	"github.com/bobchopperz/bahrululum/internal/jwtkeys"
+	"github.com/bobchopperz/bahrululum/internal/mailer"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// cliActorID records changes made from the command line without an acting
+// user.
+const cliActorID = 0
+
+type app struct {
+	cfg        *config.Config
+	tenantSlug string
+	// ctx is scoped to the tenant picked with -tenant once connected.
+	ctx      context.Context
+	userRepo repository.UserRepository
+	tenants  service.TenantService
+	admin    service.UserAdminService
+	imports  service.UserImportService
+}
+
+func main() {
+	global := flag.NewFlagSet("usermgmt", flag.ExitOnError)
+	global.Usage = printUsage
+	tenantSlug := global.String("tenant", "", "slug of the tenant to manage; the configured default when empty")
+	global.Parse(os.Args[1:])
+
+	if global.NArg() < 1 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	command, args := global.Arg(0), global.Args()[1:]
+	if command == "help" || command == "-h" || command == "--help" {
+		printUsage()
+		return
+	}
+
+	commands := map[string]func(*app, []string) error{
+		"create-admin":   (*app).createAdmin,
+		"create":         (*app).create,
+		"list":           (*app).list,
+		"set-role":       (*app).setRole,
+		"activate":       (*app).activate,
+		"deactivate":     (*app).deactivate,
+		"reset-password": (*app).resetPassword,
+		"import":         (*app).importUsers,
+		"create-tenant":  (*app).createTenant,
+		"list-tenants":   (*app).listTenants,
+	}
+	run, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", command)
+		printUsage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if *tenantSlug == "" {
+		*tenantSlug = cfg.TenantConfig.Default
+	}
+
+	if err := run(&app{cfg: cfg, tenantSlug: *tenantSlug}, args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// connect wires the same services the API server uses, so the CLI applies
+// the same validation and audit logging. Commands call it once their flags
+// are parsed, so -h works without a database.
+func (a *app) connect() error {
+	cfg := a.cfg
+	db, err := database.InitDatabase(&cfg.DatabaseConfig)
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	db = db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+
+	keys, err := jwtkeys.Load(&cfg.JWTConfig)
+	if err != nil {
+		return fmt.Errorf("jwt keys: %w", err)
+	}
+
+	mail, err := mailer.New(&cfg.MailConfig)
+	if err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+
+	a.tenants = service.NewTenantService(repository.NewTenantRepository(db), &cfg.TenantConfig)
+	t, err := a.tenants.GetBySlug(context.Background(), a.tenantSlug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("no tenant with slug %s", a.tenantSlug)
+		}
+		return err
+	}
+	a.ctx = tenant.WithID(models.ContextWithTenant(context.Background(), t), t.ID)
+
+	userRepository := repository.NewUserRepository(db)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+
+	userService := service.NewUserService(userRepository)
+	mfaService := service.NewMFAService(repository.NewMFARepository(db), userRepository, &cfg.MFAConfig)
+	securityService := service.NewSecurityService(repository.NewSecurityRepository(db), userRepository, &cfg.SecurityConfig)
+	authenticators := []service.Authenticator{service.NewLocalAuthenticator(nil)}
+	authService := service.NewAuthService(userRepository, refreshTokenRepository, mfaService, securityService, authenticators, keys, &cfg.JWTConfig, &cfg.EmailVerificationConfig, &cfg.MFAConfig)
+	verificationService := service.NewEmailVerificationService(repository.NewEmailVerificationRepository(db), userRepository, mail, &cfg.EmailVerificationConfig)
+
+	a.userRepo = userRepository
+	a.admin = service.NewUserAdminService(userRepository, userService, authService, verificationService, securityService)
+	a.imports = service.NewUserImportService(repository.NewUserImportRepository(db), userRepository, authService, securityService)
+	return nil
+}
+
+func (a *app) createAdmin(args []string) error {
+	return a.createUser("create-admin", args, constants.RoleAdmin.String())
+}
+
+func (a *app) create(args []string) error {
+	return a.createUser("create", args, "")
+}
+
+func (a *app) createUser(command string, args []string, role string) error {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	name := flags.String("name", "", "full name (required)")
+	nip := flags.String("nip", "", "12 digit NIP (required)")
+	email := flags.String("email", "", "email address (required)")
+	password := flags.String("password", "", "password; a temporary one is generated when empty")
+	roleFlag := &role
+	if role == "" {
+		roleFlag = flags.String("role", constants.RoleUser.String(), "role: user, mentor or admin")
+	}
+	flags.Parse(args)
+
+	if *name == "" || *nip == "" || *email == "" {
+		flags.Usage()
+		return errors.New("-name, -nip and -email are required")
+	}
+
+	generated := *password == ""
+	if generated {
+		token, err := util.RandomToken(12)
+		if err != nil {
+			return err
+		}
+		*password = token
+	}
+
+	req := &models.CreateUserRequest{
+		Name:          *name,
+		Nip:           *nip,
+		Email:         *email,
+		Password:      *password,
+		Role:          strings.ToLower(*roleFlag),
+		EmailVerified: true,
+	}
+	if err := validators.NewValidator().Validate(req); err != nil {
+		return err
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	user, err := a.admin.CreateUser(a.ctx, cliActorID, req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created %s %s (id %d, NIP %s)\n", user.Role, user.Name, user.ID, user.Nip)
+	if generated {
+		fmt.Printf("Temporary password: %s\n", *password)
+	}
+	return nil
+}
+
+func (a *app) list(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	query := flags.String("q", "", "search name, NIP or email")
+	role := flags.String("role", "", "filter by role")
+	status := flags.String("status", "", "filter by status: active, inactive, locked or deleted")
+	offset := flags.Int("offset", 0, "rows to skip")
+	limit := flags.Int("limit", 50, "rows to show")
+	flags.Parse(args)
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	users, total, err := a.admin.SearchUsers(a.ctx, &models.UserFilter{
+		Query:  *query,
+		Role:   *role,
+		Status: *status,
+		Offset: *offset,
+		Limit:  *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNIP\tNAME\tEMAIL\tROLE\tSTATUS\tSOURCE")
+	for _, user := range users {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", user.ID, user.Nip, user.Name, user.Email, user.Role, userStatus(user), user.AuthSource)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nShowing %d of %d users\n", len(users), total)
+	return nil
+}
+
+func (a *app) setRole(args []string) error {
+	flags := flag.NewFlagSet("set-role", flag.ExitOnError)
+	target := userFlags(flags)
+	role := flags.String("role", "", "new role: user, mentor or admin (required)")
+	flags.Parse(args)
+
+	userID, err := a.resolve(target)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.admin.ChangeRole(a.ctx, cliActorID, userID, strings.ToLower(*role))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (NIP %s) is now %s\n", user.Name, user.Nip, user.Role)
+	return nil
+}
+
+func (a *app) activate(args []string) error {
+	return a.setActive("activate", args, true)
+}
+
+func (a *app) deactivate(args []string) error {
+	return a.setActive("deactivate", args, false)
+}
+
+func (a *app) setActive(command string, args []string, active bool) error {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	target := userFlags(flags)
+	flags.Parse(args)
+
+	userID, err := a.resolve(target)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.admin.SetActive(a.ctx, cliActorID, userID, active)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (NIP %s) is now %s\n", user.Name, user.Nip, userStatus(user))
+	return nil
+}
+
+func (a *app) resetPassword(args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	target := userFlags(flags)
+	password := flags.String("password", "", "new password; a temporary one is generated when empty")
+	flags.Parse(args)
+
+	userID, err := a.resolve(target)
+	if err != nil {
+		return err
+	}
+
+	result, err := a.admin.ResetPassword(a.ctx, cliActorID, userID, &models.AdminResetPasswordRequest{Password: *password})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Password reset, all sessions of the user were ended")
+	if result.TemporaryPassword != "" {
+		fmt.Printf("Temporary password: %s\n", result.TemporaryPassword)
+	}
+	return nil
+}
+
+func (a *app) importUsers(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	path := flags.String("file", "", "CSV or XLSX file with nip, name, email and optional role columns (required)")
+	dryRun := flags.Bool("dry-run", false, "validate and report without saving")
+	deactivateMissing := flags.Bool("deactivate-missing", false, "deactivate active users not in the file")
+	flags.Parse(args)
+
+	if *path == "" {
+		flags.Usage()
+		return errors.New("-file is required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	rows, err := util.ReadSpreadsheet(file, info.Size(), info.Name(), service.UserImportMaxRows+1)
+	if errors.Is(err, util.ErrSpreadsheetTooLarge) {
+		return service.ErrImportTooLarge
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	result, err := a.imports.Import(a.ctx, cliActorID, rows, &models.UserImportOptions{
+		FileName:          info.Name(),
+		DryRun:            *dryRun,
+		DeactivateMissing: *deactivateMissing,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Report != nil {
+		for _, row := range result.Report.Rows {
+			if row.Result == constants.UserImportFailed {
+				fmt.Printf("row %d (NIP %s): %s\n", row.Row, row.Nip, strings.Join(row.Errors, "; "))
+			}
+		}
+		for _, user := range result.Report.Deactivated {
+			fmt.Printf("deactivate %s (NIP %s)\n", user.Name, user.Nip)
+		}
+	}
+
+	prefix := "Import"
+	if result.DryRun {
+		prefix = "Dry run, nothing saved"
+	}
+	fmt.Printf("%s: %d rows, %d created, %d updated, %d unchanged, %d failed, %d deactivated (import %d)\n",
+		prefix, result.Total, result.Created, result.Updated, result.Unchanged, result.Failed, result.Deactivated, result.ID)
+	return nil
+}
+
+func (a *app) createTenant(args []string) error {
+	flags := flag.NewFlagSet("create-tenant", flag.ExitOnError)
+	slug := flags.String("slug", "", "lowercase slug, also the subdomain (required)")
+	name := flags.String("name", "", "institution name (required)")
+	domain := flags.String("domain", "", "custom domain served for the tenant")
+	flags.Parse(args)
+
+	req := &models.CreateTenantRequest{Slug: *slug, Name: *name}
+	if *domain != "" {
+		req.Domain = domain
+	}
+	if err := validators.NewValidator().Validate(req); err != nil {
+		return err
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	created, err := a.tenants.CreateTenant(a.ctx, req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created tenant %s (id %d, slug %s)\n", created.Name, created.ID, created.Slug)
+	fmt.Printf("Add its first administrator with: usermgmt -tenant %s create-admin\n", created.Slug)
+	return nil
+}
+
+func (a *app) listTenants(args []string) error {
+	flags := flag.NewFlagSet("list-tenants", flag.ExitOnError)
+	flags.Parse(args)
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	tenants, err := a.tenants.ListTenants(a.ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSLUG\tNAME\tDOMAIN\tACTIVE")
+	for _, t := range tenants {
+		domain := "-"
+		if t.Domain != nil {
+			domain = *t.Domain
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\n", t.ID, t.Slug, t.Name, domain, t.IsActive)
+	}
+	return w.Flush()
+}
+
+type userTarget struct {
+	id  *uint
+	nip *string
+}
+
+func userFlags(flags *flag.FlagSet) userTarget {
+	return userTarget{
+		id:  flags.Uint("id", 0, "user ID"),
+		nip: flags.String("nip", "", "user NIP"),
+	}
+}
+
+// resolve connects and returns the ID of the user picked by -id or -nip.
+func (a *app) resolve(target userTarget) (uint, error) {
+	if *target.id == 0 && *target.nip == "" {
+		return 0, errors.New("-id or -nip is required")
+	}
+	if err := a.connect(); err != nil {
+		return 0, err
+	}
+	if *target.id != 0 {
+		return *target.id, nil
+	}
+
+	user, err := a.userRepo.GetByNip(a.ctx, *target.nip)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("no user with NIP %s", *target.nip)
+		}
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+func userStatus(user *models.UserResponse) string {
+	switch {
+	case user.DeletedAt != nil:
+		return constants.UserStatusDeleted
+	case !user.IsActive:
+		return constants.UserStatusInactive
+	default:
+		return constants.UserStatusActive
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: usermgmt [-tenant slug] <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  create-admin    Create an administrator: -name -nip -email [-password]")
+	fmt.Println("  create          Create a user: -name -nip -email [-role] [-password]")
+	fmt.Println("  list            List users: [-q] [-role] [-status] [-offset] [-limit]")
+	fmt.Println("  set-role        Change a user's role: -id|-nip -role")
+	fmt.Println("  activate        Activate a user: -id|-nip")
+	fmt.Println("  deactivate      Deactivate a user and end their sessions: -id|-nip")
+	fmt.Println("  reset-password  Set a new password: -id|-nip [-password]")
+	fmt.Println("  import          Import users from CSV or XLSX: -file [-dry-run] [-deactivate-missing]")
+	fmt.Println("  create-tenant   Create a tenant: -slug -name [-domain]")
+	fmt.Println("  list-tenants    List tenants")
+	fmt.Println()
+	fmt.Println("Users created here have a verified email address. Without -password a")
+	fmt.Println("temporary password is generated and printed once.")
+	fmt.Println("User commands act on the tenant given with -tenant, by default the")
+	fmt.Println("configured default tenant.")
+	fmt.Println("Run 'usermgmt <command> -h' for the flags of a command.")
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+func (h *APIKeyHandler) GetMyKeys(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+	return h.getKeys(c, userID)
+}
+
+func (h *APIKeyHandler) CreateMyKey(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+	return h.createKey(c, userID)
+}
+
+func (h *APIKeyHandler) RevokeMyKey(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+	return h.revokeKey(c, userID)
+}
+
+func (h *APIKeyHandler) CreateServiceAccount(c echo.Context) error {
+	var req models.CreateServiceAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	account, err := h.apiKeyService.CreateServiceAccount(c.Request().Context(), &req)
+	if err != nil {
+		return apiKeyErrorResponse(c, err, "Failed to create service account")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Service account created successfully", account)
+}
+
+func (h *APIKeyHandler) GetServiceAccounts(c echo.Context) error {
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	accounts, err := h.apiKeyService.GetServiceAccounts(c.Request().Context(), offset, limit)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to get service accounts")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Service accounts retrieved successfully", accounts)
+}
+
+func (h *APIKeyHandler) GetServiceAccountKeys(c echo.Context) error {
+	ownerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid service account ID")
+	}
+	return h.getKeys(c, uint(ownerID))
+}
+
+func (h *APIKeyHandler) CreateServiceAccountKey(c echo.Context) error {
+	ownerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid service account ID")
+	}
+	return h.createKey(c, uint(ownerID))
+}
+
+func (h *APIKeyHandler) RevokeServiceAccountKey(c echo.Context) error {
+	ownerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid service account ID")
+	}
+	return h.revokeKey(c, uint(ownerID))
+}
+
+func (h *APIKeyHandler) getKeys(c echo.Context, ownerID uint) error {
+	keys, err := h.apiKeyService.GetKeys(c.Request().Context(), ownerID)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to get API keys")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "API keys retrieved successfully", keys)
+}
+
+func (h *APIKeyHandler) createKey(c echo.Context, ownerID uint) error {
+	actorID := c.Get("user_id").(uint)
+
+	var req models.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	key, err := h.apiKeyService.CreateKey(c.Request().Context(), actorID, ownerID, &req)
+	if err != nil {
+		return apiKeyErrorResponse(c, err, "Failed to create API key")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "API key created, copy it now as it will not be shown again", key)
+}
+
+func (h *APIKeyHandler) revokeKey(c echo.Context, ownerID uint) error {
+	actorID := c.Get("user_id").(uint)
+
+	keyID, err := strconv.ParseUint(c.Param("key_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid API key ID")
+	}
+
+	if err := h.apiKeyService.RevokeKey(c.Request().Context(), actorID, ownerID, uint(keyID)); err != nil {
+		return apiKeyErrorResponse(c, err, "Failed to revoke API key")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "API key revoked successfully", nil)
+}
+
+func apiKeyErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, "User not found")
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidScope), errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrAPIKeyExpiryPassed):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, service.ErrNotServiceAccount):
+		return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrEmailTaken):
+		return util.ErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
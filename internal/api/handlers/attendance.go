@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type AttendanceHandler struct {
+	attendanceService service.AttendanceService
+}
+
+func NewAttendanceHandler(attendanceService service.AttendanceService) *AttendanceHandler {
+	return &AttendanceHandler{attendanceService: attendanceService}
+}
+
+func (h *AttendanceHandler) GetChapterSessions(c echo.Context) error {
+	chapterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid chapter ID")
+	}
+
+	sessions, err := h.attendanceService.GetChapterSessions(c.Request().Context(), uint(chapterID))
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to retrieve sessions")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+func (h *AttendanceHandler) CreateSession(c echo.Context) error {
+	chapterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid chapter ID")
+	}
+
+	var req models.CreateChapterSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	session, err := h.attendanceService.CreateSession(c.Request().Context(), uint(chapterID), &req)
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to create session")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Session created successfully", session)
+}
+
+func (h *AttendanceHandler) GetCourseSessions(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	sessions, err := h.attendanceService.GetCourseSessions(c.Request().Context(), uint(courseID))
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to retrieve sessions")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+func (h *AttendanceHandler) CreateCourseSession(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	var req models.CreateChapterSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	session, err := h.attendanceService.CreateCourseSession(c.Request().Context(), uint(courseID), &req)
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to create session")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Session created successfully", session)
+}
+
+func (h *AttendanceHandler) UpdateSession(c echo.Context) error {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+	}
+
+	var req models.UpdateChapterSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	session, err := h.attendanceService.UpdateSession(c.Request().Context(), uint(sessionID), &req)
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to update session")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Session updated successfully", session)
+}
+
+func (h *AttendanceHandler) DeleteSession(c echo.Context) error {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+	}
+
+	if err := h.attendanceService.DeleteSession(c.Request().Context(), uint(sessionID)); err != nil {
+		return attendanceErrorResponse(c, err, "Failed to delete session")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Session deleted successfully", nil)
+}
+
+func (h *AttendanceHandler) GetCheckInCode(c echo.Context) error {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+	}
+
+	code, err := h.attendanceService.GetCheckInCode(c.Request().Context(), uint(sessionID))
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to generate check-in code")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Check-in code generated successfully", code)
+}
+
+func (h *AttendanceHandler) CheckIn(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	var req models.CheckInRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	record, err := h.attendanceService.CheckIn(c.Request().Context(), userID, &req)
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to check in")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Checked in successfully", record)
+}
+
+func (h *AttendanceHandler) GetRoster(c echo.Context) error {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+	}
+
+	roster, err := h.attendanceService.GetRoster(c.Request().Context(), uint(sessionID))
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to retrieve attendance")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Attendance retrieved successfully", roster)
+}
+
+func (h *AttendanceHandler) MarkAttendance(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.MarkAttendanceRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	record, err := h.attendanceService.MarkAttendance(c.Request().Context(), actorID, uint(sessionID), uint(userID), &req)
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to mark attendance")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Attendance marked successfully", record)
+}
+
+func (h *AttendanceHandler) GetMyAttendance(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	attendance, err := h.attendanceService.GetMyAttendance(c.Request().Context(), userID, uint(courseID))
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to retrieve attendance")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Attendance retrieved successfully", attendance)
+}
+
+func (h *AttendanceHandler) GetCourseReport(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	report, err := h.attendanceService.GetCourseReport(c.Request().Context(), uint(courseID))
+	if err != nil {
+		return attendanceErrorResponse(c, err, "Failed to retrieve attendance report")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Attendance report retrieved successfully", report)
+}
+
+func attendanceErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, "Session, chapter or course not found")
+	case errors.Is(err, service.ErrInvalidCheckInCode), errors.Is(err, util.ErrInvalidHijriDate):
+		return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrCheckInClosed):
+		return util.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrSessionEndsBefore), errors.Is(err, service.ErrNotEnrolled),
+		errors.Is(err, service.ErrEnrollmentNotActive):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
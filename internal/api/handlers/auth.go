@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/bobchopperz/bahrululum/internal/api/validators"
 	"github.com/bobchopperz/bahrululum/internal/constants"
@@ -12,18 +16,26 @@ import (
 )
 
 type AuthHandler struct {
-	authService service.AuthService
-	userService service.UserService
+	authService          service.AuthService
+	userService          service.UserService
+	verificationService  service.EmailVerificationService
+	requireVerifiedLogin bool
 }
 
-func NewAuthHandler(authService service.AuthService, userService service.UserService) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, userService service.UserService, verificationService service.EmailVerificationService, requireVerifiedLogin bool) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		userService: userService,
+		authService:          authService,
+		userService:          userService,
+		verificationService:  verificationService,
+		requireVerifiedLogin: requireVerifiedLogin,
 	}
 }
 
 func (h *AuthHandler) Register(c echo.Context) error {
+	if tenant := models.TenantFromContext(c.Request().Context()); tenant != nil && !tenant.RegistrationAllowed() {
+		return util.ErrorResponse(c, http.StatusForbidden, "Self-registration is disabled")
+	}
+
 	var req models.CreateUserRequest
 
 	if err := c.Bind(&req); err != nil {
@@ -38,11 +50,25 @@ func (h *AuthHandler) Register(c echo.Context) error {
 
 	user, err := h.userService.CreateUser(c.Request().Context(), &req)
 	if err != nil {
+		if errors.Is(err, util.ErrPasswordPolicy) {
+			return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		}
 		return err
 	}
 
+	if err := h.verificationService.SendToUser(c.Request().Context(), user.ID); err != nil {
+		log.Printf("register: failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	// Accounts that cannot log in before verifying get no tokens yet
+	if h.requireVerifiedLogin {
+		return util.SuccessResponse(c, http.StatusCreated, "User registered successfully, check your inbox to verify your email address", map[string]interface{}{
+			"user": user,
+		})
+	}
+
 	// Generate tokens for the new user
-	tokens, err := h.authService.GenerateToken(user.ID)
+	tokens, err := h.authService.GenerateToken(c.Request().Context(), user.ID)
 	if err != nil {
 		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
 	}
@@ -63,10 +89,50 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return validators.ValidationErrorResponse(c, err)
 	}
 
-	tokens, err := h.authService.Login(c.Request().Context(), &req)
+	client := &models.ClientInfo{IP: c.RealIP(), UserAgent: c.Request().UserAgent()}
+
+	tokens, err := h.authService.Login(c.Request().Context(), &req, client)
 	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			return util.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		}
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+		}
 		return util.ErrorResponse(c, http.StatusUnauthorized, err.Error())
 	}
 
+	if tokens.ChallengeToken != "" {
+		message := "Two-factor authentication required"
+		if tokens.MFASetupRequired {
+			message = "Two-factor authentication must be set up before signing in"
+		}
+		return util.SuccessResponse(c, http.StatusOK, message, tokens)
+	}
+
 	return util.SuccessResponse(c, http.StatusOK, "Login successful", tokens)
 }
+
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req models.RefreshTokenRequest
+
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	tokens, err := h.authService.Refresh(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrMFARequired) {
+			return util.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+		}
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to refresh token")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", tokens)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+type BulkEnrollmentHandler struct {
+	bulkService service.BulkEnrollmentService
+}
+
+func NewBulkEnrollmentHandler(s service.BulkEnrollmentService) *BulkEnrollmentHandler {
+	return &BulkEnrollmentHandler{bulkService: s}
+}
+
+// Enroll accepts either a JSON body with a "nips" list or a multipart CSV
+// upload in the "file" field with a "nip" column.
+func (h *BulkEnrollmentHandler) Enroll(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	var req models.BulkEnrollmentRequest
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return util.ErrorResponse(c, http.StatusBadRequest, "CSV file is required")
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return util.ErrorResponse(c, http.StatusBadRequest, "Failed to read uploaded file")
+		}
+		defer file.Close()
+
+		req.Nips, err = util.ReadCSVColumn(file, "nip")
+		if err != nil {
+			return util.ErrorResponse(c, http.StatusBadRequest, "Invalid CSV file")
+		}
+		req.Async, _ = strconv.ParseBool(c.FormValue("async"))
+		if dueAt := c.FormValue("due_at"); dueAt != "" {
+			parsed, err := time.Parse(time.RFC3339, dueAt)
+			if err != nil {
+				return util.ErrorResponse(c, http.StatusBadRequest, "Invalid due_at, expected RFC3339")
+			}
+			req.DueAt = &parsed
+		}
+	} else if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if async, err := strconv.ParseBool(c.QueryParam("async")); err == nil {
+		req.Async = async
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	if req.Async || len(req.Nips) > service.BulkEnrollmentSyncLimit {
+		job, err := h.bulkService.EnrollAsync(c.Request().Context(), actorID, uint(courseID), &req)
+		if err != nil {
+			return enrollmentErrorResponse(c, err, "Failed to start bulk enrollment")
+		}
+		return util.SuccessResponse(c, http.StatusAccepted, "Bulk enrollment queued", job)
+	}
+
+	report, err := h.bulkService.Enroll(c.Request().Context(), actorID, uint(courseID), &req)
+	if err != nil {
+		return enrollmentErrorResponse(c, err, "Failed to enroll users")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Bulk enrollment completed", report)
+}
+
+func (h *BulkEnrollmentHandler) GetJob(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid job ID")
+	}
+
+	job, err := h.bulkService.GetJob(c.Request().Context(), uint(jobID))
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusNotFound, "Job not found")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Job retrieved successfully", job)
+}
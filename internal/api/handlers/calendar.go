@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type CalendarHandler struct {
+	calendarService service.CalendarService
+}
+
+func NewCalendarHandler(calendarService service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+func (h *CalendarHandler) GetFeed(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	feed, err := h.calendarService.GetFeed(c.Request().Context(), userID)
+	if err != nil {
+		return calendarErrorResponse(c, err, "Failed to retrieve calendar feed")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Calendar feed retrieved successfully", feed)
+}
+
+func (h *CalendarHandler) CreateFeed(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	feed, err := h.calendarService.CreateFeed(c.Request().Context(), userID)
+	if err != nil {
+		return calendarErrorResponse(c, err, "Failed to create calendar feed")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Calendar feed created successfully", feed)
+}
+
+func (h *CalendarHandler) DeleteFeed(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	if err := h.calendarService.DeleteFeed(c.Request().Context(), userID); err != nil {
+		return calendarErrorResponse(c, err, "Failed to delete calendar feed")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Calendar feed deleted successfully", nil)
+}
+
+// Render serves the feed to calendar clients, which authenticate with the
+// token in the URL alone.
+func (h *CalendarHandler) Render(c echo.Context) error {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	feed, err := h.calendarService.Render(c.Request().Context(), token)
+	if err != nil {
+		return calendarErrorResponse(c, err, "Failed to build calendar feed")
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, max-age=300")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
+
+func calendarErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrInvalidCalendarFeed):
+		return util.ErrorResponse(c, http.StatusNotFound, "Calendar feed not found")
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type CohortHandler struct {
+	cohortService service.CohortService
+}
+
+func NewCohortHandler(cohortService service.CohortService) *CohortHandler {
+	return &CohortHandler{cohortService: cohortService}
+}
+
+func (h *CohortHandler) GetCourseCohorts(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	cohorts, err := h.cohortService.GetCourseCohorts(c.Request().Context(), uint(courseID))
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to retrieve cohorts")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohorts retrieved successfully", cohorts)
+}
+
+func (h *CohortHandler) CreateCohort(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	var req models.CreateCohortRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	cohort, err := h.cohortService.CreateCohort(c.Request().Context(), uint(courseID), &req)
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to create cohort")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Cohort created successfully", cohort)
+}
+
+func (h *CohortHandler) GetMyCohorts(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	cohorts, err := h.cohortService.GetMyCohorts(c.Request().Context(), userID)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve cohorts")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohorts retrieved successfully", cohorts)
+}
+
+func (h *CohortHandler) GetCohort(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	cohort, err := h.cohortService.GetCohort(c.Request().Context(), actorID, uint(cohortID))
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to retrieve cohort")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort retrieved successfully", cohort)
+}
+
+func (h *CohortHandler) UpdateCohort(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	var req models.UpdateCohortRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	cohort, err := h.cohortService.UpdateCohort(c.Request().Context(), actorID, uint(cohortID), &req)
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to update cohort")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort updated successfully", cohort)
+}
+
+func (h *CohortHandler) DeleteCohort(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	if err := h.cohortService.DeleteCohort(c.Request().Context(), actorID, uint(cohortID)); err != nil {
+		return cohortErrorResponse(c, err, "Failed to delete cohort")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort deleted successfully", nil)
+}
+
+func (h *CohortHandler) SetDeadline(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	var req models.UpdateDueDateRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	cohort, err := h.cohortService.SetDeadline(c.Request().Context(), actorID, uint(cohortID), &req)
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to update cohort deadline")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort deadline updated successfully", cohort)
+}
+
+func (h *CohortHandler) AddMentor(c echo.Context) error {
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.cohortService.AddMentor(c.Request().Context(), uint(cohortID), uint(userID)); err != nil {
+		return cohortErrorResponse(c, err, "Failed to add cohort mentor")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort mentor added successfully", nil)
+}
+
+func (h *CohortHandler) RemoveMentor(c echo.Context) error {
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.cohortService.RemoveMentor(c.Request().Context(), uint(cohortID), uint(userID)); err != nil {
+		return cohortErrorResponse(c, err, "Failed to remove cohort mentor")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort mentor removed successfully", nil)
+}
+
+func (h *CohortHandler) GetMembers(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	members, err := h.cohortService.GetMembers(c.Request().Context(), actorID, uint(cohortID))
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to retrieve cohort members")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort members retrieved successfully", members)
+}
+
+func (h *CohortHandler) AddMember(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.cohortService.AddMember(c.Request().Context(), actorID, uint(cohortID), uint(userID)); err != nil {
+		return cohortErrorResponse(c, err, "Failed to add cohort member")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort member added successfully", nil)
+}
+
+func (h *CohortHandler) RemoveMember(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.cohortService.RemoveMember(c.Request().Context(), actorID, uint(cohortID), uint(userID)); err != nil {
+		return cohortErrorResponse(c, err, "Failed to remove cohort member")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort member removed successfully", nil)
+}
+
+func (h *CohortHandler) GetDashboard(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	dashboard, err := h.cohortService.GetDashboard(c.Request().Context(), actorID, uint(cohortID))
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to retrieve cohort dashboard")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Cohort dashboard retrieved successfully", dashboard)
+}
+
+func (h *CohortHandler) GetAnnouncements(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	announcements, total, err := h.cohortService.GetAnnouncements(c.Request().Context(), actorID, uint(cohortID), offset, limit)
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to retrieve announcements")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Announcements retrieved successfully", map[string]interface{}{
+		"announcements": announcements,
+		"offset":        offset,
+		"limit":         limit,
+		"count":         len(announcements),
+		"total":         total,
+	})
+}
+
+func (h *CohortHandler) PostAnnouncement(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	var req models.CreateCohortAnnouncementRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	announcement, err := h.cohortService.PostAnnouncement(c.Request().Context(), actorID, uint(cohortID), &req)
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to post announcement")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Announcement posted successfully", announcement)
+}
+
+func (h *CohortHandler) GetThreads(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	threads, total, err := h.cohortService.GetThreads(c.Request().Context(), actorID, uint(cohortID), offset, limit)
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to retrieve discussions")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Discussions retrieved successfully", map[string]interface{}{
+		"threads": threads,
+		"offset":  offset,
+		"limit":   limit,
+		"count":   len(threads),
+		"total":   total,
+	})
+}
+
+func (h *CohortHandler) CreateThread(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	var req models.CreateCohortThreadRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	thread, err := h.cohortService.CreateThread(c.Request().Context(), actorID, uint(cohortID), &req)
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to start discussion")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Discussion started successfully", thread)
+}
+
+func (h *CohortHandler) GetThread(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	threadID, err := strconv.ParseUint(c.Param("post_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid post ID")
+	}
+
+	thread, err := h.cohortService.GetThread(c.Request().Context(), actorID, uint(cohortID), uint(threadID))
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to retrieve discussion")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Discussion retrieved successfully", thread)
+}
+
+func (h *CohortHandler) Reply(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	threadID, err := strconv.ParseUint(c.Param("post_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid post ID")
+	}
+
+	var req models.CreateCohortReplyRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	reply, err := h.cohortService.Reply(c.Request().Context(), actorID, uint(cohortID), uint(threadID), &req)
+	if err != nil {
+		return cohortErrorResponse(c, err, "Failed to post reply")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Reply posted successfully", reply)
+}
+
+func (h *CohortHandler) DeletePost(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	cohortID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid cohort ID")
+	}
+
+	postID, err := strconv.ParseUint(c.Param("post_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid post ID")
+	}
+
+	if err := h.cohortService.DeletePost(c.Request().Context(), actorID, uint(cohortID), uint(postID)); err != nil {
+		return cohortErrorResponse(c, err, "Failed to delete post")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Post deleted successfully", nil)
+}
+
+func cohortErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, "Cohort, course, user or post not found")
+	case errors.Is(err, service.ErrCohortForbidden), errors.Is(err, service.ErrNotCohortMember),
+		errors.Is(err, service.ErrCohortPostForbidden):
+		return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, util.ErrInvalidHijriDate):
+		return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrCohortMemberTaken):
+		return util.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrCohortMentorRole), errors.Is(err, service.ErrNotEnrolled),
+		errors.Is(err, service.ErrEnrollmentNotActive):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/bobchopperz/bahrululum/internal/domain/service"
 	"github.com/bobchopperz/bahrululum/internal/util"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 type CourseHandler struct {
@@ -124,3 +126,49 @@ func (h *CourseHandler) DeleteCourse(c echo.Context) error {
 
 	return util.SuccessResponse(c, http.StatusOK, "Course deleted successfully", nil)
 }
+
+func (h *CourseHandler) GetPrerequisites(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	prerequisites, err := h.courseService.GetPrerequisites(c.Request().Context(), uint(courseID))
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve prerequisites")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Prerequisites retrieved successfully", prerequisites)
+}
+
+func (h *CourseHandler) SetPrerequisites(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	var req models.SetPrerequisitesRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	prerequisites, err := h.courseService.SetPrerequisites(c.Request().Context(), uint(courseID), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return util.ErrorResponse(c, http.StatusNotFound, "Course not found")
+		case errors.Is(err, service.ErrInvalidPrerequisite):
+			return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, service.ErrPrerequisiteCycle):
+			return util.ErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			return util.ErrorResponse(c, http.StatusUnprocessableEntity, "Failed to update prerequisites")
+		}
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Prerequisites updated successfully", prerequisites)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -69,6 +70,9 @@ func (h *CourseContentHandler) CreateContent(c echo.Context) error {
 	}
 
 	content, err := h.contentService.CreateContent(c.Request().Context(), &req)
+	if errors.Is(err, service.ErrHafalanTargetRequired) {
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	}
 	if err != nil {
 		return util.ErrorResponse(c, http.StatusUnprocessableEntity, "Failed to create content")
 	}
@@ -94,6 +98,9 @@ func (h *CourseContentHandler) UpdateContent(c echo.Context) error {
 	}
 
 	content, err := h.contentService.UpdateContent(c.Request().Context(), uint(contentID), &req)
+	if errors.Is(err, service.ErrHafalanTargetRequired) {
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	}
 	if err != nil {
 		return util.ErrorResponse(c, http.StatusUnprocessableEntity, "Failed to update content")
 	}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+type EmailVerificationHandler struct {
+	verificationService service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(s service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: s}
+}
+
+func (h *EmailVerificationHandler) Verify(c echo.Context) error {
+	var req models.VerifyEmailRequest
+
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	user, err := h.verificationService.Verify(c.Request().Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidVerificationToken):
+			return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrEmailTaken):
+			return util.ErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify email")
+		}
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Email verified successfully", user.ToResponse())
+}
+
+func (h *EmailVerificationHandler) Resend(c echo.Context) error {
+	var req models.ResendVerificationRequest
+
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	if err := h.verificationService.Resend(c.Request().Context(), req.Email); err != nil {
+		if errors.Is(err, service.ErrTooManyVerifyRequest) {
+			return util.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		}
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to send verification email")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "If the account exists and is unverified, a verification link has been sent", nil)
+}
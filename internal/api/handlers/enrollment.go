@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/constants"
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"github.com/bobchopperz/bahrululum/internal/domain/service"
 	"github.com/bobchopperz/bahrululum/internal/util"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 type EnrollmentHandler struct {
@@ -34,7 +38,7 @@ func (h *EnrollmentHandler) Create(c echo.Context) error {
 
 	entity, err := h.enrollmentService.Create(c.Request().Context(), userID, &req)
 	if err != nil {
-		return util.ErrorResponse(c, http.StatusUnprocessableEntity, "Something went wrong")
+		return enrollmentErrorResponse(c, err, "Something went wrong")
 	}
 
 	return util.SuccessResponse(c, http.StatusCreated, "Enrollment created successfully", entity)
@@ -59,12 +63,310 @@ func (h *EnrollmentHandler) GetEnrollment(c echo.Context) error {
 func (h *EnrollmentHandler) GetMyEnrollments(c echo.Context) error {
 	userID := c.Get("user_id").(uint)
 
-	courseIDs, err := h.enrollmentService.GetUserEnrollments(c.Request().Context(), userID)
+	status := c.QueryParam("status")
+	if status != "" && !constants.IsValidEnrollmentStatus(status) {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+	}
+
+	enrollments, err := h.enrollmentService.GetUserEnrollments(c.Request().Context(), userID, status)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve enrollments")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Enrollments retrieved successfully", map[string]interface{}{
+		"enrollments": enrollments,
+		"count":       len(enrollments),
+	})
+}
+
+func (h *EnrollmentHandler) GetMyHistory(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	events, err := h.enrollmentService.GetUserHistory(c.Request().Context(), userID)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve enrollment history")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Enrollment history retrieved successfully", map[string]interface{}{
+		"events": events,
+		"count":  len(events),
+	})
+}
+
+func (h *EnrollmentHandler) Unenroll(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	courseID, err := strconv.ParseUint(c.Param("course_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	if err := h.enrollmentService.Unenroll(c.Request().Context(), userID, uint(courseID)); err != nil {
+		return enrollmentErrorResponse(c, err, "Failed to unenroll")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unenrolled successfully", nil)
+}
+
+func (h *EnrollmentHandler) CompleteContent(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	contentID, err := strconv.ParseUint(c.Param("content_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid content ID")
+	}
+
+	entity, err := h.enrollmentService.CompleteContent(c.Request().Context(), userID, uint(contentID))
+	if err != nil {
+		return enrollmentErrorResponse(c, err, "Failed to record progress")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Progress recorded successfully", entity)
+}
+
+func (h *EnrollmentHandler) GetCourseEnrollments(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	status := c.QueryParam("status")
+	if status != "" && !constants.IsValidEnrollmentStatus(status) {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid status parameter")
+	}
+
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	enrollments, err := h.enrollmentService.GetCourseEnrollments(c.Request().Context(), uint(courseID), status, offset, limit)
 	if err != nil {
 		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve enrollments")
 	}
 
 	return util.SuccessResponse(c, http.StatusOK, "Enrollments retrieved successfully", map[string]interface{}{
-		"course_ids": courseIDs,
+		"enrollments": enrollments,
+		"offset":      offset,
+		"limit":       limit,
+		"count":       len(enrollments),
+	})
+}
+
+func (h *EnrollmentHandler) RemoveEnrollment(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.RemoveEnrollmentRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	if err := h.enrollmentService.RemoveEnrollment(c.Request().Context(), actorID, uint(userID), uint(courseID), &req); err != nil {
+		return enrollmentErrorResponse(c, err, "Failed to remove enrollment")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Enrollment removed successfully", nil)
+}
+
+func (h *EnrollmentHandler) GetPendingEnrollments(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	enrollments, err := h.enrollmentService.GetCourseEnrollments(c.Request().Context(), uint(courseID), constants.EnrollmentPending.String(), offset, limit)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve enrollment requests")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Enrollment requests retrieved successfully", map[string]interface{}{
+		"enrollments": enrollments,
+		"offset":      offset,
+		"limit":       limit,
+		"count":       len(enrollments),
+	})
+}
+
+func (h *EnrollmentHandler) ApproveEnrollment(c echo.Context) error {
+	return h.review(c, h.enrollmentService.Approve, "Enrollment approved successfully")
+}
+
+func (h *EnrollmentHandler) RejectEnrollment(c echo.Context) error {
+	return h.review(c, h.enrollmentService.Reject, "Enrollment rejected successfully")
+}
+
+func (h *EnrollmentHandler) review(c echo.Context, fn func(ctx context.Context, actorID, courseID, userID uint, req *models.ReviewEnrollmentRequest) (*models.EnrollmentResponse, error), message string) error {
+	actorID := c.Get("user_id").(uint)
+
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.ReviewEnrollmentRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	entity, err := fn(c.Request().Context(), actorID, uint(courseID), uint(userID), &req)
+	if err != nil {
+		return enrollmentErrorResponse(c, err, "Failed to review enrollment")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, message, entity)
+}
+
+func (h *EnrollmentHandler) InviteUser(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	var req models.InviteEnrollmentRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	entity, err := h.enrollmentService.Invite(c.Request().Context(), actorID, uint(courseID), &req)
+	if err != nil {
+		return enrollmentErrorResponse(c, err, "Failed to invite user")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "User invited successfully", entity)
+}
+
+func (h *EnrollmentHandler) UpdateSettings(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	var req models.UpdateEnrollmentSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	course, err := h.enrollmentService.UpdateSettings(c.Request().Context(), uint(courseID), &req)
+	if err != nil {
+		return enrollmentErrorResponse(c, err, "Failed to update enrollment settings")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Enrollment settings updated successfully", course)
+}
+
+func (h *EnrollmentHandler) UpdateDueDate(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.UpdateDueDateRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	entity, err := h.enrollmentService.UpdateDueDate(c.Request().Context(), uint(courseID), uint(userID), &req)
+	if err != nil {
+		return enrollmentErrorResponse(c, err, "Failed to update due date")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Due date updated successfully", entity)
+}
+
+func (h *EnrollmentHandler) GetOverdueEnrollments(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	enrollments, err := h.enrollmentService.GetOverdueEnrollments(c.Request().Context(), uint(courseID))
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve overdue enrollments")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Overdue enrollments retrieved successfully", map[string]interface{}{
+		"enrollments": enrollments,
+		"count":       len(enrollments),
 	})
 }
+
+func enrollmentErrorResponse(c echo.Context, err error, fallback string) error {
+	var prerequisitesErr *service.PrerequisitesError
+	if errors.As(err, &prerequisitesErr) {
+		return util.ErrorResponseWithData(c, http.StatusUnprocessableEntity, err.Error(), map[string]interface{}{
+			"missing_prerequisites": prerequisitesErr.Missing,
+		})
+	}
+
+	switch {
+	case errors.Is(err, service.ErrNotEnrolled), errors.Is(err, service.ErrContentNotPublished),
+		errors.Is(err, gorm.ErrRecordNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, util.ErrInvalidHijriDate):
+		return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrEnrollmentNotActive), errors.Is(err, service.ErrEnrollmentNotPending):
+		return util.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrEnrollmentClosed), errors.Is(err, service.ErrEnrollmentInviteOnly),
+		errors.Is(err, service.ErrContentAssessed):
+		return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, fallback)
+	}
+}
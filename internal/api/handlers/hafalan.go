@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type HafalanHandler struct {
+	hafalanService service.HafalanService
+}
+
+func NewHafalanHandler(hafalanService service.HafalanService) *HafalanHandler {
+	return &HafalanHandler{hafalanService: hafalanService}
+}
+
+func (h *HafalanHandler) GetTarget(c echo.Context) error {
+	contentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid content ID")
+	}
+
+	target, err := h.hafalanService.GetTarget(c.Request().Context(), uint(contentID))
+	if err != nil {
+		return hafalanErrorResponse(c, err, "Failed to retrieve hafalan target")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Hafalan target retrieved successfully", target)
+}
+
+func (h *HafalanHandler) SetTarget(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	contentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid content ID")
+	}
+
+	var req models.SetHafalanTargetRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	target, err := h.hafalanService.SetTarget(c.Request().Context(), actorID, uint(contentID), &req)
+	if err != nil {
+		return hafalanErrorResponse(c, err, "Failed to set hafalan target")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Hafalan target set successfully", target)
+}
+
+func (h *HafalanHandler) CreateRecord(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	contentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid content ID")
+	}
+
+	var req models.CreateHafalanRecordRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	record, err := h.hafalanService.Record(c.Request().Context(), actorID, uint(contentID), &req)
+	if err != nil {
+		return hafalanErrorResponse(c, err, "Failed to record recitation")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Recitation recorded successfully", record)
+}
+
+func (h *HafalanHandler) DeleteRecord(c echo.Context) error {
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid record ID")
+	}
+
+	if err := h.hafalanService.DeleteRecord(c.Request().Context(), uint(recordID)); err != nil {
+		return hafalanErrorResponse(c, err, "Failed to delete recitation")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Recitation deleted successfully", nil)
+}
+
+func (h *HafalanHandler) GetMyProgress(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	progress, err := h.hafalanService.GetProgress(c.Request().Context(), uint(courseID), userID)
+	if err != nil {
+		return hafalanErrorResponse(c, err, "Failed to retrieve hafalan progress")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Hafalan progress retrieved successfully", progress)
+}
+
+func (h *HafalanHandler) GetUserProgress(c echo.Context) error {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	progress, err := h.hafalanService.GetProgress(c.Request().Context(), uint(courseID), uint(userID))
+	if err != nil {
+		return hafalanErrorResponse(c, err, "Failed to retrieve hafalan progress")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Hafalan progress retrieved successfully", progress)
+}
+
+func hafalanErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, "Content, course, target or record not found")
+	case errors.Is(err, util.ErrInvalidAyahRange), errors.Is(err, util.ErrInvalidHijriDate):
+		return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotHafalanContent), errors.Is(err, service.ErrNotEnrolled),
+		errors.Is(err, service.ErrEnrollmentNotActive):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
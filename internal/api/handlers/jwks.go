@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/jwtkeys"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+type JWKSHandler struct {
+	keys *jwtkeys.KeySet
+}
+
+func NewJWKSHandler(keys *jwtkeys.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS publishes the public keys so other services can verify access
+// tokens. It is a bare JWK Set, not wrapped in the usual response envelope.
+func (h *JWKSHandler) GetJWKS(c echo.Context) error {
+	set, err := h.keys.JWKS()
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to load signing keys")
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, set)
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type LearningPathHandler struct {
+	learningPathService service.LearningPathService
+}
+
+func NewLearningPathHandler(s service.LearningPathService) *LearningPathHandler {
+	return &LearningPathHandler{learningPathService: s}
+}
+
+func (h *LearningPathHandler) GetPaths(c echo.Context) error {
+	return h.list(c, true)
+}
+
+func (h *LearningPathHandler) GetAllPaths(c echo.Context) error {
+	return h.list(c, false)
+}
+
+func (h *LearningPathHandler) list(c echo.Context, publishedOnly bool) error {
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	paths, err := h.learningPathService.GetPaths(c.Request().Context(), publishedOnly, offset, limit)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch learning paths")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Learning paths retrieved successfully", map[string]interface{}{
+		"learning_paths": paths,
+		"offset":         offset,
+		"limit":          limit,
+		"count":          len(paths),
+	})
+}
+
+func (h *LearningPathHandler) GetPath(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid learning path ID")
+	}
+
+	path, err := h.learningPathService.GetPath(c.Request().Context(), uint(id))
+	if err != nil || !path.IsPublished {
+		return util.ErrorResponse(c, http.StatusNotFound, "Learning path not found")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Learning path retrieved successfully", path)
+}
+
+func (h *LearningPathHandler) CreatePath(c echo.Context) error {
+	var req models.CreateLearningPathRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	path, err := h.learningPathService.CreatePath(c.Request().Context(), &req)
+	if err != nil {
+		return learningPathErrorResponse(c, err, "Failed to create learning path")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Learning path created successfully", path)
+}
+
+func (h *LearningPathHandler) UpdatePath(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid learning path ID")
+	}
+
+	var req models.UpdateLearningPathRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	path, err := h.learningPathService.UpdatePath(c.Request().Context(), uint(id), &req)
+	if err != nil {
+		return learningPathErrorResponse(c, err, "Failed to update learning path")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Learning path updated successfully", path)
+}
+
+func (h *LearningPathHandler) DeletePath(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid learning path ID")
+	}
+
+	if err := h.learningPathService.DeletePath(c.Request().Context(), uint(id)); err != nil {
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, "Failed to delete learning path")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Learning path deleted successfully", nil)
+}
+
+func (h *LearningPathHandler) SetCourses(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid learning path ID")
+	}
+
+	var req models.SetLearningPathCoursesRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	path, err := h.learningPathService.SetCourses(c.Request().Context(), uint(id), &req)
+	if err != nil {
+		return learningPathErrorResponse(c, err, "Failed to update learning path courses")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Learning path courses updated successfully", path)
+}
+
+func (h *LearningPathHandler) Join(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid learning path ID")
+	}
+
+	progress, err := h.learningPathService.Join(c.Request().Context(), userID, uint(id))
+	if err != nil {
+		return learningPathErrorResponse(c, err, "Failed to join learning path")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Joined learning path successfully", progress)
+}
+
+func (h *LearningPathHandler) GetProgress(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid learning path ID")
+	}
+
+	progress, err := h.learningPathService.GetProgress(c.Request().Context(), userID, uint(id))
+	if err != nil {
+		return learningPathErrorResponse(c, err, "Failed to retrieve learning path progress")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Learning path progress retrieved successfully", progress)
+}
+
+func (h *LearningPathHandler) GetMyPaths(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	paths, err := h.learningPathService.GetUserPaths(c.Request().Context(), userID)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve learning paths")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Learning paths retrieved successfully", map[string]interface{}{
+		"learning_paths": paths,
+		"count":          len(paths),
+	})
+}
+
+func learningPathErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrNotJoinedLearningPath):
+		return util.ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrLearningPathNotPublish):
+		return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidPathCourse):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, fallback)
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+type MFAHandler struct {
+	mfaService  service.MFAService
+	authService service.AuthService
+}
+
+func NewMFAHandler(mfaService service.MFAService, authService service.AuthService) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		authService: authService,
+	}
+}
+
+func (h *MFAHandler) Setup(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	setup, err := h.mfaService.Setup(c.Request().Context(), userID)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to start two-factor setup")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Scan the provisioning URI with your authenticator app, then confirm a code", setup)
+}
+
+func (h *MFAHandler) Confirm(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	var req models.ConfirmTOTPRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	codes, err := h.mfaService.Confirm(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Two-factor authentication enabled, store your recovery codes safely", &models.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func (h *MFAHandler) Disable(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	var req models.DisableTOTPRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	if err := h.mfaService.Disable(c.Request().Context(), userID, &req); err != nil {
+		return mfaErrorResponse(c, err, "Failed to disable two-factor authentication")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	var req models.ConfirmTOTPRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to regenerate recovery codes")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Recovery codes regenerated", &models.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func (h *MFAHandler) Verify(c echo.Context) error {
+	var req models.MFAVerifyRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	tokens, err := h.authService.VerifyMFA(c.Request().Context(), &req)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to verify authentication code")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Login successful", tokens)
+}
+
+func (h *MFAHandler) Enroll(c echo.Context) error {
+	var req models.MFAEnrollRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	setup, err := h.authService.BeginMFAEnrollment(c.Request().Context(), req.ChallengeToken)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to start two-factor setup")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Scan the provisioning URI with your authenticator app, then confirm a code", setup)
+}
+
+func (h *MFAHandler) EnrollConfirm(c echo.Context) error {
+	var req models.MFAEnrollConfirmRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	enrollment, err := h.authService.ConfirmMFAEnrollment(c.Request().Context(), &req)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Two-factor authentication enabled, store your recovery codes safely", enrollment)
+}
+
+func mfaErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidChallenge), errors.Is(err, service.ErrInvalidMFACode):
+		return util.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrInvalidCurrentPassword):
+		return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrTooManyMFAAttempts):
+		return util.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrMFARequired):
+		return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrMFAAlreadyEnabled), errors.Is(err, service.ErrMFANotEnabled), errors.Is(err, service.ErrMFASetupMissing):
+		return util.ErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
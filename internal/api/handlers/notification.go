@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(s service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: s}
+}
+
+func (h *NotificationHandler) GetNotifications(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	unreadOnly, _ := strconv.ParseBool(c.QueryParam("unread"))
+
+	notifications, err := h.notificationService.GetUserNotifications(c.Request().Context(), userID, unreadOnly, offset, limit)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve notifications")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Notifications retrieved successfully", map[string]interface{}{
+		"notifications": notifications,
+		"offset":        offset,
+		"limit":         limit,
+		"count":         len(notifications),
+	})
+}
+
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid notification ID")
+	}
+
+	if err := h.notificationService.MarkRead(c.Request().Context(), userID, uint(id)); err != nil {
+		return util.ErrorResponse(c, http.StatusNotFound, "Notification not found")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Notification marked as read", nil)
+}
+
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	if err := h.notificationService.MarkAllRead(c.Request().Context(), userID); err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to mark notifications as read")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Notifications marked as read", nil)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+// oidcStateCookie holds the state of the flow the browser started. The
+// callback must present the same state in the cookie and the query, so a
+// callback URL made in another browser cannot sign the victim in to the
+// attacker's account or link the attacker's identity to the victim's.
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+	frontendURL string
+}
+
+func NewOIDCHandler(oidcService service.OIDCService, frontendURL string) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		frontendURL: frontendURL,
+	}
+}
+
+// Login redirects the browser to the identity provider. API clients that
+// handle the redirect themselves can pass ?response=json.
+func (h *OIDCHandler) Login(c echo.Context) error {
+	authURL, state, err := h.oidcService.AuthorizationURL(c.Request().Context(), nil)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadGateway, "Failed to start single sign-on")
+	}
+	setOIDCStateCookie(c, state)
+
+	if c.QueryParam("response") == "json" {
+		return util.SuccessResponse(c, http.StatusOK, "Redirect to the authorization URL to sign in", &models.OIDCAuthorizationResponse{
+			AuthorizationURL: authURL,
+		})
+	}
+
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// Link starts linking the provider account to the signed-in user. It always
+// answers with JSON because the request carries a bearer token; browser
+// clients must send it with credentials so the state cookie is stored.
+func (h *OIDCHandler) Link(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	authURL, state, err := h.oidcService.AuthorizationURL(c.Request().Context(), &userID)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadGateway, "Failed to start account linking")
+	}
+	setOIDCStateCookie(c, state)
+
+	return util.SuccessResponse(c, http.StatusOK, "Redirect to the authorization URL to link your account", &models.OIDCAuthorizationResponse{
+		AuthorizationURL: authURL,
+	})
+}
+
+// Callback completes the provider redirect. With a frontend URL configured
+// the result is handed over in the URL fragment, which browsers do not send
+// to servers; otherwise it is returned as JSON.
+func (h *OIDCHandler) Callback(c echo.Context) error {
+	var req models.OIDCCallbackRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid callback parameters")
+	}
+
+	client := &models.ClientInfo{IP: c.RealIP(), UserAgent: c.Request().UserAgent()}
+
+	cookie, err := c.Cookie(oidcStateCookie)
+	clearOIDCStateCookie(c)
+	var result *models.OIDCCallbackResponse
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(req.State)) != 1 {
+		err = service.ErrInvalidOIDCState
+	} else {
+		result, err = h.oidcService.Callback(c.Request().Context(), &req, client)
+	}
+	if err != nil {
+		if h.frontendURL != "" {
+			return h.redirectFragment(c, url.Values{"error": {err.Error()}})
+		}
+		return oidcErrorResponse(c, err)
+	}
+
+	if h.frontendURL != "" {
+		return h.redirectFragment(c, callbackFragment(result))
+	}
+
+	switch {
+	case result.Identity != nil:
+		return util.SuccessResponse(c, http.StatusOK, "Account linked successfully", result.Identity)
+	case result.Tokens.ChallengeToken != "":
+		return util.SuccessResponse(c, http.StatusOK, "Two-factor authentication required", result.Tokens)
+	default:
+		return util.SuccessResponse(c, http.StatusOK, "Login successful", result.Tokens)
+	}
+}
+
+func (h *OIDCHandler) GetIdentities(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	identities, err := h.oidcService.GetIdentities(c.Request().Context(), userID)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to get linked identities")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Linked identities retrieved successfully", identities)
+}
+
+func (h *OIDCHandler) Unlink(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	if err := h.oidcService.Unlink(c.Request().Context(), userID); err != nil {
+		return oidcErrorResponse(c, err)
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Identity unlinked successfully", nil)
+}
+
+func setOIDCStateCookie(c echo.Context, state string) {
+	c.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/auth/oidc",
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearOIDCStateCookie(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/api/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *OIDCHandler) redirectFragment(c echo.Context, values url.Values) error {
+	return c.Redirect(http.StatusFound, h.frontendURL+"#"+values.Encode())
+}
+
+func callbackFragment(result *models.OIDCCallbackResponse) url.Values {
+	values := url.Values{}
+	if result.Identity != nil {
+		values.Set("linked", result.Identity.Provider)
+		return values
+	}
+
+	tokens := result.Tokens
+	values.Set("token_type", tokens.TokenType)
+	values.Set("expires_at", strconv.FormatInt(tokens.ExpiresAt, 10))
+	if tokens.ChallengeToken != "" {
+		values.Set("challenge_token", tokens.ChallengeToken)
+		values.Set("mfa_required", strconv.FormatBool(tokens.MFARequired))
+		values.Set("mfa_setup_required", strconv.FormatBool(tokens.MFASetupRequired))
+		return values
+	}
+	values.Set("access_token", tokens.AccessToken)
+	values.Set("refresh_token", tokens.RefreshToken)
+	return values
+}
+
+func oidcErrorResponse(c echo.Context, err error) error {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		return util.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrInvalidOIDCState), errors.Is(err, service.ErrOIDCProviderError):
+		return util.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrOIDCAccountNotFound), errors.Is(err, service.ErrAccountInactive),
+		errors.Is(err, service.ErrAccountLocked), errors.Is(err, service.ErrEmailNotVerified),
+		errors.Is(err, service.ErrTenantInactive):
+		return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrOIDCMissingClaim):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, service.ErrOIDCIdentityLinked), errors.Is(err, service.ErrOIDCProviderLinked), errors.Is(err, service.ErrEmailTaken):
+		return util.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrOIDCIdentityNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Single sign-on failed")
+	}
+}
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type OrgUnitHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+func NewOrgUnitHandler(orgUnitService service.OrgUnitService) *OrgUnitHandler {
+	return &OrgUnitHandler{orgUnitService: orgUnitService}
+}
+
+func (h *OrgUnitHandler) GetTree(c echo.Context) error {
+	units, err := h.orgUnitService.GetTree(c.Request().Context())
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve units")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Units retrieved successfully", units)
+}
+
+func (h *OrgUnitHandler) CreateUnit(c echo.Context) error {
+	var req models.CreateOrgUnitRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	unit, err := h.orgUnitService.CreateUnit(c.Request().Context(), &req)
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to create unit")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "Unit created successfully", unit)
+}
+
+func (h *OrgUnitHandler) GetUnit(c echo.Context) error {
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	unit, err := h.orgUnitService.GetUnit(c.Request().Context(), uint(unitID))
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to retrieve unit")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit retrieved successfully", unit)
+}
+
+func (h *OrgUnitHandler) UpdateUnit(c echo.Context) error {
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	var req models.UpdateOrgUnitRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	unit, err := h.orgUnitService.UpdateUnit(c.Request().Context(), uint(unitID), &req)
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to update unit")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit updated successfully", unit)
+}
+
+func (h *OrgUnitHandler) MoveUnit(c echo.Context) error {
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	var req models.MoveOrgUnitRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	unit, err := h.orgUnitService.MoveUnit(c.Request().Context(), uint(unitID), req.ParentID)
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to move unit")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit moved successfully", unit)
+}
+
+func (h *OrgUnitHandler) DeleteUnit(c echo.Context) error {
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	if err := h.orgUnitService.DeleteUnit(c.Request().Context(), uint(unitID)); err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to delete unit")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit deleted successfully", nil)
+}
+
+func (h *OrgUnitHandler) GetAdmins(c echo.Context) error {
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	admins, err := h.orgUnitService.GetAdmins(c.Request().Context(), uint(unitID))
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to retrieve unit admins")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit admins retrieved successfully", admins)
+}
+
+func (h *OrgUnitHandler) AddAdmin(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.orgUnitService.AddAdmin(c.Request().Context(), actorID, uint(unitID), uint(userID)); err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to add unit admin")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit admin added successfully", nil)
+}
+
+func (h *OrgUnitHandler) RemoveAdmin(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.orgUnitService.RemoveAdmin(c.Request().Context(), actorID, uint(unitID), uint(userID)); err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to remove unit admin")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit admin removed successfully", nil)
+}
+
+func (h *OrgUnitHandler) GetManagedUnits(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	units, err := h.orgUnitService.GetManagedUnits(c.Request().Context(), actorID)
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to retrieve managed units")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Managed units retrieved successfully", units)
+}
+
+func (h *OrgUnitHandler) GetMembers(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	members, total, err := h.orgUnitService.GetMembers(c.Request().Context(), actorID, uint(unitID), offset, limit)
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to retrieve unit members")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit members retrieved successfully", map[string]interface{}{
+		"members": members,
+		"offset":  offset,
+		"limit":   limit,
+		"count":   len(members),
+		"total":   total,
+	})
+}
+
+func (h *OrgUnitHandler) AddMember(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	user, err := h.orgUnitService.AddMember(c.Request().Context(), actorID, uint(unitID), uint(userID))
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to add unit member")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit member added successfully", user)
+}
+
+func (h *OrgUnitHandler) RemoveMember(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.orgUnitService.RemoveMember(c.Request().Context(), actorID, uint(unitID), uint(userID)); err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to remove unit member")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit member removed successfully", nil)
+}
+
+func (h *OrgUnitHandler) GetCourses(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	courses, err := h.orgUnitService.GetCourses(c.Request().Context(), actorID, uint(unitID))
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to retrieve mandatory courses")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Mandatory courses retrieved successfully", courses)
+}
+
+func (h *OrgUnitHandler) AssignCourse(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	var req models.AssignUnitCourseRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	result, err := h.orgUnitService.AssignCourse(c.Request().Context(), actorID, uint(unitID), &req)
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to assign course")
+	}
+
+	if result.Job != nil {
+		return util.SuccessResponse(c, http.StatusAccepted, "Course assigned, members are being enrolled", result)
+	}
+	return util.SuccessResponse(c, http.StatusCreated, "Course assigned successfully", result)
+}
+
+func (h *OrgUnitHandler) UnassignCourse(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	courseID, err := strconv.ParseUint(c.Param("course_id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid course ID")
+	}
+
+	if err := h.orgUnitService.UnassignCourse(c.Request().Context(), actorID, uint(unitID), uint(courseID)); err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to unassign course")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Course unassigned successfully", nil)
+}
+
+func (h *OrgUnitHandler) GetReport(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid unit ID")
+	}
+
+	report, err := h.orgUnitService.GetReport(c.Request().Context(), actorID, uint(unitID))
+	if err != nil {
+		return orgUnitErrorResponse(c, err, "Failed to build unit report")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Unit report retrieved successfully", report)
+}
+
+func orgUnitErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, "Unit, user or course not found")
+	case errors.Is(err, service.ErrOrgUnitForbidden), errors.Is(err, service.ErrOrgUnitOutsideUser),
+		errors.Is(err, service.ErrOrgUnitRestricted):
+		return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrOrgUnitCodeTaken), errors.Is(err, service.ErrOrgUnitCourseAssigned),
+		errors.Is(err, service.ErrOrgUnitNotEmpty):
+		return util.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrOrgUnitCycle), errors.Is(err, service.ErrNotUnitMember),
+		errors.Is(err, service.ErrServiceAccountRole):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
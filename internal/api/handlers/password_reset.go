@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService service.PasswordResetService
+}
+
+func NewPasswordResetHandler(s service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{passwordResetService: s}
+}
+
+func (h *PasswordResetHandler) Forgot(c echo.Context) error {
+	var req models.ForgotPasswordRequest
+
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	if err := h.passwordResetService.Forgot(c.Request().Context(), &req); err != nil {
+		if errors.Is(err, service.ErrTooManyResetRequest) {
+			return util.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		}
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to process password reset request")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "If the account exists, a password reset link has been sent", nil)
+}
+
+func (h *PasswordResetHandler) Reset(c echo.Context) error {
+	var req models.ResetPasswordRequest
+
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	if err := h.passwordResetService.Reset(c.Request().Context(), &req); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidResetToken):
+			return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, util.ErrPasswordPolicy):
+			return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		}
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to reset password")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Password reset successfully", nil)
+}
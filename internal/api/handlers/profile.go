@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+type ProfileHandler struct {
+	profileService service.ProfileService
+	authService    service.AuthService
+}
+
+func NewProfileHandler(profileService service.ProfileService, authService service.AuthService) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		authService:    authService,
+	}
+}
+
+func (h *ProfileHandler) GetProfile(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	profile, err := h.profileService.GetProfile(c.Request().Context(), userID)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusNotFound, "User not found")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Profile retrieved successfully", profile)
+}
+
+func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	var req models.UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	profile, err := h.profileService.UpdateProfile(c.Request().Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailTaken) {
+			return util.ErrorResponse(c, http.StatusConflict, err.Error())
+		}
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, "Failed to update profile")
+	}
+
+	message := "Profile updated successfully"
+	if profile.PendingEmail != nil && req.Email != nil {
+		message = "Profile updated successfully, check your inbox to confirm the new email address"
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, message, profile)
+}
+
+// ChangePassword updates the password and returns a fresh token pair, since
+// all previously issued refresh tokens are revoked.
+func (h *ProfileHandler) ChangePassword(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	var req models.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	if err := h.profileService.ChangePassword(c.Request().Context(), userID, &req); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCurrentPassword):
+			return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, util.ErrPasswordPolicy):
+			return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		default:
+			return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to change password")
+		}
+	}
+
+	tokens, err := h.authService.GenerateToken(c.Request().Context(), userID)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Password changed successfully", tokens)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type SecurityHandler struct {
+	securityService service.SecurityService
+}
+
+func NewSecurityHandler(s service.SecurityService) *SecurityHandler {
+	return &SecurityHandler{securityService: s}
+}
+
+func (h *SecurityHandler) UnlockUser(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.securityService.Unlock(c.Request().Context(), actorID, uint(userID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return util.ErrorResponse(c, http.StatusNotFound, "User not found")
+		}
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to unlock user")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "User unlocked successfully", nil)
+}
+
+func (h *SecurityHandler) GetEvents(c echo.Context) error {
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := &models.SecurityEventFilter{
+		Type:   c.QueryParam("type"),
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	if userIDStr := c.QueryParam("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil {
+			return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		}
+		id := uint(userID)
+		filter.UserID = &id
+	}
+
+	events, err := h.securityService.GetEvents(c.Request().Context(), filter)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve security events")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Security events retrieved successfully", map[string]interface{}{
+		"events": events,
+		"offset": offset,
+		"limit":  limit,
+		"count":  len(events),
+	})
+}
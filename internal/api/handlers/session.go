@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+func (h *SessionHandler) GetSessions(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	sessions, err := h.sessionService.GetSessions(c.Request().Context(), userID, currentSessionID(c))
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to get sessions")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+func (h *SessionHandler) Revoke(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+	}
+
+	if err := h.sessionService.Revoke(c.Request().Context(), userID, uint(sessionID)); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			return util.ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke session")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Session revoked successfully", nil)
+}
+
+// RevokeAll logs the user out everywhere. With ?keep_current=true the
+// session making the request stays signed in.
+func (h *SessionHandler) RevokeAll(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	var keepID uint
+	if c.QueryParam("keep_current") == "true" {
+		keepID = currentSessionID(c)
+	}
+
+	if err := h.sessionService.RevokeAll(c.Request().Context(), userID, keepID); err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke sessions")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Sessions revoked successfully", nil)
+}
+
+// Logout ends the session the access token belongs to.
+func (h *SessionHandler) Logout(c echo.Context) error {
+	userID := c.Get("user_id").(uint)
+
+	sessionID := currentSessionID(c)
+	if sessionID == 0 {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Token is not bound to a session, sign in again")
+	}
+
+	if err := h.sessionService.Revoke(c.Request().Context(), userID, sessionID); err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to log out")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+func (h *SessionHandler) GetUserSessions(c echo.Context) error {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	sessions, err := h.sessionService.GetSessions(c.Request().Context(), uint(userID), 0)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to get sessions")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+func (h *SessionHandler) ForceLogout(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.sessionService.ForceLogout(c.Request().Context(), actorID, uint(userID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return util.ErrorResponse(c, http.StatusNotFound, "User not found")
+		}
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to log out user")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "User logged out of all sessions", nil)
+}
+
+func currentSessionID(c echo.Context) uint {
+	if claims, ok := c.Get("user_claims").(*service.Claims); ok {
+		return claims.SessionID
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+type TenantHandler struct {
+	tenantService service.TenantService
+}
+
+func NewTenantHandler(tenantService service.TenantService) *TenantHandler {
+	return &TenantHandler{tenantService: tenantService}
+}
+
+// GetBranding returns the public branding of the tenant the request was
+// resolved to.
+func (h *TenantHandler) GetBranding(c echo.Context) error {
+	tenant := models.TenantFromContext(c.Request().Context())
+	if tenant == nil {
+		return util.ErrorResponse(c, http.StatusNotFound, service.ErrUnknownTenant.Error())
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Tenant retrieved successfully", tenant.ToBrandingResponse())
+}
+
+func (h *TenantHandler) GetTenant(c echo.Context) error {
+	tenant := models.TenantFromContext(c.Request().Context())
+	if tenant == nil {
+		return util.ErrorResponse(c, http.StatusNotFound, service.ErrUnknownTenant.Error())
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Tenant retrieved successfully", tenant.ToResponse())
+}
+
+func (h *TenantHandler) UpdateBranding(c echo.Context) error {
+	var req models.UpdateTenantBrandingRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	tenant, err := h.tenantService.UpdateBranding(c.Request().Context(), &req)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to update tenant")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Tenant updated successfully", tenant)
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/api/validators"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type UserAdminHandler struct {
+	userAdminService service.UserAdminService
+}
+
+func NewUserAdminHandler(userAdminService service.UserAdminService) *UserAdminHandler {
+	return &UserAdminHandler{userAdminService: userAdminService}
+}
+
+func (h *UserAdminHandler) SearchUsers(c echo.Context) error {
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := &models.UserFilter{
+		Query:  c.QueryParam("q"),
+		Name:   c.QueryParam("name"),
+		Nip:    c.QueryParam("nip"),
+		Email:  c.QueryParam("email"),
+		Role:   c.QueryParam("role"),
+		Status: c.QueryParam("status"),
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	users, total, err := h.userAdminService.SearchUsers(c.Request().Context(), filter)
+	if err != nil {
+		return userAdminErrorResponse(c, err, "Failed to search users")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Users retrieved successfully", map[string]interface{}{
+		"users":  users,
+		"offset": offset,
+		"limit":  limit,
+		"count":  len(users),
+		"total":  total,
+	})
+}
+
+func (h *UserAdminHandler) CreateUser(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	var req models.CreateUserRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	user, err := h.userAdminService.CreateUser(c.Request().Context(), actorID, &req)
+	if err != nil {
+		return userAdminErrorResponse(c, err, "Failed to create user")
+	}
+
+	return util.SuccessResponse(c, http.StatusCreated, "User created successfully", user)
+}
+
+func (h *UserAdminHandler) UpdateUser(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.AdminUpdateUserRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	user, err := h.userAdminService.UpdateUser(c.Request().Context(), actorID, uint(userID), &req)
+	if err != nil {
+		return userAdminErrorResponse(c, err, "Failed to update user")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "User updated successfully", user)
+}
+
+func (h *UserAdminHandler) ChangeRole(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	user, err := h.userAdminService.ChangeRole(c.Request().Context(), actorID, uint(userID), req.Role)
+	if err != nil {
+		return userAdminErrorResponse(c, err, "Failed to change role")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Role changed successfully", user)
+}
+
+func (h *UserAdminHandler) SetStatus(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.UpdateUserStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validators.ValidationErrorResponse(c, err)
+	}
+
+	user, err := h.userAdminService.SetActive(c.Request().Context(), actorID, uint(userID), *req.IsActive)
+	if err != nil {
+		return userAdminErrorResponse(c, err, "Failed to update user status")
+	}
+
+	message := "User deactivated successfully"
+	if user.IsActive {
+		message = "User activated successfully"
+	}
+	return util.SuccessResponse(c, http.StatusOK, message, user)
+}
+
+func (h *UserAdminHandler) ResetPassword(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.AdminResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	result, err := h.userAdminService.ResetPassword(c.Request().Context(), actorID, uint(userID), &req)
+	if err != nil {
+		return userAdminErrorResponse(c, err, "Failed to reset password")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "Password reset successfully", result)
+}
+
+func (h *UserAdminHandler) DeleteUser(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.userAdminService.DeleteUser(c.Request().Context(), actorID, uint(userID)); err != nil {
+		return userAdminErrorResponse(c, err, "Failed to delete user")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "User deleted successfully", nil)
+}
+
+func (h *UserAdminHandler) RestoreUser(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	user, err := h.userAdminService.RestoreUser(c.Request().Context(), actorID, uint(userID))
+	if err != nil {
+		return userAdminErrorResponse(c, err, "Failed to restore user")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "User restored successfully", user)
+}
+
+func userAdminErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, "User not found")
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrInvalidUserStatus), errors.Is(err, util.ErrPasswordPolicy):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, service.ErrNipTaken), errors.Is(err, service.ErrEmailTaken), errors.Is(err, service.ErrUserNotDeleted),
+		errors.Is(err, service.ErrLastAdmin), errors.Is(err, service.ErrExternalPassword), errors.Is(err, service.ErrServiceAccountRole):
+		return util.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrCannotModifySelf):
+		return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type UserImportHandler struct {
+	importService service.UserImportService
+}
+
+func NewUserImportHandler(importService service.UserImportService) *UserImportHandler {
+	return &UserImportHandler{importService: importService}
+}
+
+// Import accepts a multipart upload with a CSV or XLSX roster in the "file"
+// field and optional "dry_run" and "deactivate_missing" flags.
+func (h *UserImportHandler) Import(c echo.Context) error {
+	actorID := c.Get("user_id").(uint)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "CSV or XLSX file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Failed to read uploaded file")
+	}
+	defer file.Close()
+
+	// One header row plus the largest roster accepted.
+	rows, err := util.ReadSpreadsheet(file, fileHeader.Size, fileHeader.Filename, service.UserImportMaxRows+1)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrUnsupportedSpreadsheet):
+			return util.ErrorResponse(c, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, util.ErrSpreadsheetTooLarge):
+			return userImportErrorResponse(c, service.ErrImportTooLarge, "Failed to import users")
+		case errors.Is(err, util.ErrSpreadsheetTooWide):
+			return util.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		}
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid spreadsheet file")
+	}
+
+	opts := &models.UserImportOptions{FileName: fileHeader.Filename}
+	opts.DryRun, _ = strconv.ParseBool(c.FormValue("dry_run"))
+	opts.DeactivateMissing, _ = strconv.ParseBool(c.FormValue("deactivate_missing"))
+	opts.VerifyEmails, _ = strconv.ParseBool(c.FormValue("verify_emails"))
+
+	result, err := h.importService.Import(c.Request().Context(), actorID, rows, opts)
+	if err != nil {
+		return userImportErrorResponse(c, err, "Failed to import users")
+	}
+
+	message := "User import completed"
+	if opts.DryRun {
+		message = "User import preview completed, no changes were saved"
+	}
+	return util.SuccessResponse(c, http.StatusOK, message, result)
+}
+
+func (h *UserImportHandler) GetImports(c echo.Context) error {
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	imports, err := h.importService.GetImports(c.Request().Context(), offset, limit)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve user imports")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "User imports retrieved successfully", imports)
+}
+
+func (h *UserImportHandler) GetImport(c echo.Context) error {
+	importID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid import ID")
+	}
+
+	result, err := h.importService.GetImport(c.Request().Context(), uint(importID))
+	if err != nil {
+		return userImportErrorResponse(c, err, "Failed to retrieve user import")
+	}
+
+	return util.SuccessResponse(c, http.StatusOK, "User import retrieved successfully", result)
+}
+
+// ErrorReport downloads the failed rows of an import as a CSV file.
+func (h *UserImportHandler) ErrorReport(c echo.Context) error {
+	importID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return util.ErrorResponse(c, http.StatusBadRequest, "Invalid import ID")
+	}
+
+	report, err := h.importService.ErrorReport(c.Request().Context(), uint(importID))
+	if err != nil {
+		return userImportErrorResponse(c, err, "Failed to build error report")
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"user-import-%d-errors.csv\"", importID))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", report)
+}
+
+func userImportErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return util.ErrorResponse(c, http.StatusNotFound, "User import not found")
+	case errors.Is(err, service.ErrImportMissingColumns), errors.Is(err, service.ErrImportEmpty), errors.Is(err, service.ErrImportTooLarge):
+		return util.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		return util.ErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
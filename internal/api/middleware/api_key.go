@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+// JWTOrAPIKeyAuth accepts either a bearer access token or an X-API-Key
+// header carrying scope. API keys are only honoured on routes that use this
+// middleware; everywhere else JWTAuth rejects them.
+func JWTOrAPIKeyAuth(authService service.AuthService, apiKeyService service.APIKeyService, scope string) echo.MiddlewareFunc {
+	jwtAuth := JWTAuth(authService)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		bearer := jwtAuth(next)
+
+		return func(c echo.Context) error {
+			rawKey := c.Request().Header.Get("X-API-Key")
+			if rawKey == "" {
+				return bearer(c)
+			}
+
+			key, err := apiKeyService.Authenticate(c.Request().Context(), rawKey, scope)
+			if err != nil {
+				switch {
+				case errors.Is(err, service.ErrAPIKeyScope):
+					return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+				case errors.Is(err, service.ErrInvalidAPIKey):
+					return util.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+				default:
+					return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to authenticate API key")
+				}
+			}
+
+			c.Set("user_id", key.UserID)
+			c.Set("api_key", key)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Calendar lets a request choose whether dates in its response carry Hijri
+// equivalents with ?calendar=hijri or ?calendar=gregorian, overriding the
+// tenant's default. It must run after Tenant.
+func Calendar() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.QueryParam("calendar") {
+			case "hijri":
+				c.SetRequest(c.Request().WithContext(models.ContextWithHijri(c.Request().Context(), true)))
+			case "gregorian":
+				c.SetRequest(c.Request().WithContext(models.ContextWithHijri(c.Request().Context(), false)))
+			}
+			return next(c)
+		}
+	}
+}
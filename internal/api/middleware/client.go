@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/labstack/echo/v4"
+)
+
+// IPExtractor decides how c.RealIP finds the client address. Without trusted
+// proxies the connection's address is used and forwarding headers are
+// ignored, since any client could set them. With trusted proxies the address
+// is taken from X-Forwarded-For, skipping only hops within those ranges.
+func IPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// ClientInfo stores the client address and user agent in the request context
+// for session tracking and audit logging.
+func ClientInfo() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			client := &models.ClientInfo{IP: c.RealIP(), UserAgent: c.Request().UserAgent()}
+			ctx := models.ContextWithClientInfo(c.Request().Context(), client)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -5,11 +5,20 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func CORS() echo.MiddlewareFunc {
+// CORS allows the standard headers plus any extra ones the API reads, such
+// as the tenant header.
+func CORS(extraHeaders ...string) echo.MiddlewareFunc {
+	headers := []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization}
+	for _, header := range extraHeaders {
+		if header != "" {
+			headers = append(headers, header)
+		}
+	}
+
 	return middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowHeaders:     headers,
 		AllowCredentials: true,
 	})
 }
@@ -27,7 +27,7 @@ func JWTAuth(authService service.AuthService) echo.MiddlewareFunc {
 				return util.ErrorResponse(c, http.StatusUnauthorized, "Missing token")
 			}
 
-			claims, err := authService.ValidateToken(tokenString)
+			claims, err := authService.ValidateToken(c.Request().Context(), tokenString)
 			if err != nil {
 				return util.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token")
 			}
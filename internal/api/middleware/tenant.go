@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+// Tenant resolves the request's tenant and scopes the request context to it,
+// which in turn scopes every repository call made while handling it. It must
+// run before any middleware that touches the database.
+func Tenant(tenantService service.TenantService, cfg *config.TenantConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var header string
+			if cfg.Header != "" {
+				header = c.Request().Header.Get(cfg.Header)
+			}
+
+			t, err := tenantService.Resolve(c.Request().Context(), header, c.Request().Host)
+			if err != nil {
+				switch {
+				case errors.Is(err, service.ErrUnknownTenant):
+					return util.ErrorResponse(c, http.StatusNotFound, err.Error())
+				case errors.Is(err, service.ErrTenantInactive):
+					return util.ErrorResponse(c, http.StatusForbidden, err.Error())
+				default:
+					return util.ErrorResponse(c, http.StatusInternalServerError, "Failed to resolve tenant")
+				}
+			}
+
+			ctx := tenant.WithID(c.Request().Context(), t.ID)
+			ctx = models.ContextWithTenant(ctx, t)
+			c.SetRequest(c.Request().WithContext(ctx))
+			c.Set("tenant", t)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+// RequireVerifiedEmail rejects users whose email address is not verified yet.
+// It must run after JWTAuth.
+func RequireVerifiedEmail(userService service.UserService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("user_id").(uint)
+			if !ok {
+				return util.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+			}
+
+			user, err := userService.GetUser(c.Request().Context(), userID)
+			if err != nil {
+				return util.ErrorResponse(c, http.StatusUnauthorized, "User not found")
+			}
+
+			if user.EmailVerifiedAt == nil {
+				return util.ErrorResponse(c, http.StatusForbidden, service.ErrEmailNotVerified.Error())
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupAPIKeyRoutes(e *echo.Echo, apiKeyService service.APIKeyService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewAPIKeyHandler(apiKeyService)
+
+	me := e.Group("/api/me/api-keys")
+	me.Use(middleware.JWTAuth(authService))
+
+	me.GET("", h.GetMyKeys)
+	me.POST("", h.CreateMyKey)
+	me.DELETE("/:key_id", h.RevokeMyKey)
+
+	admin := e.Group("/api/admin/service-accounts")
+	admin.Use(middleware.JWTAuth(authService))
+	admin.Use(middleware.RequireAdmin(userService))
+
+	admin.GET("", h.GetServiceAccounts)
+	admin.POST("", h.CreateServiceAccount)
+	admin.GET("/:id/api-keys", h.GetServiceAccountKeys)
+	admin.POST("/:id/api-keys", h.CreateServiceAccountKey)
+	admin.DELETE("/:id/api-keys/:key_id", h.RevokeServiceAccountKey)
+}
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupAttendanceRoutes(e *echo.Echo, attendanceService service.AttendanceService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewAttendanceHandler(attendanceService)
+
+	chapters := e.Group("/api/chapters/:id/sessions")
+	chapters.Use(middleware.JWTAuth(authService))
+
+	chapters.GET("", h.GetChapterSessions)
+	chapters.POST("", h.CreateSession, middleware.RequireMentorOrAdmin(userService))
+
+	courseSessions := e.Group("/api/courses/:id/sessions")
+	courseSessions.Use(middleware.JWTAuth(authService))
+
+	courseSessions.GET("", h.GetCourseSessions)
+	courseSessions.POST("", h.CreateCourseSession, middleware.RequireMentorOrAdmin(userService))
+
+	sessions := e.Group("/api/sessions")
+	sessions.Use(middleware.JWTAuth(authService))
+	sessions.Use(middleware.RequireMentorOrAdmin(userService))
+
+	sessions.PATCH("/:id", h.UpdateSession)
+	sessions.DELETE("/:id", h.DeleteSession)
+	sessions.GET("/:id/check-in-code", h.GetCheckInCode)
+	sessions.GET("/:id/attendance", h.GetRoster)
+	sessions.PUT("/:id/attendance/:user_id", h.MarkAttendance)
+
+	attendance := e.Group("/api/attendance")
+	attendance.Use(middleware.JWTAuth(authService))
+
+	attendance.POST("/check-in", h.CheckIn)
+
+	courses := e.Group("/api/courses/:id/attendance")
+	courses.Use(middleware.JWTAuth(authService))
+
+	courses.GET("/my", h.GetMyAttendance)
+	courses.GET("", h.GetCourseReport, middleware.RequireMentorOrAdmin(userService))
+}
@@ -7,13 +7,25 @@ import (
 )
 
 type AuthRoutesOpts struct {
-	AuthService service.AuthService
-	UserService service.UserService
+	AuthService          service.AuthService
+	UserService          service.UserService
+	PasswordResetService service.PasswordResetService
+	VerificationService  service.EmailVerificationService
+	RequireVerifiedLogin bool
 }
 
 func SetupAuthRoutes(e *echo.Echo, opts AuthRoutesOpts) {
-	authHandler := handlers.NewAuthHandler(opts.AuthService, opts.UserService)
+	authHandler := handlers.NewAuthHandler(opts.AuthService, opts.UserService, opts.VerificationService, opts.RequireVerifiedLogin)
+	passwordHandler := handlers.NewPasswordResetHandler(opts.PasswordResetService)
+	verificationHandler := handlers.NewEmailVerificationHandler(opts.VerificationService)
 
 	e.POST("/api/login", authHandler.Login)
 	e.POST("/api/register", authHandler.Register)
+
+	auth := e.Group("/api/auth")
+	auth.POST("/refresh", authHandler.Refresh)
+	auth.POST("/password/forgot", passwordHandler.Forgot)
+	auth.POST("/password/reset", passwordHandler.Reset)
+	auth.POST("/verify-email", verificationHandler.Verify)
+	auth.POST("/verify-email/resend", verificationHandler.Resend)
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupCalendarRoutes(e *echo.Echo, calendarService service.CalendarService, authService service.AuthService) {
+	h := handlers.NewCalendarHandler(calendarService)
+
+	feed := e.Group("/api/calendar/feed")
+	feed.Use(middleware.JWTAuth(authService))
+
+	feed.GET("", h.GetFeed)
+	feed.POST("", h.CreateFeed)
+	feed.DELETE("", h.DeleteFeed)
+
+	// The feed itself is public; the token in the path grants access.
+	e.GET("/calendar/:token", h.Render)
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupCohortRoutes(e *echo.Echo, cohortService service.CohortService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewCohortHandler(cohortService)
+
+	courses := e.Group("/api/courses/:id/cohorts")
+	courses.Use(middleware.JWTAuth(authService))
+	courses.Use(middleware.RequireMentorOrAdmin(userService))
+
+	courses.GET("", h.GetCourseCohorts)
+	courses.POST("", h.CreateCohort)
+
+	mentors := e.Group("/api/admin/cohorts/:id/mentors")
+	mentors.Use(middleware.JWTAuth(authService))
+	mentors.Use(middleware.RequireAdmin(userService))
+
+	mentors.PUT("/:user_id", h.AddMentor)
+	mentors.DELETE("/:user_id", h.RemoveMentor)
+
+	// Members and mentors of a cohort reach these; the service checks the
+	// caller's place in the cohort.
+	cohorts := e.Group("/api/cohorts")
+	cohorts.Use(middleware.JWTAuth(authService))
+
+	cohorts.GET("/my", h.GetMyCohorts)
+	cohorts.GET("/:id", h.GetCohort)
+	cohorts.PATCH("/:id", h.UpdateCohort)
+	cohorts.DELETE("/:id", h.DeleteCohort)
+	cohorts.PUT("/:id/deadline", h.SetDeadline)
+	cohorts.GET("/:id/members", h.GetMembers)
+	cohorts.PUT("/:id/members/:user_id", h.AddMember)
+	cohorts.DELETE("/:id/members/:user_id", h.RemoveMember)
+	cohorts.GET("/:id/dashboard", h.GetDashboard)
+	cohorts.GET("/:id/announcements", h.GetAnnouncements)
+	cohorts.POST("/:id/announcements", h.PostAnnouncement)
+	cohorts.GET("/:id/discussions", h.GetThreads)
+	cohorts.POST("/:id/discussions", h.CreateThread)
+	cohorts.GET("/:id/discussions/:post_id", h.GetThread)
+	cohorts.POST("/:id/discussions/:post_id/replies", h.Reply)
+	cohorts.DELETE("/:id/discussions/:post_id", h.DeletePost)
+}
@@ -2,11 +2,12 @@ package routes
 
 import (
 	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
 	"github.com/bobchopperz/bahrululum/internal/domain/service"
 	"github.com/labstack/echo/v4"
 )
 
-func SetupCoursesRoutes(e *echo.Echo, courseService service.CourseService) {
+func SetupCoursesRoutes(e *echo.Echo, courseService service.CourseService, authService service.AuthService, userService service.UserService) {
 	courseHandler := handlers.NewCourseHandler(courseService)
 
 	courses := e.Group("/api/courses")
@@ -15,4 +16,8 @@ func SetupCoursesRoutes(e *echo.Echo, courseService service.CourseService) {
 	courses.POST("", courseHandler.CreateCourse)
 	courses.PUT("/:id", courseHandler.UpdateCourse)
 	courses.DELETE("/:id", courseHandler.DeleteCourse)
+
+	courses.GET("/:id/prerequisites", courseHandler.GetPrerequisites)
+	courses.PUT("/:id/prerequisites", courseHandler.SetPrerequisites,
+		middleware.JWTAuth(authService), middleware.RequireAdmin(userService))
 }
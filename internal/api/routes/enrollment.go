@@ -3,17 +3,66 @@ package routes
 import (
 	"github.com/bobchopperz/bahrululum/internal/api/handlers"
 	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/constants"
 	"github.com/bobchopperz/bahrululum/internal/domain/service"
 	"github.com/labstack/echo/v4"
 )
 
-func SetupEnrollmentRoutes(e *echo.Echo, enrollmentService service.EnrollmentService, authService service.AuthService) {
-	h := handlers.NewEnrollmentHandler(enrollmentService)
+type EnrollmentRoutesOpts struct {
+	EnrollmentService service.EnrollmentService
+	BulkService       service.BulkEnrollmentService
+	AuthService       service.AuthService
+	APIKeyService     service.APIKeyService
+	UserService       service.UserService
+	// RequireVerifiedEmail blocks self-enrollment until the user's email
+	// address is verified.
+	RequireVerifiedEmail bool
+}
+
+func SetupEnrollmentRoutes(e *echo.Echo, opts EnrollmentRoutesOpts) {
+	h := handlers.NewEnrollmentHandler(opts.EnrollmentService)
+	bulk := handlers.NewBulkEnrollmentHandler(opts.BulkService)
 
 	enrollments := e.Group("/api/enrollments")
-	enrollments.Use(middleware.JWTAuth(authService))
+	enrollments.Use(middleware.JWTAuth(opts.AuthService))
 
 	enrollments.GET("/my", h.GetMyEnrollments)
+	enrollments.GET("/history", h.GetMyHistory)
 	enrollments.GET("/:course_id", h.GetEnrollment)
-	enrollments.POST("", h.Create)
+	if opts.RequireVerifiedEmail {
+		enrollments.POST("", h.Create, middleware.RequireVerifiedEmail(opts.UserService))
+	} else {
+		enrollments.POST("", h.Create)
+	}
+	enrollments.DELETE("/:course_id", h.Unenroll)
+	enrollments.POST("/progress/:content_id", h.CompleteContent)
+
+	// Admin enrollment routes also accept API keys so integrations can
+	// enroll users and pull completions.
+	readAuth := middleware.JWTOrAPIKeyAuth(opts.AuthService, opts.APIKeyService, constants.ScopeEnrollmentsRead)
+	writeAuth := middleware.JWTOrAPIKeyAuth(opts.AuthService, opts.APIKeyService, constants.ScopeEnrollmentsWrite)
+	requireAdmin := middleware.RequireAdmin(opts.UserService)
+
+	admin := e.Group("/api/admin/courses/:id/enrollments")
+	admin.GET("", h.GetCourseEnrollments, readAuth, requireAdmin)
+	admin.GET("/overdue", h.GetOverdueEnrollments, readAuth, requireAdmin)
+	admin.PUT("/:user_id/due-date", h.UpdateDueDate, writeAuth, requireAdmin)
+	admin.DELETE("/:user_id", h.RemoveEnrollment, writeAuth, requireAdmin)
+	admin.POST("/bulk", bulk.Enroll, writeAuth, requireAdmin)
+
+	e.GET("/api/admin/enrollment-jobs/:id", bulk.GetJob, readAuth, requireAdmin)
+
+	e.PUT("/api/admin/courses/:id/enrollment-settings", h.UpdateSettings,
+		middleware.JWTAuth(opts.AuthService), middleware.RequireAdmin(opts.UserService))
+
+	review := e.Group("/api/courses/:id/enrollment-requests")
+	review.Use(middleware.JWTAuth(opts.AuthService))
+	review.Use(middleware.RequireMentorOrAdmin(opts.UserService))
+
+	review.GET("", h.GetPendingEnrollments)
+	review.POST("/:user_id/approve", h.ApproveEnrollment)
+	review.POST("/:user_id/reject", h.RejectEnrollment)
+
+	e.POST("/api/courses/:id/invitations", h.InviteUser,
+		middleware.JWTAuth(opts.AuthService), middleware.RequireMentorOrAdmin(opts.UserService))
 }
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupHafalanRoutes(e *echo.Echo, hafalanService service.HafalanService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewHafalanHandler(hafalanService)
+
+	contents := e.Group("/api/contents/:id/hafalan")
+	contents.Use(middleware.JWTAuth(authService))
+
+	contents.GET("", h.GetTarget)
+	contents.PUT("", h.SetTarget, middleware.RequireMentorOrAdmin(userService))
+	contents.POST("/records", h.CreateRecord, middleware.RequireMentorOrAdmin(userService))
+
+	records := e.Group("/api/hafalan/records")
+	records.Use(middleware.JWTAuth(authService))
+	records.Use(middleware.RequireMentorOrAdmin(userService))
+
+	records.DELETE("/:id", h.DeleteRecord)
+
+	courses := e.Group("/api/courses/:id/hafalan")
+	courses.Use(middleware.JWTAuth(authService))
+
+	courses.GET("/my", h.GetMyProgress)
+	courses.GET("/users/:user_id", h.GetUserProgress, middleware.RequireMentorOrAdmin(userService))
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/jwtkeys"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupJWKSRoutes(e *echo.Echo, keys *jwtkeys.KeySet) {
+	handler := handlers.NewJWKSHandler(keys)
+
+	e.GET("/.well-known/jwks.json", handler.GetJWKS)
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupLearningPathRoutes(e *echo.Echo, learningPathService service.LearningPathService, authService service.AuthService, userService service.UserService, requireVerifiedEmail bool) {
+	h := handlers.NewLearningPathHandler(learningPathService)
+
+	paths := e.Group("/api/learning-paths")
+	paths.GET("", h.GetPaths)
+	paths.GET("/my", h.GetMyPaths, middleware.JWTAuth(authService))
+	paths.GET("/:id", h.GetPath)
+	joinMiddleware := []echo.MiddlewareFunc{middleware.JWTAuth(authService)}
+	if requireVerifiedEmail {
+		joinMiddleware = append(joinMiddleware, middleware.RequireVerifiedEmail(userService))
+	}
+	paths.POST("/:id/join", h.Join, joinMiddleware...)
+	paths.GET("/:id/progress", h.GetProgress, middleware.JWTAuth(authService))
+
+	admin := e.Group("/api/admin/learning-paths")
+	admin.Use(middleware.JWTAuth(authService))
+	admin.Use(middleware.RequireAdmin(userService))
+
+	admin.GET("", h.GetAllPaths)
+	admin.POST("", h.CreatePath)
+	admin.PUT("/:id", h.UpdatePath)
+	admin.DELETE("/:id", h.DeletePath)
+	admin.PUT("/:id/courses", h.SetCourses)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupMFARoutes(e *echo.Echo, mfaService service.MFAService, authService service.AuthService) {
+	h := handlers.NewMFAHandler(mfaService, authService)
+
+	login := e.Group("/api/auth/mfa")
+	login.POST("/verify", h.Verify)
+	login.POST("/enroll", h.Enroll)
+	login.POST("/enroll/confirm", h.EnrollConfirm)
+
+	me := e.Group("/api/me/mfa")
+	me.Use(middleware.JWTAuth(authService))
+
+	me.POST("/totp", h.Setup)
+	me.POST("/totp/confirm", h.Confirm)
+	me.POST("/totp/disable", h.Disable)
+	me.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+}
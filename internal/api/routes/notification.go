@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupNotificationRoutes(e *echo.Echo, notificationService service.NotificationService, authService service.AuthService) {
+	h := handlers.NewNotificationHandler(notificationService)
+
+	notifications := e.Group("/api/notifications")
+	notifications.Use(middleware.JWTAuth(authService))
+
+	notifications.GET("", h.GetNotifications)
+	notifications.POST("/read", h.MarkAllRead)
+	notifications.POST("/:id/read", h.MarkRead)
+}
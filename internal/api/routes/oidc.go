@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupOIDCRoutes(e *echo.Echo, oidcService service.OIDCService, authService service.AuthService, frontendURL string) {
+	h := handlers.NewOIDCHandler(oidcService, frontendURL)
+
+	sso := e.Group("/api/auth/oidc")
+	sso.GET("/login", h.Login)
+	sso.GET("/callback", h.Callback)
+
+	me := e.Group("/api/me/identities")
+	me.Use(middleware.JWTAuth(authService))
+
+	me.GET("", h.GetIdentities)
+	me.POST("/oidc/link", h.Link)
+	me.DELETE("/oidc", h.Unlink)
+}
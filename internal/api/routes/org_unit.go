@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupOrgUnitRoutes(e *echo.Echo, orgUnitService service.OrgUnitService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewOrgUnitHandler(orgUnitService)
+
+	admin := e.Group("/api/admin/org-units")
+	admin.Use(middleware.JWTAuth(authService))
+	admin.Use(middleware.RequireAdmin(userService))
+
+	admin.GET("", h.GetTree)
+	admin.POST("", h.CreateUnit)
+	admin.GET("/:id", h.GetUnit)
+	admin.PATCH("/:id", h.UpdateUnit)
+	admin.PUT("/:id/parent", h.MoveUnit)
+	admin.DELETE("/:id", h.DeleteUnit)
+	admin.GET("/:id/admins", h.GetAdmins)
+	admin.PUT("/:id/admins/:user_id", h.AddAdmin)
+	admin.DELETE("/:id/admins/:user_id", h.RemoveAdmin)
+
+	// Unit admins reach these as well; the service checks that the caller
+	// administers the unit or one of its ancestors.
+	units := e.Group("/api/units")
+	units.Use(middleware.JWTAuth(authService))
+
+	units.GET("/managed", h.GetManagedUnits)
+	units.GET("/:id/members", h.GetMembers)
+	units.PUT("/:id/members/:user_id", h.AddMember)
+	units.DELETE("/:id/members/:user_id", h.RemoveMember)
+	units.GET("/:id/courses", h.GetCourses)
+	units.POST("/:id/courses", h.AssignCourse)
+	units.DELETE("/:id/courses/:course_id", h.UnassignCourse)
+	units.GET("/:id/report", h.GetReport)
+}
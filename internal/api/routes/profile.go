@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupProfileRoutes(e *echo.Echo, profileService service.ProfileService, authService service.AuthService) {
+	h := handlers.NewProfileHandler(profileService, authService)
+
+	me := e.Group("/api/me")
+	me.Use(middleware.JWTAuth(authService))
+
+	me.GET("", h.GetProfile)
+	me.PUT("", h.UpdateProfile)
+	me.POST("/password", h.ChangePassword)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupSecurityRoutes(e *echo.Echo, securityService service.SecurityService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewSecurityHandler(securityService)
+
+	admin := e.Group("/api/admin")
+	admin.Use(middleware.JWTAuth(authService))
+	admin.Use(middleware.RequireAdmin(userService))
+
+	admin.POST("/users/:id/unlock", h.UnlockUser)
+	admin.GET("/security-events", h.GetEvents)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupSessionRoutes(e *echo.Echo, sessionService service.SessionService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewSessionHandler(sessionService)
+
+	e.POST("/api/auth/logout", h.Logout, middleware.JWTAuth(authService))
+
+	me := e.Group("/api/me/sessions")
+	me.Use(middleware.JWTAuth(authService))
+
+	me.GET("", h.GetSessions)
+	me.DELETE("", h.RevokeAll)
+	me.DELETE("/:id", h.Revoke)
+
+	admin := e.Group("/api/admin/users/:id/sessions")
+	admin.Use(middleware.JWTAuth(authService))
+	admin.Use(middleware.RequireAdmin(userService))
+
+	admin.GET("", h.GetUserSessions)
+	admin.DELETE("", h.ForceLogout)
+}
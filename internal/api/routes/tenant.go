@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupTenantRoutes(e *echo.Echo, tenantService service.TenantService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewTenantHandler(tenantService)
+
+	e.GET("/api/tenant", h.GetBranding)
+
+	admin := e.Group("/api/admin/tenant")
+	admin.Use(middleware.JWTAuth(authService))
+	admin.Use(middleware.RequireAdmin(userService))
+
+	admin.GET("", h.GetTenant)
+	admin.PATCH("", h.UpdateBranding)
+}
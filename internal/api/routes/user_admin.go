@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+)
+
+func SetupUserAdminRoutes(e *echo.Echo, userAdminService service.UserAdminService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewUserAdminHandler(userAdminService)
+
+	admin := e.Group("/api/admin/users")
+	admin.Use(middleware.JWTAuth(authService))
+	admin.Use(middleware.RequireAdmin(userService))
+
+	admin.GET("", h.SearchUsers)
+	admin.POST("", h.CreateUser)
+	admin.PATCH("/:id", h.UpdateUser)
+	admin.PUT("/:id/role", h.ChangeRole)
+	admin.PUT("/:id/status", h.SetStatus)
+	admin.POST("/:id/reset-password", h.ResetPassword)
+	admin.DELETE("/:id", h.DeleteUser)
+	admin.POST("/:id/restore", h.RestoreUser)
+}
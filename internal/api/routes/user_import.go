@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/bobchopperz/bahrululum/internal/api/handlers"
+	"github.com/bobchopperz/bahrululum/internal/api/middleware"
+	"github.com/bobchopperz/bahrululum/internal/domain/service"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// userImportBodyLimit bounds roster uploads. A full roster of
+// service.UserImportMaxRows rows is well under a megabyte as CSV.
+const userImportBodyLimit = "10M"
+
+func SetupUserImportRoutes(e *echo.Echo, importService service.UserImportService, authService service.AuthService, userService service.UserService) {
+	h := handlers.NewUserImportHandler(importService)
+
+	imports := e.Group("/api/admin/user-imports")
+	imports.Use(middleware.JWTAuth(authService))
+	imports.Use(middleware.RequireAdmin(userService))
+
+	imports.POST("", h.Import, echomiddleware.BodyLimit(userImportBodyLimit))
+	imports.GET("", h.GetImports)
+	imports.GET("/:id", h.GetImport)
+	imports.GET("/:id/errors", h.ErrorReport)
+}
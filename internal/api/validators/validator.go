@@ -2,7 +2,9 @@ package validators
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/bobchopperz/bahrululum/internal/util"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
@@ -16,7 +18,14 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 }
 
 func NewValidator() *CustomValidator {
-	return &CustomValidator{validator: validator.New()}
+	v := validator.New()
+	// hijri accepts a Hijri date such as 1447-09-01, optionally followed by
+	// a clock time such as T08:00.
+	_ = v.RegisterValidation("hijri", func(fl validator.FieldLevel) bool {
+		_, err := util.ParseHijri(fl.Field().String(), time.UTC)
+		return err == nil
+	})
+	return &CustomValidator{validator: v}
 }
 
 func ValidationErrorResponse(c echo.Context, err error) error {
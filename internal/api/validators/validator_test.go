@@ -0,0 +1,29 @@
+package validators
+
+import "testing"
+
+func TestHijriValidation(t *testing.T) {
+	type request struct {
+		DueAtHijri *string `validate:"omitempty,hijri"`
+	}
+	v := NewValidator()
+
+	for value, valid := range map[string]bool{
+		"1447-09-01":       true,
+		"1445-12-30":       true,
+		"1447-09-01T08:00": true,
+		"1445-13-01":       false,
+		"1444-12-30":       false,
+		"01-09-1447":       false,
+		"":                 false,
+	} {
+		err := v.Validate(&request{DueAtHijri: &value})
+		if (err == nil) != valid {
+			t.Errorf("%q: valid = %v, want %v", value, err == nil, valid)
+		}
+	}
+
+	if err := v.Validate(&request{}); err != nil {
+		t.Errorf("omitted date: %v", err)
+	}
+}
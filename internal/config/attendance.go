@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type AttendanceConfig struct {
+	// CodeRotation is how long each self check-in code is shown before the
+	// next one replaces it. The previous code is still accepted so a scan
+	// made just before the switch goes through.
+	CodeRotation time.Duration `mapstructure:"code_rotation"`
+	// CheckInOpensBefore lets learners check in this long before a session
+	// starts.
+	CheckInOpensBefore time.Duration `mapstructure:"check_in_opens_before"`
+	// LateAfter marks check-ins made this long after the start as late.
+	LateAfter time.Duration `mapstructure:"late_after"`
+}
+
+// Validate rejects settings the check-in codes cannot work with. Codes are
+// numbered in whole seconds of CodeRotation, so it must be at least a second.
+func (c *AttendanceConfig) Validate() error {
+	if c.CodeRotation < time.Second {
+		return fmt.Errorf("attendance.code_rotation must be at least 1s, got %s", c.CodeRotation)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+type CalendarConfig struct {
+	// FeedURL is the public base URL of the API that personal calendar feed
+	// links are built on.
+	FeedURL string `mapstructure:"feed_url"`
+	// History keeps past events in feeds for this long after they end.
+	History time.Duration `mapstructure:"history"`
+}
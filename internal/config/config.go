@@ -7,10 +7,21 @@ import (
 )
 
 type Config struct {
-	Server         ServerConfig   `mapstructure:"server"`
-	DatabaseConfig DatabaseConfig `mapstructure:"database"`
-	LoggerConfig   LoggerConfig   `mapstructure:"logger"`
-	JWTConfig      JWTConfig      `mapstructure:"jwt"`
+	Server                  ServerConfig            `mapstructure:"server"`
+	DatabaseConfig          DatabaseConfig          `mapstructure:"database"`
+	LoggerConfig            LoggerConfig            `mapstructure:"logger"`
+	JWTConfig               JWTConfig               `mapstructure:"jwt"`
+	ReminderConfig          ReminderConfig          `mapstructure:"reminders"`
+	MailConfig              MailConfig              `mapstructure:"mail"`
+	PasswordResetConfig     PasswordResetConfig     `mapstructure:"password_reset"`
+	EmailVerificationConfig EmailVerificationConfig `mapstructure:"email_verification"`
+	MFAConfig               MFAConfig               `mapstructure:"mfa"`
+	SecurityConfig          SecurityConfig          `mapstructure:"security"`
+	OIDCConfig              OIDCConfig              `mapstructure:"oidc"`
+	LDAPConfig              LDAPConfig              `mapstructure:"ldap"`
+	TenantConfig            TenantConfig            `mapstructure:"tenant"`
+	AttendanceConfig        AttendanceConfig        `mapstructure:"attendance"`
+	CalendarConfig          CalendarConfig          `mapstructure:"calendar"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -35,6 +46,9 @@ func LoadConfig() (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
+	if err := config.AttendanceConfig.Validate(); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
@@ -49,6 +63,62 @@ func setDefaults() {
 	viper.SetDefault("database.max_idle_conns", 5)
 	viper.SetDefault("jwt.expiry", "15m")
 	viper.SetDefault("jwt.refresh_expiry", "168h")
+	viper.SetDefault("reminders.enabled", true)
+	viper.SetDefault("reminders.interval", "1h")
+	viper.SetDefault("reminders.before", []string{"168h", "24h"})
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "no-reply@bahrululum.local")
+	viper.SetDefault("mail.port", "587")
+	viper.SetDefault("mail.dir", "./tmp/mail")
+	viper.SetDefault("password_reset.url", "http://localhost:3000/reset-password")
+	viper.SetDefault("password_reset.token_expiry", "1h")
+	viper.SetDefault("password_reset.max_requests", 3)
+	viper.SetDefault("password_reset.window", "1h")
+	viper.SetDefault("email_verification.url", "http://localhost:3000/verify-email")
+	viper.SetDefault("email_verification.token_expiry", "24h")
+	viper.SetDefault("email_verification.require_for_login", false)
+	viper.SetDefault("email_verification.require_for_enrollment", true)
+	viper.SetDefault("email_verification.resend_max", 3)
+	viper.SetDefault("email_verification.resend_window", "1h")
+	viper.SetDefault("mfa.issuer", "Bahrul Ulum")
+	viper.SetDefault("mfa.required_roles", []string{"admin", "mentor"})
+	viper.SetDefault("mfa.challenge_expiry", "5m")
+	viper.SetDefault("mfa.max_attempts", 5)
+	viper.SetDefault("security.max_failed_logins", 5)
+	viper.SetDefault("security.lockout_duration", "15m")
+	viper.SetDefault("security.delay_after", 2)
+	viper.SetDefault("security.base_delay", "2s")
+	viper.SetDefault("security.max_delay", "1m")
+	viper.SetDefault("security.ip_max_failures", 20)
+	viper.SetDefault("security.ip_window", "15m")
+	viper.SetDefault("oidc.enabled", false)
+	viper.SetDefault("oidc.provider", "oidc")
+	viper.SetDefault("oidc.scopes", []string{"openid", "profile", "email"})
+	viper.SetDefault("oidc.state_expiry", "10m")
+	viper.SetDefault("oidc.nip_claim", "nip")
+	viper.SetDefault("oidc.email_claim", "email")
+	viper.SetDefault("oidc.name_claim", "name")
+	viper.SetDefault("oidc.groups_claim", "groups")
+	viper.SetDefault("oidc.default_role", "user")
+	viper.SetDefault("oidc.auto_provision", true)
+	viper.SetDefault("oidc.link_by_nip", true)
+	viper.SetDefault("ldap.enabled", false)
+	viper.SetDefault("ldap.timeout", "5s")
+	viper.SetDefault("ldap.user_object_class", "person")
+	viper.SetDefault("ldap.nip_attribute", "employeeID")
+	viper.SetDefault("ldap.name_attribute", "displayName")
+	viper.SetDefault("ldap.email_attribute", "mail")
+	viper.SetDefault("ldap.group_attribute", "memberOf")
+	viper.SetDefault("ldap.auto_provision", true)
+	viper.SetDefault("ldap.default_role", "user")
+	viper.SetDefault("tenant.header", "X-Tenant")
+	viper.SetDefault("tenant.default", "default")
+	viper.SetDefault("tenant.cache_ttl", "1m")
+	viper.SetDefault("attendance.code_rotation", "30s")
+	viper.SetDefault("attendance.check_in_opens_before", "15m")
+	viper.SetDefault("attendance.late_after", "15m")
+	viper.SetDefault("calendar.feed_url", "http://localhost:8080")
+	viper.SetDefault("calendar.history", "720h")
 	viper.SetDefault("logger.level", "info")
 	viper.SetDefault("logger.format", "text")
 }
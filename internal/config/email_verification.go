@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+type EmailVerificationConfig struct {
+	URL         string        `mapstructure:"url"`
+	TokenExpiry time.Duration `mapstructure:"token_expiry"`
+	// RequireForLogin rejects logins until the email address is verified.
+	RequireForLogin bool `mapstructure:"require_for_login"`
+	// RequireForEnrollment blocks enrolling in courses and learning paths
+	// until the email address is verified.
+	RequireForEnrollment bool          `mapstructure:"require_for_enrollment"`
+	ResendMax            int           `mapstructure:"resend_max"`
+	ResendWindow         time.Duration `mapstructure:"resend_window"`
+}
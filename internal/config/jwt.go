@@ -3,7 +3,22 @@ package config
 import "time"
 
 type JWTConfig struct {
+	// Secret signs HS256 tokens when no signing key is configured. With a
+	// signing key set it only verifies older HS256 tokens; remove it once
+	// those have expired.
 	Secret     string        `mapstructure:"secret"`
 	Expiry     time.Duration `mapstructure:"expiry"`
 	RefreshExp time.Duration `mapstructure:"refresh_expiry"`
+	// SigningKeyID selects the key in Keys that signs new tokens. Other keys
+	// only verify, which allows rotating keys without logging users out.
+	SigningKeyID string         `mapstructure:"signing_key_id"`
+	Keys         []JWTKeyConfig `mapstructure:"keys"`
+}
+
+// JWTKeyConfig is an RSA (RS256) or Ed25519 (EdDSA) key in PEM format.
+// Retired keys only need the public half.
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
 }
@@ -0,0 +1,38 @@
+package config
+
+import "time"
+
+type LDAPConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// URL is an ldap:// or ldaps:// address. StartTLS upgrades ldap://.
+	URL                string        `mapstructure:"url"`
+	StartTLS           bool          `mapstructure:"start_tls"`
+	InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify"`
+	Timeout            time.Duration `mapstructure:"timeout"`
+
+	// BindDN and BindPassword are the service account used to find users.
+	// Leave empty if the directory allows anonymous search.
+	BindDN       string `mapstructure:"bind_dn"`
+	BindPassword string `mapstructure:"bind_password"`
+	BaseDN       string `mapstructure:"base_dn"`
+
+	UserObjectClass string `mapstructure:"user_object_class"`
+	NipAttribute    string `mapstructure:"nip_attribute"`
+	NameAttribute   string `mapstructure:"name_attribute"`
+	EmailAttribute  string `mapstructure:"email_attribute"`
+
+	// GroupAttribute lists the groups of a user entry. RoleMapping maps
+	// group DNs to roles; the most privileged matching role wins and users
+	// without a matching group get DefaultRole. Without a mapping roles are
+	// managed locally.
+	GroupAttribute string            `mapstructure:"group_attribute"`
+	RoleMapping    map[string]string `mapstructure:"role_mapping"`
+
+	AutoProvision bool   `mapstructure:"auto_provision"`
+	DefaultRole   string `mapstructure:"default_role"`
+	// LocalFallbackRoles limits local password login to these roles while
+	// LDAP is enabled, so only break-glass accounts bypass the directory.
+	// Local accounts with these roles never sign in through the directory,
+	// even when their NIP is in it. Empty allows every local account.
+	LocalFallbackRoles []string `mapstructure:"local_fallback_roles"`
+}
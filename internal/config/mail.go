@@ -0,0 +1,11 @@
+package config
+
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"` // 'smtp', 'file' or 'log'
+	From     string `mapstructure:"from"`
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Dir      string `mapstructure:"dir"` // output directory for the file driver
+}
@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+type MFAConfig struct {
+	Issuer string `mapstructure:"issuer"`
+	// RequiredRoles lists roles that must enroll TOTP before they can sign in.
+	RequiredRoles   []string      `mapstructure:"required_roles"`
+	ChallengeExpiry time.Duration `mapstructure:"challenge_expiry"`
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+}
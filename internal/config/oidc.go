@@ -0,0 +1,30 @@
+package config
+
+import "time"
+
+type OIDCConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Provider     string   `mapstructure:"provider"` // name stored with linked identities
+	IssuerURL    string   `mapstructure:"issuer_url"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+	// FrontendURL receives the tokens in the URL fragment after the callback.
+	// When empty the callback responds with JSON instead.
+	FrontendURL string        `mapstructure:"frontend_url"`
+	StateExpiry time.Duration `mapstructure:"state_expiry"`
+
+	NipClaim    string `mapstructure:"nip_claim"`
+	EmailClaim  string `mapstructure:"email_claim"`
+	NameClaim   string `mapstructure:"name_claim"`
+	GroupsClaim string `mapstructure:"groups_claim"`
+	// RoleMapping maps group claim values to roles. The most privileged
+	// matching role wins; users without a matching group get DefaultRole.
+	RoleMapping   map[string]string `mapstructure:"role_mapping"`
+	DefaultRole   string            `mapstructure:"default_role"`
+	AutoProvision bool              `mapstructure:"auto_provision"`
+	// LinkByNip links a first-time SSO login to an existing account with the
+	// same NIP.
+	LinkByNip bool `mapstructure:"link_by_nip"`
+}
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type PasswordResetConfig struct {
+	URL         string        `mapstructure:"url"`
+	TokenExpiry time.Duration `mapstructure:"token_expiry"`
+	MaxRequests int           `mapstructure:"max_requests"`
+	Window      time.Duration `mapstructure:"window"`
+}
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type ReminderConfig struct {
+	Enabled  bool            `mapstructure:"enabled"`
+	Interval time.Duration   `mapstructure:"interval"`
+	Before   []time.Duration `mapstructure:"before"`
+}
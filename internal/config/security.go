@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+type SecurityConfig struct {
+	// MaxFailedLogins locks an account after this many consecutive failures.
+	MaxFailedLogins int           `mapstructure:"max_failed_logins"`
+	LockoutDuration time.Duration `mapstructure:"lockout_duration"`
+	// Failures beyond DelayAfter must wait BaseDelay, doubling with every
+	// further failure up to MaxDelay, before the next attempt.
+	DelayAfter int           `mapstructure:"delay_after"`
+	BaseDelay  time.Duration `mapstructure:"base_delay"`
+	MaxDelay   time.Duration `mapstructure:"max_delay"`
+	// IPMaxFailures throttles a client address after this many failures
+	// within IPWindow, across all accounts.
+	IPMaxFailures int           `mapstructure:"ip_max_failures"`
+	IPWindow      time.Duration `mapstructure:"ip_window"`
+}
@@ -7,4 +7,9 @@ type ServerConfig struct {
 	Host    string        `mapstructure:"host"`
 	Mode    string        `mapstructure:"mode"`
 	Timeout time.Duration `mapstructure:"timeout"`
+	// TrustedProxies lists the addresses or CIDR ranges of the reverse
+	// proxies in front of the server. Client addresses are taken from
+	// X-Forwarded-For only when the request came through one of them;
+	// without any, the connection's address is used.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+type TenantConfig struct {
+	// Header names the request header that selects a tenant by slug. It takes
+	// precedence over the host name, which suits API clients and proxies.
+	Header string `mapstructure:"header"`
+	// BaseDomain enables subdomain resolution: school-a.<base_domain> selects
+	// the tenant with slug school-a. Tenants with a custom domain are matched
+	// on the full host name first.
+	BaseDomain string `mapstructure:"base_domain"`
+	// Default is the slug used when neither header nor host selects a
+	// tenant. Leave empty to reject such requests.
+	Default string `mapstructure:"default"`
+	// CacheTTL is how long resolved tenants are cached in memory.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
@@ -0,0 +1,33 @@
+package constants
+
+type AttendanceStatus string
+
+const (
+	AttendancePresent AttendanceStatus = "present"
+	AttendanceLate    AttendanceStatus = "late"
+	AttendanceExcused AttendanceStatus = "excused"
+	AttendanceAbsent  AttendanceStatus = "absent"
+)
+
+var AllAttendanceStatuses = []AttendanceStatus{
+	AttendancePresent,
+	AttendanceLate,
+	AttendanceExcused,
+	AttendanceAbsent,
+}
+
+func (s AttendanceStatus) String() string {
+	return string(s)
+}
+
+func IsValidAttendanceStatus(status string) bool {
+	for _, validStatus := range AllAttendanceStatuses {
+		if validStatus.String() == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AttendanceUnmarked is reported for a learner with no record for a session.
+const AttendanceUnmarked = "unmarked"
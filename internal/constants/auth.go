@@ -0,0 +1,10 @@
+package constants
+
+// Where an account's credentials are checked.
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+	AuthSourceOIDC  = "oidc"
+	// Service accounts cannot sign in and only authenticate with API keys.
+	AuthSourceService = "service"
+)
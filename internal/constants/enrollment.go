@@ -0,0 +1,109 @@
+package constants
+
+type EnrollmentStatus string
+
+const (
+	EnrollmentActive    EnrollmentStatus = "active"
+	EnrollmentCompleted EnrollmentStatus = "completed"
+	EnrollmentDropped   EnrollmentStatus = "dropped"
+	EnrollmentExpired   EnrollmentStatus = "expired"
+	EnrollmentPending   EnrollmentStatus = "pending"
+	EnrollmentWaitlist  EnrollmentStatus = "waitlisted"
+	EnrollmentInvited   EnrollmentStatus = "invited"
+	EnrollmentRejected  EnrollmentStatus = "rejected"
+)
+
+var AllEnrollmentStatuses = []EnrollmentStatus{
+	EnrollmentActive,
+	EnrollmentCompleted,
+	EnrollmentDropped,
+	EnrollmentExpired,
+	EnrollmentPending,
+	EnrollmentWaitlist,
+	EnrollmentInvited,
+	EnrollmentRejected,
+}
+
+func (s EnrollmentStatus) String() string {
+	return string(s)
+}
+
+func IsValidEnrollmentStatus(status string) bool {
+	for _, validStatus := range AllEnrollmentStatuses {
+		if validStatus.String() == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Enrollment history actions recorded in enrollment_events.
+const (
+	EnrollmentEventEnrolled   = "enrolled"
+	EnrollmentEventReenrolled = "reenrolled"
+	EnrollmentEventDropped    = "dropped"
+	EnrollmentEventRemoved    = "removed"
+	EnrollmentEventCompleted  = "completed"
+	EnrollmentEventExpired    = "expired"
+	EnrollmentEventRequested  = "requested"
+	EnrollmentEventWaitlisted = "waitlisted"
+	EnrollmentEventPromoted   = "promoted"
+	EnrollmentEventApproved   = "approved"
+	EnrollmentEventRejected   = "rejected"
+	EnrollmentEventInvited    = "invited"
+)
+
+type EnrollmentMode string
+
+const (
+	EnrollmentModeOpen     EnrollmentMode = "open"
+	EnrollmentModeApproval EnrollmentMode = "approval"
+	EnrollmentModeInvite   EnrollmentMode = "invite"
+	EnrollmentModeClosed   EnrollmentMode = "closed"
+)
+
+var AllEnrollmentModes = []EnrollmentMode{
+	EnrollmentModeOpen,
+	EnrollmentModeApproval,
+	EnrollmentModeInvite,
+	EnrollmentModeClosed,
+}
+
+func (m EnrollmentMode) String() string {
+	return string(m)
+}
+
+func IsValidEnrollmentMode(mode string) bool {
+	for _, validMode := range AllEnrollmentModes {
+		if validMode.String() == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Per-row outcomes reported by bulk enrollment.
+const (
+	BulkResultCreated         = "created"
+	BulkResultWaitlisted      = "waitlisted"
+	BulkResultAlreadyEnrolled = "already_enrolled"
+	BulkResultUnknownNip      = "unknown_nip"
+	BulkResultInactiveUser    = "inactive_user"
+	BulkResultDuplicate       = "duplicate"
+	// BulkResultReenrolled is a dropped or expired learner who had already
+	// completed the course and is restored as completed.
+	BulkResultReenrolled = "reenrolled"
+)
+
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+func (s JobStatus) String() string {
+	return string(s)
+}
@@ -0,0 +1,36 @@
+package constants
+
+// ContentTypeHafalan is a Qur'an memorization activity. Mentors record the
+// learner's recitations, and the content is done once its target range is
+// memorized.
+const ContentTypeHafalan = "hafalan"
+
+type HafalanKind string
+
+const (
+	// HafalanZiyadah is the recitation of a newly memorized range.
+	HafalanZiyadah HafalanKind = "ziyadah"
+	// HafalanMurajaah is the revision of a range memorized before.
+	HafalanMurajaah HafalanKind = "murajaah"
+)
+
+type HafalanGrade string
+
+const (
+	HafalanMumtaz       HafalanGrade = "mumtaz"
+	HafalanJayyidJiddan HafalanGrade = "jayyid_jiddan"
+	HafalanJayyid       HafalanGrade = "jayyid"
+	HafalanMaqbul       HafalanGrade = "maqbul"
+	// HafalanRasib is a failed recitation that has to be repeated.
+	HafalanRasib HafalanGrade = "rasib"
+)
+
+func (g HafalanGrade) String() string {
+	return string(g)
+}
+
+// Passing reports whether a recitation with the grade counts the range as
+// memorized.
+func (g HafalanGrade) Passing() bool {
+	return g != HafalanRasib
+}
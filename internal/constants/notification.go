@@ -0,0 +1,8 @@
+package constants
+
+const (
+	NotificationEnrollmentDue      = "enrollment_due"
+	NotificationEnrollmentOverdue  = "enrollment_overdue"
+	NotificationCohortAnnouncement = "cohort_announcement"
+	NotificationCohortDeadline     = "cohort_deadline"
+)
@@ -0,0 +1,21 @@
+package constants
+
+// API key scopes. A key can only call routes that accept one of its scopes.
+const (
+	ScopeEnrollmentsRead  = "enrollments:read"
+	ScopeEnrollmentsWrite = "enrollments:write"
+)
+
+var AllScopes = []string{
+	ScopeEnrollmentsRead,
+	ScopeEnrollmentsWrite,
+}
+
+func IsValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package constants
+
+const (
+	SecurityEventAccountLocked    = "account_locked"
+	SecurityEventAccountUnlocked  = "account_unlocked"
+	SecurityEventIPThrottled      = "ip_throttled"
+	SecurityEventSSOLinked        = "sso_linked"
+	SecurityEventSSOUnlinked      = "sso_unlinked"
+	SecurityEventSessionRevoked   = "session_revoked"
+	SecurityEventForcedLogout     = "forced_logout"
+	SecurityEventAPIKeyCreated    = "api_key_created"
+	SecurityEventAPIKeyRevoked    = "api_key_revoked"
+	SecurityEventUserCreated      = "user_created"
+	SecurityEventUserUpdated      = "user_updated"
+	SecurityEventRoleChanged      = "role_changed"
+	SecurityEventUserActivated    = "user_activated"
+	SecurityEventUserDeactivated  = "user_deactivated"
+	SecurityEventPasswordReset    = "password_reset_by_admin"
+	SecurityEventUserDeleted      = "user_deleted"
+	SecurityEventUserRestored     = "user_restored"
+	SecurityEventUnitAdminAdded   = "unit_admin_added"
+	SecurityEventUnitAdminRemoved = "unit_admin_removed"
+	SecurityEventOrgUnitChanged   = "org_unit_changed"
+)
@@ -0,0 +1,25 @@
+package constants
+
+// User statuses accepted by the admin user search.
+const (
+	UserStatusActive   = "active"
+	UserStatusInactive = "inactive"
+	UserStatusLocked   = "locked"
+	UserStatusDeleted  = "deleted"
+)
+
+func IsValidUserStatus(status string) bool {
+	switch status {
+	case UserStatusActive, UserStatusInactive, UserStatusLocked, UserStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// Per-row results of a user import. A dry run reports what would happen.
+const (
+	UserImportCreated   = "created"
+	UserImportUpdated   = "updated"
+	UserImportUnchanged = "unchanged"
+	UserImportFailed    = "failed"
+)
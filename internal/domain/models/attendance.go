@@ -0,0 +1,248 @@
+package models
+
+import (
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"gorm.io/gorm"
+)
+
+// ChapterSession is a live or in-person meeting held for a course, or for
+// one of its chapters when ChapterID is set.
+type ChapterSession struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID   uint      `json:"-" gorm:"not null;index"`
+	CourseID   uint      `json:"course_id" gorm:"not null;index"`
+	ChapterID  *uint     `json:"chapter_id" gorm:"index"`
+	Title      string    `json:"title" gorm:"not null;size:255"`
+	StartsAt   time.Time `json:"starts_at" gorm:"not null"`
+	EndsAt     time.Time `json:"ends_at" gorm:"not null"`
+	Location   *string   `json:"location" gorm:"size:255"`
+	MeetingURL *string   `json:"meeting_url" gorm:"size:500"`
+	// CheckInSecret signs the rotating self check-in codes.
+	CheckInSecret string `json:"-" gorm:"not null;size:64"`
+	// Sequence counts the changes made to the session, for calendar feeds.
+	Sequence  int            `json:"-" gorm:"not null;default:0"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// AttendanceRecord is a learner's attendance at a session, marked by a
+// mentor or by checking in.
+type AttendanceRecord struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	SessionID   uint       `json:"session_id" gorm:"not null"`
+	UserID      uint       `json:"user_id" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;size:20"`
+	CheckedInAt *time.Time `json:"checked_in_at"`
+	MarkedBy    *uint      `json:"marked_by"`
+	Note        *string    `json:"note" gorm:"size:500"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CreateChapterSessionRequest takes each time either as a timestamp or as a
+// Hijri date and clock time, such as 1447-09-01T08:00, in the tenant's
+// timezone.
+type CreateChapterSessionRequest struct {
+	Title         string    `json:"title" validate:"required,min=1,max=255"`
+	StartsAt      time.Time `json:"starts_at" validate:"required_without=StartsAtHijri"`
+	EndsAt        time.Time `json:"ends_at" validate:"required_without=EndsAtHijri"`
+	StartsAtHijri *string   `json:"starts_at_hijri,omitempty" validate:"omitempty,hijri"`
+	EndsAtHijri   *string   `json:"ends_at_hijri,omitempty" validate:"omitempty,hijri"`
+	Location      *string   `json:"location,omitempty" validate:"omitempty,max=255"`
+	MeetingURL    *string   `json:"meeting_url,omitempty" validate:"omitempty,url,max=500"`
+}
+
+type UpdateChapterSessionRequest struct {
+	Title         *string    `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	StartsAt      *time.Time `json:"starts_at,omitempty"`
+	EndsAt        *time.Time `json:"ends_at,omitempty"`
+	StartsAtHijri *string    `json:"starts_at_hijri,omitempty" validate:"omitempty,hijri"`
+	EndsAtHijri   *string    `json:"ends_at_hijri,omitempty" validate:"omitempty,hijri"`
+	Location      *string    `json:"location,omitempty" validate:"omitempty,max=255"`
+	MeetingURL    *string    `json:"meeting_url,omitempty" validate:"omitempty,url,max=500"`
+}
+
+type MarkAttendanceRequest struct {
+	Status string  `json:"status" validate:"required,oneof=present late excused absent"`
+	Note   *string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+// CheckInRequest carries the code scanned from the QR code shown in the
+// session.
+type CheckInRequest struct {
+	Code string `json:"code" validate:"required,max=100"`
+}
+
+type ChapterSessionResponse struct {
+	ID         uint      `json:"id"`
+	CourseID   uint      `json:"course_id"`
+	ChapterID  *uint     `json:"chapter_id"`
+	Title      string    `json:"title"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Location   *string   `json:"location"`
+	MeetingURL *string   `json:"meeting_url"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	StartsAtHijri *HijriDate `json:"starts_at_hijri,omitempty"`
+	EndsAtHijri   *HijriDate `json:"ends_at_hijri,omitempty"`
+}
+
+// CheckInCodeResponse is the code to show as a QR code until ExpiresAt.
+type CheckInCodeResponse struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AttendanceRecordResponse struct {
+	SessionID   uint       `json:"session_id"`
+	UserID      uint       `json:"user_id"`
+	Status      string     `json:"status"`
+	CheckedInAt *time.Time `json:"checked_in_at"`
+	MarkedBy    *uint      `json:"marked_by"`
+	Note        *string    `json:"note"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// SessionRosterEntry is an enrolled learner's attendance at one session.
+type SessionRosterEntry struct {
+	User        *UserResponse `json:"user"`
+	Status      string        `json:"status"`
+	CheckedInAt *time.Time    `json:"checked_in_at"`
+	Note        *string       `json:"note"`
+}
+
+// AttendanceSummary counts a learner's attendance over the sessions held so
+// far. Sessions without a record count as absent, and excused sessions are
+// left out of Percent.
+type AttendanceSummary struct {
+	Held    int `json:"held"`
+	Present int `json:"present"`
+	Late    int `json:"late"`
+	Excused int `json:"excused"`
+	Absent  int `json:"absent"`
+	Percent int `json:"percent"`
+}
+
+type SessionAttendance struct {
+	Session *ChapterSessionResponse `json:"session"`
+	Status  string                  `json:"status"`
+}
+
+type MyAttendanceResponse struct {
+	CourseID   uint                `json:"course_id"`
+	MinPercent *int                `json:"min_attendance_percent"`
+	Summary    AttendanceSummary   `json:"summary"`
+	Sessions   []SessionAttendance `json:"sessions"`
+}
+
+type LearnerAttendance struct {
+	User    *UserResponse     `json:"user"`
+	Summary AttendanceSummary `json:"summary"`
+	// MeetsRequirement reports whether the learner's attendance is enough
+	// for completion.
+	MeetsRequirement bool `json:"meets_requirement"`
+}
+
+type CourseAttendanceReport struct {
+	CourseID   uint                 `json:"course_id"`
+	MinPercent *int                 `json:"min_attendance_percent"`
+	Sessions   int                  `json:"sessions"`
+	Held       int                  `json:"held"`
+	Users      []*LearnerAttendance `json:"users"`
+}
+
+func (s *ChapterSession) ToResponse() *ChapterSessionResponse {
+	return &ChapterSessionResponse{
+		ID:         s.ID,
+		CourseID:   s.CourseID,
+		ChapterID:  s.ChapterID,
+		Title:      s.Title,
+		StartsAt:   s.StartsAt,
+		EndsAt:     s.EndsAt,
+		Location:   s.Location,
+		MeetingURL: s.MeetingURL,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// SetHijri adds the Hijri equivalents of the session's times when the
+// calendar asks for them.
+func (r *ChapterSessionResponse) SetHijri(calendar Calendar) *ChapterSessionResponse {
+	r.StartsAtHijri = calendar.HijriOf(&r.StartsAt)
+	r.EndsAtHijri = calendar.HijriOf(&r.EndsAt)
+	return r
+}
+
+// ResolveHijri sets the times given as Hijri dates.
+func (r *CreateChapterSessionRequest) ResolveHijri(calendar Calendar) error {
+	startsAt, endsAt := &r.StartsAt, &r.EndsAt
+	if err := calendar.resolveHijri(r.StartsAtHijri, &startsAt); err != nil {
+		return err
+	}
+	if err := calendar.resolveHijri(r.EndsAtHijri, &endsAt); err != nil {
+		return err
+	}
+	r.StartsAt, r.EndsAt = *startsAt, *endsAt
+	return nil
+}
+
+// ResolveHijri sets the times given as Hijri dates.
+func (r *UpdateChapterSessionRequest) ResolveHijri(calendar Calendar) error {
+	if err := calendar.resolveHijri(r.StartsAtHijri, &r.StartsAt); err != nil {
+		return err
+	}
+	return calendar.resolveHijri(r.EndsAtHijri, &r.EndsAt)
+}
+
+// IsHeld reports whether the session has started by now.
+func (s *ChapterSession) IsHeld(now time.Time) bool {
+	return !s.StartsAt.After(now)
+}
+
+func (r *AttendanceRecord) ToResponse() *AttendanceRecordResponse {
+	return &AttendanceRecordResponse{
+		SessionID:   r.SessionID,
+		UserID:      r.UserID,
+		Status:      r.Status,
+		CheckedInAt: r.CheckedInAt,
+		MarkedBy:    r.MarkedBy,
+		Note:        r.Note,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// SummarizeAttendance counts the statuses a learner has for the held
+// sessions, given by ID.
+func SummarizeAttendance(held []uint, statuses map[uint]string) AttendanceSummary {
+	summary := AttendanceSummary{Held: len(held)}
+	for _, sessionID := range held {
+		switch constants.AttendanceStatus(statuses[sessionID]) {
+		case constants.AttendancePresent:
+			summary.Present++
+		case constants.AttendanceLate:
+			summary.Late++
+		case constants.AttendanceExcused:
+			summary.Excused++
+		default:
+			summary.Absent++
+		}
+	}
+
+	summary.Percent = 100
+	if counted := summary.Held - summary.Excused; counted > 0 {
+		summary.Percent = (summary.Present + summary.Late) * 100 / counted
+	}
+	return summary
+}
+
+// Meets reports whether the attendance satisfies a minimum percentage. A nil
+// minimum means attendance does not count toward completion.
+func (s AttendanceSummary) Meets(minPercent *int) bool {
+	return minPercent == nil || s.Percent >= *minPercent
+}
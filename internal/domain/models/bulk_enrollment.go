@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type BulkEnrollmentJob struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID    uint       `json:"-" gorm:"not null;index"`
+	CourseID    uint       `json:"course_id" gorm:"not null"`
+	ActorID     *uint      `json:"actor_id"`
+	Status      string     `json:"status" gorm:"not null;size:20;default:'queued'"`
+	Total       int        `json:"total" gorm:"not null;default:0"`
+	Processed   int        `json:"processed" gorm:"not null;default:0"`
+	Report      *string    `json:"-" gorm:"type:text"`
+	Error       *string    `json:"error" gorm:"type:text"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type BulkEnrollmentRequest struct {
+	Nips  []string   `json:"nips" validate:"required,min=1,dive,required"`
+	DueAt *time.Time `json:"due_at,omitempty"`
+	Async bool       `json:"async,omitempty"`
+}
+
+type BulkEnrollmentRow struct {
+	Row     int    `json:"row"`
+	Nip     string `json:"nip"`
+	UserID  *uint  `json:"user_id,omitempty"`
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+}
+
+type BulkEnrollmentReport struct {
+	CourseID        uint                `json:"course_id"`
+	Total           int                 `json:"total"`
+	Created         int                 `json:"created"`
+	Reenrolled      int                 `json:"reenrolled"`
+	Waitlisted      int                 `json:"waitlisted"`
+	AlreadyEnrolled int                 `json:"already_enrolled"`
+	UnknownNip      int                 `json:"unknown_nip"`
+	InactiveUser    int                 `json:"inactive_user"`
+	Skipped         int                 `json:"skipped"`
+	Rows            []BulkEnrollmentRow `json:"rows"`
+}
+
+type BulkEnrollmentJobResponse struct {
+	ID          uint                  `json:"id"`
+	CourseID    uint                  `json:"course_id"`
+	Status      string                `json:"status"`
+	Total       int                   `json:"total"`
+	Processed   int                   `json:"processed"`
+	Error       *string               `json:"error"`
+	CompletedAt *time.Time            `json:"completed_at"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	Report      *BulkEnrollmentReport `json:"report,omitempty"`
+}
+
+func (j *BulkEnrollmentJob) ToResponse() *BulkEnrollmentJobResponse {
+	resp := &BulkEnrollmentJobResponse{
+		ID:          j.ID,
+		CourseID:    j.CourseID,
+		Status:      j.Status,
+		Total:       j.Total,
+		Processed:   j.Processed,
+		Error:       j.Error,
+		CompletedAt: j.CompletedAt,
+		CreatedAt:   j.CreatedAt,
+		UpdatedAt:   j.UpdatedAt,
+	}
+	if j.Report != nil {
+		var report BulkEnrollmentReport
+		if err := json.Unmarshal([]byte(*j.Report), &report); err == nil {
+			resp.Report = &report
+		}
+	}
+	return resp
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// CalendarFeed is a user's personal iCalendar feed. Calendar clients cannot
+// log in, so the feed is reached by a secret token in its URL; only the
+// token's SHA-256 is stored.
+type CalendarFeed struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID      uint       `json:"-" gorm:"not null;index"`
+	UserID        uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	TokenHash     string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type CalendarFeedResponse struct {
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+// CreatedCalendarFeedResponse carries the feed URL, which is shown only once.
+type CreatedCalendarFeedResponse struct {
+	*CalendarFeedResponse
+	URL string `json:"url"`
+}
+
+func (f *CalendarFeed) ToResponse() *CalendarFeedResponse {
+	return &CalendarFeedResponse{
+		CreatedAt:     f.CreatedAt,
+		LastFetchedAt: f.LastFetchedAt,
+	}
+}
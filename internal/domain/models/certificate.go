@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type Certificate struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID       uint      `json:"-" gorm:"not null;index"`
+	Number         string    `json:"number" gorm:"not null;size:50;uniqueIndex"`
+	UserID         uint      `json:"user_id" gorm:"not null"`
+	CourseID       *uint     `json:"course_id"`
+	LearningPathID *uint     `json:"learning_path_id"`
+	IssuedAt       time.Time `json:"issued_at"`
+}
+
+type CertificateResponse struct {
+	ID             uint      `json:"id"`
+	Number         string    `json:"number"`
+	UserID         uint      `json:"user_id"`
+	CourseID       *uint     `json:"course_id,omitempty"`
+	LearningPathID *uint     `json:"learning_path_id,omitempty"`
+	IssuedAt       time.Time `json:"issued_at"`
+	// CompletionDate is the issue date ready to print in both calendars.
+	CompletionDate *CertificateDate `json:"completion_date,omitempty"`
+
+	IssuedAtHijri *HijriDate `json:"issued_at_hijri,omitempty"`
+}
+
+// CertificateDate is a date as certificate templates print it.
+type CertificateDate struct {
+	Gregorian string `json:"gregorian"` // such as 19 October 2026
+	Hijri     string `json:"hijri"`     // such as 7 Jumada al-Ula 1448 AH
+}
+
+func (c *Certificate) ToResponse() *CertificateResponse {
+	return &CertificateResponse{
+		ID:             c.ID,
+		Number:         c.Number,
+		UserID:         c.UserID,
+		CourseID:       c.CourseID,
+		LearningPathID: c.LearningPathID,
+		IssuedAt:       c.IssuedAt,
+	}
+}
+
+// SetDates adds the printable completion date in both calendars, and the
+// Hijri issue date when the calendar asks for it.
+func (r *CertificateResponse) SetDates(calendar Calendar) *CertificateResponse {
+	r.CompletionDate = &CertificateDate{
+		Gregorian: r.IssuedAt.In(calendar.Location).Format("2 January 2006"),
+		Hijri:     calendar.ToHijri(r.IssuedAt).Formatted,
+	}
+	r.IssuedAtHijri = calendar.HijriOf(&r.IssuedAt)
+	return r
+}
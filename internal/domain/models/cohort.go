@@ -0,0 +1,260 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Cohort is a group of learners in a course that studies together under its
+// own mentors, such as a halaqah.
+type Cohort struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID    uint       `json:"-" gorm:"not null;index"`
+	CourseID    uint       `json:"course_id" gorm:"not null;index"`
+	Name        string     `json:"name" gorm:"not null;size:255"`
+	Description *string    `json:"description" gorm:"type:text"`
+	StartsAt    *time.Time `json:"starts_at"`
+	// DueAt is the cohort's deadline for the course. It is copied to the
+	// enrollments of the members.
+	DueAt     *time.Time `json:"due_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	Course Course `json:"-" gorm:"foreignKey:CourseID"`
+}
+
+// CohortMember places a learner in a cohort. A learner belongs to at most
+// one cohort per course.
+type CohortMember struct {
+	CohortID  uint      `json:"cohort_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	CourseID  uint      `json:"course_id" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// CohortMentor lets a mentor manage a cohort and follow its progress.
+type CohortMentor struct {
+	CohortID  uint      `json:"cohort_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// CohortAnnouncement is posted by a mentor to the members of a cohort.
+type CohortAnnouncement struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CohortID  uint      `json:"cohort_id" gorm:"not null;index"`
+	AuthorID  uint      `json:"author_id" gorm:"not null"`
+	Title     string    `json:"title" gorm:"not null;size:255"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Author User `json:"-" gorm:"foreignKey:AuthorID"`
+}
+
+// CohortPost is a discussion thread in a cohort, or a reply to one when
+// ParentID is set. Only threads have a title.
+type CohortPost struct {
+	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	CohortID  uint           `json:"cohort_id" gorm:"not null;index"`
+	ParentID  *uint          `json:"parent_id" gorm:"index"`
+	AuthorID  uint           `json:"author_id" gorm:"not null"`
+	Title     *string        `json:"title" gorm:"size:255"`
+	Body      string         `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Author User `json:"-" gorm:"foreignKey:AuthorID"`
+}
+
+// CreateCohortRequest takes dates as timestamps or as Hijri dates,
+// optionally with a clock time.
+type CreateCohortRequest struct {
+	Name          string     `json:"name" validate:"required,min=2,max=255"`
+	Description   *string    `json:"description,omitempty" validate:"omitempty,max=5000"`
+	StartsAt      *time.Time `json:"starts_at,omitempty"`
+	DueAt         *time.Time `json:"due_at,omitempty"`
+	StartsAtHijri *string    `json:"starts_at_hijri,omitempty" validate:"omitempty,hijri"`
+	DueAtHijri    *string    `json:"due_at_hijri,omitempty" validate:"omitempty,hijri"`
+}
+
+type UpdateCohortRequest struct {
+	Name          *string    `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	Description   *string    `json:"description,omitempty" validate:"omitempty,max=5000"`
+	StartsAt      *time.Time `json:"starts_at,omitempty"`
+	StartsAtHijri *string    `json:"starts_at_hijri,omitempty" validate:"omitempty,hijri"`
+}
+
+type CreateCohortAnnouncementRequest struct {
+	Title string `json:"title" validate:"required,min=1,max=255"`
+	Body  string `json:"body" validate:"required,max=10000"`
+}
+
+type CreateCohortThreadRequest struct {
+	Title string `json:"title" validate:"required,min=1,max=255"`
+	Body  string `json:"body" validate:"required,max=10000"`
+}
+
+type CreateCohortReplyRequest struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
+
+type CohortResponse struct {
+	ID          uint            `json:"id"`
+	CourseID    uint            `json:"course_id"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	StartsAt    *time.Time      `json:"starts_at"`
+	DueAt       *time.Time      `json:"due_at"`
+	Members     int64           `json:"members"`
+	Mentors     []*CohortPerson `json:"mentors"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Course      *CourseResponse `json:"course,omitempty"`
+
+	StartsAtHijri *HijriDate `json:"starts_at_hijri,omitempty"`
+	DueAtHijri    *HijriDate `json:"due_at_hijri,omitempty"`
+}
+
+// CohortPerson is how cohort members see each other and their mentors,
+// without contact details.
+type CohortPerson struct {
+	ID        uint    `json:"id"`
+	Name      string  `json:"name"`
+	AvatarURL *string `json:"avatar_url"`
+}
+
+type CohortAnnouncementResponse struct {
+	ID        uint          `json:"id"`
+	CohortID  uint          `json:"cohort_id"`
+	Title     string        `json:"title"`
+	Body      string        `json:"body"`
+	Author    *CohortPerson `json:"author"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type CohortPostResponse struct {
+	ID        uint                  `json:"id"`
+	CohortID  uint                  `json:"cohort_id"`
+	ParentID  *uint                 `json:"parent_id"`
+	Title     *string               `json:"title,omitempty"`
+	Body      string                `json:"body"`
+	Author    *CohortPerson         `json:"author"`
+	Replies   int64                 `json:"replies"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Thread    []*CohortPostResponse `json:"thread,omitempty"`
+}
+
+type CohortMemberProgress struct {
+	User            *UserResponse `json:"user"`
+	Status          string        `json:"status"`
+	ProgressPercent int           `json:"progress_percent"`
+	DueAt           *time.Time    `json:"due_at"`
+	CompletedAt     *time.Time    `json:"completed_at"`
+	Overdue         bool          `json:"overdue"`
+
+	DueAtHijri       *HijriDate `json:"due_at_hijri,omitempty"`
+	CompletedAtHijri *HijriDate `json:"completed_at_hijri,omitempty"`
+}
+
+// CohortDashboard summarizes the course progress of a cohort's members.
+type CohortDashboard struct {
+	CohortID        uint                    `json:"cohort_id"`
+	CourseID        uint                    `json:"course_id"`
+	DueAt           *time.Time              `json:"due_at"`
+	Members         int                     `json:"members"`
+	Completed       int                     `json:"completed"`
+	Overdue         int                     `json:"overdue"`
+	AverageProgress int                     `json:"average_progress"`
+	Users           []*CohortMemberProgress `json:"users"`
+
+	DueAtHijri *HijriDate `json:"due_at_hijri,omitempty"`
+}
+
+func (c *Cohort) ToResponse() *CohortResponse {
+	resp := &CohortResponse{
+		ID:          c.ID,
+		CourseID:    c.CourseID,
+		Name:        c.Name,
+		Description: c.Description,
+		StartsAt:    c.StartsAt,
+		DueAt:       c.DueAt,
+		Mentors:     []*CohortPerson{},
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+	if c.Course.ID != 0 {
+		resp.Course = c.Course.ToResponse()
+	}
+	return resp
+}
+
+// SetHijri adds the Hijri equivalents of the cohort's dates when the
+// calendar asks for them.
+func (r *CohortResponse) SetHijri(calendar Calendar) *CohortResponse {
+	r.StartsAtHijri = calendar.HijriOf(r.StartsAt)
+	r.DueAtHijri = calendar.HijriOf(r.DueAt)
+	return r
+}
+
+// SetHijri adds the Hijri equivalents of the dashboard's dates when the
+// calendar asks for them.
+func (d *CohortDashboard) SetHijri(calendar Calendar) *CohortDashboard {
+	d.DueAtHijri = calendar.HijriOf(d.DueAt)
+	for _, user := range d.Users {
+		user.DueAtHijri = calendar.HijriOf(user.DueAt)
+		user.CompletedAtHijri = calendar.HijriOf(user.CompletedAt)
+	}
+	return d
+}
+
+// ResolveHijri sets the dates given as Hijri dates.
+func (r *CreateCohortRequest) ResolveHijri(calendar Calendar) error {
+	if err := calendar.resolveHijri(r.StartsAtHijri, &r.StartsAt); err != nil {
+		return err
+	}
+	return calendar.resolveHijri(r.DueAtHijri, &r.DueAt)
+}
+
+// ResolveHijri sets the dates given as Hijri dates.
+func (r *UpdateCohortRequest) ResolveHijri(calendar Calendar) error {
+	return calendar.resolveHijri(r.StartsAtHijri, &r.StartsAt)
+}
+
+func (u *User) ToCohortPerson() *CohortPerson {
+	return &CohortPerson{
+		ID:        u.ID,
+		Name:      u.Name,
+		AvatarURL: u.AvatarURL,
+	}
+}
+
+func (a *CohortAnnouncement) ToResponse() *CohortAnnouncementResponse {
+	return &CohortAnnouncementResponse{
+		ID:        a.ID,
+		CohortID:  a.CohortID,
+		Title:     a.Title,
+		Body:      a.Body,
+		Author:    a.Author.ToCohortPerson(),
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+func (p *CohortPost) ToResponse() *CohortPostResponse {
+	return &CohortPostResponse{
+		ID:        p.ID,
+		CohortID:  p.CohortID,
+		ParentID:  p.ParentID,
+		Title:     p.Title,
+		Body:      p.Body,
+		Author:    p.Author.ToCohortPerson(),
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
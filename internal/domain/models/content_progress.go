@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type ContentProgress struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	EnrollmentID uint      `json:"enrollment_id" gorm:"not null"`
+	ContentID    uint      `json:"content_id" gorm:"not null"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+func (ContentProgress) TableName() string {
+	return "content_progress"
+}
@@ -7,12 +7,18 @@ import (
 )
 
 type Course struct {
-	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name        string         `json:"name" gorm:"not null; size:255" validate:"required,min=2,max=100"`
-	Description string         `json:"description" gorm:"type:text; not null;"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                   uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID             uint           `json:"-" gorm:"not null;index"`
+	Name                 string         `json:"name" gorm:"not null; size:255" validate:"required,min=2,max=100"`
+	Description          string         `json:"description" gorm:"type:text; not null;"`
+	EnrollmentMode       string         `json:"enrollment_mode" gorm:"not null;size:20;default:'open'"` // 'open', 'approval', 'invite', 'closed'
+	MaxSeats             *int           `json:"max_seats"`                                              // nil means unlimited
+	DefaultDueDays       *int           `json:"default_due_days"`
+	AccessDays           *int           `json:"access_days"`            // days of access from admission; nil means unlimited
+	MinAttendancePercent *int           `json:"min_attendance_percent"` // attendance needed to complete; nil means not required
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type CreateCourseRequest struct {
@@ -20,20 +26,38 @@ type CreateCourseRequest struct {
 	Description string `json:"description" validate:"required"`
 }
 
+type UpdateEnrollmentSettingsRequest struct {
+	EnrollmentMode       string `json:"enrollment_mode" validate:"required,oneof=open approval invite closed"`
+	MaxSeats             *int   `json:"max_seats,omitempty" validate:"omitempty,min=0"`
+	DefaultDueDays       *int   `json:"default_due_days,omitempty" validate:"omitempty,min=0"`
+	AccessDays           *int   `json:"access_days,omitempty" validate:"omitempty,min=0"`
+	MinAttendancePercent *int   `json:"min_attendance_percent,omitempty" validate:"omitempty,min=0,max=100"`
+}
+
 type CourseResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                   uint      `json:"id"`
+	Name                 string    `json:"name"`
+	Description          string    `json:"description"`
+	EnrollmentMode       string    `json:"enrollment_mode"`
+	MaxSeats             *int      `json:"max_seats"`
+	DefaultDueDays       *int      `json:"default_due_days"`
+	AccessDays           *int      `json:"access_days"`
+	MinAttendancePercent *int      `json:"min_attendance_percent"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 }
 
 func (u *Course) ToResponse() *CourseResponse {
 	return &CourseResponse{
-		ID:          u.ID,
-		Name:        u.Name,
-		Description: u.Description,
-		CreatedAt:   u.CreatedAt,
-		UpdatedAt:   u.UpdatedAt,
+		ID:                   u.ID,
+		Name:                 u.Name,
+		Description:          u.Description,
+		EnrollmentMode:       u.EnrollmentMode,
+		MaxSeats:             u.MaxSeats,
+		DefaultDueDays:       u.DefaultDueDays,
+		AccessDays:           u.AccessDays,
+		MinAttendancePercent: u.MinAttendancePercent,
+		CreatedAt:            u.CreatedAt,
+		UpdatedAt:            u.UpdatedAt,
 	}
 }
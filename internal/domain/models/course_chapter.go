@@ -7,6 +7,7 @@ import (
 
 type CourseChapter struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
+	TenantID     uint           `json:"-" gorm:"not null;index"`
 	CourseID     uint           `json:"course_id" gorm:"not null"`
 	Title        string         `json:"title" gorm:"type:varchar(255);not null"`
 	Description  *string        `json:"description" gorm:"type:text"`
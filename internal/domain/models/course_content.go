@@ -7,10 +7,11 @@ import (
 
 type CourseContent struct {
 	ID              uint           `json:"id" gorm:"primaryKey"`
+	TenantID        uint           `json:"-" gorm:"not null;index"`
 	ChapterID       uint           `json:"chapter_id" gorm:"not null"`
 	Title           string         `json:"title" gorm:"type:varchar(255);not null"`
 	Description     *string        `json:"description" gorm:"type:text"`
-	ContentType     string         `json:"content_type" gorm:"type:varchar(50);not null"` // 'video', 'text', 'image', 'pdf', 'link', etc.
+	ContentType     string         `json:"content_type" gorm:"type:varchar(50);not null"` // 'video', 'text', 'image', 'pdf', 'link', 'hafalan', etc.
 	FileURL         *string        `json:"file_url" gorm:"type:varchar(500)"`
 	ContentText     *string        `json:"content_text" gorm:"type:text"`
 	ContentOrder    int            `json:"content_order" gorm:"not null;default:1"`
@@ -27,7 +28,7 @@ type CreateCourseContentRequest struct {
 	ChapterID       uint    `json:"chapter_id" validate:"required"`
 	Title           string  `json:"title" validate:"required,min=1,max=255"`
 	Description     *string `json:"description,omitempty"`
-	ContentType     string  `json:"content_type" validate:"required,oneof=video text image pdf link audio document"`
+	ContentType     string  `json:"content_type" validate:"required,oneof=video text image pdf link audio document hafalan"`
 	FileURL         *string `json:"file_url,omitempty" validate:"omitempty,url"`
 	ContentText     *string `json:"content_text,omitempty"`
 	ContentOrder    int     `json:"content_order,omitempty"`
@@ -38,7 +39,7 @@ type CreateCourseContentRequest struct {
 type UpdateCourseContentRequest struct {
 	Title           *string `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
 	Description     *string `json:"description,omitempty"`
-	ContentType     *string `json:"content_type,omitempty" validate:"omitempty,oneof=video text image pdf link audio document"`
+	ContentType     *string `json:"content_type,omitempty" validate:"omitempty,oneof=video text image pdf link audio document hafalan"`
 	FileURL         *string `json:"file_url,omitempty" validate:"omitempty,url"`
 	ContentText     *string `json:"content_text,omitempty"`
 	ContentOrder    *int    `json:"content_order,omitempty"`
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type CoursePrerequisite struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CourseID       uint      `json:"course_id" gorm:"not null"`
+	PrerequisiteID uint      `json:"prerequisite_id" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	Prerequisite Course `json:"prerequisite,omitempty" gorm:"foreignKey:PrerequisiteID"`
+}
+
+type SetPrerequisitesRequest struct {
+	PrerequisiteIDs []uint `json:"prerequisite_ids" validate:"dive,required"`
+}
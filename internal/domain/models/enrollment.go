@@ -1,29 +1,231 @@
 package models
 
 import (
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
 	"gorm.io/gorm"
 )
 
 type Enrollment struct {
 	gorm.Model
-	UserID   uint `json:"user_id"`
-	CourseID uint `json:"course_id"`
-	User     User
-	Course   Course
+	TenantID         uint       `json:"-" gorm:"not null;index"`
+	UserID           uint       `json:"user_id"`
+	CourseID         uint       `json:"course_id"`
+	Status           string     `json:"status" gorm:"not null;size:20;default:'active'"`
+	ProgressPercent  int        `json:"progress_percent" gorm:"not null;default:0"`
+	EnrolledAt       time.Time  `json:"enrolled_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+	DroppedAt        *time.Time `json:"dropped_at"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	QueuedAt         *time.Time `json:"queued_at"`
+	DueAt            *time.Time `json:"due_at"`
+	CalendarSequence int        `json:"-" gorm:"not null;default:0"` // bumped when DueAt changes, for calendar feeds
+	ExpirySequence   int        `json:"-" gorm:"not null;default:0"` // bumped when ExpiresAt changes, for calendar feeds
+	User             User
+	Course           Course
+}
+
+type EnrollmentEvent struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	EnrollmentID uint      `json:"enrollment_id" gorm:"not null"`
+	Action       string    `json:"action" gorm:"not null;size:30"`
+	FromStatus   *string   `json:"from_status" gorm:"size:20"`
+	ToStatus     string    `json:"to_status" gorm:"not null;size:20"`
+	ActorID      *uint     `json:"actor_id"`
+	Note         *string   `json:"note" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	Enrollment Enrollment `json:"-" gorm:"foreignKey:EnrollmentID"`
+}
+
+type EnrollmentReminder struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	EnrollmentID uint      `json:"enrollment_id" gorm:"not null"`
+	Kind         string    `json:"kind" gorm:"not null;size:30"`
+	SentAt       time.Time `json:"sent_at"`
 }
 
 type CreateEnrollmentRequest struct {
 	CourseID uint `json:"course_id" validate:"required"`
 }
 
+type InviteEnrollmentRequest struct {
+	UserID uint `json:"user_id" validate:"required"`
+}
+
+type ReviewEnrollmentRequest struct {
+	Note *string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+// UpdateDueDateRequest takes the due date as a timestamp or as a Hijri date,
+// optionally with a clock time. Leaving both out clears the due date.
+type UpdateDueDateRequest struct {
+	DueAt      *time.Time `json:"due_at"`
+	DueAtHijri *string    `json:"due_at_hijri,omitempty" validate:"omitempty,hijri"`
+}
+
+type RemoveEnrollmentRequest struct {
+	Note *string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
 type EnrollmentResponse struct {
-	UserID   uint `json:"user_id"`
-	CourseID uint `json:"course_id"`
+	ID               uint            `json:"id"`
+	UserID           uint            `json:"user_id"`
+	CourseID         uint            `json:"course_id"`
+	Status           string          `json:"status"`
+	ProgressPercent  int             `json:"progress_percent"`
+	EnrolledAt       time.Time       `json:"enrolled_at"`
+	EnrolledAtHijri  *HijriDate      `json:"enrolled_at_hijri,omitempty"`
+	CompletedAt      *time.Time      `json:"completed_at"`
+	CompletedAtHijri *HijriDate      `json:"completed_at_hijri,omitempty"`
+	DroppedAt        *time.Time      `json:"dropped_at"`
+	ExpiresAt        *time.Time      `json:"expires_at"`
+	ExpiresAtHijri   *HijriDate      `json:"expires_at_hijri,omitempty"`
+	QueuedAt         *time.Time      `json:"queued_at,omitempty"`
+	QueuePosition    *int            `json:"queue_position,omitempty"`
+	DueAt            *time.Time      `json:"due_at"`
+	DueAtHijri       *HijriDate      `json:"due_at_hijri,omitempty"`
+	Overdue          bool            `json:"overdue"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	Course           *CourseResponse `json:"course,omitempty"`
+	User             *UserResponse   `json:"user,omitempty"`
+}
+
+type EnrollmentEventResponse struct {
+	ID           uint      `json:"id"`
+	EnrollmentID uint      `json:"enrollment_id"`
+	Action       string    `json:"action"`
+	FromStatus   *string   `json:"from_status"`
+	ToStatus     string    `json:"to_status"`
+	ActorID      *uint     `json:"actor_id"`
+	Note         *string   `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 func (u *Enrollment) ToResponse() *EnrollmentResponse {
-	return &EnrollmentResponse{
-		UserID:   u.UserID,
-		CourseID: u.CourseID,
+	resp := &EnrollmentResponse{
+		ID:              u.ID,
+		UserID:          u.UserID,
+		CourseID:        u.CourseID,
+		Status:          u.CurrentStatus().String(),
+		ProgressPercent: u.ProgressPercent,
+		EnrolledAt:      u.EnrolledAt,
+		CompletedAt:     u.CompletedAt,
+		DroppedAt:       u.DroppedAt,
+		ExpiresAt:       u.ExpiresAt,
+		QueuedAt:        u.QueuedAt,
+		DueAt:           u.DueAt,
+		Overdue:         u.IsOverdue(),
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	}
+	if u.Course.ID != 0 {
+		resp.Course = u.Course.ToResponse()
+	}
+	if u.User.ID != 0 {
+		resp.User = u.User.ToResponse()
+	}
+	return resp
+}
+
+// SetHijri adds the Hijri equivalents of the enrollment's dates when the
+// calendar asks for them.
+func (r *EnrollmentResponse) SetHijri(calendar Calendar) *EnrollmentResponse {
+	r.EnrolledAtHijri = calendar.HijriOf(&r.EnrolledAt)
+	r.CompletedAtHijri = calendar.HijriOf(r.CompletedAt)
+	r.ExpiresAtHijri = calendar.HijriOf(r.ExpiresAt)
+	r.DueAtHijri = calendar.HijriOf(r.DueAt)
+	return r
+}
+
+// CurrentStatus reports the stored status, treating an active enrollment whose
+// access window has passed as expired.
+func (u *Enrollment) CurrentStatus() constants.EnrollmentStatus {
+	status := constants.EnrollmentStatus(u.Status)
+	if status == constants.EnrollmentActive && u.ExpiresAt != nil && u.ExpiresAt.Before(time.Now()) {
+		return constants.EnrollmentExpired
+	}
+	return status
+}
+
+// IsOverdue reports whether an unfinished enrollment is past its due date.
+func (u *Enrollment) IsOverdue() bool {
+	return u.DueAt != nil && u.CompletedAt == nil && u.IsActive() && u.DueAt.Before(time.Now())
+}
+
+// ApplyDefaultDueDate sets the due date from the course default when none was
+// assigned explicitly.
+func (u *Enrollment) ApplyDefaultDueDate(course *Course) {
+	if u.DueAt != nil || course.DefaultDueDays == nil || *course.DefaultDueDays == 0 {
+		return
+	}
+	due := time.Now().AddDate(0, 0, *course.DefaultDueDays)
+	u.DueAt = &due
+}
+
+// ApplyAccessDays starts the course's access window for an enrollment being
+// admitted. Courses without an access limit leave the expiry as it is.
+func (u *Enrollment) ApplyAccessDays(course *Course) {
+	if course.AccessDays == nil || *course.AccessDays == 0 {
+		return
+	}
+	expires := time.Now().AddDate(0, 0, *course.AccessDays)
+	u.SetExpiresAt(&expires)
+}
+
+// ResolveHijri sets the due date given as a Hijri date.
+func (r *UpdateDueDateRequest) ResolveHijri(calendar Calendar) error {
+	return calendar.resolveHijri(r.DueAtHijri, &r.DueAt)
+}
+
+// SetDueAt changes the due date, raising CalendarSequence so calendar feeds
+// show the change, and reports whether the date changed.
+func (u *Enrollment) SetDueAt(due *time.Time) bool {
+	unchanged := (u.DueAt == nil && due == nil) || (u.DueAt != nil && due != nil && u.DueAt.Equal(*due))
+	if unchanged {
+		return false
+	}
+	u.DueAt = due
+	u.CalendarSequence++
+	return true
+}
+
+// SetExpiresAt changes the access expiry, raising ExpirySequence so calendar
+// feeds show the change, and reports whether the expiry changed.
+func (u *Enrollment) SetExpiresAt(expires *time.Time) bool {
+	unchanged := (u.ExpiresAt == nil && expires == nil) || (u.ExpiresAt != nil && expires != nil && u.ExpiresAt.Equal(*expires))
+	if unchanged {
+		return false
+	}
+	u.ExpiresAt = expires
+	u.ExpirySequence++
+	return true
+}
+
+func (u *Enrollment) IsActive() bool {
+	return u.CurrentStatus() == constants.EnrollmentActive
+}
+
+// HasAccess reports whether the learner can currently use the course material.
+func (u *Enrollment) HasAccess() bool {
+	switch u.CurrentStatus() {
+	case constants.EnrollmentActive, constants.EnrollmentCompleted:
+		return true
+	}
+	return false
+}
+
+func (e *EnrollmentEvent) ToResponse() *EnrollmentEventResponse {
+	return &EnrollmentEventResponse{
+		ID:           e.ID,
+		EnrollmentID: e.EnrollmentID,
+		Action:       e.Action,
+		FromStatus:   e.FromStatus,
+		ToStatus:     e.ToStatus,
+		ActorID:      e.ActorID,
+		Note:         e.Note,
+		CreatedAt:    e.CreatedAt,
 	}
 }
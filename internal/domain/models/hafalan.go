@@ -0,0 +1,184 @@
+package models
+
+import (
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/util"
+)
+
+// HafalanTarget is the range of the Qur'an, possibly spanning several
+// surahs, a hafalan content asks the learner to memorize.
+type HafalanTarget struct {
+	ContentID uint      `json:"content_id" gorm:"primaryKey"`
+	TenantID  uint      `json:"-" gorm:"not null;index"`
+	FromSurah int       `json:"from_surah" gorm:"not null"`
+	FromAyah  int       `json:"from_ayah" gorm:"not null"`
+	ToSurah   int       `json:"to_surah" gorm:"not null"`
+	ToAyah    int       `json:"to_ayah" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HafalanRecord is a learner's recitation of a range of one surah, heard and
+// graded by a mentor.
+type HafalanRecord struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID   uint      `json:"-" gorm:"not null;index"`
+	ContentID  uint      `json:"content_id" gorm:"not null"`
+	UserID     uint      `json:"user_id" gorm:"not null"`
+	Kind       string    `json:"kind" gorm:"not null;size:20"`
+	Surah      int       `json:"surah" gorm:"not null"`
+	FromAyah   int       `json:"from_ayah" gorm:"not null"`
+	ToAyah     int       `json:"to_ayah" gorm:"not null"`
+	Grade      string    `json:"grade" gorm:"not null;size:20"`
+	Note       *string   `json:"note" gorm:"size:500"`
+	RecordedBy *uint     `json:"recorded_by"`
+	RecordedAt time.Time `json:"recorded_at" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SetHafalanTargetRequest struct {
+	FromSurah int `json:"from_surah" validate:"required,min=1,max=114"`
+	FromAyah  int `json:"from_ayah" validate:"required,min=1"`
+	ToSurah   int `json:"to_surah" validate:"required,min=1,max=114"`
+	ToAyah    int `json:"to_ayah" validate:"required,min=1"`
+}
+
+// CreateHafalanRecordRequest takes the time of the recitation either as a
+// timestamp or as a Hijri date, and defaults to now.
+type CreateHafalanRecordRequest struct {
+	UserID          uint       `json:"user_id" validate:"required"`
+	Kind            string     `json:"kind" validate:"required,oneof=ziyadah murajaah"`
+	Surah           int        `json:"surah" validate:"required,min=1,max=114"`
+	FromAyah        int        `json:"from_ayah" validate:"required,min=1"`
+	ToAyah          int        `json:"to_ayah" validate:"required,min=1,gtefield=FromAyah"`
+	Grade           string     `json:"grade" validate:"required,oneof=mumtaz jayyid_jiddan jayyid maqbul rasib"`
+	Note            *string    `json:"note,omitempty" validate:"omitempty,max=500"`
+	RecordedAt      *time.Time `json:"recorded_at,omitempty"`
+	RecordedAtHijri *string    `json:"recorded_at_hijri,omitempty" validate:"omitempty,hijri"`
+}
+
+type HafalanTargetResponse struct {
+	ContentID     uint   `json:"content_id"`
+	FromSurah     int    `json:"from_surah"`
+	FromSurahName string `json:"from_surah_name"`
+	FromAyah      int    `json:"from_ayah"`
+	ToSurah       int    `json:"to_surah"`
+	ToSurahName   string `json:"to_surah_name"`
+	ToAyah        int    `json:"to_ayah"`
+	Ayahs         int    `json:"ayahs"`
+}
+
+type HafalanRecordResponse struct {
+	ID         uint      `json:"id"`
+	ContentID  uint      `json:"content_id"`
+	UserID     uint      `json:"user_id"`
+	Kind       string    `json:"kind"`
+	Surah      int       `json:"surah"`
+	SurahName  string    `json:"surah_name"`
+	FromAyah   int       `json:"from_ayah"`
+	ToAyah     int       `json:"to_ayah"`
+	Grade      string    `json:"grade"`
+	Note       *string   `json:"note"`
+	RecordedBy *uint     `json:"recorded_by"`
+	RecordedAt time.Time `json:"recorded_at"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	RecordedAtHijri *HijriDate `json:"recorded_at_hijri,omitempty"`
+}
+
+// JuzCoverage is how much of one juz a learner has memorized.
+type JuzCoverage struct {
+	Juz       int `json:"juz"`
+	Memorized int `json:"memorized"`
+	Ayahs     int `json:"ayahs"`
+	Percent   int `json:"percent"`
+}
+
+// HafalanActivityProgress is a learner's progress on the target of one
+// hafalan content.
+type HafalanActivityProgress struct {
+	Content   *CourseContentResponse `json:"content"`
+	Target    *HafalanTargetResponse `json:"target"`
+	Memorized int                    `json:"memorized"`
+	Percent   int                    `json:"percent"`
+	Completed bool                   `json:"completed"`
+}
+
+// HafalanTimelineEntry is a recitation with the learner's coverage right
+// after it.
+type HafalanTimelineEntry struct {
+	Record         *HafalanRecordResponse `json:"record"`
+	MemorizedAyahs int                    `json:"memorized_ayahs"`
+	JuzCovered     float64                `json:"juz_covered"`
+}
+
+// HafalanProgress is a learner's memorization over the hafalan contents of a
+// course. An ayah counts as memorized once it has been recited with a
+// passing grade; JuzCovered adds up the share memorized of each juz.
+type HafalanProgress struct {
+	CourseID       uint                       `json:"course_id"`
+	UserID         uint                       `json:"user_id"`
+	MemorizedAyahs int                        `json:"memorized_ayahs"`
+	JuzCovered     float64                    `json:"juz_covered"`
+	JuzCompleted   int                        `json:"juz_completed"`
+	Juz            []JuzCoverage              `json:"juz"`
+	Activities     []*HafalanActivityProgress `json:"activities"`
+	Timeline       []HafalanTimelineEntry     `json:"timeline"`
+}
+
+// Range returns the indexes of the first and last ayah of the target.
+func (t *HafalanTarget) Range() (int, int, error) {
+	return util.AyahRange(t.FromSurah, t.FromAyah, t.ToSurah, t.ToAyah)
+}
+
+// Range returns the indexes of the first and last ayah recited.
+func (r *HafalanRecord) Range() (int, int, error) {
+	return util.AyahRange(r.Surah, r.FromAyah, r.Surah, r.ToAyah)
+}
+
+func (t *HafalanTarget) ToResponse() *HafalanTargetResponse {
+	resp := &HafalanTargetResponse{
+		ContentID:     t.ContentID,
+		FromSurah:     t.FromSurah,
+		FromSurahName: util.SurahName(t.FromSurah),
+		FromAyah:      t.FromAyah,
+		ToSurah:       t.ToSurah,
+		ToSurahName:   util.SurahName(t.ToSurah),
+		ToAyah:        t.ToAyah,
+	}
+	if from, to, err := t.Range(); err == nil {
+		resp.Ayahs = to - from + 1
+	}
+	return resp
+}
+
+func (r *HafalanRecord) ToResponse() *HafalanRecordResponse {
+	return &HafalanRecordResponse{
+		ID:         r.ID,
+		ContentID:  r.ContentID,
+		UserID:     r.UserID,
+		Kind:       r.Kind,
+		Surah:      r.Surah,
+		SurahName:  util.SurahName(r.Surah),
+		FromAyah:   r.FromAyah,
+		ToAyah:     r.ToAyah,
+		Grade:      r.Grade,
+		Note:       r.Note,
+		RecordedBy: r.RecordedBy,
+		RecordedAt: r.RecordedAt,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+// SetHijri adds the Hijri date of the recitation when the calendar asks for
+// it.
+func (r *HafalanRecordResponse) SetHijri(calendar Calendar) *HafalanRecordResponse {
+	r.RecordedAtHijri = calendar.HijriOf(&r.RecordedAt)
+	return r
+}
+
+// ResolveHijri sets the time given as a Hijri date.
+func (r *CreateHafalanRecordRequest) ResolveHijri(calendar Calendar) error {
+	return calendar.resolveHijri(r.RecordedAtHijri, &r.RecordedAt)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/util"
+)
+
+// HijriDate is the Hijri equivalent of a date in a response.
+type HijriDate struct {
+	Date      string `json:"date"`      // such as 1447-09-01
+	Formatted string `json:"formatted"` // such as 1 Ramadan 1447 AH
+}
+
+// Calendar is how a request reads and renders dates: in the tenant's
+// timezone, and with Hijri equivalents when asked for.
+type Calendar struct {
+	Hijri      bool
+	Adjustment int
+	Location   *time.Location
+}
+
+type hijriKey struct{}
+
+// ContextWithHijri records whether the request asked for Hijri dates,
+// overriding the tenant's default.
+func ContextWithHijri(ctx context.Context, on bool) context.Context {
+	return context.WithValue(ctx, hijriKey{}, on)
+}
+
+// CalendarFromContext returns the calendar of the request's tenant, with the
+// request's own choice of Hijri dates applied.
+func CalendarFromContext(ctx context.Context) Calendar {
+	calendar := Calendar{Location: time.UTC}
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		settings := tenant.GetSettings()
+		calendar.Hijri = settings.HijriDates
+		calendar.Adjustment = settings.HijriAdjustment
+		if settings.Timezone != "" {
+			if loc, err := time.LoadLocation(settings.Timezone); err == nil {
+				calendar.Location = loc
+			}
+		}
+	}
+	if on, ok := ctx.Value(hijriKey{}).(bool); ok {
+		calendar.Hijri = on
+	}
+	return calendar
+}
+
+// ToHijri converts the date of t whether or not Hijri dates were asked for.
+func (c Calendar) ToHijri(t time.Time) *HijriDate {
+	date := util.HijriFromTime(t.In(c.Location).AddDate(0, 0, c.Adjustment))
+	return &HijriDate{Date: date.String(), Formatted: date.Format()}
+}
+
+// HijriOf returns the Hijri equivalent of t for a response, or nil when
+// Hijri dates were not asked for.
+func (c Calendar) HijriOf(t *time.Time) *HijriDate {
+	if !c.Hijri || t == nil {
+		return nil
+	}
+	return c.ToHijri(*t)
+}
+
+// ParseHijri reads a Hijri date, optionally with a clock time, given in the
+// calendar's timezone.
+func (c Calendar) ParseHijri(value string) (time.Time, error) {
+	t, err := util.ParseHijri(value, c.Location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.AddDate(0, 0, -c.Adjustment), nil
+}
+
+// resolveHijri replaces *dst with the time a Hijri input stands for, when
+// one was given.
+func (c Calendar) resolveHijri(hijri *string, dst **time.Time) error {
+	if hijri == nil {
+		return nil
+	}
+	t, err := c.ParseHijri(*hijri)
+	if err != nil {
+		return err
+	}
+	*dst = &t
+	return nil
+}
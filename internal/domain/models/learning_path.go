@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LearningPath struct {
+	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID    uint           `json:"-" gorm:"not null;index"`
+	Name        string         `json:"name" gorm:"not null;size:255"`
+	Description *string        `json:"description" gorm:"type:text"`
+	IsPublished bool           `json:"is_published" gorm:"not null;default:false"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Courses []LearningPathCourse `json:"courses,omitempty" gorm:"foreignKey:LearningPathID"`
+}
+
+type LearningPathCourse struct {
+	ID             uint `json:"id" gorm:"primaryKey;autoIncrement"`
+	LearningPathID uint `json:"learning_path_id" gorm:"not null"`
+	CourseID       uint `json:"course_id" gorm:"not null"`
+	Position       int  `json:"position" gorm:"not null;default:1"`
+
+	Course Course `json:"course,omitempty" gorm:"foreignKey:CourseID"`
+}
+
+type LearningPathEnrollment struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	LearningPathID uint       `json:"learning_path_id" gorm:"not null"`
+	UserID         uint       `json:"user_id" gorm:"not null"`
+	EnrolledAt     time.Time  `json:"enrolled_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+
+	LearningPath LearningPath `json:"-" gorm:"foreignKey:LearningPathID"`
+}
+
+type CreateLearningPathRequest struct {
+	Name        string  `json:"name" validate:"required,min=2,max=255"`
+	Description *string `json:"description,omitempty"`
+	IsPublished bool    `json:"is_published,omitempty"`
+	CourseIDs   []uint  `json:"course_ids,omitempty" validate:"dive,required"`
+}
+
+type UpdateLearningPathRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	Description *string `json:"description,omitempty"`
+	IsPublished *bool   `json:"is_published,omitempty"`
+}
+
+type SetLearningPathCoursesRequest struct {
+	CourseIDs []uint `json:"course_ids" validate:"required,min=1,dive,required"`
+}
+
+type LearningPathCourseResponse struct {
+	Position int             `json:"position"`
+	Course   *CourseResponse `json:"course"`
+}
+
+type LearningPathResponse struct {
+	ID          uint                          `json:"id"`
+	Name        string                        `json:"name"`
+	Description *string                       `json:"description"`
+	IsPublished bool                          `json:"is_published"`
+	Courses     []*LearningPathCourseResponse `json:"courses"`
+	CreatedAt   time.Time                     `json:"created_at"`
+	UpdatedAt   time.Time                     `json:"updated_at"`
+}
+
+type LearningPathCourseProgress struct {
+	Position  int             `json:"position"`
+	Course    *CourseResponse `json:"course"`
+	Status    *string         `json:"status"`
+	Completed bool            `json:"completed"`
+}
+
+type LearningPathProgressResponse struct {
+	LearningPath     *LearningPathResponse         `json:"learning_path"`
+	EnrolledAt       time.Time                     `json:"enrolled_at"`
+	CompletedAt      *time.Time                    `json:"completed_at"`
+	CompletedCourses int                           `json:"completed_courses"`
+	TotalCourses     int                           `json:"total_courses"`
+	ProgressPercent  int                           `json:"progress_percent"`
+	Courses          []*LearningPathCourseProgress `json:"courses"`
+	Certificate      *CertificateResponse          `json:"certificate,omitempty"`
+}
+
+func (p *LearningPath) ToResponse() *LearningPathResponse {
+	courses := make([]*LearningPathCourseResponse, len(p.Courses))
+	for i, pc := range p.Courses {
+		courses[i] = &LearningPathCourseResponse{
+			Position: pc.Position,
+			Course:   pc.Course.ToResponse(),
+		}
+	}
+
+	return &LearningPathResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		IsPublished: p.IsPublished,
+		Courses:     courses,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
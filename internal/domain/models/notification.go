@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID  uint       `json:"-" gorm:"not null;index"`
+	UserID    uint       `json:"user_id" gorm:"not null"`
+	Type      string     `json:"type" gorm:"not null;size:50"`
+	Title     string     `json:"title" gorm:"not null;size:255"`
+	Body      string     `json:"body" gorm:"type:text"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (n *Notification) ToResponse() *NotificationResponse {
+	return &NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Body:      n.Body,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
@@ -0,0 +1,166 @@
+package models
+
+import "time"
+
+// OrgUnit is a department or unit in the organization tree.
+type OrgUnit struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID  uint      `json:"-" gorm:"not null;index"`
+	ParentID  *uint     `json:"parent_id"`
+	Name      string    `json:"name" gorm:"not null;size:255"`
+	Code      string    `json:"code" gorm:"uniqueIndex;not null;size:50"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrgUnitAdmin lets a user manage a unit and all of its sub-units.
+type OrgUnitAdmin struct {
+	OrgUnitID uint      `json:"org_unit_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// OrgUnitCourse makes a course mandatory for a unit's members, and with
+// IncludeSubUnits for the members of its sub-units too.
+type OrgUnitCourse struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrgUnitID       uint      `json:"org_unit_id" gorm:"not null"`
+	CourseID        uint      `json:"course_id" gorm:"not null"`
+	IncludeSubUnits bool      `json:"include_sub_units" gorm:"not null;default:true"`
+	DueInDays       *int      `json:"due_in_days"`
+	AssignedBy      *uint     `json:"assigned_by"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	Course Course `json:"-" gorm:"foreignKey:CourseID"`
+}
+
+// DueAt returns the due date for an enrollment made at from, or nil to use
+// the course default.
+func (c *OrgUnitCourse) DueAt(from time.Time) *time.Time {
+	if c.DueInDays == nil {
+		return nil
+	}
+	due := from.AddDate(0, 0, *c.DueInDays)
+	return &due
+}
+
+type CreateOrgUnitRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=255"`
+	Code     string `json:"code" validate:"required,max=50"`
+	ParentID *uint  `json:"parent_id,omitempty"`
+}
+
+type UpdateOrgUnitRequest struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	Code *string `json:"code,omitempty" validate:"omitempty,min=1,max=50"`
+}
+
+// MoveOrgUnitRequest moves a unit under another one, or to the top level
+// when ParentID is null.
+type MoveOrgUnitRequest struct {
+	ParentID *uint `json:"parent_id"`
+}
+
+type AssignUnitCourseRequest struct {
+	CourseID        uint  `json:"course_id" validate:"required"`
+	IncludeSubUnits *bool `json:"include_sub_units,omitempty"`
+	DueInDays       *int  `json:"due_in_days,omitempty" validate:"omitempty,min=1,max=3650"`
+}
+
+type OrgUnitResponse struct {
+	ID        uint               `json:"id"`
+	ParentID  *uint              `json:"parent_id"`
+	Name      string             `json:"name"`
+	Code      string             `json:"code"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Children  []*OrgUnitResponse `json:"children,omitempty"`
+}
+
+type OrgUnitCourseResponse struct {
+	ID              uint            `json:"id"`
+	OrgUnitID       uint            `json:"org_unit_id"`
+	IncludeSubUnits bool            `json:"include_sub_units"`
+	DueInDays       *int            `json:"due_in_days"`
+	AssignedBy      *uint           `json:"assigned_by"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Course          *CourseResponse `json:"course"`
+	// Inherited marks an assignment made on an ancestor unit.
+	Inherited bool `json:"inherited"`
+}
+
+// OrgUnitCourseAssignmentResponse is the new assignment with the result of
+// enrolling the current members: a report, or a job for large units.
+type OrgUnitCourseAssignmentResponse struct {
+	Assignment *OrgUnitCourseResponse     `json:"assignment"`
+	Report     *BulkEnrollmentReport      `json:"report,omitempty"`
+	Job        *BulkEnrollmentJobResponse `json:"job,omitempty"`
+}
+
+type MandatoryCourseStatus struct {
+	CourseID    uint       `json:"course_id"`
+	CourseName  string     `json:"course_name"`
+	Status      string     `json:"status"`
+	DueAt       *time.Time `json:"due_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	Overdue     bool       `json:"overdue"`
+
+	DueAtHijri       *HijriDate `json:"due_at_hijri,omitempty"`
+	CompletedAtHijri *HijriDate `json:"completed_at_hijri,omitempty"`
+}
+
+// SetHijri adds the Hijri equivalents of the status's dates when the
+// calendar asks for them.
+func (s *MandatoryCourseStatus) SetHijri(calendar Calendar) *MandatoryCourseStatus {
+	s.DueAtHijri = calendar.HijriOf(s.DueAt)
+	s.CompletedAtHijri = calendar.HijriOf(s.CompletedAt)
+	return s
+}
+
+type OrgUnitMemberReport struct {
+	User      *UserResponse           `json:"user"`
+	Assigned  int                     `json:"assigned"`
+	Completed int                     `json:"completed"`
+	Overdue   int                     `json:"overdue"`
+	Courses   []MandatoryCourseStatus `json:"courses"`
+}
+
+// OrgUnitReport summarizes mandatory course completion for the members of a
+// unit and its sub-units.
+type OrgUnitReport struct {
+	OrgUnitID uint                   `json:"org_unit_id"`
+	Members   int                    `json:"members"`
+	Assigned  int                    `json:"assigned"`
+	Completed int                    `json:"completed"`
+	Overdue   int                    `json:"overdue"`
+	Users     []*OrgUnitMemberReport `json:"users"`
+}
+
+func (u *OrgUnit) ToResponse() *OrgUnitResponse {
+	return &OrgUnitResponse{
+		ID:        u.ID,
+		ParentID:  u.ParentID,
+		Name:      u.Name,
+		Code:      u.Code,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+func (c *OrgUnitCourse) ToResponse(unitID uint) *OrgUnitCourseResponse {
+	resp := &OrgUnitCourseResponse{
+		ID:              c.ID,
+		OrgUnitID:       c.OrgUnitID,
+		IncludeSubUnits: c.IncludeSubUnits,
+		DueInDays:       c.DueInDays,
+		AssignedBy:      c.AssignedBy,
+		CreatedAt:       c.CreatedAt,
+		Inherited:       c.OrgUnitID != unitID,
+	}
+	if c.Course.ID != 0 {
+		resp.Course = c.Course.ToResponse()
+	}
+	return resp
+}
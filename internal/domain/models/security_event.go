@@ -0,0 +1,49 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+type SecurityEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID  uint      `json:"-" gorm:"not null;index"`
+	UserID    *uint     `json:"user_id"`
+	ActorID   *uint     `json:"actor_id"`
+	Type      string    `json:"type" gorm:"not null;size:50"`
+	IPAddress *string   `json:"ip_address" gorm:"size:64"`
+	UserAgent *string   `json:"user_agent" gorm:"size:500"`
+	Details   *string   `json:"details" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClientInfo describes the client making a request, for throttling and audit
+// purposes.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type clientInfoKey struct{}
+
+// ContextWithClientInfo attaches the requesting client to ctx so services can
+// record it without every call passing it along.
+func ContextWithClientInfo(ctx context.Context, client *ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, client)
+}
+
+// ClientInfoFromContext returns the client attached to ctx, or an empty
+// ClientInfo.
+func ClientInfoFromContext(ctx context.Context) *ClientInfo {
+	if client, ok := ctx.Value(clientInfoKey{}).(*ClientInfo); ok {
+		return client
+	}
+	return &ClientInfo{}
+}
+
+type SecurityEventFilter struct {
+	UserID *uint
+	Type   string
+	Offset int
+	Limit  int
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Tenant is an institution hosted on the deployment. Tenants are resolved
+// per request from a header, a custom domain or a subdomain.
+type Tenant struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Slug         string    `json:"slug" gorm:"uniqueIndex;not null;size:63"`
+	Name         string    `json:"name" gorm:"not null;size:255"`
+	Domain       *string   `json:"domain" gorm:"uniqueIndex;size:255"`
+	IsActive     bool      `json:"is_active" gorm:"not null;default:true"`
+	LogoURL      *string   `json:"logo_url" gorm:"size:500"`
+	PrimaryColor *string   `json:"primary_color" gorm:"size:7"`
+	SupportEmail *string   `json:"support_email" gorm:"size:255"`
+	Settings     string    `json:"-" gorm:"type:text;not null;default:'{}'"` // JSON TenantSettings
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TenantSettings override deployment configuration for one tenant. Empty
+// values fall back to the configuration.
+type TenantSettings struct {
+	// AllowRegistration turns self-registration off when false.
+	AllowRegistration *bool  `json:"allow_registration,omitempty"`
+	Locale            string `json:"locale,omitempty" validate:"omitempty,max=10"`
+	Timezone          string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	// PasswordResetURL and EmailVerificationURL point the links in emails at
+	// the tenant's own frontend.
+	PasswordResetURL     string `json:"password_reset_url,omitempty" validate:"omitempty,url"`
+	EmailVerificationURL string `json:"email_verification_url,omitempty" validate:"omitempty,url"`
+	// HijriDates adds Hijri equivalents to dates in responses unless a
+	// request asks otherwise.
+	HijriDates bool `json:"hijri_dates,omitempty"`
+	// HijriAdjustment shifts the tabular Hijri calendar by whole days to
+	// follow the local sighting of the new moon.
+	HijriAdjustment int `json:"hijri_adjustment,omitempty" validate:"min=-2,max=2"`
+}
+
+type CreateTenantRequest struct {
+	Slug   string  `json:"slug" validate:"required,min=2,max=63,hostname_rfc1123,lowercase"`
+	Name   string  `json:"name" validate:"required,min=2,max=255"`
+	Domain *string `json:"domain,omitempty" validate:"omitempty,fqdn"`
+}
+
+// UpdateTenantBrandingRequest is what a tenant's administrators may change
+// about their own tenant.
+type UpdateTenantBrandingRequest struct {
+	Name         *string         `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	LogoURL      *string         `json:"logo_url,omitempty" validate:"omitempty,url,max=500"`
+	PrimaryColor *string         `json:"primary_color,omitempty" validate:"omitempty,hexcolor,len=7"`
+	SupportEmail *string         `json:"support_email,omitempty" validate:"omitempty,email"`
+	Settings     *TenantSettings `json:"settings,omitempty"`
+}
+
+// TenantBrandingResponse is the public view of the current tenant, used by
+// frontends to theme themselves before anyone signs in.
+type TenantBrandingResponse struct {
+	Slug              string  `json:"slug"`
+	Name              string  `json:"name"`
+	LogoURL           *string `json:"logo_url"`
+	PrimaryColor      *string `json:"primary_color"`
+	SupportEmail      *string `json:"support_email"`
+	Locale            string  `json:"locale,omitempty"`
+	Timezone          string  `json:"timezone,omitempty"`
+	AllowRegistration bool    `json:"allow_registration"`
+}
+
+type TenantResponse struct {
+	ID           uint           `json:"id"`
+	Slug         string         `json:"slug"`
+	Name         string         `json:"name"`
+	Domain       *string        `json:"domain"`
+	IsActive     bool           `json:"is_active"`
+	LogoURL      *string        `json:"logo_url"`
+	PrimaryColor *string        `json:"primary_color"`
+	SupportEmail *string        `json:"support_email"`
+	Settings     TenantSettings `json:"settings"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// GetSettings decodes the tenant's settings. Malformed settings are treated
+// as empty.
+func (t *Tenant) GetSettings() TenantSettings {
+	var settings TenantSettings
+	if t.Settings != "" {
+		_ = json.Unmarshal([]byte(t.Settings), &settings)
+	}
+	return settings
+}
+
+func (t *Tenant) SetSettings(settings TenantSettings) error {
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	t.Settings = string(encoded)
+	return nil
+}
+
+// RegistrationAllowed reports whether users may sign themselves up.
+func (t *Tenant) RegistrationAllowed() bool {
+	settings := t.GetSettings()
+	return settings.AllowRegistration == nil || *settings.AllowRegistration
+}
+
+func (t *Tenant) ToResponse() *TenantResponse {
+	return &TenantResponse{
+		ID:           t.ID,
+		Slug:         t.Slug,
+		Name:         t.Name,
+		Domain:       t.Domain,
+		IsActive:     t.IsActive,
+		LogoURL:      t.LogoURL,
+		PrimaryColor: t.PrimaryColor,
+		SupportEmail: t.SupportEmail,
+		Settings:     t.GetSettings(),
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+func (t *Tenant) ToBrandingResponse() *TenantBrandingResponse {
+	settings := t.GetSettings()
+	return &TenantBrandingResponse{
+		Slug:              t.Slug,
+		Name:              t.Name,
+		LogoURL:           t.LogoURL,
+		PrimaryColor:      t.PrimaryColor,
+		SupportEmail:      t.SupportEmail,
+		Locale:            settings.Locale,
+		Timezone:          settings.Timezone,
+		AllowRegistration: t.RegistrationAllowed(),
+	}
+}
+
+type tenantKey struct{}
+
+// ContextWithTenant attaches the resolved tenant to ctx so services can read
+// its settings.
+func ContextWithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant attached to ctx, or nil outside a
+// request.
+func TenantFromContext(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return tenant
+}
@@ -1,27 +1,56 @@
 package models
 
-import (
-	"time"
-
-	"gorm.io/gorm"
-)
+import "time"
 
+// RefreshToken is a login session. The row is kept across refreshes, only
+// the token hash and expiry are rotated, so its ID identifies the session.
 type RefreshToken struct {
-	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID    uint           `json:"user_id" gorm:"not null"`
-	Token     string         `json:"token" gorm:"uniqueIndex;not null;size:500"`
-	ExpiresAt time.Time      `json:"expires_at"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     uint       `json:"user_id" gorm:"not null"`
+	Token      string     `json:"-" gorm:"uniqueIndex;not null;size:255"` // SHA-256 of the issued token
+	DeviceName *string    `json:"device_name" gorm:"size:255"`
+	IPAddress  *string    `json:"ip_address" gorm:"size:64"`
+	UserAgent  *string    `json:"user_agent" gorm:"size:500"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
 
 	User User `json:"user" gorm:"constraint:OnUpdate:Cascade,OnDelete:CASCADE;"`
 }
 
 type TokenResponse struct {
-	AccessToken  string        `json:"access_token"`
-	RefreshToken string        `json:"refresh_token"`
+	AccessToken  string        `json:"access_token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
 	ExpiresAt    int64         `json:"expires_at"`
 	TokenType    string        `json:"token_type"`
 	User         *UserResponse `json:"user,omitempty"`
+
+	// Set instead of the token pair when the login needs a second factor.
+	MFARequired      bool   `json:"mfa_required,omitempty"`
+	MFASetupRequired bool   `json:"mfa_setup_required,omitempty"`
+	ChallengeToken   string `json:"challenge_token,omitempty"`
+}
+
+type SessionResponse struct {
+	ID         uint       `json:"id"`
+	DeviceName *string    `json:"device_name"`
+	IPAddress  *string    `json:"ip_address"`
+	UserAgent  *string    `json:"user_agent"`
+	Current    bool       `json:"current"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
+
+func (t *RefreshToken) ToSessionResponse(currentID uint) *SessionResponse {
+	return &SessionResponse{
+		ID:         t.ID,
+		DeviceName: t.DeviceName,
+		IPAddress:  t.IPAddress,
+		UserAgent:  t.UserAgent,
+		Current:    t.ID == currentID,
+		CreatedAt:  t.CreatedAt,
+		LastUsedAt: t.LastUsedAt,
+		ExpiresAt:  t.ExpiresAt,
+	}
 }
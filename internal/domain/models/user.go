@@ -8,16 +8,30 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name      string         `json:"name" gorm:"not null; size:255" validate:"required,min=2,max=100"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null;size:255" validate:"required,email"`
-	Nip       string         `json:"nip" gorm:"uniqueIndex;not null;size:12" validate:"required,min=12,max=12"`
-	Password  string         `json:"-" gorm:"not null;size:255"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	Role      string         `json:"role" gorm:"not null;size:50;default:'user'" validate:"required"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID        uint           `json:"-" gorm:"not null;index"`
+	Name            string         `json:"name" gorm:"not null; size:255" validate:"required,min=2,max=100"`
+	Email           string         `json:"email" gorm:"uniqueIndex;not null;size:255" validate:"required,email"`
+	Nip             string         `json:"nip" gorm:"uniqueIndex;not null;size:12" validate:"required,min=12,max=12"`
+	Password        string         `json:"-" gorm:"not null;size:255"`
+	IsActive        bool           `json:"is_active" gorm:"default:true"`
+	AuthSource      string         `json:"auth_source" gorm:"not null;size:20;default:'local'"`
+	OrgUnitID       *uint          `json:"org_unit_id"`
+	Role            string         `json:"role" gorm:"not null;size:50;default:'user'" validate:"required"`
+	AvatarURL       *string        `json:"avatar_url" gorm:"size:500"`
+	Phone           *string        `json:"phone" gorm:"size:20"`
+	Bio             *string        `json:"bio" gorm:"type:text"`
+	PendingEmail    *string        `json:"pending_email" gorm:"size:255"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	TOTPSecret      *string        `json:"-" gorm:"column:totp_secret;size:64"`
+	TOTPEnabledAt   *time.Time     `json:"-" gorm:"column:totp_enabled_at"`
+	TOTPLastStep    *int64         `json:"-" gorm:"column:totp_last_step"`
+	FailedLogins    int            `json:"-" gorm:"column:failed_login_attempts;not null;default:0"`
+	LastFailedLogin *time.Time     `json:"-" gorm:"column:last_failed_login_at"`
+	LockedUntil     *time.Time     `json:"locked_until"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type CreateUserRequest struct {
@@ -26,6 +40,60 @@ type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 	Role     string `json:"role" validate:"required"`
+	// EmailVerified marks the address as verified on creation. It is never
+	// read from request bodies; only trusted callers such as the command
+	// line tools set it.
+	EmailVerified bool `json:"-"`
+}
+
+type UpdateProfileRequest struct {
+	Name      *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Email     *string `json:"email,omitempty" validate:"omitempty,email"`
+	AvatarURL *string `json:"avatar_url,omitempty" validate:"omitempty,url,max=500"`
+	Phone     *string `json:"phone,omitempty" validate:"omitempty,min=6,max=20"`
+	Bio       *string `json:"bio,omitempty" validate:"omitempty,max=1000"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type AdminUpdateUserRequest struct {
+	Name  *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Email *string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" validate:"required"`
+}
+
+// AdminResetPasswordRequest sets a user's password. Without a password a
+// temporary one is generated and returned once.
+type AdminResetPasswordRequest struct {
+	Password string `json:"password,omitempty"`
+}
+
+type AdminResetPasswordResponse struct {
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+}
+
+// UserFilter narrows the admin user search. Query matches name, NIP or email;
+// the other text fields match their own column. Status is one of the
+// constants.UserStatus values.
+type UserFilter struct {
+	Query  string
+	Name   string
+	Nip    string
+	Email  string
+	Role   string
+	Status string
+	Offset int
+	Limit  int
 }
 
 type LoginRequest struct {
@@ -34,24 +102,75 @@ type LoginRequest struct {
 }
 
 type UserResponse struct {
-	ID        uint `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	IsActive  bool      `json:"is_active"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Nip             string     `json:"nip"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	AuthSource      string     `json:"auth_source"`
+	OrgUnitID       *uint      `json:"org_unit_id"`
+	Role            string     `json:"role"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ProfileResponse struct {
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	PendingEmail    *string    `json:"pending_email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	Nip             string     `json:"nip"`
+	Role            string     `json:"role"`
+	AvatarURL       *string    `json:"avatar_url"`
+	Phone           *string    `json:"phone"`
+	Bio             *string    `json:"bio"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 func (u *User) ToResponse() *UserResponse {
-	return &UserResponse{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		IsActive:  u.IsActive,
-		Role:      u.Role,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+	response := &UserResponse{
+		ID:              u.ID,
+		Name:            u.Name,
+		Email:           u.Email,
+		Nip:             u.Nip,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		MFAEnabled:      u.IsMFAEnabled(),
+		LockedUntil:     u.LockedUntil,
+		IsActive:        u.IsActive,
+		AuthSource:      u.AuthSource,
+		OrgUnitID:       u.OrgUnitID,
+		Role:            u.Role,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	}
+	if u.DeletedAt.Valid {
+		response.DeletedAt = &u.DeletedAt.Time
+	}
+	return response
+}
+
+func (u *User) ToProfileResponse() *ProfileResponse {
+	return &ProfileResponse{
+		ID:              u.ID,
+		Name:            u.Name,
+		Email:           u.Email,
+		PendingEmail:    u.PendingEmail,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		MFAEnabled:      u.IsMFAEnabled(),
+		Nip:             u.Nip,
+		Role:            u.Role,
+		AvatarURL:       u.AvatarURL,
+		Phone:           u.Phone,
+		Bio:             u.Bio,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
 	}
 }
 
@@ -76,6 +195,19 @@ func (u *User) IsMentor() bool {
 	return u.HasRole(constants.RoleMentor)
 }
 
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *User) IsMFAEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// IsLocked reports whether the account is temporarily locked out.
+func (u *User) IsLocked() bool {
+	return u.LockedUntil != nil && time.Now().Before(*u.LockedUntil)
+}
+
 func (u *User) IsUser() bool {
 	return u.HasRole(constants.RoleUser)
 }
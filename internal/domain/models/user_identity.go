@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// UserIdentity links a local account to an account at an external identity
+// provider.
+type UserIdentity struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID    uint       `json:"-" gorm:"not null;index"`
+	UserID      uint       `json:"user_id" gorm:"not null"`
+	Provider    string     `json:"provider" gorm:"not null;size:50"`
+	Subject     string     `json:"subject" gorm:"not null;size:255"`
+	Email       *string    `json:"email" gorm:"size:255"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OIDCLoginState is the server side half of an authorization request. Only
+// the hash of the state parameter is stored. TenantID is the tenant the flow
+// started in, which the callback continues in.
+type OIDCLoginState struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint      `gorm:"not null;index"`
+	StateHash    string    `gorm:"not null;size:64;uniqueIndex"`
+	Nonce        string    `gorm:"not null;size:255"`
+	CodeVerifier string    `gorm:"not null;size:255"`
+	LinkUserID   *uint     `gorm:"column:link_user_id"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	CreatedAt    time.Time
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
+
+type OIDCCallbackRequest struct {
+	State            string `query:"state"`
+	Code             string `query:"code"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+}
+
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OIDCCallbackResponse is the outcome of a provider callback: a login result
+// for sign-in flows, or the new identity for account linking.
+type OIDCCallbackResponse struct {
+	Tokens   *TokenResponse `json:"tokens,omitempty"`
+	Identity *UserIdentity  `json:"identity,omitempty"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UserImport records a roster import and its per-row report.
+type UserImport struct {
+	ID                uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID          uint      `json:"-" gorm:"not null;index"`
+	ActorID           *uint     `json:"actor_id"`
+	FileName          string    `json:"file_name" gorm:"not null;size:255"`
+	DryRun            bool      `json:"dry_run" gorm:"not null;default:false"`
+	DeactivateMissing bool      `json:"deactivate_missing" gorm:"not null;default:false"`
+	Total             int       `json:"total" gorm:"not null;default:0"`
+	Created           int       `json:"created" gorm:"not null;default:0"`
+	Updated           int       `json:"updated" gorm:"not null;default:0"`
+	Unchanged         int       `json:"unchanged" gorm:"not null;default:0"`
+	Failed            int       `json:"failed" gorm:"not null;default:0"`
+	Deactivated       int       `json:"deactivated" gorm:"not null;default:0"`
+	Report            *string   `json:"-" gorm:"type:text"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type UserImportOptions struct {
+	FileName          string
+	DryRun            bool
+	DeactivateMissing bool
+	// VerifyEmails marks the imported addresses as verified, for rosters
+	// taken from a trusted source such as the HR system.
+	VerifyEmails bool
+}
+
+type UserImportRow struct {
+	Row    int      `json:"row"`
+	Nip    string   `json:"nip"`
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Role   string   `json:"role"`
+	UserID *uint    `json:"user_id,omitempty"`
+	Result string   `json:"result"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type UserImportDeactivation struct {
+	UserID uint   `json:"user_id"`
+	Nip    string `json:"nip"`
+	Name   string `json:"name"`
+}
+
+type UserImportReport struct {
+	Rows        []UserImportRow          `json:"rows"`
+	Deactivated []UserImportDeactivation `json:"deactivated"`
+}
+
+type UserImportResponse struct {
+	ID                uint              `json:"id"`
+	FileName          string            `json:"file_name"`
+	DryRun            bool              `json:"dry_run"`
+	DeactivateMissing bool              `json:"deactivate_missing"`
+	Total             int               `json:"total"`
+	Created           int               `json:"created"`
+	Updated           int               `json:"updated"`
+	Unchanged         int               `json:"unchanged"`
+	Failed            int               `json:"failed"`
+	Deactivated       int               `json:"deactivated"`
+	CreatedAt         time.Time         `json:"created_at"`
+	Report            *UserImportReport `json:"report,omitempty"`
+}
+
+func (i *UserImport) ToResponse() *UserImportResponse {
+	resp := &UserImportResponse{
+		ID:                i.ID,
+		FileName:          i.FileName,
+		DryRun:            i.DryRun,
+		DeactivateMissing: i.DeactivateMissing,
+		Total:             i.Total,
+		Created:           i.Created,
+		Updated:           i.Updated,
+		Unchanged:         i.Unchanged,
+		Failed:            i.Failed,
+		Deactivated:       i.Deactivated,
+		CreatedAt:         i.CreatedAt,
+	}
+	resp.Report = i.GetReport()
+	return resp
+}
+
+// GetReport decodes the stored report, or returns nil if there is none.
+func (i *UserImport) GetReport() *UserImportReport {
+	if i.Report == nil {
+		return nil
+	}
+	var report UserImportReport
+	if err := json.Unmarshal([]byte(*i.Report), &report); err != nil {
+		return nil
+	}
+	return &report
+}
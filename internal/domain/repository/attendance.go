@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AttendanceRepository interface {
+	CreateSession(ctx context.Context, session *models.ChapterSession) error
+	GetSession(ctx context.Context, id uint) (*models.ChapterSession, error)
+	UpdateSession(ctx context.Context, session *models.ChapterSession) error
+	DeleteSession(ctx context.Context, id uint) error
+	ListSessionsByChapter(ctx context.Context, chapterID uint) ([]*models.ChapterSession, error)
+	ListSessionsByCourse(ctx context.Context, courseID uint) ([]*models.ChapterSession, error)
+	// ListCalendarSessions returns the sessions of the courses that end after
+	// since, deleted ones included so feeds can publish them as cancelled.
+	ListCalendarSessions(ctx context.Context, courseIDs []uint, since time.Time) ([]*models.ChapterSession, error)
+	// HeldSessionIDs returns the course's sessions that started before the
+	// given time.
+	HeldSessionIDs(ctx context.Context, courseID uint, before time.Time) ([]uint, error)
+	GetRecord(ctx context.Context, sessionID, userID uint) (*models.AttendanceRecord, error)
+	// SaveRecord creates the learner's record for the session or replaces
+	// the existing one.
+	SaveRecord(ctx context.Context, record *models.AttendanceRecord) error
+	ListRecordsBySession(ctx context.Context, sessionID uint) ([]*models.AttendanceRecord, error)
+	// ListRecordsByCourse returns the records of the users for all sessions
+	// of the course.
+	ListRecordsByCourse(ctx context.Context, courseID uint, userIDs []uint) ([]*models.AttendanceRecord, error)
+}
+
+type attendanceRepository struct {
+	db *gorm.DB
+}
+
+func NewAttendanceRepository(db *gorm.DB) AttendanceRepository {
+	return &attendanceRepository{db}
+}
+
+func (r *attendanceRepository) CreateSession(ctx context.Context, session *models.ChapterSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *attendanceRepository) GetSession(ctx context.Context, id uint) (*models.ChapterSession, error) {
+	var session models.ChapterSession
+	if err := r.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *attendanceRepository) UpdateSession(ctx context.Context, session *models.ChapterSession) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+func (r *attendanceRepository) DeleteSession(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ChapterSession{}, "id = ?", id).Error
+}
+
+func (r *attendanceRepository) ListSessionsByChapter(ctx context.Context, chapterID uint) ([]*models.ChapterSession, error) {
+	var sessions []*models.ChapterSession
+	err := r.db.WithContext(ctx).Where("chapter_id = ?", chapterID).Order("starts_at ASC, id ASC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *attendanceRepository) ListSessionsByCourse(ctx context.Context, courseID uint) ([]*models.ChapterSession, error) {
+	var sessions []*models.ChapterSession
+	err := r.db.WithContext(ctx).Where("course_id = ?", courseID).Order("starts_at ASC, id ASC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *attendanceRepository) ListCalendarSessions(ctx context.Context, courseIDs []uint, since time.Time) ([]*models.ChapterSession, error) {
+	var sessions []*models.ChapterSession
+	if len(courseIDs) == 0 {
+		return sessions, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("course_id IN ? AND ends_at >= ?", courseIDs, since).
+		Order("starts_at ASC, id ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *attendanceRepository) HeldSessionIDs(ctx context.Context, courseID uint, before time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&models.ChapterSession{}).
+		Where("course_id = ? AND starts_at <= ?", courseID, before).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *attendanceRepository) GetRecord(ctx context.Context, sessionID, userID uint) (*models.AttendanceRecord, error) {
+	var record models.AttendanceRecord
+	if err := r.db.WithContext(ctx).First(&record, "session_id = ? AND user_id = ?", sessionID, userID).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *attendanceRepository) SaveRecord(ctx context.Context, record *models.AttendanceRecord) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "checked_in_at", "marked_by", "note", "updated_at"}),
+	}).Create(record).Error
+}
+
+func (r *attendanceRepository) ListRecordsBySession(ctx context.Context, sessionID uint) ([]*models.AttendanceRecord, error) {
+	var records []*models.AttendanceRecord
+	err := r.db.WithContext(ctx).Where("session_id = ?", sessionID).Find(&records).Error
+	return records, err
+}
+
+func (r *attendanceRepository) ListRecordsByCourse(ctx context.Context, courseID uint, userIDs []uint) ([]*models.AttendanceRecord, error) {
+	var records []*models.AttendanceRecord
+	if len(userIDs) == 0 {
+		return records, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("session_id IN (?)", r.db.WithContext(ctx).Model(&models.ChapterSession{}).Select("id").Where("course_id = ?", courseID)).
+		Where("user_id IN ?", userIDs).
+		Find(&records).Error
+	return records, err
+}
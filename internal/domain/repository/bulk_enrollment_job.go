@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type BulkEnrollmentJobRepository interface {
+	Create(ctx context.Context, job *models.BulkEnrollmentJob) error
+	GetByID(ctx context.Context, id uint) (*models.BulkEnrollmentJob, error)
+	Update(ctx context.Context, job *models.BulkEnrollmentJob) error
+	// FailUnfinished marks every queued or running job as failed with the
+	// message.
+	FailUnfinished(ctx context.Context, message string) error
+}
+
+type bulkEnrollmentJobRepository struct {
+	db *gorm.DB
+}
+
+func NewBulkEnrollmentJobRepository(db *gorm.DB) BulkEnrollmentJobRepository {
+	return &bulkEnrollmentJobRepository{db}
+}
+
+func (r *bulkEnrollmentJobRepository) Create(ctx context.Context, job *models.BulkEnrollmentJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *bulkEnrollmentJobRepository) GetByID(ctx context.Context, id uint) (*models.BulkEnrollmentJob, error) {
+	var job models.BulkEnrollmentJob
+	err := r.db.WithContext(ctx).First(&job, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &job, err
+}
+
+func (r *bulkEnrollmentJobRepository) Update(ctx context.Context, job *models.BulkEnrollmentJob) error {
+	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *bulkEnrollmentJobRepository) FailUnfinished(ctx context.Context, message string) error {
+	return r.db.WithContext(ctx).Model(&models.BulkEnrollmentJob{}).
+		Where("status IN ?", []string{constants.JobQueued.String(), constants.JobRunning.String()}).
+		Updates(map[string]interface{}{
+			"status":       constants.JobFailed.String(),
+			"error":        message,
+			"completed_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CalendarFeedRepository interface {
+	GetByUserID(ctx context.Context, userID uint) (*models.CalendarFeed, error)
+	GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error)
+	// Save creates the user's feed or replaces the token of the existing one.
+	Save(ctx context.Context, feed *models.CalendarFeed) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+	Touch(ctx context.Context, id uint) error
+}
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db}
+}
+
+func (r *calendarFeedRepository) GetByUserID(ctx context.Context, userID uint) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.WithContext(ctx).First(&feed, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *calendarFeedRepository) GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.WithContext(ctx).First(&feed, "token_hash = ?", tokenHash).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *calendarFeedRepository) Save(ctx context.Context, feed *models.CalendarFeed) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "last_fetched_at", "created_at"}),
+	}).Create(feed).Error
+}
+
+func (r *calendarFeedRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.CalendarFeed{}, "user_id = ?", userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *calendarFeedRepository) Touch(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.CalendarFeed{}).
+		Where("id = ?", id).
+		Update("last_fetched_at", time.Now()).Error
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type CertificateRepository interface {
+	Create(ctx context.Context, certificate *models.Certificate) error
+	GetByNumber(ctx context.Context, number string) (*models.Certificate, error)
+	GetByUserAndPath(ctx context.Context, userID, pathID uint) (*models.Certificate, error)
+}
+
+type certificateRepository struct {
+	db *gorm.DB
+}
+
+func NewCertificateRepository(db *gorm.DB) CertificateRepository {
+	return &certificateRepository{db}
+}
+
+func (r *certificateRepository) Create(ctx context.Context, certificate *models.Certificate) error {
+	if err := r.db.WithContext(ctx).Create(certificate).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *certificateRepository) GetByNumber(ctx context.Context, number string) (*models.Certificate, error) {
+	var certificate models.Certificate
+	err := r.db.WithContext(ctx).First(&certificate, "number = ?", number).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &certificate, err
+}
+
+func (r *certificateRepository) GetByUserAndPath(ctx context.Context, userID, pathID uint) (*models.Certificate, error) {
+	var certificate models.Certificate
+	err := r.db.WithContext(ctx).First(&certificate, "user_id = ? AND learning_path_id = ?", userID, pathID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &certificate, err
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CohortRepository interface {
+	Create(ctx context.Context, cohort *models.Cohort) error
+	GetByID(ctx context.Context, id uint) (*models.Cohort, error)
+	Update(ctx context.Context, cohort *models.Cohort) error
+	Delete(ctx context.Context, id uint) error
+	ListByCourse(ctx context.Context, courseID uint) ([]*models.Cohort, error)
+	// ListByUser returns the cohorts the user is a member or mentor of, with
+	// the course loaded.
+	ListByUser(ctx context.Context, userID uint) ([]*models.Cohort, error)
+	// CountMembers returns the member count of each of the cohorts.
+	CountMembers(ctx context.Context, cohortIDs []uint) (map[uint]int64, error)
+	AddMember(ctx context.Context, member *models.CohortMember) error
+	RemoveMember(ctx context.Context, cohortID, userID uint) error
+	// GetMembership returns the user's cohort membership in the course.
+	GetMembership(ctx context.Context, courseID, userID uint) (*models.CohortMember, error)
+	ListMembers(ctx context.Context, cohortID uint) ([]*models.CohortMember, error)
+	IsMember(ctx context.Context, cohortID, userID uint) (bool, error)
+	AddMentor(ctx context.Context, mentor *models.CohortMentor) error
+	RemoveMentor(ctx context.Context, cohortID, userID uint) error
+	// ListMentors returns the mentors of the cohorts with the users loaded.
+	ListMentors(ctx context.Context, cohortIDs []uint) ([]*models.CohortMentor, error)
+	IsMentor(ctx context.Context, cohortID, userID uint) (bool, error)
+	CreateAnnouncement(ctx context.Context, announcement *models.CohortAnnouncement) error
+	ListAnnouncements(ctx context.Context, cohortID uint, offset, limit int) ([]*models.CohortAnnouncement, int64, error)
+	CreatePost(ctx context.Context, post *models.CohortPost) error
+	GetPost(ctx context.Context, cohortID, id uint) (*models.CohortPost, error)
+	DeletePost(ctx context.Context, id uint) error
+	ListThreads(ctx context.Context, cohortID uint, offset, limit int) ([]*models.CohortPost, int64, error)
+	ListReplies(ctx context.Context, parentID uint) ([]*models.CohortPost, error)
+	// CountReplies returns the reply count of each of the threads.
+	CountReplies(ctx context.Context, parentIDs []uint) (map[uint]int64, error)
+	// Transaction runs fn in a transaction that also covers enrollments, as
+	// cohort deadlines are copied to the members' enrollments.
+	Transaction(ctx context.Context, fn func(repo CohortRepository, enrollmentRepo EnrollmentRepository) error) error
+}
+
+type cohortRepository struct {
+	db *gorm.DB
+}
+
+func NewCohortRepository(db *gorm.DB) CohortRepository {
+	return &cohortRepository{db}
+}
+
+func (r *cohortRepository) Create(ctx context.Context, cohort *models.Cohort) error {
+	return r.db.WithContext(ctx).Create(cohort).Error
+}
+
+func (r *cohortRepository) GetByID(ctx context.Context, id uint) (*models.Cohort, error) {
+	var cohort models.Cohort
+	if err := r.db.WithContext(ctx).First(&cohort, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &cohort, nil
+}
+
+func (r *cohortRepository) Update(ctx context.Context, cohort *models.Cohort) error {
+	return r.db.WithContext(ctx).Save(cohort).Error
+}
+
+func (r *cohortRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Cohort{}, "id = ?", id).Error
+}
+
+func (r *cohortRepository) ListByCourse(ctx context.Context, courseID uint) ([]*models.Cohort, error) {
+	var cohorts []*models.Cohort
+	err := r.db.WithContext(ctx).Where("course_id = ?", courseID).Order("name ASC, id ASC").Find(&cohorts).Error
+	return cohorts, err
+}
+
+func (r *cohortRepository) ListByUser(ctx context.Context, userID uint) ([]*models.Cohort, error) {
+	var cohorts []*models.Cohort
+	err := r.db.WithContext(ctx).
+		Preload("Course").
+		Where("id IN (?) OR id IN (?)",
+			r.db.Model(&models.CohortMember{}).Select("cohort_id").Where("user_id = ?", userID),
+			r.db.Model(&models.CohortMentor{}).Select("cohort_id").Where("user_id = ?", userID)).
+		Order("name ASC, id ASC").
+		Find(&cohorts).Error
+	return cohorts, err
+}
+
+func (r *cohortRepository) CountMembers(ctx context.Context, cohortIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(cohortIDs))
+	if len(cohortIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		CohortID uint
+		Count    int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.CohortMember{}).
+		Select("cohort_id, COUNT(*) AS count").
+		Where("cohort_id IN ?", cohortIDs).
+		Group("cohort_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.CohortID] = row.Count
+	}
+	return counts, err
+}
+
+func (r *cohortRepository) AddMember(ctx context.Context, member *models.CohortMember) error {
+	return r.db.WithContext(ctx).Create(member).Error
+}
+
+func (r *cohortRepository) RemoveMember(ctx context.Context, cohortID, userID uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.CohortMember{}, "cohort_id = ? AND user_id = ?", cohortID, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *cohortRepository) GetMembership(ctx context.Context, courseID, userID uint) (*models.CohortMember, error) {
+	var member models.CohortMember
+	if err := r.db.WithContext(ctx).First(&member, "course_id = ? AND user_id = ?", courseID, userID).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *cohortRepository) ListMembers(ctx context.Context, cohortID uint) ([]*models.CohortMember, error) {
+	var members []*models.CohortMember
+	err := r.db.WithContext(ctx).Preload("User").Where("cohort_id = ?", cohortID).Order("created_at ASC").Find(&members).Error
+	return members, err
+}
+
+func (r *cohortRepository) IsMember(ctx context.Context, cohortID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.CohortMember{}).
+		Where("cohort_id = ? AND user_id = ?", cohortID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *cohortRepository) AddMentor(ctx context.Context, mentor *models.CohortMentor) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(mentor).Error
+}
+
+func (r *cohortRepository) RemoveMentor(ctx context.Context, cohortID, userID uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.CohortMentor{}, "cohort_id = ? AND user_id = ?", cohortID, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *cohortRepository) ListMentors(ctx context.Context, cohortIDs []uint) ([]*models.CohortMentor, error) {
+	var mentors []*models.CohortMentor
+	if len(cohortIDs) == 0 {
+		return mentors, nil
+	}
+	err := r.db.WithContext(ctx).Preload("User").Where("cohort_id IN ?", cohortIDs).Order("created_at ASC").Find(&mentors).Error
+	return mentors, err
+}
+
+func (r *cohortRepository) IsMentor(ctx context.Context, cohortID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.CohortMentor{}).
+		Where("cohort_id = ? AND user_id = ?", cohortID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *cohortRepository) CreateAnnouncement(ctx context.Context, announcement *models.CohortAnnouncement) error {
+	return r.db.WithContext(ctx).Create(announcement).Error
+}
+
+func (r *cohortRepository) ListAnnouncements(ctx context.Context, cohortID uint, offset, limit int) ([]*models.CohortAnnouncement, int64, error) {
+	var announcements []*models.CohortAnnouncement
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.CohortAnnouncement{}).Where("cohort_id = ?", cohortID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Author").Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&announcements).Error
+	return announcements, total, err
+}
+
+func (r *cohortRepository) CreatePost(ctx context.Context, post *models.CohortPost) error {
+	return r.db.WithContext(ctx).Create(post).Error
+}
+
+func (r *cohortRepository) GetPost(ctx context.Context, cohortID, id uint) (*models.CohortPost, error) {
+	var post models.CohortPost
+	if err := r.db.WithContext(ctx).Preload("Author").First(&post, "cohort_id = ? AND id = ?", cohortID, id).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (r *cohortRepository) DeletePost(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.CohortPost{}, "id = ? OR parent_id = ?", id, id).Error
+}
+
+func (r *cohortRepository) ListThreads(ctx context.Context, cohortID uint, offset, limit int) ([]*models.CohortPost, int64, error) {
+	var threads []*models.CohortPost
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.CohortPost{}).Where("cohort_id = ? AND parent_id IS NULL", cohortID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Author").Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&threads).Error
+	return threads, total, err
+}
+
+func (r *cohortRepository) ListReplies(ctx context.Context, parentID uint) ([]*models.CohortPost, error) {
+	var replies []*models.CohortPost
+	err := r.db.WithContext(ctx).Preload("Author").Where("parent_id = ?", parentID).Order("created_at ASC, id ASC").Find(&replies).Error
+	return replies, err
+}
+
+func (r *cohortRepository) CountReplies(ctx context.Context, parentIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ParentID uint
+		Count    int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.CohortPost{}).
+		Select("parent_id, COUNT(*) AS count").
+		Where("parent_id IN ?", parentIDs).
+		Group("parent_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.ParentID] = row.Count
+	}
+	return counts, err
+}
+
+func (r *cohortRepository) Transaction(ctx context.Context, fn func(repo CohortRepository, enrollmentRepo EnrollmentRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&cohortRepository{tx}, &enrollmentRepository{tx})
+	})
+}
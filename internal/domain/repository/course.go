@@ -14,6 +14,10 @@ type CourseRepository interface {
 	Update(ctx context.Context, course *models.Course) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, offset, limit int) ([]*models.Course, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]*models.Course, error)
+	GetPrerequisites(ctx context.Context, courseID uint) ([]*models.Course, error)
+	GetPrerequisiteIDs(ctx context.Context, courseID uint) ([]uint, error)
+	SetPrerequisites(ctx context.Context, courseID uint, prerequisiteIDs []uint) error
 }
 
 type courseRepository struct {
@@ -59,3 +63,47 @@ func (r *courseRepository) GetByID(ctx context.Context, id uint) (*models.Course
 	}
 	return &course, err
 }
+
+func (r *courseRepository) GetByIDs(ctx context.Context, ids []uint) ([]*models.Course, error) {
+	var courses []*models.Course
+	if len(ids) == 0 {
+		return courses, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&courses).Error
+	return courses, err
+}
+
+func (r *courseRepository) GetPrerequisites(ctx context.Context, courseID uint) ([]*models.Course, error) {
+	var courses []*models.Course
+	err := r.db.WithContext(ctx).
+		Joins("JOIN course_prerequisites ON course_prerequisites.prerequisite_id = courses.id").
+		Where("course_prerequisites.course_id = ?", courseID).
+		Order("courses.name ASC").
+		Find(&courses).Error
+	return courses, err
+}
+
+func (r *courseRepository) GetPrerequisiteIDs(ctx context.Context, courseID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&models.CoursePrerequisite{}).
+		Where("course_id = ?", courseID).
+		Pluck("prerequisite_id", &ids).Error
+	return ids, err
+}
+
+func (r *courseRepository) SetPrerequisites(ctx context.Context, courseID uint, prerequisiteIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("course_id = ?", courseID).Delete(&models.CoursePrerequisite{}).Error; err != nil {
+			return err
+		}
+		if len(prerequisiteIDs) == 0 {
+			return nil
+		}
+
+		prerequisites := make([]models.CoursePrerequisite, len(prerequisiteIDs))
+		for i, id := range prerequisiteIDs {
+			prerequisites[i] = models.CoursePrerequisite{CourseID: courseID, PrerequisiteID: id}
+		}
+		return tx.Create(&prerequisites).Error
+	})
+}
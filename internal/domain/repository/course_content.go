@@ -15,6 +15,14 @@ type CourseContentRepository interface {
 	Update(ctx context.Context, content *models.CourseContent) error
 	Delete(ctx context.Context, id uint) error
 	GetByContentType(ctx context.Context, contentType string) ([]models.CourseContent, error)
+	GetCourseID(ctx context.Context, contentID uint) (uint, error)
+	// GetPublishedCourseID returns the course of a content that is published
+	// in a published chapter, and not found for any other content.
+	GetPublishedCourseID(ctx context.Context, contentID uint) (uint, error)
+	CountPublishedByCourse(ctx context.Context, courseID uint) (int64, error)
+	// ListPublishedByCourse returns the course's published contents of a
+	// type, in published chapters.
+	ListPublishedByCourse(ctx context.Context, courseID uint, contentType string) ([]models.CourseContent, error)
 }
 
 type courseContentRepository struct {
@@ -66,3 +74,46 @@ func (r *courseContentRepository) GetByContentType(ctx context.Context, contentT
 	err := r.db.WithContext(ctx).Where("content_type = ?", contentType).Find(&contents).Error
 	return contents, err
 }
+
+func (r *courseContentRepository) GetCourseID(ctx context.Context, contentID uint) (uint, error) {
+	var chapter models.CourseChapter
+	err := r.db.WithContext(ctx).
+		Joins("JOIN course_contents ON course_contents.chapter_id = course_chapters.id AND course_contents.deleted_at IS NULL").
+		Where("course_contents.id = ?", contentID).
+		First(&chapter).Error
+	if err != nil {
+		return 0, err
+	}
+	return chapter.CourseID, nil
+}
+
+func (r *courseContentRepository) GetPublishedCourseID(ctx context.Context, contentID uint) (uint, error) {
+	var chapter models.CourseChapter
+	err := r.db.WithContext(ctx).
+		Joins("JOIN course_contents ON course_contents.chapter_id = course_chapters.id AND course_contents.deleted_at IS NULL").
+		Where("course_contents.id = ? AND course_contents.is_published = ? AND course_chapters.is_published = ?", contentID, true, true).
+		First(&chapter).Error
+	if err != nil {
+		return 0, err
+	}
+	return chapter.CourseID, nil
+}
+
+func (r *courseContentRepository) CountPublishedByCourse(ctx context.Context, courseID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.CourseContent{}).
+		Joins("JOIN course_chapters ON course_chapters.id = course_contents.chapter_id AND course_chapters.deleted_at IS NULL").
+		Where("course_chapters.course_id = ? AND course_chapters.is_published = ? AND course_contents.is_published = ?", courseID, true, true).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *courseContentRepository) ListPublishedByCourse(ctx context.Context, courseID uint, contentType string) ([]models.CourseContent, error) {
+	var contents []models.CourseContent
+	err := r.db.WithContext(ctx).
+		Joins("JOIN course_chapters ON course_chapters.id = course_contents.chapter_id AND course_chapters.deleted_at IS NULL").
+		Where("course_chapters.course_id = ? AND course_chapters.is_published = ? AND course_contents.is_published = ? AND course_contents.content_type = ?", courseID, true, true, contentType).
+		Order("course_chapters.chapter_order ASC, course_contents.content_order ASC, course_contents.id ASC").
+		Find(&contents).Error
+	return contents, err
+}
@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/bobchopperz/bahrululum/internal/constants"
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type EnrollmentRepository interface {
@@ -14,9 +17,31 @@ type EnrollmentRepository interface {
 	GetByCourseID(ctx context.Context, id uint) (*models.Enrollment, error)
 	GetByUserAndCourse(ctx context.Context, userID, courseID uint) (*models.Enrollment, error)
 	GetByUserID(ctx context.Context, userID uint) ([]*models.Enrollment, error)
+	GetByUserAndCourses(ctx context.Context, userID uint, courseIDs []uint) ([]*models.Enrollment, error)
+	ListByUsersAndCourses(ctx context.Context, userIDs, courseIDs []uint) ([]*models.Enrollment, error)
 	Update(ctx context.Context, course *models.Enrollment) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, offset, limit int) ([]*models.Enrollment, error)
+	ListByCourse(ctx context.Context, courseID uint, status string, offset, limit int) ([]*models.Enrollment, error)
+	// ListWithAccess returns the active and completed enrollments of the
+	// course with the users loaded.
+	ListWithAccess(ctx context.Context, courseID uint) ([]*models.Enrollment, error)
+	CreateEvent(ctx context.Context, event *models.EnrollmentEvent) error
+	GetEventsByUserID(ctx context.Context, userID uint) ([]*models.EnrollmentEvent, error)
+	CreateProgress(ctx context.Context, progress *models.ContentProgress) error
+	CountProgress(ctx context.Context, enrollmentID uint) (int64, error)
+	LockCourse(ctx context.Context, courseID uint) (*models.Course, error)
+	UpdateCourseSettings(ctx context.Context, course *models.Course) error
+	CountSeatsTaken(ctx context.Context, courseID uint) (int64, error)
+	GetQueueHead(ctx context.Context, courseID uint, status string) (*models.Enrollment, error)
+	CountQueuedBefore(ctx context.Context, courseID uint, status string, queuedAt time.Time) (int64, error)
+	ListDueBefore(ctx context.Context, before time.Time) ([]*models.Enrollment, error)
+	ListOverdueByCourse(ctx context.Context, courseID uint) ([]*models.Enrollment, error)
+	CreateReminder(ctx context.Context, reminder *models.EnrollmentReminder) (bool, error)
+	// DeleteReminders forgets the reminders sent for an enrollment, so they
+	// are sent again for a new due date.
+	DeleteReminders(ctx context.Context, enrollmentID uint) error
+	Transaction(ctx context.Context, fn func(repo EnrollmentRepository) error) error
 }
 
 type enrollmentRepository struct {
@@ -83,6 +108,168 @@ func (r *enrollmentRepository) GetByUserAndCourse(ctx context.Context, userID, c
 
 func (r *enrollmentRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.Enrollment, error) {
 	var enrollments []*models.Enrollment
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&enrollments).Error
+	err := r.db.WithContext(ctx).Preload("Course").Where("user_id = ?", userID).Order("enrolled_at DESC").Find(&enrollments).Error
 	return enrollments, err
 }
+
+func (r *enrollmentRepository) GetByUserAndCourses(ctx context.Context, userID uint, courseIDs []uint) ([]*models.Enrollment, error) {
+	var enrollments []*models.Enrollment
+	if len(courseIDs) == 0 {
+		return enrollments, nil
+	}
+	err := r.db.WithContext(ctx).Where("user_id = ? AND course_id IN ?", userID, courseIDs).Find(&enrollments).Error
+	return enrollments, err
+}
+
+func (r *enrollmentRepository) ListByUsersAndCourses(ctx context.Context, userIDs, courseIDs []uint) ([]*models.Enrollment, error) {
+	var enrollments []*models.Enrollment
+	if len(userIDs) == 0 || len(courseIDs) == 0 {
+		return enrollments, nil
+	}
+	err := r.db.WithContext(ctx).Where("user_id IN ? AND course_id IN ?", userIDs, courseIDs).Find(&enrollments).Error
+	return enrollments, err
+}
+
+func (r *enrollmentRepository) ListByCourse(ctx context.Context, courseID uint, status string, offset, limit int) ([]*models.Enrollment, error) {
+	var enrollments []*models.Enrollment
+	query := r.db.WithContext(ctx).Preload("User").Where("course_id = ?", courseID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("COALESCE(queued_at, enrolled_at) ASC").Offset(offset).Limit(limit).Find(&enrollments).Error
+	return enrollments, err
+}
+
+func (r *enrollmentRepository) ListWithAccess(ctx context.Context, courseID uint) ([]*models.Enrollment, error) {
+	var enrollments []*models.Enrollment
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("course_id = ? AND status IN ?", courseID, []string{constants.EnrollmentActive.String(), constants.EnrollmentCompleted.String()}).
+		Order("enrolled_at ASC").
+		Find(&enrollments).Error
+	return enrollments, err
+}
+
+func (r *enrollmentRepository) CreateEvent(ctx context.Context, event *models.EnrollmentEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *enrollmentRepository) GetEventsByUserID(ctx context.Context, userID uint) ([]*models.EnrollmentEvent, error) {
+	var events []*models.EnrollmentEvent
+	err := r.db.WithContext(ctx).
+		Joins("JOIN enrollments ON enrollments.id = enrollment_events.enrollment_id").
+		Where("enrollments.user_id = ?", userID).
+		Order("enrollment_events.created_at DESC").
+		Find(&events).Error
+	return events, err
+}
+
+func (r *enrollmentRepository) CreateProgress(ctx context.Context, progress *models.ContentProgress) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(progress).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *enrollmentRepository) CountProgress(ctx context.Context, enrollmentID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ContentProgress{}).
+		Joins("JOIN course_contents ON course_contents.id = content_progress.content_id AND course_contents.deleted_at IS NULL").
+		Joins("JOIN course_chapters ON course_chapters.id = course_contents.chapter_id AND course_chapters.deleted_at IS NULL").
+		Where("content_progress.enrollment_id = ? AND course_chapters.is_published = ? AND course_contents.is_published = ?", enrollmentID, true, true).
+		Count(&count).Error
+	return count, err
+}
+
+// LockCourse loads the course row with FOR UPDATE so that seat accounting for
+// the course is serialized until the surrounding transaction ends.
+func (r *enrollmentRepository) LockCourse(ctx context.Context, courseID uint) (*models.Course, error) {
+	var course models.Course
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&course, "id = ?", courseID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+func (r *enrollmentRepository) UpdateCourseSettings(ctx context.Context, course *models.Course) error {
+	return r.db.WithContext(ctx).Model(&models.Course{}).Where("id = ?", course.ID).Updates(map[string]interface{}{
+		"enrollment_mode":        course.EnrollmentMode,
+		"max_seats":              course.MaxSeats,
+		"default_due_days":       course.DefaultDueDays,
+		"access_days":            course.AccessDays,
+		"min_attendance_percent": course.MinAttendancePercent,
+	}).Error
+}
+
+func (r *enrollmentRepository) CountSeatsTaken(ctx context.Context, courseID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Enrollment{}).
+		Where("course_id = ? AND status = ?", courseID, constants.EnrollmentActive.String()).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *enrollmentRepository) GetQueueHead(ctx context.Context, courseID uint, status string) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	err := r.db.WithContext(ctx).
+		Where("course_id = ? AND status = ?", courseID, status).
+		Order("queued_at ASC, id ASC").
+		First(&enrollment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+func (r *enrollmentRepository) CountQueuedBefore(ctx context.Context, courseID uint, status string, queuedAt time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Enrollment{}).
+		Where("course_id = ? AND status = ? AND queued_at < ?", courseID, status, queuedAt).
+		Count(&count).Error
+	return count, err
+}
+
+// ListDueBefore returns unfinished active enrollments whose due date falls
+// before the given time, with their course loaded.
+func (r *enrollmentRepository) ListDueBefore(ctx context.Context, before time.Time) ([]*models.Enrollment, error) {
+	var enrollments []*models.Enrollment
+	err := r.db.WithContext(ctx).Preload("Course").
+		Where("status = ? AND completed_at IS NULL AND due_at IS NOT NULL AND due_at <= ?", constants.EnrollmentActive.String(), before).
+		Order("due_at ASC").
+		Find(&enrollments).Error
+	return enrollments, err
+}
+
+func (r *enrollmentRepository) ListOverdueByCourse(ctx context.Context, courseID uint) ([]*models.Enrollment, error) {
+	var enrollments []*models.Enrollment
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("course_id = ? AND status = ? AND completed_at IS NULL AND due_at < ?", courseID, constants.EnrollmentActive.String(), time.Now()).
+		Order("due_at ASC").
+		Find(&enrollments).Error
+	return enrollments, err
+}
+
+// CreateReminder records that a reminder was sent and reports false when the
+// same reminder had already been recorded.
+func (r *enrollmentRepository) CreateReminder(ctx context.Context, reminder *models.EnrollmentReminder) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *enrollmentRepository) DeleteReminders(ctx context.Context, enrollmentID uint) error {
+	return r.db.WithContext(ctx).Where("enrollment_id = ?", enrollmentID).Delete(&models.EnrollmentReminder{}).Error
+}
+
+func (r *enrollmentRepository) Transaction(ctx context.Context, fn func(repo EnrollmentRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&enrollmentRepository{tx})
+	})
+}
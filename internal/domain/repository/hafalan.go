@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HafalanRepository interface {
+	GetTarget(ctx context.Context, contentID uint) (*models.HafalanTarget, error)
+	// SaveTarget sets the content's target, replacing the existing one.
+	SaveTarget(ctx context.Context, target *models.HafalanTarget) error
+	ListTargets(ctx context.Context, contentIDs []uint) ([]*models.HafalanTarget, error)
+	CreateRecord(ctx context.Context, record *models.HafalanRecord) error
+	GetRecord(ctx context.Context, id uint) (*models.HafalanRecord, error)
+	DeleteRecord(ctx context.Context, id uint) error
+	// ListRecords returns the user's records for the contents, oldest first.
+	ListRecords(ctx context.Context, contentIDs []uint, userID uint) ([]*models.HafalanRecord, error)
+	// Transaction runs fn in a transaction that also covers enrollments, as
+	// recitations complete contents.
+	Transaction(ctx context.Context, fn func(repo HafalanRepository, enrollmentRepo EnrollmentRepository) error) error
+}
+
+type hafalanRepository struct {
+	db *gorm.DB
+}
+
+func NewHafalanRepository(db *gorm.DB) HafalanRepository {
+	return &hafalanRepository{db}
+}
+
+func (r *hafalanRepository) GetTarget(ctx context.Context, contentID uint) (*models.HafalanTarget, error) {
+	var target models.HafalanTarget
+	if err := r.db.WithContext(ctx).First(&target, "content_id = ?", contentID).Error; err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *hafalanRepository) SaveTarget(ctx context.Context, target *models.HafalanTarget) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "content_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"from_surah", "from_ayah", "to_surah", "to_ayah", "updated_at"}),
+	}).Create(target).Error
+}
+
+func (r *hafalanRepository) ListTargets(ctx context.Context, contentIDs []uint) ([]*models.HafalanTarget, error) {
+	var targets []*models.HafalanTarget
+	if len(contentIDs) == 0 {
+		return targets, nil
+	}
+	err := r.db.WithContext(ctx).Where("content_id IN ?", contentIDs).Find(&targets).Error
+	return targets, err
+}
+
+func (r *hafalanRepository) CreateRecord(ctx context.Context, record *models.HafalanRecord) error {
+	return r.db.WithContext(ctx).Create(record).Error
+}
+
+func (r *hafalanRepository) GetRecord(ctx context.Context, id uint) (*models.HafalanRecord, error) {
+	var record models.HafalanRecord
+	if err := r.db.WithContext(ctx).First(&record, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *hafalanRepository) DeleteRecord(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.HafalanRecord{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *hafalanRepository) ListRecords(ctx context.Context, contentIDs []uint, userID uint) ([]*models.HafalanRecord, error) {
+	var records []*models.HafalanRecord
+	if len(contentIDs) == 0 {
+		return records, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("content_id IN ? AND user_id = ?", contentIDs, userID).
+		Order("recorded_at ASC, id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *hafalanRepository) Transaction(ctx context.Context, fn func(repo HafalanRepository, enrollmentRepo EnrollmentRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&hafalanRepository{tx}, &enrollmentRepository{tx})
+	})
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type LearningPathRepository interface {
+	Create(ctx context.Context, path *models.LearningPath) error
+	GetByID(ctx context.Context, id uint) (*models.LearningPath, error)
+	Update(ctx context.Context, path *models.LearningPath) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, publishedOnly bool, offset, limit int) ([]*models.LearningPath, error)
+	ReplaceCourses(ctx context.Context, pathID uint, courseIDs []uint) error
+	GetEnrollment(ctx context.Context, pathID, userID uint) (*models.LearningPathEnrollment, error)
+	CreateEnrollment(ctx context.Context, enrollment *models.LearningPathEnrollment) error
+	UpdateEnrollment(ctx context.Context, enrollment *models.LearningPathEnrollment) error
+	GetEnrollmentsByUser(ctx context.Context, userID uint) ([]*models.LearningPathEnrollment, error)
+}
+
+type learningPathRepository struct {
+	db *gorm.DB
+}
+
+func NewLearningPathRepository(db *gorm.DB) LearningPathRepository {
+	return &learningPathRepository{db}
+}
+
+func withOrderedCourses(db *gorm.DB) *gorm.DB {
+	return db.Preload("Courses", func(db *gorm.DB) *gorm.DB {
+		return db.Order("learning_path_courses.position ASC")
+	}).Preload("Courses.Course")
+}
+
+func (r *learningPathRepository) Create(ctx context.Context, path *models.LearningPath) error {
+	if err := r.db.WithContext(ctx).Create(path).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *learningPathRepository) GetByID(ctx context.Context, id uint) (*models.LearningPath, error) {
+	var path models.LearningPath
+	err := withOrderedCourses(r.db.WithContext(ctx)).First(&path, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &path, err
+}
+
+func (r *learningPathRepository) Update(ctx context.Context, path *models.LearningPath) error {
+	if err := r.db.WithContext(ctx).Omit("Courses").Save(path).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *learningPathRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&models.LearningPath{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *learningPathRepository) List(ctx context.Context, publishedOnly bool, offset, limit int) ([]*models.LearningPath, error) {
+	var paths []*models.LearningPath
+	query := withOrderedCourses(r.db.WithContext(ctx))
+	if publishedOnly {
+		query = query.Where("is_published = ?", true)
+	}
+	err := query.Order("name ASC").Offset(offset).Limit(limit).Find(&paths).Error
+	return paths, err
+}
+
+func (r *learningPathRepository) ReplaceCourses(ctx context.Context, pathID uint, courseIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("learning_path_id = ?", pathID).Delete(&models.LearningPathCourse{}).Error; err != nil {
+			return err
+		}
+		if len(courseIDs) == 0 {
+			return nil
+		}
+
+		courses := make([]models.LearningPathCourse, len(courseIDs))
+		for i, id := range courseIDs {
+			courses[i] = models.LearningPathCourse{LearningPathID: pathID, CourseID: id, Position: i + 1}
+		}
+		return tx.Create(&courses).Error
+	})
+}
+
+func (r *learningPathRepository) GetEnrollment(ctx context.Context, pathID, userID uint) (*models.LearningPathEnrollment, error) {
+	var enrollment models.LearningPathEnrollment
+	err := r.db.WithContext(ctx).First(&enrollment, "learning_path_id = ? AND user_id = ?", pathID, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &enrollment, err
+}
+
+func (r *learningPathRepository) CreateEnrollment(ctx context.Context, enrollment *models.LearningPathEnrollment) error {
+	if err := r.db.WithContext(ctx).Create(enrollment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *learningPathRepository) UpdateEnrollment(ctx context.Context, enrollment *models.LearningPathEnrollment) error {
+	if err := r.db.WithContext(ctx).Omit("LearningPath").Save(enrollment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *learningPathRepository) GetEnrollmentsByUser(ctx context.Context, userID uint) ([]*models.LearningPathEnrollment, error) {
+	var enrollments []*models.LearningPathEnrollment
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("enrolled_at DESC").Find(&enrollments).Error
+	return enrollments, err
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrgUnitRepository interface {
+	Create(ctx context.Context, unit *models.OrgUnit) error
+	GetByID(ctx context.Context, id uint) (*models.OrgUnit, error)
+	GetByCode(ctx context.Context, code string) (*models.OrgUnit, error)
+	Update(ctx context.Context, unit *models.OrgUnit) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context) ([]*models.OrgUnit, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]*models.OrgUnit, error)
+	// SubtreeIDs returns the unit and all of its descendants.
+	SubtreeIDs(ctx context.Context, id uint) ([]uint, error)
+	// AncestorIDs returns the unit and all of its ancestors up to the root.
+	AncestorIDs(ctx context.Context, id uint) ([]uint, error)
+	CountChildren(ctx context.Context, id uint) (int64, error)
+	CountMembers(ctx context.Context, unitIDs []uint) (int64, error)
+	ListMembers(ctx context.Context, unitIDs []uint, offset, limit int) ([]*models.User, error)
+	// ListActiveMembers returns every active member of the units, for course
+	// assignment and reports.
+	ListActiveMembers(ctx context.Context, unitIDs []uint) ([]*models.User, error)
+	SetUserUnit(ctx context.Context, userID uint, unitID *uint) error
+	AddAdmin(ctx context.Context, admin *models.OrgUnitAdmin) error
+	RemoveAdmin(ctx context.Context, unitID, userID uint) error
+	ListAdmins(ctx context.Context, unitID uint) ([]*models.OrgUnitAdmin, error)
+	// AdminUnitIDs returns the units the user administers directly, without
+	// their sub-units.
+	AdminUnitIDs(ctx context.Context, userID uint) ([]uint, error)
+	IsAdminOf(ctx context.Context, userID uint, unitIDs []uint) (bool, error)
+	CreateCourse(ctx context.Context, assignment *models.OrgUnitCourse) error
+	GetCourse(ctx context.Context, unitID, courseID uint) (*models.OrgUnitCourse, error)
+	DeleteCourse(ctx context.Context, id uint) error
+	// ListCourses returns the course assignments made on any of the units,
+	// with the course loaded.
+	ListCourses(ctx context.Context, unitIDs []uint) ([]*models.OrgUnitCourse, error)
+}
+
+type orgUnitRepository struct {
+	db *gorm.DB
+}
+
+func NewOrgUnitRepository(db *gorm.DB) OrgUnitRepository {
+	return &orgUnitRepository{db}
+}
+
+func (r *orgUnitRepository) Create(ctx context.Context, unit *models.OrgUnit) error {
+	return r.db.WithContext(ctx).Create(unit).Error
+}
+
+func (r *orgUnitRepository) GetByID(ctx context.Context, id uint) (*models.OrgUnit, error) {
+	var unit models.OrgUnit
+	if err := r.db.WithContext(ctx).First(&unit, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+func (r *orgUnitRepository) GetByCode(ctx context.Context, code string) (*models.OrgUnit, error) {
+	var unit models.OrgUnit
+	if err := r.db.WithContext(ctx).First(&unit, "code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+func (r *orgUnitRepository) Update(ctx context.Context, unit *models.OrgUnit) error {
+	return r.db.WithContext(ctx).Save(unit).Error
+}
+
+func (r *orgUnitRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.OrgUnit{}, "id = ?", id).Error
+}
+
+func (r *orgUnitRepository) List(ctx context.Context) ([]*models.OrgUnit, error) {
+	var units []*models.OrgUnit
+	err := r.db.WithContext(ctx).Order("name ASC, id ASC").Find(&units).Error
+	return units, err
+}
+
+func (r *orgUnitRepository) GetByIDs(ctx context.Context, ids []uint) ([]*models.OrgUnit, error) {
+	var units []*models.OrgUnit
+	if len(ids) == 0 {
+		return units, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("name ASC, id ASC").Find(&units).Error
+	return units, err
+}
+
+func (r *orgUnitRepository) SubtreeIDs(ctx context.Context, id uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM org_units WHERE id IN (?)
+			UNION
+			SELECT org_units.id FROM org_units JOIN subtree ON org_units.parent_id = subtree.id
+		)
+		SELECT id FROM subtree`, r.anchor(ctx, id)).Scan(&ids).Error
+	return ids, err
+}
+
+func (r *orgUnitRepository) AncestorIDs(ctx context.Context, id uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM org_units WHERE id IN (?)
+			UNION
+			SELECT org_units.id, org_units.parent_id FROM org_units JOIN ancestors ON org_units.id = ancestors.parent_id
+		)
+		SELECT id FROM ancestors`, r.anchor(ctx, id)).Scan(&ids).Error
+	return ids, err
+}
+
+// anchor selects the unit a recursive query starts from. Raw SQL is not
+// tenant scoped, but the subquery is, and units only link to units of their
+// own tenant.
+func (r *orgUnitRepository) anchor(ctx context.Context, id uint) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&models.OrgUnit{}).Select("id").Where("id = ?", id)
+}
+
+func (r *orgUnitRepository) CountChildren(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.OrgUnit{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+func (r *orgUnitRepository) CountMembers(ctx context.Context, unitIDs []uint) (int64, error) {
+	var count int64
+	if len(unitIDs) == 0 {
+		return 0, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("org_unit_id IN ?", unitIDs).Count(&count).Error
+	return count, err
+}
+
+func (r *orgUnitRepository) ListMembers(ctx context.Context, unitIDs []uint, offset, limit int) ([]*models.User, error) {
+	var users []*models.User
+	if len(unitIDs) == 0 {
+		return users, nil
+	}
+	err := r.db.WithContext(ctx).Where("org_unit_id IN ?", unitIDs).Order("name ASC, id ASC").Offset(offset).Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func (r *orgUnitRepository) ListActiveMembers(ctx context.Context, unitIDs []uint) ([]*models.User, error) {
+	var users []*models.User
+	if len(unitIDs) == 0 {
+		return users, nil
+	}
+	err := r.db.WithContext(ctx).Where("org_unit_id IN ? AND is_active = ?", unitIDs, true).Order("name ASC, id ASC").Find(&users).Error
+	return users, err
+}
+
+func (r *orgUnitRepository) SetUserUnit(ctx context.Context, userID uint, unitID *uint) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("org_unit_id", unitID).Error
+}
+
+func (r *orgUnitRepository) AddAdmin(ctx context.Context, admin *models.OrgUnitAdmin) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(admin).Error
+}
+
+func (r *orgUnitRepository) RemoveAdmin(ctx context.Context, unitID, userID uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.OrgUnitAdmin{}, "org_unit_id = ? AND user_id = ?", unitID, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *orgUnitRepository) ListAdmins(ctx context.Context, unitID uint) ([]*models.OrgUnitAdmin, error) {
+	var admins []*models.OrgUnitAdmin
+	err := r.db.WithContext(ctx).Preload("User").Where("org_unit_id = ?", unitID).Order("created_at ASC").Find(&admins).Error
+	return admins, err
+}
+
+func (r *orgUnitRepository) AdminUnitIDs(ctx context.Context, userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&models.OrgUnitAdmin{}).Where("user_id = ?", userID).Pluck("org_unit_id", &ids).Error
+	return ids, err
+}
+
+func (r *orgUnitRepository) IsAdminOf(ctx context.Context, userID uint, unitIDs []uint) (bool, error) {
+	var count int64
+	if len(unitIDs) == 0 {
+		return false, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.OrgUnitAdmin{}).Where("user_id = ? AND org_unit_id IN ?", userID, unitIDs).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *orgUnitRepository) CreateCourse(ctx context.Context, assignment *models.OrgUnitCourse) error {
+	return r.db.WithContext(ctx).Create(assignment).Error
+}
+
+func (r *orgUnitRepository) GetCourse(ctx context.Context, unitID, courseID uint) (*models.OrgUnitCourse, error) {
+	var assignment models.OrgUnitCourse
+	if err := r.db.WithContext(ctx).Preload("Course").First(&assignment, "org_unit_id = ? AND course_id = ?", unitID, courseID).Error; err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+func (r *orgUnitRepository) DeleteCourse(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.OrgUnitCourse{}, "id = ?", id).Error
+}
+
+func (r *orgUnitRepository) ListCourses(ctx context.Context, unitIDs []uint) ([]*models.OrgUnitCourse, error) {
+	var assignments []*models.OrgUnitCourse
+	if len(unitIDs) == 0 {
+		return assignments, nil
+	}
+	err := r.db.WithContext(ctx).Preload("Course").Where("org_unit_id IN ?", unitIDs).Order("created_at ASC, id ASC").Find(&assignments).Error
+	return assignments, err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	GetByID(ctx context.Context, id uint) (*models.RefreshToken, error)
+	ListActiveByUserID(ctx context.Context, userID uint) ([]*models.RefreshToken, error)
+	Rotate(ctx context.Context, session *models.RefreshToken, oldHash string) (bool, error)
+	Touch(ctx context.Context, id uint) error
+	Delete(ctx context.Context, id uint) (bool, error)
+	DeleteForUser(ctx context.Context, userID, id uint) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+	DeleteByUserIDExcept(ctx context.Context, userID, keepID uint) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) GetByToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.WithContext(ctx).First(&token, "token = ?", tokenHash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &token, err
+}
+
+func (r *refreshTokenRepository) GetByID(ctx context.Context, id uint) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.WithContext(ctx).First(&token, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &token, err
+}
+
+func (r *refreshTokenRepository) ListActiveByUserID(ctx context.Context, userID uint) ([]*models.RefreshToken, error) {
+	var tokens []*models.RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC NULLS LAST, created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Rotate replaces the session's token hash, expiry and client details, but
+// only while the stored hash is still oldHash. It reports whether the rotation
+// happened, so a refresh token can only be exchanged once even under
+// concurrent refreshes.
+func (r *refreshTokenRepository) Rotate(ctx context.Context, session *models.RefreshToken, oldHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND token = ?", session.ID, oldHash).
+		Updates(map[string]interface{}{
+			"token":        session.Token,
+			"expires_at":   session.ExpiresAt,
+			"ip_address":   session.IPAddress,
+			"user_agent":   session.UserAgent,
+			"device_name":  session.DeviceName,
+			"last_used_at": session.LastUsedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *refreshTokenRepository) Touch(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", time.Now()).Error
+}
+
+// Delete removes a single token and reports whether it still existed, so a
+// token can only be exchanged once even under concurrent refreshes.
+func (r *refreshTokenRepository) Delete(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&models.RefreshToken{}, "id = ?", id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *refreshTokenRepository) DeleteForUser(ctx context.Context, userID, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&models.RefreshToken{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *refreshTokenRepository) DeleteByUserIDExcept(ctx context.Context, userID, keepID uint) error {
+	return r.db.WithContext(ctx).Delete(&models.RefreshToken{}, "user_id = ? AND id <> ?", userID, keepID).Error
+}
+
+func (r *refreshTokenRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	if err := r.db.WithContext(ctx).Delete(&models.RefreshToken{}, "user_id = ?", userID).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context) error {
+	if err := r.db.WithContext(ctx).Delete(&models.RefreshToken{}, "expires_at < ?", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SecurityRepository interface {
+	CreateEvent(ctx context.Context, event *models.SecurityEvent) error
+	ListEvents(ctx context.Context, filter *models.SecurityEventFilter) ([]*models.SecurityEvent, error)
+	// RecordFailedLogin increments the user's consecutive failure counter and
+	// returns the new value.
+	RecordFailedLogin(ctx context.Context, userID uint) (int, error)
+	LockUser(ctx context.Context, userID uint, until time.Time) error
+	ResetFailedLogins(ctx context.Context, userID uint) error
+}
+
+type securityRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityRepository(db *gorm.DB) SecurityRepository {
+	return &securityRepository{db}
+}
+
+func (r *securityRepository) CreateEvent(ctx context.Context, event *models.SecurityEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *securityRepository) ListEvents(ctx context.Context, filter *models.SecurityEventFilter) ([]*models.SecurityEvent, error) {
+	var events []*models.SecurityEvent
+
+	query := r.db.WithContext(ctx).Model(&models.SecurityEvent{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	err := query.Order("created_at DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&events).Error
+	return events, err
+}
+
+func (r *securityRepository) RecordFailedLogin(ctx context.Context, userID uint) (int, error) {
+	user := models.User{ID: userID}
+	err := r.db.WithContext(ctx).Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+		UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": gorm.Expr("failed_login_attempts + 1"),
+			"last_failed_login_at":  time.Now(),
+		}).Error
+	return user.FailedLogins, err
+}
+
+func (r *securityRepository) LockUser(ctx context.Context, userID uint, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		UpdateColumn("locked_until", until).Error
+}
+
+func (r *securityRepository) ResetFailedLogins(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"last_failed_login_at":  nil,
+			"locked_until":          nil,
+		}).Error
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// TenantRepository reads and writes the tenants themselves, which are not
+// tenant owned.
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *models.Tenant) error
+	GetByID(ctx context.Context, id uint) (*models.Tenant, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	GetByDomain(ctx context.Context, domain string) (*models.Tenant, error)
+	Update(ctx context.Context, tenant *models.Tenant) error
+	List(ctx context.Context) ([]*models.Tenant, error)
+}
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db}
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant *models.Tenant) error {
+	return r.db.WithContext(ctx).Create(tenant).Error
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, id uint) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := r.db.WithContext(ctx).First(&tenant, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) GetBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := r.db.WithContext(ctx).First(&tenant, "slug = ?", slug).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) GetByDomain(ctx context.Context, domain string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := r.db.WithContext(ctx).First(&tenant, "LOWER(domain) = LOWER(?)", domain).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) Update(ctx context.Context, tenant *models.Tenant) error {
+	return r.db.WithContext(ctx).Save(tenant).Error
+}
+
+func (r *tenantRepository) List(ctx context.Context) ([]*models.Tenant, error) {
+	var tenants []*models.Tenant
+	err := r.db.WithContext(ctx).Order("slug ASC").Find(&tenants).Error
+	return tenants, err
+}
@@ -0,0 +1,125 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errRollback = errors.New("rollback")
+
+// withDatabase runs fn in a transaction on the migrated database named by
+// TEST_DATABASE_URL and rolls it back.
+func withDatabase(t *testing.T, fn func(tx *gorm.DB)) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		t.Fatal(err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		t.Cleanup(func() { sqlDB.Close() })
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		fn(tx)
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatal(err)
+	}
+}
+
+func TestTenantsCannotReachEachOther(t *testing.T) {
+	withDatabase(t, func(tx *gorm.DB) {
+		var tenants [2]models.Tenant
+		for i, slug := range []string{"tenant-test-a", "tenant-test-b"} {
+			tenants[i] = models.Tenant{Slug: slug, Name: slug, IsActive: true}
+			if err := tx.Create(&tenants[i]).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		a := tenant.WithID(context.Background(), tenants[0].ID)
+		b := tenant.WithID(context.Background(), tenants[1].ID)
+
+		users := repository.NewUserRepository(tx)
+		courses := repository.NewCourseRepository(tx)
+
+		var seeded [2]*models.User
+		for i, ctx := range []context.Context{a, b} {
+			seeded[i] = &models.User{Name: "Tenant User", Nip: []string{"990000000001", "990000000002"}[i], Email: tenants[i].Slug + "@example.com", Password: "x"}
+			if err := users.Create(ctx, seeded[i]); err != nil {
+				t.Fatal(err)
+			}
+			if err := courses.Create(ctx, &models.Course{Name: tenants[i].Slug, Description: "x"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		userA, userB := seeded[0], seeded[1]
+
+		// Reads.
+		if _, err := users.GetByID(a, userB.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("tenant A read tenant B's user: %v", err)
+		}
+		if _, err := users.GetByNip(a, userB.Nip); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("tenant A found tenant B's user by NIP: %v", err)
+		}
+		listed, err := courses.List(a, 0, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, course := range listed {
+			if course.TenantID != tenants[0].ID {
+				t.Fatalf("tenant A listed a course of tenant %d", course.TenantID)
+			}
+		}
+
+		// Writes.
+		hijacked := *userB
+		hijacked.Name = "Hijacked"
+		if err := users.Update(a, &hijacked); err != nil {
+			t.Fatal(err)
+		}
+		if err := users.Delete(a, userB.ID); err != nil {
+			t.Fatal(err)
+		}
+		planted := &models.User{TenantID: tenants[1].ID, Name: "Planted", Nip: "990000000009", Email: "planted@example.com", Password: "x"}
+		if err := users.Create(a, planted); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := users.GetByID(b, userB.ID)
+		if err != nil {
+			t.Fatalf("tenant B's user after tenant A's writes: %v", err)
+		}
+		if got.Name != userB.Name || got.TenantID != tenants[1].ID {
+			t.Fatalf("tenant A changed tenant B's user: %+v", got)
+		}
+		if _, err := users.GetByID(b, planted.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("tenant A planted a user in tenant B: %v", err)
+		}
+
+		// A system context reaches both tenants.
+		if _, err := users.GetByID(context.Background(), userA.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := users.GetByID(context.Background(), userB.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/bobchopperz/bahrululum/internal/tenant/tenanttest"
+	"gorm.io/gorm"
+)
+
+// tenantOwned reads and writes each tenant-owned model through its
+// repository.
+var tenantOwned = []struct {
+	model string
+	run   func(ctx context.Context, db *gorm.DB) error
+}{
+	{"User", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewUserRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.User{TenantID: 1, Name: "x"}),
+			ignore(repo.GetByID(ctx, 1)),
+			ignore(repo.List(ctx, 0, 10)),
+			repo.Update(ctx, &models.User{ID: 1, TenantID: 1}),
+			repo.Delete(ctx, 1),
+		)
+	}},
+	{"Course", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewCourseRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.Course{TenantID: 1}),
+			ignore(repo.GetByID(ctx, 1)),
+			repo.Update(ctx, &models.Course{ID: 1, TenantID: 1}),
+			repo.Delete(ctx, 1),
+		)
+	}},
+	{"CourseChapter", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewCourseChapterRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.CourseChapter{TenantID: 1}),
+			ignore(repo.GetByCourseID(ctx, 1)),
+			repo.Update(ctx, &models.CourseChapter{ID: 1, TenantID: 1}),
+			repo.Delete(ctx, 1),
+		)
+	}},
+	{"CourseContent", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewCourseContentRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.CourseContent{TenantID: 1}),
+			ignore(repo.GetByChapterID(ctx, 1)),
+			repo.Update(ctx, &models.CourseContent{ID: 1, TenantID: 1}),
+			repo.Delete(ctx, 1),
+		)
+	}},
+	{"Enrollment", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewEnrollmentRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.Enrollment{TenantID: 1}),
+			ignore(repo.GetByUserID(ctx, 1)),
+			repo.Update(ctx, &models.Enrollment{Model: gorm.Model{ID: 1}, TenantID: 1}),
+			repo.Delete(ctx, 1),
+		)
+	}},
+	{"Cohort", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewCohortRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.Cohort{TenantID: 1}),
+			repo.Update(ctx, &models.Cohort{ID: 1, TenantID: 1}),
+			repo.Delete(ctx, 1),
+		)
+	}},
+	{"ChapterSession", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewAttendanceRepository(db)
+		return firstError(
+			repo.CreateSession(ctx, &models.ChapterSession{TenantID: 1}),
+			ignore(repo.GetSession(ctx, 1)),
+			repo.UpdateSession(ctx, &models.ChapterSession{ID: 1, TenantID: 1}),
+			repo.DeleteSession(ctx, 1),
+		)
+	}},
+	{"LearningPath", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewLearningPathRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.LearningPath{TenantID: 1}),
+			ignore(repo.List(ctx, false, 0, 10)),
+			repo.Update(ctx, &models.LearningPath{ID: 1, TenantID: 1}),
+			repo.Delete(ctx, 1),
+		)
+	}},
+	{"Certificate", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewCertificateRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.Certificate{TenantID: 1}),
+			ignore(repo.GetByNumber(ctx, "x")),
+		)
+	}},
+	{"Notification", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewNotificationRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.Notification{TenantID: 1}),
+			ignore(repo.ListByUser(ctx, 1, false, 0, 10)),
+			repo.MarkRead(ctx, 1, 1),
+			repo.MarkAllRead(ctx, 1),
+		)
+	}},
+	{"CalendarFeed", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewCalendarFeedRepository(db)
+		return firstError(
+			repo.Save(ctx, &models.CalendarFeed{TenantID: 1}),
+			ignore(repo.GetByUserID(ctx, 1)),
+			repo.Touch(ctx, 1),
+			repo.DeleteByUserID(ctx, 1),
+		)
+	}},
+	{"UserImport", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewUserImportRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.UserImport{TenantID: 1}),
+			ignore(repo.GetByID(ctx, 1)),
+			ignore(repo.List(ctx, 0, 10)),
+		)
+	}},
+	{"SecurityEvent", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewSecurityRepository(db)
+		return firstError(
+			repo.CreateEvent(ctx, &models.SecurityEvent{TenantID: 1}),
+			ignore(repo.ListEvents(ctx, &models.SecurityEventFilter{})),
+		)
+	}},
+	{"APIKey", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewAPIKeyRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.APIKey{TenantID: 1}),
+			ignore(repo.ListByUserID(ctx, 1)),
+			ignore(repo.Revoke(ctx, 1, 1)),
+			repo.Touch(ctx, 1, nil),
+		)
+	}},
+	{"UserIdentity", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewUserIdentityRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.UserIdentity{TenantID: 1}),
+			ignore(repo.GetBySubject(ctx, "mock", "x")),
+			ignore(repo.Delete(ctx, 1, "mock")),
+			repo.TouchLogin(ctx, 1, nil),
+		)
+	}},
+	{"OIDCLoginState", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewUserIdentityRepository(db)
+		return repo.CreateState(ctx, &models.OIDCLoginState{TenantID: 1, ExpiresAt: time.Now()})
+	}},
+	{"BulkEnrollmentJob", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewBulkEnrollmentJobRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.BulkEnrollmentJob{TenantID: 1}),
+			ignore(repo.GetByID(ctx, 1)),
+			repo.Update(ctx, &models.BulkEnrollmentJob{ID: 1, TenantID: 1}),
+		)
+	}},
+	{"HafalanTarget", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewHafalanRepository(db)
+		return firstError(
+			repo.SaveTarget(ctx, &models.HafalanTarget{ContentID: 1, TenantID: 1}),
+			ignore(repo.GetTarget(ctx, 1)),
+			ignore(repo.ListTargets(ctx, []uint{1})),
+		)
+	}},
+	{"HafalanRecord", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewHafalanRepository(db)
+		return firstError(
+			repo.CreateRecord(ctx, &models.HafalanRecord{TenantID: 1}),
+			ignore(repo.GetRecord(ctx, 1)),
+			ignore(repo.ListRecords(ctx, []uint{1}, 1)),
+			repo.DeleteRecord(ctx, 1),
+		)
+	}},
+	{"OrgUnit", func(ctx context.Context, db *gorm.DB) error {
+		repo := repository.NewOrgUnitRepository(db)
+		return firstError(
+			repo.Create(ctx, &models.OrgUnit{TenantID: 1}),
+			ignore(repo.GetByCode(ctx, "x")),
+			ignore(repo.List(ctx)),
+			repo.Update(ctx, &models.OrgUnit{ID: 1, TenantID: 1}),
+			repo.Delete(ctx, 1),
+		)
+	}},
+}
+
+func ignore[T any](_ T, err error) error {
+	return err
+}
+
+// firstError returns the first error other than not found, which is all the
+// recording driver answers lookups with.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rows given to another tenant's ID, or planted with another tenant's ID,
+// must stay out of reach: every statement is bound to the context's tenant.
+func TestRepositoriesStayInTheirTenant(t *testing.T) {
+	db, recorder, err := tenanttest.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tenantOwned {
+		t.Run(tt.model, func(t *testing.T) {
+			recorder.Reset()
+			if err := tt.run(tenant.WithID(context.Background(), 2), db); err != nil {
+				t.Fatal(err)
+			}
+
+			statements := recorder.Statements()
+			if len(statements) == 0 {
+				t.Fatal("no statement sent")
+			}
+			for _, statement := range statements {
+				if err := statement.CheckScoped(2); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
 
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"gorm.io/gorm"
@@ -16,6 +20,20 @@ type UserRepository interface {
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, offset, limit int) ([]*models.User, error)
+	ListByAuthSource(ctx context.Context, source string, offset, limit int) ([]*models.User, error)
+	// Search lists users matching the filter, including soft-deleted ones
+	// when filtering by the deleted status, and returns the total count.
+	Search(ctx context.Context, filter *models.UserFilter) ([]*models.User, int64, error)
+	// GetByIDUnscoped also finds soft-deleted users.
+	GetByIDUnscoped(ctx context.Context, id uint) (*models.User, error)
+	// FindByNipOrEmail returns the users, deleted or not, holding either
+	// value. There are at most two.
+	FindByNipOrEmail(ctx context.Context, nip, email string) ([]*models.User, error)
+	Restore(ctx context.Context, id uint) error
+	CountActiveByRole(ctx context.Context, role string) (int64, error)
+	// ListActive returns every active user, for roster synchronisation.
+	ListActive(ctx context.Context) ([]*models.User, error)
+	Transaction(ctx context.Context, fn func(repo UserRepository) error) error
 }
 
 type userRepository struct {
@@ -53,6 +71,100 @@ func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*models
 	return users, err
 }
 
+func (r *userRepository) ListByAuthSource(ctx context.Context, source string, offset, limit int) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.WithContext(ctx).Where("auth_source = ?", source).Offset(offset).Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Search(ctx context.Context, filter *models.UserFilter) ([]*models.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.User{})
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := likePattern(q)
+		query = query.Where("name ILIKE ? OR nip ILIKE ? OR email ILIKE ?", pattern, pattern, pattern)
+	}
+	if name := strings.TrimSpace(filter.Name); name != "" {
+		query = query.Where("name ILIKE ?", likePattern(name))
+	}
+	if nip := strings.TrimSpace(filter.Nip); nip != "" {
+		query = query.Where("nip ILIKE ?", likePattern(nip))
+	}
+	if email := strings.TrimSpace(filter.Email); email != "" {
+		query = query.Where("email ILIKE ?", likePattern(email))
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	switch filter.Status {
+	case constants.UserStatusActive:
+		query = query.Where("is_active = ?", true)
+	case constants.UserStatusInactive:
+		query = query.Where("is_active = ?", false)
+	case constants.UserStatusLocked:
+		query = query.Where("locked_until > ?", time.Now())
+	case constants.UserStatusDeleted:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*models.User
+	err := query.Order("name ASC, id ASC").Offset(filter.Offset).Limit(filter.Limit).Find(&users).Error
+	return users, total, err
+}
+
+func (r *userRepository) GetByIDUnscoped(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Unscoped().First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByNipOrEmail(ctx context.Context, nip, email string) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.WithContext(ctx).Unscoped().Where("nip = ? OR email = ?", nip, email).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil).Error
+}
+
+func (r *userRepository) CountActiveByRole(ctx context.Context, role string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("role = ? AND is_active = ?", role, true).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *userRepository) ListActive(ctx context.Context) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Order("id ASC").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Transaction(ctx context.Context, fn func(repo UserRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&userRepository{tx})
+	})
+}
+
+// likePattern wraps value for a substring ILIKE match, escaping the
+// wildcard characters it contains.
+func likePattern(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+	return "%" + value + "%"
+}
+
 func (r *userRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
 	err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*models.UserIdentity, error)
+	Delete(ctx context.Context, userID uint, provider string) (bool, error)
+	TouchLogin(ctx context.Context, id uint, email *string) error
+	CreateState(ctx context.Context, state *models.OIDCLoginState) error
+	ConsumeState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error)
+	DeleteExpiredStates(ctx context.Context) error
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db}
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *userIdentityRepository) GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).First(&identity, "provider = ? AND subject = ?", provider, subject).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &identity, err
+}
+
+func (r *userIdentityRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.UserIdentity, error) {
+	var identities []*models.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+func (r *userIdentityRepository) Delete(ctx context.Context, userID uint, provider string) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&models.UserIdentity{}, "user_id = ? AND provider = ?", userID, provider)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *userIdentityRepository) TouchLogin(ctx context.Context, id uint, email *string) error {
+	return r.db.WithContext(ctx).Model(&models.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_login_at": time.Now(), "email": email}).Error
+}
+
+func (r *userIdentityRepository) CreateState(ctx context.Context, state *models.OIDCLoginState) error {
+	if err := r.db.WithContext(ctx).Create(state).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConsumeState deletes and returns the login state so each state can complete
+// at most one callback.
+func (r *userIdentityRepository) ConsumeState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	var state models.OIDCLoginState
+	result := r.db.WithContext(ctx).Raw(
+		"DELETE FROM oidc_login_states WHERE state_hash = ? RETURNING *", stateHash,
+	).Scan(&state)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &state, nil
+}
+
+func (r *userIdentityRepository) DeleteExpiredStates(ctx context.Context) error {
+	return r.db.WithContext(ctx).Delete(&models.OIDCLoginState{}, "expires_at < ?", time.Now()).Error
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type UserImportRepository interface {
+	Create(ctx context.Context, userImport *models.UserImport) error
+	GetByID(ctx context.Context, id uint) (*models.UserImport, error)
+	List(ctx context.Context, offset, limit int) ([]*models.UserImport, error)
+}
+
+type userImportRepository struct {
+	db *gorm.DB
+}
+
+func NewUserImportRepository(db *gorm.DB) UserImportRepository {
+	return &userImportRepository{db}
+}
+
+func (r *userImportRepository) Create(ctx context.Context, userImport *models.UserImport) error {
+	return r.db.WithContext(ctx).Create(userImport).Error
+}
+
+func (r *userImportRepository) GetByID(ctx context.Context, id uint) (*models.UserImport, error) {
+	var userImport models.UserImport
+	if err := r.db.WithContext(ctx).First(&userImport, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &userImport, nil
+}
+
+// List returns imports newest first, without their reports.
+func (r *userImportRepository) List(ctx context.Context, offset, limit int) ([]*models.UserImport, error) {
+	var imports []*models.UserImport
+	err := r.db.WithContext(ctx).Omit("report").Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&imports).Error
+	return imports, err
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidAPIKey      = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyScope        = errors.New("API key does not have the required scope")
+	ErrInvalidScope       = errors.New("unknown API key scope")
+	ErrAPIKeyNotFound     = errors.New("API key not found")
+	ErrNotServiceAccount  = errors.New("user is not a service account")
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrAPIKeyExpiryPassed = errors.New("expiry must be in the future")
+)
+
+const (
+	apiKeyPrefix = "bhl_"
+	// apiKeyTouchInterval limits how often a key's last used details are
+	// written.
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKeyService interface {
+	CreateServiceAccount(ctx context.Context, req *models.CreateServiceAccountRequest) (*models.UserResponse, error)
+	GetServiceAccounts(ctx context.Context, offset, limit int) ([]*models.UserResponse, error)
+	// CreateKey issues a key for ownerID. Keys for other users can only be
+	// created for service accounts.
+	CreateKey(ctx context.Context, actorID, ownerID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKeyResponse, error)
+	GetKeys(ctx context.Context, ownerID uint) ([]*models.APIKeyResponse, error)
+	RevokeKey(ctx context.Context, actorID, ownerID, keyID uint) error
+	// Authenticate resolves a raw key with the given scope to its key record.
+	Authenticate(ctx context.Context, rawKey, scope string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo     repository.APIKeyRepository
+	userRepo repository.UserRepository
+	security SecurityService
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, userRepo repository.UserRepository, security SecurityService) APIKeyService {
+	return &apiKeyService{
+		repo:     repo,
+		userRepo: userRepo,
+		security: security,
+	}
+}
+
+// CreateServiceAccount creates a user that cannot sign in. It gets a
+// generated NIP starting with SA and, unless given, a placeholder email.
+func (s *apiKeyService) CreateServiceAccount(ctx context.Context, req *models.CreateServiceAccountRequest) (*models.UserResponse, error) {
+	if !constants.IsValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+
+	nip, err := serviceAccountNip()
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		email = strings.ToLower(nip) + "@service-account.invalid"
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	password, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	user := &models.User{
+		Name:            strings.TrimSpace(req.Name),
+		Email:           email,
+		Nip:             nip,
+		Role:            req.Role,
+		Password:        string(password),
+		IsActive:        true,
+		AuthSource:      constants.AuthSourceService,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user.ToResponse(), nil
+}
+
+func (s *apiKeyService) GetServiceAccounts(ctx context.Context, offset, limit int) ([]*models.UserResponse, error) {
+	users, err := s.userRepo.ListByAuthSource(ctx, constants.AuthSourceService, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = user.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, actorID, ownerID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKeyResponse, error) {
+	owner, err := s.userRepo.GetByID(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if actorID != ownerID && owner.AuthSource != constants.AuthSourceService {
+		return nil, ErrNotServiceAccount
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !constants.IsValidScope(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrAPIKeyExpiryPassed
+	}
+
+	prefix, err := util.RandomToken(6)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	prefix = apiKeyPrefix + strings.NewReplacer("-", "x", "_", "x").Replace(prefix)
+	rawKey := prefix + "_" + secret
+
+	key := &models.APIKey{
+		UserID:    ownerID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    prefix,
+		KeyHash:   util.HashToken(rawKey),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: &actorID,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	s.logEvent(ctx, actorID, ownerID, constants.SecurityEventAPIKeyCreated, key)
+
+	return &models.CreatedAPIKeyResponse{
+		APIKeyResponse: key.ToResponse(),
+		Key:            rawKey,
+	}, nil
+}
+
+func (s *apiKeyService) GetKeys(ctx context.Context, ownerID uint) ([]*models.APIKeyResponse, error) {
+	keys, err := s.repo.ListByUserID(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = key.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, actorID, ownerID, keyID uint) error {
+	revoked, err := s.repo.Revoke(ctx, ownerID, keyID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+
+	s.logEvent(ctx, actorID, ownerID, constants.SecurityEventAPIKeyRevoked, &models.APIKey{ID: keyID})
+	return nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey, scope string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByHash(ctx, util.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if !key.IsActive() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if !key.HasScope(scope) {
+		return nil, ErrAPIKeyScope
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > apiKeyTouchInterval {
+		client := models.ClientInfoFromContext(ctx)
+		if err := s.repo.Touch(ctx, key.ID, optionalString(client.IP)); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+func (s *apiKeyService) logEvent(ctx context.Context, actorID, ownerID uint, eventType string, key *models.APIKey) {
+	details := fmt.Sprintf("key %d", key.ID)
+	if key.Prefix != "" {
+		details = fmt.Sprintf("key %d (%s) scopes %s", key.ID, key.Prefix, key.Scopes)
+	}
+
+	event := newSecurityEvent(&ownerID, eventType, models.ClientInfoFromContext(ctx), details)
+	event.ActorID = &actorID
+	s.security.LogEvent(ctx, event)
+}
+
+// serviceAccountNip returns SA followed by ten random digits. Real NIPs are
+// numeric, so generated ones cannot collide with staff.
+func serviceAccountNip() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1e10))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SA%010d", n.Int64()), nil
+}
@@ -0,0 +1,508 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCheckInCode = errors.New("check-in code is invalid or has expired")
+	ErrCheckInClosed      = errors.New("check-in is not open for this session")
+	ErrSessionEndsBefore  = errors.New("session must end after it starts")
+)
+
+// AttendanceService schedules course and chapter sessions and records who attended
+// them. Attendance counts toward completion for courses with a minimum
+// attendance percentage.
+type AttendanceService interface {
+	CreateSession(ctx context.Context, chapterID uint, req *models.CreateChapterSessionRequest) (*models.ChapterSessionResponse, error)
+	// CreateCourseSession schedules a session for the course as a whole
+	// rather than for one chapter.
+	CreateCourseSession(ctx context.Context, courseID uint, req *models.CreateChapterSessionRequest) (*models.ChapterSessionResponse, error)
+	GetChapterSessions(ctx context.Context, chapterID uint) ([]*models.ChapterSessionResponse, error)
+	GetCourseSessions(ctx context.Context, courseID uint) ([]*models.ChapterSessionResponse, error)
+	UpdateSession(ctx context.Context, id uint, req *models.UpdateChapterSessionRequest) (*models.ChapterSessionResponse, error)
+	DeleteSession(ctx context.Context, id uint) error
+	// GetCheckInCode returns the current self check-in code of a session
+	// that is open for check-in.
+	GetCheckInCode(ctx context.Context, id uint) (*models.CheckInCodeResponse, error)
+	// CheckIn records the learner as present, or late, at the session the
+	// code belongs to.
+	CheckIn(ctx context.Context, userID uint, req *models.CheckInRequest) (*models.AttendanceRecordResponse, error)
+	// GetRoster returns every enrolled learner with their attendance at the
+	// session.
+	GetRoster(ctx context.Context, id uint) ([]*models.SessionRosterEntry, error)
+	MarkAttendance(ctx context.Context, actorID, id, userID uint, req *models.MarkAttendanceRequest) (*models.AttendanceRecordResponse, error)
+	GetMyAttendance(ctx context.Context, userID, courseID uint) (*models.MyAttendanceResponse, error)
+	GetCourseReport(ctx context.Context, courseID uint) (*models.CourseAttendanceReport, error)
+}
+
+type attendanceService struct {
+	repo           repository.AttendanceRepository
+	chapterRepo    repository.CourseChapterRepository
+	courseRepo     repository.CourseRepository
+	enrollmentRepo repository.EnrollmentRepository
+	enrollments    EnrollmentService
+	cfg            *config.AttendanceConfig
+}
+
+func NewAttendanceService(repo repository.AttendanceRepository, chapterRepo repository.CourseChapterRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, enrollments EnrollmentService, cfg *config.AttendanceConfig) AttendanceService {
+	return &attendanceService{
+		repo:           repo,
+		chapterRepo:    chapterRepo,
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+		enrollments:    enrollments,
+		cfg:            cfg,
+	}
+}
+
+func (s *attendanceService) CreateSession(ctx context.Context, chapterID uint, req *models.CreateChapterSessionRequest) (*models.ChapterSessionResponse, error) {
+	chapter, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createSession(ctx, chapter.CourseID, &chapter.ID, req)
+}
+
+func (s *attendanceService) CreateCourseSession(ctx context.Context, courseID uint, req *models.CreateChapterSessionRequest) (*models.ChapterSessionResponse, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID); err != nil {
+		return nil, err
+	}
+
+	return s.createSession(ctx, courseID, nil, req)
+}
+
+func (s *attendanceService) GetChapterSessions(ctx context.Context, chapterID uint) ([]*models.ChapterSessionResponse, error) {
+	if _, err := s.chapterRepo.GetByID(ctx, chapterID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.ListSessionsByChapter(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := models.CalendarFromContext(ctx)
+	responses := make([]*models.ChapterSessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = session.ToResponse().SetHijri(calendar)
+	}
+
+	return responses, nil
+}
+
+func (s *attendanceService) GetCourseSessions(ctx context.Context, courseID uint) ([]*models.ChapterSessionResponse, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.ListSessionsByCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := models.CalendarFromContext(ctx)
+	responses := make([]*models.ChapterSessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = session.ToResponse().SetHijri(calendar)
+	}
+
+	return responses, nil
+}
+
+func (s *attendanceService) UpdateSession(ctx context.Context, id uint, req *models.UpdateChapterSessionRequest) (*models.ChapterSessionResponse, error) {
+	calendar := models.CalendarFromContext(ctx)
+	if err := req.ResolveHijri(calendar); err != nil {
+		return nil, err
+	}
+
+	session, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		session.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.StartsAt != nil {
+		session.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		session.EndsAt = *req.EndsAt
+	}
+	if req.Location != nil {
+		session.Location = optionalString(*req.Location)
+	}
+	if req.MeetingURL != nil {
+		session.MeetingURL = optionalString(*req.MeetingURL)
+	}
+	if !session.EndsAt.After(session.StartsAt) {
+		return nil, ErrSessionEndsBefore
+	}
+	session.Sequence++
+
+	if err := s.repo.UpdateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session.ToResponse().SetHijri(calendar), nil
+}
+
+func (s *attendanceService) DeleteSession(ctx context.Context, id uint) error {
+	if _, err := s.repo.GetSession(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteSession(ctx, id)
+}
+
+func (s *attendanceService) GetCheckInCode(ctx context.Context, id uint) (*models.CheckInCodeResponse, error) {
+	session, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !s.checkInOpen(session, now) {
+		return nil, ErrCheckInClosed
+	}
+
+	step := s.step(now)
+	return &models.CheckInCodeResponse{
+		Code:      checkInCode(session, step),
+		ExpiresAt: time.Unix((step+1)*int64(s.cfg.CodeRotation.Seconds()), 0),
+	}, nil
+}
+
+func (s *attendanceService) CheckIn(ctx context.Context, userID uint, req *models.CheckInRequest) (*models.AttendanceRecordResponse, error) {
+	sessionID, step, ok := parseCheckInCode(req.Code)
+	if !ok {
+		return nil, ErrInvalidCheckInCode
+	}
+
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCheckInCode
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	current := s.step(now)
+	if (step != current && step != current-1) || !hmac.Equal([]byte(req.Code), []byte(checkInCode(session, step))) {
+		return nil, ErrInvalidCheckInCode
+	}
+	if !s.checkInOpen(session, now) {
+		return nil, ErrCheckInClosed
+	}
+
+	if _, err := s.enrollment(ctx, session.CourseID, userID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetRecord(ctx, session.ID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	// Checking in again, or after a mentor marked the learner, keeps the
+	// record; only an absence is replaced.
+	if existing != nil && existing.Status != constants.AttendanceAbsent.String() {
+		return existing.ToResponse(), nil
+	}
+
+	status := constants.AttendancePresent
+	if now.After(session.StartsAt.Add(s.cfg.LateAfter)) {
+		status = constants.AttendanceLate
+	}
+	record := &models.AttendanceRecord{
+		SessionID:   session.ID,
+		UserID:      userID,
+		Status:      status.String(),
+		CheckedInAt: &now,
+	}
+	if err := s.repo.SaveRecord(ctx, record); err != nil {
+		return nil, err
+	}
+
+	if err := s.enrollments.RefreshCompletion(ctx, userID, session.CourseID, userID); err != nil {
+		return nil, err
+	}
+
+	return record.ToResponse(), nil
+}
+
+func (s *attendanceService) GetRoster(ctx context.Context, id uint) ([]*models.SessionRosterEntry, error) {
+	session, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.enrollmentRepo.ListWithAccess(ctx, session.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.ListRecordsBySession(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[uint]*models.AttendanceRecord, len(records))
+	for _, record := range records {
+		byUser[record.UserID] = record
+	}
+
+	roster := make([]*models.SessionRosterEntry, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		if !enrollment.HasAccess() {
+			continue
+		}
+
+		entry := &models.SessionRosterEntry{
+			User:   enrollment.User.ToResponse(),
+			Status: constants.AttendanceUnmarked,
+		}
+		if record, ok := byUser[enrollment.UserID]; ok {
+			entry.Status = record.Status
+			entry.CheckedInAt = record.CheckedInAt
+			entry.Note = record.Note
+		}
+		roster = append(roster, entry)
+	}
+
+	return roster, nil
+}
+
+func (s *attendanceService) MarkAttendance(ctx context.Context, actorID, id, userID uint, req *models.MarkAttendanceRequest) (*models.AttendanceRecordResponse, error) {
+	session, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.enrollment(ctx, session.CourseID, userID); err != nil {
+		return nil, err
+	}
+
+	record := &models.AttendanceRecord{
+		SessionID: session.ID,
+		UserID:    userID,
+		Status:    req.Status,
+		MarkedBy:  &actorID,
+		Note:      req.Note,
+	}
+	existing, err := s.repo.GetRecord(ctx, session.ID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		record.CheckedInAt = existing.CheckedInAt
+	}
+	if err := s.repo.SaveRecord(ctx, record); err != nil {
+		return nil, err
+	}
+
+	if err := s.enrollments.RefreshCompletion(ctx, actorID, session.CourseID, userID); err != nil {
+		return nil, err
+	}
+
+	return record.ToResponse(), nil
+}
+
+func (s *attendanceService) GetMyAttendance(ctx context.Context, userID, courseID uint) (*models.MyAttendanceResponse, error) {
+	course, err := s.courseRepo.GetByID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.enrollment(ctx, courseID, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.ListSessionsByCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.ListRecordsByCourse(ctx, courseID, []uint{userID})
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[uint]string, len(records))
+	for _, record := range records {
+		statuses[record.SessionID] = record.Status
+	}
+
+	now := time.Now()
+	calendar := models.CalendarFromContext(ctx)
+	response := &models.MyAttendanceResponse{
+		CourseID:   courseID,
+		MinPercent: course.MinAttendancePercent,
+		Sessions:   make([]models.SessionAttendance, len(sessions)),
+	}
+	var held []uint
+	for i, session := range sessions {
+		status, ok := statuses[session.ID]
+		if !ok {
+			status = constants.AttendanceUnmarked
+		}
+		response.Sessions[i] = models.SessionAttendance{Session: session.ToResponse().SetHijri(calendar), Status: status}
+		if session.IsHeld(now) {
+			held = append(held, session.ID)
+		}
+	}
+	response.Summary = models.SummarizeAttendance(held, statuses)
+
+	return response, nil
+}
+
+func (s *attendanceService) GetCourseReport(ctx context.Context, courseID uint) (*models.CourseAttendanceReport, error) {
+	course, err := s.courseRepo.GetByID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.ListSessionsByCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var held []uint
+	for _, session := range sessions {
+		if session.IsHeld(now) {
+			held = append(held, session.ID)
+		}
+	}
+
+	enrollments, err := s.enrollmentRepo.ListWithAccess(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint, len(enrollments))
+	for i, enrollment := range enrollments {
+		userIDs[i] = enrollment.UserID
+	}
+	records, err := s.repo.ListRecordsByCourse(ctx, courseID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[uint]map[uint]string, len(enrollments))
+	for _, record := range records {
+		if byUser[record.UserID] == nil {
+			byUser[record.UserID] = make(map[uint]string)
+		}
+		byUser[record.UserID][record.SessionID] = record.Status
+	}
+
+	report := &models.CourseAttendanceReport{
+		CourseID:   courseID,
+		MinPercent: course.MinAttendancePercent,
+		Sessions:   len(sessions),
+		Held:       len(held),
+		Users:      make([]*models.LearnerAttendance, 0, len(enrollments)),
+	}
+	for _, enrollment := range enrollments {
+		if !enrollment.HasAccess() {
+			continue
+		}
+		summary := models.SummarizeAttendance(held, byUser[enrollment.UserID])
+		report.Users = append(report.Users, &models.LearnerAttendance{
+			User:             enrollment.User.ToResponse(),
+			Summary:          summary,
+			MeetsRequirement: summary.Meets(course.MinAttendancePercent),
+		})
+	}
+
+	return report, nil
+}
+
+func (s *attendanceService) createSession(ctx context.Context, courseID uint, chapterID *uint, req *models.CreateChapterSessionRequest) (*models.ChapterSessionResponse, error) {
+	calendar := models.CalendarFromContext(ctx)
+	if err := req.ResolveHijri(calendar); err != nil {
+		return nil, err
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, ErrSessionEndsBefore
+	}
+
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.ChapterSession{
+		CourseID:      courseID,
+		ChapterID:     chapterID,
+		Title:         strings.TrimSpace(req.Title),
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
+		Location:      req.Location,
+		MeetingURL:    req.MeetingURL,
+		CheckInSecret: secret,
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session.ToResponse().SetHijri(calendar), nil
+}
+
+// enrollment returns the learner's enrollment in the course if it gives
+// access to the course.
+func (s *attendanceService) enrollment(ctx context.Context, courseID, userID uint) (*models.Enrollment, error) {
+	enrollment, err := s.enrollmentRepo.GetByUserAndCourse(ctx, userID, courseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, err
+	}
+	if !enrollment.HasAccess() {
+		return nil, ErrEnrollmentNotActive
+	}
+	return enrollment, nil
+}
+
+func (s *attendanceService) checkInOpen(session *models.ChapterSession, now time.Time) bool {
+	return !now.Before(session.StartsAt.Add(-s.cfg.CheckInOpensBefore)) && !now.After(session.EndsAt)
+}
+
+func (s *attendanceService) step(now time.Time) int64 {
+	return now.Unix() / int64(s.cfg.CodeRotation.Seconds())
+}
+
+// checkInCode signs the session and time step with the session's secret. The
+// code carries both so the server can check it without storing codes.
+func checkInCode(session *models.ChapterSession, step int64) string {
+	payload := fmt.Sprintf("%d.%d", session.ID, step)
+	mac := hmac.New(sha256.New, []byte(session.CheckInSecret))
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func parseCheckInCode(code string) (uint, int64, bool) {
+	parts := strings.Split(code, ".")
+	if len(parts) != 3 {
+		return 0, 0, false
+	}
+	sessionID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	step, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint(sessionID), step, true
+}
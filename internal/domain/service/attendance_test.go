@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type memoryAttendance struct {
+	repository.AttendanceRepository
+	sessions []*models.ChapterSession
+	records  []*models.AttendanceRecord
+}
+
+func (r *memoryAttendance) GetSession(ctx context.Context, id uint) (*models.ChapterSession, error) {
+	for _, session := range r.sessions {
+		if session.ID == id {
+			return session, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAttendance) GetRecord(ctx context.Context, sessionID, userID uint) (*models.AttendanceRecord, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAttendance) SaveRecord(ctx context.Context, record *models.AttendanceRecord) error {
+	r.records = append(r.records, record)
+	return nil
+}
+
+type refreshes struct {
+	EnrollmentService
+}
+
+func (refreshes) RefreshCompletion(ctx context.Context, actorID, courseID, userID uint) error {
+	return nil
+}
+
+func TestCheckInAcceptsOnlyCurrentCodesOfTheSession(t *testing.T) {
+	now := time.Now()
+	attendance := &memoryAttendance{sessions: []*models.ChapterSession{
+		{ID: 1, CourseID: 1, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), CheckInSecret: "first-secret"},
+		{ID: 2, CourseID: 1, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), CheckInSecret: "second-secret"},
+	}}
+	enrollments := &memoryEnrollments{enrollments: []*models.Enrollment{learner(7, constants.EnrollmentActive)}}
+	cfg := &config.AttendanceConfig{CodeRotation: time.Hour, LateAfter: time.Hour}
+	svc := NewAttendanceService(attendance, nil, nil, enrollments, refreshes{}, cfg)
+	session := attendance.sessions[0]
+	ctx := context.Background()
+
+	current, err := svc.GetCheckInCode(ctx, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, step, _ := parseCheckInCode(current.Code)
+	tampered := []byte(current.Code)
+	tampered[len(tampered)-1] ^= 1
+
+	rejected := map[string]string{
+		"two steps old":          checkInCode(session, step-2),
+		"another session's code": "1" + strings.TrimPrefix(checkInCode(attendance.sessions[1], step), "2"),
+		"tampered mac":           string(tampered),
+		"not a code":             "1.2",
+		"unknown session":        "9" + strings.TrimPrefix(current.Code, "1"),
+	}
+	for name, code := range rejected {
+		if _, err := svc.CheckIn(ctx, 7, &models.CheckInRequest{Code: code}); !errors.Is(err, ErrInvalidCheckInCode) {
+			t.Errorf("%s: check in = %v, want %v", name, err, ErrInvalidCheckInCode)
+		}
+	}
+	if len(attendance.records) != 0 {
+		t.Fatalf("rejected codes recorded %d check-ins", len(attendance.records))
+	}
+
+	for name, code := range map[string]string{"current": current.Code, "previous": checkInCode(session, step-1)} {
+		if _, err := svc.CheckIn(ctx, 7, &models.CheckInRequest{Code: code}); err != nil {
+			t.Errorf("%s code: %v", name, err)
+		}
+	}
+	if len(attendance.records) != 2 {
+		t.Fatalf("recorded %d check-ins for the current and previous codes", len(attendance.records))
+	}
+}
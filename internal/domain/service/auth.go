@@ -4,53 +4,145 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/bobchopperz/bahrululum/internal/config"
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/jwtkeys"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/bobchopperz/bahrululum/internal/util"
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type AuthService interface {
-	Login(ctx context.Context, req *models.LoginRequest) (*models.TokenResponse, error)
-	ValidateToken(tokenString string) (*Claims, error)
-	GenerateToken(userID uint) (*models.TokenResponse, error)
+	Login(ctx context.Context, req *models.LoginRequest, client *models.ClientInfo) (*models.TokenResponse, error)
+	CompleteLogin(ctx context.Context, user *models.User) (*models.TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.TokenResponse, error)
+	ValidateToken(ctx context.Context, tokenString string) (*Claims, error)
+	GenerateToken(ctx context.Context, userID uint) (*models.TokenResponse, error)
+	RevokeUserTokens(ctx context.Context, userID uint) error
+	VerifyMFA(ctx context.Context, req *models.MFAVerifyRequest) (*models.TokenResponse, error)
+	BeginMFAEnrollment(ctx context.Context, challengeToken string) (*models.TOTPSetupResponse, error)
+	ConfirmMFAEnrollment(ctx context.Context, req *models.MFAEnrollConfirmRequest) (*models.MFAEnrollmentResponse, error)
 }
 
+const (
+	tokenTypeAccess       = "access"
+	tokenTypeRefresh      = "refresh"
+	tokenTypeMFAChallenge = "mfa_challenge"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrAccountInactive     = errors.New("user account is inactive")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+)
+
+// sessionTouchInterval limits how often authenticated requests update a
+// session's last used time.
+const sessionTouchInterval = 5 * time.Minute
+
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint   `json:"user_id"`
+	TokenType string `json:"token_type,omitempty"`
+	SessionID uint   `json:"sid,omitempty"`
+	TenantID  uint   `json:"tid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type authService struct {
-	userRepo  repository.UserRepository
-	jwtConfig *config.JWTConfig
+	userRepo        repository.UserRepository
+	refreshRepo     repository.RefreshTokenRepository
+	mfa             MFAService
+	security        SecurityService
+	authenticators  []Authenticator
+	keys            *jwtkeys.KeySet
+	jwtConfig       *config.JWTConfig
+	verificationCfg *config.EmailVerificationConfig
+	mfaCfg          *config.MFAConfig
+	mfaLimiter      *util.RateLimiter
 }
 
-func NewAuthService(userRepo repository.UserRepository, jwtConfig *config.JWTConfig) AuthService {
+func NewAuthService(userRepo repository.UserRepository, refreshRepo repository.RefreshTokenRepository, mfa MFAService, security SecurityService, authenticators []Authenticator, keys *jwtkeys.KeySet, jwtConfig *config.JWTConfig, verificationCfg *config.EmailVerificationConfig, mfaCfg *config.MFAConfig) AuthService {
 	return &authService{
-		userRepo:  userRepo,
-		jwtConfig: jwtConfig,
+		userRepo:        userRepo,
+		refreshRepo:     refreshRepo,
+		mfa:             mfa,
+		security:        security,
+		authenticators:  authenticators,
+		keys:            keys,
+		jwtConfig:       jwtConfig,
+		verificationCfg: verificationCfg,
+		mfaCfg:          mfaCfg,
+		mfaLimiter:      util.NewRateLimiter(mfaCfg.MaxAttempts, mfaCfg.ChallengeExpiry),
 	}
 }
 
-func (s *authService) Login(ctx context.Context, req *models.LoginRequest) (*models.TokenResponse, error) {
+// Login checks the NIP and password against the authenticator chain.
+// Attempts are throttled per account and per client address; see
+// SecurityService.
+func (s *authService) Login(ctx context.Context, req *models.LoginRequest, client *models.ClientInfo) (*models.TokenResponse, error) {
 	user, err := s.userRepo.GetByNip(ctx, req.Nip)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.security.CheckLogin(ctx, user, client); err != nil {
+		return nil, err
+	}
+
+	authenticated, err := s.authenticate(ctx, user, req)
 	if err != nil {
-		return nil, fmt.Errorf("Invalid credentials")
+		s.security.RecordLoginFailure(ctx, user, client)
+		return nil, err
 	}
+	user = authenticated
+
+	s.security.RecordLoginSuccess(ctx, user)
+
+	return s.CompleteLogin(ctx, user)
+}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, fmt.Errorf("Invalid credentials")
+// authenticate runs the authenticators in order until one accepts or rejects
+// the credentials. Errors other than a rejection, such as an unreachable
+// directory, are logged and the next authenticator is tried, which lets
+// local break-glass accounts sign in during a directory outage.
+func (s *authService) authenticate(ctx context.Context, user *models.User, req *models.LoginRequest) (*models.User, error) {
+	for _, authenticator := range s.authenticators {
+		authenticated, err := authenticator.Authenticate(ctx, user, req.Nip, req.Password)
+		switch {
+		case err == nil:
+			return authenticated, nil
+		case errors.Is(err, ErrInvalidCredentials):
+			return nil, err
+		case !errors.Is(err, ErrAuthenticatorSkip):
+			log.Printf("login: %s authenticator: %v", authenticator.Name(), err)
+		}
 	}
 
+	return nil, ErrInvalidCredentials
+}
+
+// CompleteLogin applies the account checks shared by every sign-in method to
+// an already authenticated user and issues either a token pair or an MFA
+// challenge.
+func (s *authService) CompleteLogin(ctx context.Context, user *models.User) (*models.TokenResponse, error) {
 	if !user.IsActive {
-		return nil, fmt.Errorf("user account is inactive")
+		return nil, ErrAccountInactive
 	}
 
-	token, err := s.GenerateToken(user.ID)
+	if s.verificationCfg.RequireForLogin && !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	if user.IsMFAEnabled() || s.mfa.Required(user) {
+		return s.mfaChallenge(ctx, user)
+	}
+
+	token, err := s.GenerateToken(ctx, user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token")
 	}
@@ -60,60 +152,224 @@ func (s *authService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 	return token, nil
 }
 
-func (s *authService) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// Refresh exchanges a stored refresh token for a new token pair. Each refresh
+// token can be used once; the session keeps its ID and gets a new token.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*models.TokenResponse, error) {
+	claims, err := s.parseToken(ctx, refreshToken)
+	if err != nil || claims.TokenType != tokenTypeRefresh {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	oldHash := util.HashToken(refreshToken)
+	session, err := s.refreshRepo.GetByToken(ctx, oldHash)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if session.UserID != claims.UserID || time.Now().After(session.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil || !user.IsActive {
+		s.refreshRepo.Delete(ctx, session.ID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if s.mfa.Required(user) && !user.IsMFAEnabled() {
+		return nil, ErrMFARequired
+	}
+
+	newRefreshToken, expiresAt, err := s.signRefreshToken(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Token = util.HashToken(newRefreshToken)
+	session.ExpiresAt = expiresAt
+	setSessionClient(ctx, session)
+
+	rotated, err := s.refreshRepo.Rotate(ctx, session, oldHash)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	token, err := s.tokenPair(ctx, session, newRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	token.User = user.ToResponse()
+
+	return token, nil
+}
+
+// RevokeUserTokens deletes every refresh token issued to the user.
+func (s *authService) RevokeUserTokens(ctx context.Context, userID uint) error {
+	return s.refreshRepo.DeleteByUserID(ctx, userID)
+}
+
+// ValidateToken validates an access token. Refresh and MFA challenge tokens
+// are rejected, as are access tokens whose session has been revoked.
+func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := s.parseToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != "" && claims.TokenType != tokenTypeAccess {
+		return nil, fmt.Errorf("%s token cannot be used for authentication", claims.TokenType)
+	}
+
+	if claims.SessionID != 0 {
+		if err := s.checkSession(ctx, claims); err != nil {
+			return nil, err
 		}
-		return []byte(s.jwtConfig.Secret), nil
-	})
+	}
+
+	return claims, nil
+}
+
+// checkSession makes sure the access token's session still exists, and
+// refreshes its last used time at most every sessionTouchInterval.
+func (s *authService) checkSession(ctx context.Context, claims *Claims) error {
+	session, err := s.refreshRepo.GetByID(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+	if session.UserID != claims.UserID || time.Now().After(session.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+
+	if session.LastUsedAt == nil || time.Since(*session.LastUsedAt) > sessionTouchInterval {
+		if err := s.refreshRepo.Touch(ctx, session.ID); err != nil {
+			log.Printf("session %d: failed to update last used time: %v", session.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// parseToken verifies a token and checks that it was issued for the tenant
+// in ctx. Tokens from before tenants existed carry no tenant and are only
+// accepted by the default tenant.
+func (s *authService) parseToken(ctx context.Context, tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keys.Keyfunc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+
+	issuedFor := claims.TenantID
+	if issuedFor == 0 {
+		issuedFor = tenant.DefaultID
+	}
+	if issuedFor != tenant.ID(ctx) {
+		return nil, errors.New("token was issued for another tenant")
 	}
 
-	return nil, errors.New("invalid token claims")
+	return claims, nil
 }
 
-func (s *authService) GenerateToken(userID uint) (*models.TokenResponse, error) {
-	accessClaimns := &Claims{
-		UserID: userID,
+// GenerateToken starts a new session for the user and returns its token
+// pair. The client recorded with the session is taken from ctx.
+func (s *authService) GenerateToken(ctx context.Context, userID uint) (*models.TokenResponse, error) {
+	refreshToken, expiresAt, err := s.signRefreshToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.RefreshToken{
+		UserID:    userID,
+		Token:     util.HashToken(refreshToken),
+		ExpiresAt: expiresAt,
+	}
+	setSessionClient(ctx, session)
+
+	if err := s.refreshRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return s.tokenPair(ctx, session, refreshToken)
+}
+
+func (s *authService) signRefreshToken(ctx context.Context, userID uint) (string, time.Time, error) {
+	jti, err := util.RandomToken(16)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.jwtConfig.RefreshExp)
+	claims := &Claims{
+		UserID:    userID,
+		TokenType: tokenTypeRefresh,
+		TenantID:  tenant.ID(ctx),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtConfig.Expiry)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "go-rest-api",
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaimns)
-	accessTokenString, err := accessToken.SignedString([]byte(s.jwtConfig.Secret))
+	signed, err := s.keys.Sign(claims)
 	if err != nil {
-		return nil, fmt.Errorf("filed to sign access token: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to sign refresh token: %w", err)
 	}
 
-	refreshClaims := &Claims{
-		UserID: userID,
+	return signed, expiresAt, nil
+}
+
+// tokenPair signs an access token bound to the session and pairs it with the
+// session's refresh token.
+func (s *authService) tokenPair(ctx context.Context, session *models.RefreshToken, refreshToken string) (*models.TokenResponse, error) {
+	expiresAt := time.Now().Add(s.jwtConfig.Expiry)
+	accessClaims := &Claims{
+		UserID:    session.UserID,
+		TokenType: tokenTypeAccess,
+		SessionID: session.ID,
+		TenantID:  tenant.ID(ctx),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtConfig.RefreshExp)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "go-rest-api",
 		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(s.jwtConfig.Secret))
+	accessToken, err := s.keys.Sign(accessClaims)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign refresh token: %w", err)
+		return nil, fmt.Errorf("filed to sign access token: %w", err)
 	}
 
 	return &models.TokenResponse{
-		AccessToken: accessTokenString,
-
-		RefreshToken: refreshTokenString,
-		ExpiresAt:    time.Now().Add(s.jwtConfig.Expiry).Unix(),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt.Unix(),
 		TokenType:    "Bearer",
 	}, nil
 }
+
+// setSessionClient records the requesting client on the session.
+func setSessionClient(ctx context.Context, session *models.RefreshToken) {
+	client := models.ClientInfoFromContext(ctx)
+	now := time.Now()
+
+	userAgent := client.UserAgent
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
+	}
+
+	session.IPAddress = optionalString(client.IP)
+	session.UserAgent = optionalString(userAgent)
+	session.DeviceName = optionalString(util.DeviceName(client.UserAgent))
+	session.LastUsedAt = &now
+}
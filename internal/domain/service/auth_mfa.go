@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidChallenge   = errors.New("invalid or expired MFA challenge")
+	ErrTooManyMFAAttempts = errors.New("too many authentication attempts, sign in again later")
+)
+
+// mfaChallenge answers a password login that still needs a second factor
+// with a short-lived challenge token instead of a token pair. Users whose
+// role requires two-factor login but who have not enrolled yet use the same
+// token to enroll.
+func (s *authService) mfaChallenge(ctx context.Context, user *models.User) (*models.TokenResponse, error) {
+	expiresAt := time.Now().Add(s.mfaCfg.ChallengeExpiry)
+	claims := &Claims{
+		UserID:    user.ID,
+		TokenType: tokenTypeMFAChallenge,
+		TenantID:  tenant.ID(ctx),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "go-rest-api",
+		},
+	}
+
+	challenge, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign challenge token: %w", err)
+	}
+
+	return &models.TokenResponse{
+		ExpiresAt:        expiresAt.Unix(),
+		TokenType:        "MFA",
+		MFARequired:      user.IsMFAEnabled(),
+		MFASetupRequired: !user.IsMFAEnabled(),
+		ChallengeToken:   challenge,
+	}, nil
+}
+
+// VerifyMFA completes a challenged login with a TOTP or recovery code.
+func (s *authService) VerifyMFA(ctx context.Context, req *models.MFAVerifyRequest) (*models.TokenResponse, error) {
+	user, err := s.challengeUser(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsMFAEnabled() {
+		return nil, ErrMFANotEnabled
+	}
+
+	if err := s.mfa.Verify(ctx, user, req.Code, req.RecoveryCode); err != nil {
+		return nil, err
+	}
+
+	return s.issueLoginTokens(ctx, user)
+}
+
+// BeginMFAEnrollment starts TOTP enrollment for a user whose role requires it.
+func (s *authService) BeginMFAEnrollment(ctx context.Context, challengeToken string) (*models.TOTPSetupResponse, error) {
+	user, err := s.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mfa.Setup(ctx, user.ID)
+}
+
+// ConfirmMFAEnrollment enables TOTP and completes the login.
+func (s *authService) ConfirmMFAEnrollment(ctx context.Context, req *models.MFAEnrollConfirmRequest) (*models.MFAEnrollmentResponse, error) {
+	user, err := s.challengeUser(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.mfa.Confirm(ctx, user.ID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueLoginTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAEnrollmentResponse{
+		RecoveryCodes: codes,
+		Tokens:        tokens,
+	}, nil
+}
+
+// challengeUser resolves the user behind a challenge token. Attempts are
+// limited per user to stop guessing codes within the challenge lifetime.
+func (s *authService) challengeUser(ctx context.Context, challengeToken string) (*models.User, error) {
+	claims, err := s.parseToken(ctx, challengeToken)
+	if err != nil || claims.TokenType != tokenTypeMFAChallenge {
+		return nil, ErrInvalidChallenge
+	}
+
+	if !s.mfaLimiter.Allow(strconv.FormatUint(uint64(claims.UserID), 10)) {
+		return nil, ErrTooManyMFAAttempts
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidChallenge
+	}
+
+	return user, nil
+}
+
+func (s *authService) issueLoginTokens(ctx context.Context, user *models.User) (*models.TokenResponse, error) {
+	token, err := s.GenerateToken(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token")
+	}
+
+	token.User = user.ToResponse()
+
+	return token, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("Invalid credentials")
+	// ErrAuthenticatorSkip tells the chain that an authenticator does not
+	// handle the account and the next one should be tried.
+	ErrAuthenticatorSkip = errors.New("authenticator does not handle this account")
+)
+
+// Authenticator checks a NIP and password. user is the local account with
+// that NIP, or nil if there is none yet; authenticators backed by an external
+// directory may create it. Returning ErrInvalidCredentials ends the chain,
+// ErrAuthenticatorSkip and any other error move on to the next authenticator.
+type Authenticator interface {
+	Name() string
+	Authenticate(ctx context.Context, user *models.User, nip, password string) (*models.User, error)
+}
+
+type localAuthenticator struct {
+	roles []string
+}
+
+// NewLocalAuthenticator checks the bcrypt password stored with local
+// accounts. When roles is non-empty only accounts with one of those roles
+// may sign in this way.
+func NewLocalAuthenticator(roles []string) Authenticator {
+	return &localAuthenticator{roles: roles}
+}
+
+func (a *localAuthenticator) Name() string {
+	return constants.AuthSourceLocal
+}
+
+func (a *localAuthenticator) Authenticate(ctx context.Context, user *models.User, nip, password string) (*models.User, error) {
+	if user == nil || user.AuthSource != constants.AuthSourceLocal || !a.allowed(user.Role) {
+		return nil, ErrAuthenticatorSkip
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+func (a *localAuthenticator) allowed(role string) bool {
+	if len(a.roles) == 0 {
+		return true
+	}
+	for _, r := range a.roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"gorm.io/gorm"
+)
+
+const (
+	// BulkEnrollmentSyncLimit is the largest list processed inside the request;
+	// anything bigger is handed to a background job.
+	BulkEnrollmentSyncLimit = 500
+
+	bulkEnrollmentChunkSize = 200
+	bulkEnrollmentNote      = "bulk assignment"
+)
+
+type BulkEnrollmentService interface {
+	Enroll(ctx context.Context, actorID, courseID uint, req *models.BulkEnrollmentRequest) (*models.BulkEnrollmentReport, error)
+	EnrollAsync(ctx context.Context, actorID, courseID uint, req *models.BulkEnrollmentRequest) (*models.BulkEnrollmentJobResponse, error)
+	GetJob(ctx context.Context, id uint) (*models.BulkEnrollmentJobResponse, error)
+	// FailInterruptedJobs marks the jobs a previous run of the server left
+	// queued or running as failed, since nothing will finish them.
+	FailInterruptedJobs(ctx context.Context) error
+}
+
+type bulkEnrollmentService struct {
+	repo       repository.EnrollmentRepository
+	courseRepo repository.CourseRepository
+	userRepo   repository.UserRepository
+	jobRepo    repository.BulkEnrollmentJobRepository
+}
+
+func NewBulkEnrollmentService(repo repository.EnrollmentRepository, courseRepo repository.CourseRepository, userRepo repository.UserRepository, jobRepo repository.BulkEnrollmentJobRepository) BulkEnrollmentService {
+	return &bulkEnrollmentService{
+		repo:       repo,
+		courseRepo: courseRepo,
+		userRepo:   userRepo,
+		jobRepo:    jobRepo,
+	}
+}
+
+// Enroll assigns every listed NIP to the course in a single transaction.
+func (s *bulkEnrollmentService) Enroll(ctx context.Context, actorID, courseID uint, req *models.BulkEnrollmentRequest) (*models.BulkEnrollmentReport, error) {
+	rows, err := s.resolve(ctx, req.Nips)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.process(ctx, actorID, courseID, req.DueAt, rows); err != nil {
+		return nil, err
+	}
+
+	return summarize(courseID, rows), nil
+}
+
+// EnrollAsync records a job and processes the list in the background. Each
+// chunk is committed on its own so progress is visible while the job runs.
+func (s *bulkEnrollmentService) EnrollAsync(ctx context.Context, actorID, courseID uint, req *models.BulkEnrollmentRequest) (*models.BulkEnrollmentJobResponse, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID); err != nil {
+		return nil, err
+	}
+
+	job := &models.BulkEnrollmentJob{
+		CourseID: courseID,
+		ActorID:  &actorID,
+		Status:   constants.JobQueued.String(),
+		Total:    len(req.Nips),
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// The job belongs to the goroutine from here on, so the response is
+	// built first. The goroutine outlives the request and runs in the job's
+	// tenant, which the insert stamped.
+	response := job.ToResponse()
+	go s.run(tenant.WithID(context.Background(), job.TenantID), job, actorID, req.Nips, req.DueAt)
+
+	return response, nil
+}
+
+func (s *bulkEnrollmentService) GetJob(ctx context.Context, id uint) (*models.BulkEnrollmentJobResponse, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return job.ToResponse(), nil
+}
+
+func (s *bulkEnrollmentService) FailInterruptedJobs(ctx context.Context) error {
+	return s.jobRepo.FailUnfinished(ctx, "interrupted by a server restart")
+}
+
+func (s *bulkEnrollmentService) run(ctx context.Context, job *models.BulkEnrollmentJob, actorID uint, nips []string, dueAt *time.Time) {
+	job.Status = constants.JobRunning.String()
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		log.Printf("bulk enrollment job %d: %v", job.ID, err)
+	}
+
+	rows, err := s.resolve(ctx, nips)
+	if err == nil {
+		for start := 0; start < len(rows); start += bulkEnrollmentChunkSize {
+			end := min(start+bulkEnrollmentChunkSize, len(rows))
+			if err = s.process(ctx, actorID, job.CourseID, dueAt, rows[start:end]); err != nil {
+				break
+			}
+
+			job.Processed = end
+			if err := s.jobRepo.Update(ctx, job); err != nil {
+				log.Printf("bulk enrollment job %d: %v", job.ID, err)
+			}
+		}
+	}
+
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Status = constants.JobCompleted.String()
+	if err != nil {
+		msg := err.Error()
+		job.Error = &msg
+		job.Status = constants.JobFailed.String()
+	}
+
+	if report, err := json.Marshal(summarize(job.CourseID, rows[:job.Processed])); err == nil {
+		encoded := string(report)
+		job.Report = &encoded
+	}
+
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		log.Printf("bulk enrollment job %d: %v", job.ID, err)
+	}
+}
+
+// resolve looks up each NIP and pre-fills the result for rows that cannot be
+// enrolled, leaving Result empty for rows that still need processing.
+func (s *bulkEnrollmentService) resolve(ctx context.Context, nips []string) ([]models.BulkEnrollmentRow, error) {
+	rows := make([]models.BulkEnrollmentRow, len(nips))
+	seen := make(map[string]bool, len(nips))
+
+	for i, raw := range nips {
+		nip := strings.TrimSpace(raw)
+		rows[i] = models.BulkEnrollmentRow{Row: i + 1, Nip: nip}
+
+		if seen[nip] {
+			rows[i].Result = constants.BulkResultDuplicate
+			continue
+		}
+		seen[nip] = true
+
+		user, err := s.userRepo.GetByNip(ctx, nip)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				rows[i].Result = constants.BulkResultUnknownNip
+				continue
+			}
+			return nil, err
+		}
+
+		rows[i].UserID = &user.ID
+		if !user.IsActive {
+			rows[i].Result = constants.BulkResultInactiveUser
+		}
+	}
+
+	return rows, nil
+}
+
+func (s *bulkEnrollmentService) process(ctx context.Context, actorID, courseID uint, dueAt *time.Time, rows []models.BulkEnrollmentRow) error {
+	return s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		course, err := repo.LockCourse(ctx, courseID)
+		if err != nil {
+			return err
+		}
+
+		for i := range rows {
+			if rows[i].Result != "" {
+				continue
+			}
+
+			result, err := s.assign(ctx, repo, course, actorID, *rows[i].UserID, dueAt)
+			if err != nil {
+				return err
+			}
+			rows[i].Result = result
+		}
+		return nil
+	})
+}
+
+// assign enrolls a single user on behalf of an admin. Admin assignment skips
+// the course's enrollment mode but still respects the seat limit. An explicit
+// due date overrides the course default.
+func (s *bulkEnrollmentService) assign(ctx context.Context, repo repository.EnrollmentRepository, course *models.Course, actorID, userID uint, dueAt *time.Time) (string, error) {
+	note := bulkEnrollmentNote
+
+	existing, err := repo.GetByUserAndCourse(ctx, userID, course.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	if existing != nil {
+		previous := existing.CurrentStatus()
+		switch previous {
+		case constants.EnrollmentActive, constants.EnrollmentCompleted, constants.EnrollmentWaitlist:
+			return constants.BulkResultAlreadyEnrolled, nil
+		}
+
+		existing.DroppedAt = nil
+		existing.SetExpiresAt(nil)
+		if dueAt != nil && existing.SetDueAt(dueAt) {
+			if err := repo.DeleteReminders(ctx, existing.ID); err != nil {
+				return "", err
+			}
+		}
+		if existing.CompletedAt != nil {
+			err := transitionFrom(ctx, repo, existing, previous, constants.EnrollmentCompleted, constants.EnrollmentEventReenrolled, &actorID, &note)
+			return constants.BulkResultReenrolled, err
+		}
+
+		status, err := seatStatus(ctx, repo, course)
+		if err != nil {
+			return "", err
+		}
+		admit(existing, course, status)
+		if err := transitionFrom(ctx, repo, existing, previous, status, admissionAction(status, constants.EnrollmentEventEnrolled), &actorID, &note); err != nil {
+			return "", err
+		}
+		return bulkResult(status), nil
+	}
+
+	status, err := seatStatus(ctx, repo, course)
+	if err != nil {
+		return "", err
+	}
+
+	enrollment := &models.Enrollment{
+		CourseID:   course.ID,
+		UserID:     userID,
+		EnrolledAt: time.Now(),
+		DueAt:      dueAt,
+	}
+	enrollment.ApplyDefaultDueDate(course)
+	admit(enrollment, course, status)
+
+	if err := repo.Create(ctx, enrollment); err != nil {
+		return "", err
+	}
+	if err := repo.CreateEvent(ctx, &models.EnrollmentEvent{
+		EnrollmentID: enrollment.ID,
+		Action:       admissionAction(status, constants.EnrollmentEventEnrolled),
+		ToStatus:     enrollment.Status,
+		ActorID:      &actorID,
+		Note:         &note,
+	}); err != nil {
+		return "", err
+	}
+
+	return bulkResult(status), nil
+}
+
+func bulkResult(status constants.EnrollmentStatus) string {
+	if status == constants.EnrollmentWaitlist {
+		return constants.BulkResultWaitlisted
+	}
+	return constants.BulkResultCreated
+}
+
+func summarize(courseID uint, rows []models.BulkEnrollmentRow) *models.BulkEnrollmentReport {
+	report := &models.BulkEnrollmentReport{
+		CourseID: courseID,
+		Total:    len(rows),
+		Rows:     rows,
+	}
+
+	for _, row := range rows {
+		switch row.Result {
+		case constants.BulkResultCreated:
+			report.Created++
+		case constants.BulkResultReenrolled:
+			report.Reenrolled++
+		case constants.BulkResultWaitlisted:
+			report.Waitlisted++
+		case constants.BulkResultAlreadyEnrolled:
+			report.AlreadyEnrolled++
+		case constants.BulkResultUnknownNip:
+			report.UnknownNip++
+		case constants.BulkResultInactiveUser:
+			report.InactiveUser++
+		default:
+			report.Skipped++
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCalendarFeed = errors.New("calendar feed not found")
+
+// calendarTouchInterval limits how often a feed's last fetch time is
+// written; clients poll feeds often.
+const calendarTouchInterval = 15 * time.Minute
+
+// CalendarService manages personal calendar feeds and renders them as
+// iCalendar for the learner's sessions, due dates and enrollment expiries.
+type CalendarService interface {
+	GetFeed(ctx context.Context, userID uint) (*models.CalendarFeedResponse, error)
+	// CreateFeed issues a new feed URL for the user, invalidating the
+	// previous one.
+	CreateFeed(ctx context.Context, userID uint) (*models.CreatedCalendarFeedResponse, error)
+	DeleteFeed(ctx context.Context, userID uint) error
+	// Render returns the iCalendar document of the feed the token belongs to.
+	Render(ctx context.Context, token string) ([]byte, error)
+}
+
+type calendarService struct {
+	repo           repository.CalendarFeedRepository
+	userRepo       repository.UserRepository
+	enrollmentRepo repository.EnrollmentRepository
+	attendanceRepo repository.AttendanceRepository
+	tenantRepo     repository.TenantRepository
+	cfg            *config.CalendarConfig
+}
+
+func NewCalendarService(repo repository.CalendarFeedRepository, userRepo repository.UserRepository, enrollmentRepo repository.EnrollmentRepository, attendanceRepo repository.AttendanceRepository, tenantRepo repository.TenantRepository, cfg *config.CalendarConfig) CalendarService {
+	return &calendarService{
+		repo:           repo,
+		userRepo:       userRepo,
+		enrollmentRepo: enrollmentRepo,
+		attendanceRepo: attendanceRepo,
+		tenantRepo:     tenantRepo,
+		cfg:            cfg,
+	}
+}
+
+func (s *calendarService) GetFeed(ctx context.Context, userID uint) (*models.CalendarFeedResponse, error) {
+	feed, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return feed.ToResponse(), nil
+}
+
+func (s *calendarService) CreateFeed(ctx context.Context, userID uint) (*models.CreatedCalendarFeedResponse, error) {
+	token, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &models.CalendarFeed{
+		UserID:    userID,
+		TokenHash: util.HashToken(token),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Save(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedCalendarFeedResponse{
+		CalendarFeedResponse: feed.ToResponse(),
+		URL:                  strings.TrimRight(s.cfg.FeedURL, "/") + "/calendar/" + token + ".ics",
+	}, nil
+}
+
+func (s *calendarService) DeleteFeed(ctx context.Context, userID uint) error {
+	return s.repo.DeleteByUserID(ctx, userID)
+}
+
+func (s *calendarService) Render(ctx context.Context, token string) ([]byte, error) {
+	// Feed URLs are built from one configured host for every tenant, so the
+	// feed is looked up across tenants and the rest of the request runs in
+	// the tenant it belongs to.
+	feed, err := s.repo.GetByHash(tenant.System(ctx), util.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCalendarFeed
+		}
+		return nil, err
+	}
+
+	ctx, err = s.feedTenant(ctx, feed.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, feed.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidCalendarFeed
+	}
+
+	now := time.Now()
+	events, err := s.events(ctx, user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "Learning schedule"
+	if t := models.TenantFromContext(ctx); t != nil {
+		name = t.Name + " " + strings.ToLower(name)
+	}
+
+	var buf bytes.Buffer
+	if err := util.WriteICalendar(&buf, name, now, events); err != nil {
+		return nil, err
+	}
+
+	if feed.LastFetchedAt == nil || now.Sub(*feed.LastFetchedAt) > calendarTouchInterval {
+		if err := s.repo.Touch(ctx, feed.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// feedTenant scopes ctx to the tenant a feed belongs to. Feeds of inactive
+// tenants are not served.
+func (s *calendarService) feedTenant(ctx context.Context, id uint) (context.Context, error) {
+	t, err := s.tenantRepo.GetByID(tenant.System(ctx), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCalendarFeed
+		}
+		return nil, err
+	}
+	if !t.IsActive {
+		return nil, ErrInvalidCalendarFeed
+	}
+	return models.ContextWithTenant(tenant.WithID(ctx, t.ID), t), nil
+}
+
+// events lists the sessions of the courses the user can access, and the due
+// dates and access expiries of their active enrollments.
+func (s *calendarService) events(ctx context.Context, userID uint, now time.Time) ([]util.ICalEvent, error) {
+	enrollments, err := s.enrollmentRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := now.Add(-s.cfg.History)
+	courseNames := make(map[uint]string, len(enrollments))
+	var courseIDs []uint
+	var events []util.ICalEvent
+	for _, enrollment := range enrollments {
+		if !enrollment.HasAccess() {
+			continue
+		}
+		courseIDs = append(courseIDs, enrollment.CourseID)
+		courseNames[enrollment.CourseID] = enrollment.Course.Name
+
+		if !enrollment.IsActive() || enrollment.CompletedAt != nil {
+			continue
+		}
+		if enrollment.DueAt != nil && enrollment.DueAt.After(since) {
+			events = append(events, util.ICalEvent{
+				UID:         s.uid("enrollment-%d-due", enrollment.ID),
+				Sequence:    enrollment.CalendarSequence,
+				Summary:     "Due: " + enrollment.Course.Name,
+				Description: fmt.Sprintf("Complete %s by this date.", enrollment.Course.Name),
+				Start:       *enrollment.DueAt,
+				Modified:    enrollment.UpdatedAt,
+			})
+		}
+		if enrollment.ExpiresAt != nil && enrollment.ExpiresAt.After(since) {
+			events = append(events, util.ICalEvent{
+				UID:         s.uid("enrollment-%d-expires", enrollment.ID),
+				Sequence:    enrollment.ExpirySequence,
+				Summary:     "Access ends: " + enrollment.Course.Name,
+				Description: fmt.Sprintf("Your enrollment in %s expires at this time.", enrollment.Course.Name),
+				Start:       *enrollment.ExpiresAt,
+				Modified:    enrollment.UpdatedAt,
+			})
+		}
+	}
+
+	sessions, err := s.attendanceRepo.ListCalendarSessions(ctx, courseIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		event := util.ICalEvent{
+			UID:      s.uid("session-%d", session.ID),
+			Sequence: session.Sequence,
+			Summary:  courseNames[session.CourseID] + ": " + session.Title,
+			Start:    session.StartsAt,
+			End:      session.EndsAt,
+			Modified: session.UpdatedAt,
+		}
+		if session.Location != nil {
+			event.Location = *session.Location
+		}
+		if session.MeetingURL != nil {
+			event.URL = *session.MeetingURL
+			if event.Location == "" {
+				event.Location = *session.MeetingURL
+			}
+		}
+		// Deleting a session does not bump its sequence, so the
+		// cancellation is published as one more change.
+		if session.DeletedAt.Valid {
+			event.Cancelled = true
+			event.Sequence++
+			event.Modified = session.DeletedAt.Time
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// uid builds an event UID that stays the same however often the event
+// changes.
+func (s *calendarService) uid(format string, id uint) string {
+	domain := "bahrululum"
+	if u, err := url.Parse(s.cfg.FeedURL); err == nil && u.Hostname() != "" {
+		domain = u.Hostname()
+	}
+	return fmt.Sprintf(format, id) + "@" + domain
+}
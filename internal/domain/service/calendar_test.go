@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"gorm.io/gorm"
+)
+
+// The calendar fakes answer only within the context's tenant, or across
+// tenants in a system context, as the tenant plugin scopes queries.
+
+func inTenant(ctx context.Context, id uint) bool {
+	scoped, ok := tenant.FromContext(ctx)
+	return !ok || scoped == id
+}
+
+type calendarFeeds struct {
+	repository.CalendarFeedRepository
+	feeds []*models.CalendarFeed
+}
+
+func (r *calendarFeeds) GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	for _, feed := range r.feeds {
+		if feed.TokenHash == tokenHash && inTenant(ctx, feed.TenantID) {
+			return feed, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *calendarFeeds) Touch(ctx context.Context, id uint) error {
+	return nil
+}
+
+type calendarUsers struct {
+	repository.UserRepository
+	users []*models.User
+}
+
+func (r *calendarUsers) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id && inTenant(ctx, user.TenantID) {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type calendarEnrollments struct {
+	repository.EnrollmentRepository
+	enrollments []*models.Enrollment
+}
+
+func (r *calendarEnrollments) GetByUserID(ctx context.Context, userID uint) ([]*models.Enrollment, error) {
+	var enrollments []*models.Enrollment
+	for _, enrollment := range r.enrollments {
+		if enrollment.UserID == userID && inTenant(ctx, enrollment.TenantID) {
+			enrollments = append(enrollments, enrollment)
+		}
+	}
+	return enrollments, nil
+}
+
+type noSessions struct {
+	repository.AttendanceRepository
+}
+
+func (noSessions) ListCalendarSessions(ctx context.Context, courseIDs []uint, since time.Time) ([]*models.ChapterSession, error) {
+	return nil, nil
+}
+
+type calendarTenants struct {
+	repository.TenantRepository
+	tenants []*models.Tenant
+}
+
+func (r *calendarTenants) GetByID(ctx context.Context, id uint) (*models.Tenant, error) {
+	for _, t := range r.tenants {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestRenderServesFeedsOfEveryTenant(t *testing.T) {
+	expires := time.Now().Add(30 * 24 * time.Hour)
+	enrollment := &models.Enrollment{
+		TenantID:   2,
+		UserID:     7,
+		CourseID:   3,
+		Status:     constants.EnrollmentActive.String(),
+		EnrolledAt: time.Now(),
+		ExpiresAt:  &expires,
+		Course:     models.Course{ID: 3, TenantID: 2, Name: "Tahsin"},
+	}
+	enrollment.ID = 5
+	later := expires.Add(7 * 24 * time.Hour)
+	enrollment.SetExpiresAt(&later)
+
+	tenants := &calendarTenants{tenants: []*models.Tenant{
+		{ID: 1, Name: "Default", IsActive: true},
+		{ID: 2, Name: "Pesantren", IsActive: true},
+	}}
+	calendar := NewCalendarService(
+		&calendarFeeds{feeds: []*models.CalendarFeed{{ID: 1, TenantID: 2, UserID: 7, TokenHash: util.HashToken("feed-token")}}},
+		&calendarUsers{users: []*models.User{{ID: 7, TenantID: 2, IsActive: true}}},
+		&calendarEnrollments{enrollments: []*models.Enrollment{enrollment}},
+		noSessions{},
+		tenants,
+		&config.CalendarConfig{FeedURL: "https://lms.example.com", History: 24 * time.Hour},
+	)
+
+	// The feed host resolves to the default tenant.
+	ctx := models.ContextWithTenant(tenant.WithID(context.Background(), 1), tenants.tenants[0])
+	body, err := calendar.Render(ctx, "feed-token")
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	ics := string(body)
+	if !strings.Contains(ics, "X-WR-CALNAME:Pesantren learning schedule") {
+		t.Fatalf("feed not rendered in its own tenant:\n%s", ics)
+	}
+	if !strings.Contains(ics, "UID:enrollment-5-expires@lms.example.com\r\nSEQUENCE:1\r\n") {
+		t.Fatalf("rescheduled expiry not sequenced:\n%s", ics)
+	}
+
+	tenants.tenants[1].IsActive = false
+	if _, err := calendar.Render(ctx, "feed-token"); !errors.Is(err, ErrInvalidCalendarFeed) {
+		t.Fatalf("feed of an inactive tenant = %v, want %v", err, ErrInvalidCalendarFeed)
+	}
+}
@@ -0,0 +1,685 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCohortForbidden     = errors.New("you are not a mentor of this cohort")
+	ErrNotCohortMember     = errors.New("you are not a member of this cohort")
+	ErrCohortMemberTaken   = errors.New("user is already in a cohort of this course")
+	ErrCohortMentorRole    = errors.New("cohort mentors must be mentors or administrators")
+	ErrCohortPostForbidden = errors.New("only the author or a mentor can delete this post")
+)
+
+// cohortRole is what the acting user may do in a cohort. Staff are the
+// cohort's mentors and global administrators.
+type cohortRole int
+
+const (
+	cohortOutsider cohortRole = iota
+	cohortParticipant
+	cohortStaff
+)
+
+// CohortService manages learner groups within a course. Methods taking an
+// acting user check that the user is a member of the cohort, or for managing
+// it, one of its mentors or an administrator.
+type CohortService interface {
+	CreateCohort(ctx context.Context, courseID uint, req *models.CreateCohortRequest) (*models.CohortResponse, error)
+	GetCourseCohorts(ctx context.Context, courseID uint) ([]*models.CohortResponse, error)
+	// GetMyCohorts returns the cohorts the user studies in or mentors.
+	GetMyCohorts(ctx context.Context, userID uint) ([]*models.CohortResponse, error)
+	GetCohort(ctx context.Context, actorID, id uint) (*models.CohortResponse, error)
+	UpdateCohort(ctx context.Context, actorID, id uint, req *models.UpdateCohortRequest) (*models.CohortResponse, error)
+	DeleteCohort(ctx context.Context, actorID, id uint) error
+	// SetDeadline sets the cohort's deadline and moves the due date of each
+	// member's enrollment to it. Removing the deadline clears the due dates
+	// copied from it.
+	SetDeadline(ctx context.Context, actorID, id uint, req *models.UpdateDueDateRequest) (*models.CohortResponse, error)
+	AddMentor(ctx context.Context, id, userID uint) error
+	RemoveMentor(ctx context.Context, id, userID uint) error
+
+	GetMembers(ctx context.Context, actorID, id uint) ([]*models.UserResponse, error)
+	// AddMember puts an enrolled learner in the cohort and applies the
+	// cohort's deadline to their enrollment.
+	AddMember(ctx context.Context, actorID, id, userID uint) error
+	RemoveMember(ctx context.Context, actorID, id, userID uint) error
+	GetDashboard(ctx context.Context, actorID, id uint) (*models.CohortDashboard, error)
+
+	// PostAnnouncement publishes an announcement and notifies the members.
+	PostAnnouncement(ctx context.Context, actorID, id uint, req *models.CreateCohortAnnouncementRequest) (*models.CohortAnnouncementResponse, error)
+	GetAnnouncements(ctx context.Context, actorID, id uint, offset, limit int) ([]*models.CohortAnnouncementResponse, int64, error)
+	GetThreads(ctx context.Context, actorID, id uint, offset, limit int) ([]*models.CohortPostResponse, int64, error)
+	CreateThread(ctx context.Context, actorID, id uint, req *models.CreateCohortThreadRequest) (*models.CohortPostResponse, error)
+	// GetThread returns a thread with its replies.
+	GetThread(ctx context.Context, actorID, id, threadID uint) (*models.CohortPostResponse, error)
+	Reply(ctx context.Context, actorID, id, threadID uint, req *models.CreateCohortReplyRequest) (*models.CohortPostResponse, error)
+	// DeletePost removes a reply, or a thread with its replies.
+	DeletePost(ctx context.Context, actorID, id, postID uint) error
+}
+
+type cohortService struct {
+	repo           repository.CohortRepository
+	userRepo       repository.UserRepository
+	courseRepo     repository.CourseRepository
+	enrollmentRepo repository.EnrollmentRepository
+	notifications  NotificationService
+}
+
+func NewCohortService(repo repository.CohortRepository, userRepo repository.UserRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, notifications NotificationService) CohortService {
+	return &cohortService{
+		repo:           repo,
+		userRepo:       userRepo,
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+		notifications:  notifications,
+	}
+}
+
+func (s *cohortService) CreateCohort(ctx context.Context, courseID uint, req *models.CreateCohortRequest) (*models.CohortResponse, error) {
+	calendar := models.CalendarFromContext(ctx)
+	if err := req.ResolveHijri(calendar); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.courseRepo.GetByID(ctx, courseID); err != nil {
+		return nil, err
+	}
+
+	cohort := &models.Cohort{
+		CourseID:    courseID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		StartsAt:    req.StartsAt,
+		DueAt:       req.DueAt,
+	}
+	if err := s.repo.Create(ctx, cohort); err != nil {
+		return nil, err
+	}
+
+	return cohort.ToResponse().SetHijri(calendar), nil
+}
+
+func (s *cohortService) GetCourseCohorts(ctx context.Context, courseID uint) ([]*models.CohortResponse, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID); err != nil {
+		return nil, err
+	}
+
+	cohorts, err := s.repo.ListByCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return s.responses(ctx, cohorts)
+}
+
+func (s *cohortService) GetMyCohorts(ctx context.Context, userID uint) ([]*models.CohortResponse, error) {
+	cohorts, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.responses(ctx, cohorts)
+}
+
+func (s *cohortService) GetCohort(ctx context.Context, actorID, id uint) (*models.CohortResponse, error) {
+	cohort, err := s.authorize(ctx, actorID, id, cohortParticipant)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.responses(ctx, []*models.Cohort{cohort})
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+func (s *cohortService) UpdateCohort(ctx context.Context, actorID, id uint, req *models.UpdateCohortRequest) (*models.CohortResponse, error) {
+	if err := req.ResolveHijri(models.CalendarFromContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	cohort, err := s.authorize(ctx, actorID, id, cohortStaff)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		cohort.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		cohort.Description = optionalString(*req.Description)
+	}
+	if req.StartsAt != nil {
+		cohort.StartsAt = req.StartsAt
+	}
+
+	if err := s.repo.Update(ctx, cohort); err != nil {
+		return nil, err
+	}
+
+	return s.GetCohort(ctx, actorID, id)
+}
+
+func (s *cohortService) DeleteCohort(ctx context.Context, actorID, id uint) error {
+	if _, err := s.authorize(ctx, actorID, id, cohortStaff); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *cohortService) SetDeadline(ctx context.Context, actorID, id uint, req *models.UpdateDueDateRequest) (*models.CohortResponse, error) {
+	calendar := models.CalendarFromContext(ctx)
+	if err := req.ResolveHijri(calendar); err != nil {
+		return nil, err
+	}
+
+	cohort, err := s.authorize(ctx, actorID, id, cohortStaff)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := cohort.DueAt
+	cohort.DueAt = req.DueAt
+
+	// The members are notified only once every enrollment has the deadline.
+	var members []*models.CohortMember
+	err = s.repo.Transaction(ctx, func(repo repository.CohortRepository, enrollmentRepo repository.EnrollmentRepository) error {
+		if err := repo.Update(ctx, cohort); err != nil {
+			return err
+		}
+
+		var err error
+		members, err = repo.ListMembers(ctx, cohort.ID)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			if err := applyDeadline(ctx, enrollmentRepo, cohort, member.UserID, previous); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cohort.DueAt == nil {
+		return s.GetCohort(ctx, actorID, id)
+	}
+	due := cohort.DueAt.In(calendar.Location).Format("2006-01-02 15:04 MST")
+	if hijri := calendar.HijriOf(cohort.DueAt); hijri != nil {
+		due += " (" + hijri.Formatted + ")"
+	}
+	for _, member := range members {
+		s.notify(ctx, member.UserID, constants.NotificationCohortDeadline,
+			fmt.Sprintf("New deadline for %s", cohort.Name),
+			fmt.Sprintf("Your cohort %s is due to complete the course by %s.", cohort.Name, due))
+	}
+
+	return s.GetCohort(ctx, actorID, id)
+}
+
+func (s *cohortService) AddMentor(ctx context.Context, id, userID uint) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsMentor() && !user.IsAdmin() {
+		return ErrCohortMentorRole
+	}
+
+	return s.repo.AddMentor(ctx, &models.CohortMentor{CohortID: id, UserID: userID})
+}
+
+func (s *cohortService) RemoveMentor(ctx context.Context, id, userID uint) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.RemoveMentor(ctx, id, userID)
+}
+
+func (s *cohortService) GetMembers(ctx context.Context, actorID, id uint) ([]*models.UserResponse, error) {
+	if _, err := s.authorize(ctx, actorID, id, cohortStaff); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.UserResponse, len(members))
+	for i, member := range members {
+		responses[i] = member.User.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *cohortService) AddMember(ctx context.Context, actorID, id, userID uint) error {
+	cohort, err := s.authorize(ctx, actorID, id, cohortStaff)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := s.enrollmentRepo.GetByUserAndCourse(ctx, userID, cohort.CourseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotEnrolled
+		}
+		return err
+	}
+	if !enrollment.HasAccess() {
+		return ErrEnrollmentNotActive
+	}
+
+	membership, err := s.repo.GetMembership(ctx, cohort.CourseID, userID)
+	if err == nil {
+		if membership.CohortID == cohort.ID {
+			return nil
+		}
+		return ErrCohortMemberTaken
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err := s.repo.AddMember(ctx, &models.CohortMember{
+		CohortID: cohort.ID,
+		UserID:   userID,
+		CourseID: cohort.CourseID,
+	}); err != nil {
+		return err
+	}
+
+	return s.enrollmentRepo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		return applyDeadline(ctx, repo, cohort, userID, nil)
+	})
+}
+
+func (s *cohortService) RemoveMember(ctx context.Context, actorID, id, userID uint) error {
+	if _, err := s.authorize(ctx, actorID, id, cohortStaff); err != nil {
+		return err
+	}
+	return s.repo.RemoveMember(ctx, id, userID)
+}
+
+func (s *cohortService) GetDashboard(ctx context.Context, actorID, id uint) (*models.CohortDashboard, error) {
+	cohort, err := s.authorize(ctx, actorID, id, cohortStaff)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+	enrollments, err := s.enrollmentRepo.ListByUsersAndCourses(ctx, userIDs, []uint{cohort.CourseID})
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[uint]*models.Enrollment, len(enrollments))
+	for _, enrollment := range enrollments {
+		byUser[enrollment.UserID] = enrollment
+	}
+
+	dashboard := &models.CohortDashboard{
+		CohortID: cohort.ID,
+		CourseID: cohort.CourseID,
+		DueAt:    cohort.DueAt,
+		Members:  len(members),
+		Users:    make([]*models.CohortMemberProgress, len(members)),
+	}
+	totalProgress := 0
+	for i, member := range members {
+		progress := &models.CohortMemberProgress{
+			User:   member.User.ToResponse(),
+			Status: mandatoryCourseNotEnrolled,
+		}
+		if enrollment, ok := byUser[member.UserID]; ok {
+			progress.Status = enrollment.CurrentStatus().String()
+			progress.ProgressPercent = enrollment.ProgressPercent
+			progress.DueAt = enrollment.DueAt
+			progress.CompletedAt = enrollment.CompletedAt
+			progress.Overdue = enrollment.IsOverdue()
+		}
+
+		if progress.CompletedAt != nil {
+			dashboard.Completed++
+		}
+		if progress.Overdue {
+			dashboard.Overdue++
+		}
+		totalProgress += progress.ProgressPercent
+		dashboard.Users[i] = progress
+	}
+	if len(members) > 0 {
+		dashboard.AverageProgress = totalProgress / len(members)
+	}
+
+	return dashboard.SetHijri(models.CalendarFromContext(ctx)), nil
+}
+
+func (s *cohortService) PostAnnouncement(ctx context.Context, actorID, id uint, req *models.CreateCohortAnnouncementRequest) (*models.CohortAnnouncementResponse, error) {
+	cohort, err := s.authorize(ctx, actorID, id, cohortStaff)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	announcement := &models.CohortAnnouncement{
+		CohortID: cohort.ID,
+		AuthorID: actorID,
+		Title:    strings.TrimSpace(req.Title),
+		Body:     req.Body,
+	}
+	if err := s.repo.CreateAnnouncement(ctx, announcement); err != nil {
+		return nil, err
+	}
+	announcement.Author = *author
+
+	members, err := s.repo.ListMembers(ctx, cohort.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		s.notify(ctx, member.UserID, constants.NotificationCohortAnnouncement,
+			fmt.Sprintf("%s: %s", cohort.Name, announcement.Title), announcement.Body)
+	}
+
+	return announcement.ToResponse(), nil
+}
+
+func (s *cohortService) GetAnnouncements(ctx context.Context, actorID, id uint, offset, limit int) ([]*models.CohortAnnouncementResponse, int64, error) {
+	if _, err := s.authorize(ctx, actorID, id, cohortParticipant); err != nil {
+		return nil, 0, err
+	}
+
+	announcements, total, err := s.repo.ListAnnouncements(ctx, id, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*models.CohortAnnouncementResponse, len(announcements))
+	for i, announcement := range announcements {
+		responses[i] = announcement.ToResponse()
+	}
+
+	return responses, total, nil
+}
+
+func (s *cohortService) GetThreads(ctx context.Context, actorID, id uint, offset, limit int) ([]*models.CohortPostResponse, int64, error) {
+	if _, err := s.authorize(ctx, actorID, id, cohortParticipant); err != nil {
+		return nil, 0, err
+	}
+
+	threads, total, err := s.repo.ListThreads(ctx, id, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	threadIDs := make([]uint, len(threads))
+	for i, thread := range threads {
+		threadIDs[i] = thread.ID
+	}
+	replies, err := s.repo.CountReplies(ctx, threadIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*models.CohortPostResponse, len(threads))
+	for i, thread := range threads {
+		responses[i] = thread.ToResponse()
+		responses[i].Replies = replies[thread.ID]
+	}
+
+	return responses, total, nil
+}
+
+func (s *cohortService) CreateThread(ctx context.Context, actorID, id uint, req *models.CreateCohortThreadRequest) (*models.CohortPostResponse, error) {
+	if _, err := s.authorize(ctx, actorID, id, cohortParticipant); err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSpace(req.Title)
+	return s.createPost(ctx, &models.CohortPost{
+		CohortID: id,
+		AuthorID: actorID,
+		Title:    &title,
+		Body:     req.Body,
+	})
+}
+
+func (s *cohortService) GetThread(ctx context.Context, actorID, id, threadID uint) (*models.CohortPostResponse, error) {
+	if _, err := s.authorize(ctx, actorID, id, cohortParticipant); err != nil {
+		return nil, err
+	}
+
+	thread, err := s.thread(ctx, id, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := s.repo.ListReplies(ctx, thread.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := thread.ToResponse()
+	response.Replies = int64(len(replies))
+	response.Thread = make([]*models.CohortPostResponse, len(replies))
+	for i, reply := range replies {
+		response.Thread[i] = reply.ToResponse()
+	}
+
+	return response, nil
+}
+
+func (s *cohortService) Reply(ctx context.Context, actorID, id, threadID uint, req *models.CreateCohortReplyRequest) (*models.CohortPostResponse, error) {
+	if _, err := s.authorize(ctx, actorID, id, cohortParticipant); err != nil {
+		return nil, err
+	}
+
+	thread, err := s.thread(ctx, id, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createPost(ctx, &models.CohortPost{
+		CohortID: id,
+		ParentID: &thread.ID,
+		AuthorID: actorID,
+		Body:     req.Body,
+	})
+}
+
+func (s *cohortService) DeletePost(ctx context.Context, actorID, id, postID uint) error {
+	cohort, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	role, err := s.role(ctx, actorID, cohort)
+	if err != nil {
+		return err
+	}
+	if role == cohortOutsider {
+		return ErrNotCohortMember
+	}
+
+	post, err := s.repo.GetPost(ctx, id, postID)
+	if err != nil {
+		return err
+	}
+	if post.AuthorID != actorID && role != cohortStaff {
+		return ErrCohortPostForbidden
+	}
+
+	return s.repo.DeletePost(ctx, post.ID)
+}
+
+// authorize loads the cohort and checks that the acting user has at least
+// the given role in it.
+func (s *cohortService) authorize(ctx context.Context, actorID, id uint, required cohortRole) (*models.Cohort, error) {
+	cohort, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.role(ctx, actorID, cohort)
+	if err != nil {
+		return nil, err
+	}
+	if role < required {
+		if required == cohortStaff {
+			return nil, ErrCohortForbidden
+		}
+		return nil, ErrNotCohortMember
+	}
+
+	return cohort, nil
+}
+
+func (s *cohortService) role(ctx context.Context, actorID uint, cohort *models.Cohort) (cohortRole, error) {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return cohortOutsider, err
+	}
+	if actor.IsAdmin() {
+		return cohortStaff, nil
+	}
+
+	mentor, err := s.repo.IsMentor(ctx, cohort.ID, actorID)
+	if err != nil {
+		return cohortOutsider, err
+	}
+	if mentor {
+		return cohortStaff, nil
+	}
+
+	member, err := s.repo.IsMember(ctx, cohort.ID, actorID)
+	if err != nil {
+		return cohortOutsider, err
+	}
+	if member {
+		return cohortParticipant, nil
+	}
+
+	return cohortOutsider, nil
+}
+
+// thread returns a top-level post of the cohort.
+func (s *cohortService) thread(ctx context.Context, cohortID, threadID uint) (*models.CohortPost, error) {
+	thread, err := s.repo.GetPost(ctx, cohortID, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.ParentID != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return thread, nil
+}
+
+func (s *cohortService) createPost(ctx context.Context, post *models.CohortPost) (*models.CohortPostResponse, error) {
+	author, err := s.userRepo.GetByID(ctx, post.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreatePost(ctx, post); err != nil {
+		return nil, err
+	}
+	post.Author = *author
+
+	return post.ToResponse(), nil
+}
+
+// applyDeadline copies the cohort's deadline to the member's enrollment in
+// repo's transaction. Without a cohort deadline the enrollment keeps its own
+// due date, except one copied from the previous cohort deadline, which is
+// cleared.
+func applyDeadline(ctx context.Context, repo repository.EnrollmentRepository, cohort *models.Cohort, userID uint, previous *time.Time) error {
+	if cohort.DueAt == nil && previous == nil {
+		return nil
+	}
+
+	enrollment, err := repo.GetByUserAndCourse(ctx, userID, cohort.CourseID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if cohort.DueAt == nil {
+		if enrollment.DueAt == nil || !enrollment.DueAt.Equal(*previous) {
+			return nil
+		}
+		return updateDueAt(ctx, repo, enrollment, nil)
+	}
+	due := *cohort.DueAt
+	return updateDueAt(ctx, repo, enrollment, &due)
+}
+
+func (s *cohortService) notify(ctx context.Context, userID uint, notificationType, title, body string) {
+	if err := s.notifications.Notify(ctx, userID, notificationType, title, body); err != nil {
+		log.Printf("cohort notification for user %d: %v", userID, err)
+	}
+}
+
+// responses adds member counts and mentors to the cohorts.
+func (s *cohortService) responses(ctx context.Context, cohorts []*models.Cohort) ([]*models.CohortResponse, error) {
+	ids := make([]uint, len(cohorts))
+	for i, cohort := range cohorts {
+		ids[i] = cohort.ID
+	}
+
+	counts, err := s.repo.CountMembers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	mentors, err := s.repo.ListMentors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byCohort := make(map[uint][]*models.CohortPerson, len(cohorts))
+	for _, mentor := range mentors {
+		byCohort[mentor.CohortID] = append(byCohort[mentor.CohortID], mentor.User.ToCohortPerson())
+	}
+
+	calendar := models.CalendarFromContext(ctx)
+	responses := make([]*models.CohortResponse, len(cohorts))
+	for i, cohort := range cohorts {
+		responses[i] = cohort.ToResponse().SetHijri(calendar)
+		responses[i].Members = counts[cohort.ID]
+		if people, ok := byCohort[cohort.ID]; ok {
+			responses[i].Mentors = people
+		}
+	}
+
+	return responses, nil
+}
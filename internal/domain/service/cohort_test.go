@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+)
+
+type memoryCohorts struct {
+	repository.CohortRepository
+	cohort      *models.Cohort
+	members     []*models.CohortMember
+	enrollments repository.EnrollmentRepository
+}
+
+func (r *memoryCohorts) GetByID(ctx context.Context, id uint) (*models.Cohort, error) {
+	copied := *r.cohort
+	return &copied, nil
+}
+
+func (r *memoryCohorts) Update(ctx context.Context, cohort *models.Cohort) error {
+	r.cohort = cohort
+	return nil
+}
+
+func (r *memoryCohorts) ListMembers(ctx context.Context, cohortID uint) ([]*models.CohortMember, error) {
+	return r.members, nil
+}
+
+func (r *memoryCohorts) CountMembers(ctx context.Context, cohortIDs []uint) (map[uint]int64, error) {
+	return map[uint]int64{r.cohort.ID: int64(len(r.members))}, nil
+}
+
+func (r *memoryCohorts) ListMentors(ctx context.Context, cohortIDs []uint) ([]*models.CohortMentor, error) {
+	return nil, nil
+}
+
+func (r *memoryCohorts) Transaction(ctx context.Context, fn func(repo repository.CohortRepository, enrollmentRepo repository.EnrollmentRepository) error) error {
+	return fn(r, r.enrollments)
+}
+
+// brokenEnrollments fails to load the enrollment of one user.
+type brokenEnrollments struct {
+	*memoryEnrollments
+	userID uint
+}
+
+func (r *brokenEnrollments) GetByUserAndCourse(ctx context.Context, userID, courseID uint) (*models.Enrollment, error) {
+	if userID == r.userID {
+		return nil, errors.New("connection reset")
+	}
+	return r.memoryEnrollments.GetByUserAndCourse(ctx, userID, courseID)
+}
+
+type sentNotifications struct {
+	NotificationService
+	to []uint
+}
+
+func (n *sentNotifications) Notify(ctx context.Context, userID uint, notificationType, title, body string) error {
+	n.to = append(n.to, userID)
+	return nil
+}
+
+func TestSetDeadline(t *testing.T) {
+	deadline := time.Date(2026, time.December, 1, 17, 0, 0, 0, time.UTC)
+	own := time.Date(2026, time.November, 1, 17, 0, 0, 0, time.UTC)
+	admin := &calendarUsers{users: []*models.User{{ID: 50, Role: constants.RoleAdmin.String()}}}
+
+	setup := func() (*memoryCohorts, *memoryEnrollments, *sentNotifications, CohortService) {
+		enrollments := &memoryEnrollments{}
+		cohorts := &memoryCohorts{cohort: &models.Cohort{ID: 4, CourseID: 1, Name: "Angkatan 1"}, enrollments: enrollments}
+		for _, userID := range []uint{1, 2} {
+			enrollments.enrollments = append(enrollments.enrollments, learner(userID, constants.EnrollmentActive))
+			cohorts.members = append(cohorts.members, &models.CohortMember{CohortID: 4, CourseID: 1, UserID: userID})
+		}
+		notifications := &sentNotifications{}
+		return cohorts, enrollments, notifications, NewCohortService(cohorts, admin, nil, enrollments, notifications)
+	}
+
+	t.Run("copies the deadline and notifies after saving", func(t *testing.T) {
+		cohorts, enrollments, notifications, svc := setup()
+		if _, err := svc.SetDeadline(context.Background(), 50, 4, &models.UpdateDueDateRequest{DueAt: &deadline}); err != nil {
+			t.Fatal(err)
+		}
+		for _, enrollment := range enrollments.enrollments {
+			if enrollment.DueAt == nil || !enrollment.DueAt.Equal(deadline) {
+				t.Fatalf("member %d due at %v, want %v", enrollment.UserID, enrollment.DueAt, deadline)
+			}
+		}
+		if len(notifications.to) != 2 || cohorts.cohort.DueAt == nil {
+			t.Fatalf("notified %v, cohort due at %v", notifications.to, cohorts.cohort.DueAt)
+		}
+	})
+
+	t.Run("notifies nobody when a member cannot be updated", func(t *testing.T) {
+		cohorts, enrollments, notifications, _ := setup()
+		cohorts.enrollments = &brokenEnrollments{memoryEnrollments: enrollments, userID: 2}
+		svc := NewCohortService(cohorts, admin, nil, enrollments, notifications)
+		if _, err := svc.SetDeadline(context.Background(), 50, 4, &models.UpdateDueDateRequest{DueAt: &deadline}); err == nil {
+			t.Fatal("set deadline succeeded although a member could not be updated")
+		}
+		if len(notifications.to) != 0 {
+			t.Fatalf("notified %v of a deadline that was rolled back", notifications.to)
+		}
+	})
+
+	t.Run("removing the deadline clears the copied due dates", func(t *testing.T) {
+		cohorts, enrollments, _, svc := setup()
+		cohorts.cohort.DueAt = &deadline
+		copied := deadline
+		enrollments.enrollments[0].DueAt = &copied
+		enrollments.enrollments[1].DueAt = &own
+
+		if _, err := svc.SetDeadline(context.Background(), 50, 4, &models.UpdateDueDateRequest{}); err != nil {
+			t.Fatal(err)
+		}
+		if enrollments.enrollments[0].DueAt != nil {
+			t.Fatalf("copied due date kept: %v", enrollments.enrollments[0].DueAt)
+		}
+		if enrollments.enrollments[1].DueAt == nil || !enrollments.enrollments[1].DueAt.Equal(own) {
+			t.Fatalf("member's own due date changed to %v", enrollments.enrollments[1].DueAt)
+		}
+	})
+}
@@ -2,17 +2,25 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"github.com/bobchopperz/bahrululum/internal/domain/repository"
 )
 
+var (
+	ErrInvalidPrerequisite = errors.New("prerequisite course does not exist")
+	ErrPrerequisiteCycle   = errors.New("prerequisites would create a cycle")
+)
+
 type CourseService interface {
 	CreateCourse(ctx context.Context, req *models.CreateCourseRequest) (*models.CourseResponse, error)
 	GetCourse(ctx context.Context, id uint) (*models.CourseResponse, error)
 	GetCourses(ctx context.Context, offset, limit int) ([]*models.CourseResponse, error)
 	UpdateCourse(ctx context.Context, id uint, updates map[string]interface{}) (*models.CourseResponse, error)
 	DeleteCourse(ctx context.Context, id uint) error
+	GetPrerequisites(ctx context.Context, id uint) ([]*models.CourseResponse, error)
+	SetPrerequisites(ctx context.Context, id uint, req *models.SetPrerequisitesRequest) ([]*models.CourseResponse, error)
 }
 
 type courseService struct {
@@ -86,3 +94,87 @@ func (s *courseService) DeleteCourse(ctx context.Context, id uint) error {
 	}
 	return nil
 }
+
+func (s *courseService) GetPrerequisites(ctx context.Context, id uint) ([]*models.CourseResponse, error) {
+	courses, err := s.repo.GetPrerequisites(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.CourseResponse, len(courses))
+	for i, course := range courses {
+		responses[i] = course.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *courseService) SetPrerequisites(ctx context.Context, id uint, req *models.SetPrerequisitesRequest) ([]*models.CourseResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	ids := uniqueIDs(req.PrerequisiteIDs)
+
+	courses, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(courses) != len(ids) {
+		return nil, ErrInvalidPrerequisite
+	}
+
+	for _, prerequisiteID := range ids {
+		cyclic, err := s.dependsOn(ctx, prerequisiteID, id, map[uint]bool{})
+		if err != nil {
+			return nil, err
+		}
+		if cyclic {
+			return nil, ErrPrerequisiteCycle
+		}
+	}
+
+	if err := s.repo.SetPrerequisites(ctx, id, ids); err != nil {
+		return nil, err
+	}
+
+	return s.GetPrerequisites(ctx, id)
+}
+
+// dependsOn reports whether courseID requires target, directly or through
+// other prerequisites.
+func (s *courseService) dependsOn(ctx context.Context, courseID, target uint, visited map[uint]bool) (bool, error) {
+	if courseID == target {
+		return true, nil
+	}
+	if visited[courseID] {
+		return false, nil
+	}
+	visited[courseID] = true
+
+	ids, err := s.repo.GetPrerequisiteIDs(ctx, courseID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range ids {
+		found, err := s.dependsOn(ctx, id, target, visited)
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"github.com/bobchopperz/bahrululum/internal/constants"
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"gorm.io/gorm"
 )
 
 type CourseContentService interface {
@@ -16,11 +19,12 @@ type CourseContentService interface {
 }
 
 type courseContentService struct {
-	repo repository.CourseContentRepository
+	repo        repository.CourseContentRepository
+	hafalanRepo repository.HafalanRepository
 }
 
-func NewCourseContentService(repo repository.CourseContentRepository) CourseContentService {
-	return &courseContentService{repo: repo}
+func NewCourseContentService(repo repository.CourseContentRepository, hafalanRepo repository.HafalanRepository) CourseContentService {
+	return &courseContentService{repo: repo, hafalanRepo: hafalanRepo}
 }
 
 func (s *courseContentService) CreateContent(ctx context.Context, req *models.CreateCourseContentRequest) (*models.CourseContentResponse, error) {
@@ -40,6 +44,9 @@ func (s *courseContentService) CreateContent(ctx context.Context, req *models.Cr
 		content.ContentOrder = 1
 	}
 
+	if err := s.checkPublishable(ctx, content); err != nil {
+		return nil, err
+	}
 	if err := s.repo.Create(ctx, content); err != nil {
 		return nil, err
 	}
@@ -101,6 +108,9 @@ func (s *courseContentService) UpdateContent(ctx context.Context, id uint, req *
 		content.DurationMinutes = req.DurationMinutes
 	}
 
+	if err := s.checkPublishable(ctx, content); err != nil {
+		return nil, err
+	}
 	if err := s.repo.Update(ctx, content); err != nil {
 		return nil, err
 	}
@@ -115,6 +125,25 @@ func (s *courseContentService) DeleteContent(ctx context.Context, id uint) error
 	return nil
 }
 
+// checkPublishable keeps hafalan contents without a target unpublished, as
+// learners could never complete them.
+func (s *courseContentService) checkPublishable(ctx context.Context, content *models.CourseContent) error {
+	if !content.IsPublished || content.ContentType != constants.ContentTypeHafalan {
+		return nil
+	}
+	if content.ID == 0 {
+		return ErrHafalanTargetRequired
+	}
+
+	if _, err := s.hafalanRepo.GetTarget(ctx, content.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrHafalanTargetRequired
+		}
+		return err
+	}
+	return nil
+}
+
 func (s *courseContentService) GetContentsByType(ctx context.Context, contentType string) ([]models.CourseContentResponse, error) {
 	contents, err := s.repo.GetByContentType(ctx, contentType)
 	if err != nil {
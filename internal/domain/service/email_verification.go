@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/mailer"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailNotVerified         = errors.New("email address has not been verified")
+	ErrTooManyVerifyRequest     = errors.New("too many verification emails requested, try again later")
+)
+
+type EmailVerificationService interface {
+	Send(ctx context.Context, user *models.User, email string) error
+	SendToUser(ctx context.Context, userID uint) error
+	Resend(ctx context.Context, email string) error
+	Verify(ctx context.Context, token string) (*models.User, error)
+}
+
+type emailVerificationService struct {
+	repo     repository.EmailVerificationRepository
+	userRepo repository.UserRepository
+	mailer   mailer.Mailer
+	cfg      *config.EmailVerificationConfig
+	limiter  *util.RateLimiter
+}
+
+func NewEmailVerificationService(repo repository.EmailVerificationRepository, userRepo repository.UserRepository, mail mailer.Mailer, cfg *config.EmailVerificationConfig) EmailVerificationService {
+	return &emailVerificationService{
+		repo:     repo,
+		userRepo: userRepo,
+		mailer:   mail,
+		cfg:      cfg,
+		limiter:  util.NewRateLimiter(cfg.ResendMax, cfg.ResendWindow),
+	}
+}
+
+// SendToUser sends a verification link to the user's current address unless
+// it is already verified.
+func (s *emailVerificationService) SendToUser(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	return s.Send(ctx, user, user.Email)
+}
+
+// Resend sends a new verification link to an unverified account. Like the
+// password reset request it reports success for unknown addresses and is
+// throttled per address.
+func (s *emailVerificationService) Resend(ctx context.Context, email string) error {
+	email = strings.TrimSpace(email)
+	if !s.limiter.Allow(strings.ToLower(email)) {
+		return ErrTooManyVerifyRequest
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if !user.IsActive || user.IsEmailVerified() {
+		return nil
+	}
+
+	return s.Send(ctx, user, user.Email)
+}
+
+// Send emails a verification link for the given address. Older links for the
+// user stop working so only the latest requested address can be confirmed.
+func (s *emailVerificationService) Send(ctx context.Context, user *models.User, email string) error {
+	if err := s.repo.InvalidateByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := util.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, &models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: util.HashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.TokenExpiry),
+	}); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm this email address by opening the link below within %s:\n\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			user.Name, s.cfg.TokenExpiry, tokenLink(tenantURL(ctx, s.cfg.URL, func(t models.TenantSettings) string { return t.EmailVerificationURL }), token)),
+	})
+}
+
+// Verify consumes a verification token and marks the address verified. When
+// the token was issued for a pending email change the new address replaces
+// the current one.
+func (s *emailVerificationService) Verify(ctx context.Context, token string) (*models.User, error) {
+	stored, err := s.repo.GetByHash(ctx, util.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	if stored.Email != user.Email {
+		if user.PendingEmail == nil || *user.PendingEmail != stored.Email {
+			return nil, ErrInvalidVerificationToken
+		}
+
+		existing, err := s.userRepo.GetByEmail(ctx, stored.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if existing != nil && existing.ID != user.ID {
+			return nil, ErrEmailTaken
+		}
+	}
+
+	consumed, err := s.repo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	now := time.Now()
+	if stored.Email != user.Email {
+		user.Email = stored.Email
+		user.PendingEmail = nil
+	}
+	user.EmailVerifiedAt = &now
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
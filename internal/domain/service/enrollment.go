@@ -3,47 +3,152 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/bobchopperz/bahrululum/internal/constants"
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"github.com/bobchopperz/bahrululum/internal/domain/repository"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrNotEnrolled          = errors.New("user is not enrolled in this course")
+	ErrEnrollmentNotActive  = errors.New("enrollment is not active")
+	ErrEnrollmentNotPending = errors.New("enrollment is not awaiting approval")
+	ErrEnrollmentClosed     = errors.New("course is closed for enrollment")
+	ErrEnrollmentInviteOnly = errors.New("course is open to invited users only")
+	ErrContentAssessed      = errors.New("content is completed by a mentor's assessment")
+	ErrContentNotPublished  = errors.New("content is not published")
+)
+
+// PrerequisitesError is returned when a learner has not completed every
+// prerequisite of the course they are trying to join.
+type PrerequisitesError struct {
+	Missing []*models.CourseResponse
+}
+
+func (e *PrerequisitesError) Error() string {
+	return "prerequisite courses have not been completed"
+}
+
 type EnrollmentService interface {
 	Create(ctx context.Context, userID uint, req *models.CreateEnrollmentRequest) (*models.EnrollmentResponse, error)
 	GetByCouseID(ctx context.Context, id uint) (*models.EnrollmentResponse, error)
 	CheckEnrollment(ctx context.Context, userID, courseID uint) (bool, error)
-	GetUserEnrollments(ctx context.Context, userID uint) ([]uint, error)
+	GetUserEnrollments(ctx context.Context, userID uint, status string) ([]*models.EnrollmentResponse, error)
+	GetUserHistory(ctx context.Context, userID uint) ([]*models.EnrollmentEventResponse, error)
+	GetCourseEnrollments(ctx context.Context, courseID uint, status string, offset, limit int) ([]*models.EnrollmentResponse, error)
+	Unenroll(ctx context.Context, userID, courseID uint) error
+	RemoveEnrollment(ctx context.Context, actorID, userID, courseID uint, req *models.RemoveEnrollmentRequest) error
+	CompleteContent(ctx context.Context, userID, contentID uint) (*models.EnrollmentResponse, error)
+	// CompleteContentFor records a content as done on the learner's behalf,
+	// for contents such as hafalan that a mentor assesses, in the caller's
+	// transaction.
+	CompleteContentFor(ctx context.Context, repo repository.EnrollmentRepository, actorID, userID, contentID uint) error
+	Invite(ctx context.Context, actorID, courseID uint, req *models.InviteEnrollmentRequest) (*models.EnrollmentResponse, error)
+	Approve(ctx context.Context, actorID, courseID, userID uint, req *models.ReviewEnrollmentRequest) (*models.EnrollmentResponse, error)
+	Reject(ctx context.Context, actorID, courseID, userID uint, req *models.ReviewEnrollmentRequest) (*models.EnrollmentResponse, error)
+	UpdateSettings(ctx context.Context, courseID uint, req *models.UpdateEnrollmentSettingsRequest) (*models.CourseResponse, error)
+	UpdateDueDate(ctx context.Context, courseID, userID uint, req *models.UpdateDueDateRequest) (*models.EnrollmentResponse, error)
+	GetOverdueEnrollments(ctx context.Context, courseID uint) ([]*models.EnrollmentResponse, error)
+	// RefreshCompletion completes an active enrollment whose contents are all
+	// done once the other requirements, such as attendance, are met too.
+	RefreshCompletion(ctx context.Context, actorID, courseID, userID uint) error
 }
 
 type enrollmentService struct {
-	repo repository.EnrollmentRepository
+	repo           repository.EnrollmentRepository
+	contentRepo    repository.CourseContentRepository
+	courseRepo     repository.CourseRepository
+	attendanceRepo repository.AttendanceRepository
 }
 
-func NewEnrollmentService(repo repository.EnrollmentRepository) EnrollmentService {
-	return &enrollmentService{repo: repo}
+func NewEnrollmentService(repo repository.EnrollmentRepository, contentRepo repository.CourseContentRepository, courseRepo repository.CourseRepository, attendanceRepo repository.AttendanceRepository) EnrollmentService {
+	return &enrollmentService{repo: repo, contentRepo: contentRepo, courseRepo: courseRepo, attendanceRepo: attendanceRepo}
 }
 
 func (s *enrollmentService) Create(ctx context.Context, userID uint, req *models.CreateEnrollmentRequest) (*models.EnrollmentResponse, error) {
-	existing, err := s.repo.GetByUserAndCourse(ctx, userID, req.CourseID)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
-	}
+	var enrollment *models.Enrollment
 
-	if existing != nil {
-		return existing.ToResponse(), nil
-	}
+	err := s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		course, err := repo.LockCourse(ctx, req.CourseID)
+		if err != nil {
+			return err
+		}
 
-	enrollment := &models.Enrollment{
-		CourseID: req.CourseID,
-		UserID:   userID,
-	}
+		existing, err := repo.GetByUserAndCourse(ctx, userID, req.CourseID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if existing == nil {
+			if err := s.checkPrerequisites(ctx, repo, userID, course.ID); err != nil {
+				return err
+			}
+
+			status, err := s.admissionStatus(ctx, repo, course, false)
+			if err != nil {
+				return err
+			}
+
+			enrollment = &models.Enrollment{
+				CourseID:   req.CourseID,
+				UserID:     userID,
+				EnrolledAt: time.Now(),
+			}
+			enrollment.ApplyDefaultDueDate(course)
+			admit(enrollment, course, status)
+
+			if err := repo.Create(ctx, enrollment); err != nil {
+				return err
+			}
+			return repo.CreateEvent(ctx, &models.EnrollmentEvent{
+				EnrollmentID: enrollment.ID,
+				Action:       admissionAction(status, constants.EnrollmentEventEnrolled),
+				ToStatus:     enrollment.Status,
+				ActorID:      &userID,
+			})
+		}
+
+		enrollment = existing
+		previous := existing.CurrentStatus()
+		switch previous {
+		case constants.EnrollmentActive, constants.EnrollmentCompleted,
+			constants.EnrollmentPending, constants.EnrollmentWaitlist:
+			return nil
+		}
+
+		// Re-enrollment reuses the previous row so completed contents and
+		// progress carry over. The status is captured first because clearing
+		// the deadlines changes it.
+		existing.DroppedAt = nil
+		existing.SetExpiresAt(nil)
+		if existing.CompletedAt != nil {
+			return transitionFrom(ctx, repo, existing, previous, constants.EnrollmentCompleted, constants.EnrollmentEventReenrolled, &userID, nil)
+		}
+
+		if err := s.checkPrerequisites(ctx, repo, userID, course.ID); err != nil {
+			return err
+		}
 
-	if err := s.repo.Create(ctx, enrollment); err != nil {
+		invited := previous == constants.EnrollmentInvited
+		status, err := s.admissionStatus(ctx, repo, course, invited)
+		if err != nil {
+			return err
+		}
+
+		action := constants.EnrollmentEventReenrolled
+		if invited {
+			action = constants.EnrollmentEventEnrolled
+		}
+		admit(existing, course, status)
+		return transitionFrom(ctx, repo, existing, previous, status, admissionAction(status, action), &userID, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return enrollment.ToResponse(), nil
+	return s.withQueuePosition(ctx, enrollment)
 }
 
 func (s *enrollmentService) CheckEnrollment(ctx context.Context, userID, courseID uint) (bool, error) {
@@ -54,21 +159,316 @@ func (s *enrollmentService) CheckEnrollment(ctx context.Context, userID, courseI
 		}
 		return false, err
 	}
-	return enrollment != nil, nil
+
+	return enrollment.HasAccess(), nil
 }
 
-func (s *enrollmentService) GetUserEnrollments(ctx context.Context, userID uint) ([]uint, error) {
+func (s *enrollmentService) GetUserEnrollments(ctx context.Context, userID uint, status string) ([]*models.EnrollmentResponse, error) {
 	enrollments, err := s.repo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	courseIDs := make([]uint, len(enrollments))
+	calendar := models.CalendarFromContext(ctx)
+	responses := make([]*models.EnrollmentResponse, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		if status != "" && enrollment.CurrentStatus().String() != status {
+			continue
+		}
+		responses = append(responses, enrollment.ToResponse().SetHijri(calendar))
+	}
+
+	return responses, nil
+}
+
+func (s *enrollmentService) GetUserHistory(ctx context.Context, userID uint) ([]*models.EnrollmentEventResponse, error) {
+	events, err := s.repo.GetEventsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.EnrollmentEventResponse, len(events))
+	for i, event := range events {
+		responses[i] = event.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *enrollmentService) GetCourseEnrollments(ctx context.Context, courseID uint, status string, offset, limit int) ([]*models.EnrollmentResponse, error) {
+	enrollments, err := s.repo.ListByCourse(ctx, courseID, status, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := models.CalendarFromContext(ctx)
+	responses := make([]*models.EnrollmentResponse, len(enrollments))
 	for i, enrollment := range enrollments {
-		courseIDs[i] = enrollment.CourseID
+		responses[i] = enrollment.ToResponse().SetHijri(calendar)
+	}
+
+	return responses, nil
+}
+
+func (s *enrollmentService) Unenroll(ctx context.Context, userID, courseID uint) error {
+	return s.drop(ctx, userID, courseID, constants.EnrollmentEventDropped, userID, nil)
+}
+
+func (s *enrollmentService) RemoveEnrollment(ctx context.Context, actorID, userID, courseID uint, req *models.RemoveEnrollmentRequest) error {
+	return s.drop(ctx, userID, courseID, constants.EnrollmentEventRemoved, actorID, req.Note)
+}
+
+func (s *enrollmentService) drop(ctx context.Context, userID, courseID uint, action string, actorID uint, note *string) error {
+	return s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		course, err := repo.LockCourse(ctx, courseID)
+		if err != nil {
+			return err
+		}
+
+		enrollment, err := repo.GetByUserAndCourse(ctx, userID, courseID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotEnrolled
+			}
+			return err
+		}
+
+		switch enrollment.CurrentStatus() {
+		case constants.EnrollmentDropped, constants.EnrollmentRejected:
+			return ErrEnrollmentNotActive
+		}
+
+		now := time.Now()
+		enrollment.DroppedAt = &now
+
+		if err := transition(ctx, repo, enrollment, constants.EnrollmentDropped, action, &actorID, note); err != nil {
+			return err
+		}
+
+		return s.promoteWaitlist(ctx, repo, course)
+	})
+}
+
+func (s *enrollmentService) Invite(ctx context.Context, actorID, courseID uint, req *models.InviteEnrollmentRequest) (*models.EnrollmentResponse, error) {
+	var enrollment *models.Enrollment
+
+	err := s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		if _, err := repo.LockCourse(ctx, courseID); err != nil {
+			return err
+		}
+
+		existing, err := repo.GetByUserAndCourse(ctx, req.UserID, courseID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if existing == nil {
+			enrollment = &models.Enrollment{
+				CourseID:   courseID,
+				UserID:     req.UserID,
+				Status:     constants.EnrollmentInvited.String(),
+				EnrolledAt: time.Now(),
+			}
+			if err := repo.Create(ctx, enrollment); err != nil {
+				return err
+			}
+			return repo.CreateEvent(ctx, &models.EnrollmentEvent{
+				EnrollmentID: enrollment.ID,
+				Action:       constants.EnrollmentEventInvited,
+				ToStatus:     enrollment.Status,
+				ActorID:      &actorID,
+			})
+		}
+
+		enrollment = existing
+		// Queued users keep their place and their request; an invite would
+		// only send them back to the start.
+		switch existing.CurrentStatus() {
+		case constants.EnrollmentActive, constants.EnrollmentCompleted, constants.EnrollmentInvited,
+			constants.EnrollmentPending, constants.EnrollmentWaitlist:
+			return nil
+		}
+		return transition(ctx, repo, existing, constants.EnrollmentInvited, constants.EnrollmentEventInvited, &actorID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withQueuePosition(ctx, enrollment)
+}
+
+func (s *enrollmentService) Approve(ctx context.Context, actorID, courseID, userID uint, req *models.ReviewEnrollmentRequest) (*models.EnrollmentResponse, error) {
+	var enrollment *models.Enrollment
+
+	err := s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		course, err := repo.LockCourse(ctx, courseID)
+		if err != nil {
+			return err
+		}
+
+		enrollment, err = s.getPending(ctx, repo, userID, courseID)
+		if err != nil {
+			return err
+		}
+
+		status, err := seatStatus(ctx, repo, course)
+		if err != nil {
+			return err
+		}
+		previous := enrollment.CurrentStatus()
+		admit(enrollment, course, status)
+		return transitionFrom(ctx, repo, enrollment, previous, status, constants.EnrollmentEventApproved, &actorID, req.Note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withQueuePosition(ctx, enrollment)
+}
+
+func (s *enrollmentService) Reject(ctx context.Context, actorID, courseID, userID uint, req *models.ReviewEnrollmentRequest) (*models.EnrollmentResponse, error) {
+	var enrollment *models.Enrollment
+
+	err := s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		if _, err := repo.LockCourse(ctx, courseID); err != nil {
+			return err
+		}
+
+		var err error
+		enrollment, err = s.getPending(ctx, repo, userID, courseID)
+		if err != nil {
+			return err
+		}
+		return transition(ctx, repo, enrollment, constants.EnrollmentRejected, constants.EnrollmentEventRejected, &actorID, req.Note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enrollment.ToResponse().SetHijri(models.CalendarFromContext(ctx)), nil
+}
+
+func (s *enrollmentService) UpdateSettings(ctx context.Context, courseID uint, req *models.UpdateEnrollmentSettingsRequest) (*models.CourseResponse, error) {
+	var course *models.Course
+
+	err := s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		var err error
+		course, err = repo.LockCourse(ctx, courseID)
+		if err != nil {
+			return err
+		}
+
+		course.EnrollmentMode = req.EnrollmentMode
+		course.MaxSeats = nil
+		if req.MaxSeats != nil && *req.MaxSeats > 0 {
+			course.MaxSeats = req.MaxSeats
+		}
+		course.DefaultDueDays = nil
+		if req.DefaultDueDays != nil && *req.DefaultDueDays > 0 {
+			course.DefaultDueDays = req.DefaultDueDays
+		}
+		course.AccessDays = nil
+		if req.AccessDays != nil && *req.AccessDays > 0 {
+			course.AccessDays = req.AccessDays
+		}
+
+		course.MinAttendancePercent = nil
+		if req.MinAttendancePercent != nil && *req.MinAttendancePercent > 0 {
+			course.MinAttendancePercent = req.MinAttendancePercent
+		}
+
+		if err := repo.UpdateCourseSettings(ctx, course); err != nil {
+			return err
+		}
+
+		// Raising or removing the seat limit may free places for waitlisted users.
+		return s.promoteWaitlist(ctx, repo, course)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return course.ToResponse(), nil
+}
+
+func (s *enrollmentService) CompleteContent(ctx context.Context, userID, contentID uint) (*models.EnrollmentResponse, error) {
+	content, err := s.contentRepo.GetByID(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+	if content.ContentType == constants.ContentTypeHafalan {
+		return nil, ErrContentAssessed
+	}
+
+	return s.completeContent(ctx, userID, userID, contentID)
+}
+
+func (s *enrollmentService) CompleteContentFor(ctx context.Context, repo repository.EnrollmentRepository, actorID, userID, contentID uint) error {
+	_, err := s.completeContentIn(ctx, repo, actorID, userID, contentID)
+	return err
+}
+
+func (s *enrollmentService) completeContent(ctx context.Context, actorID, userID, contentID uint) (*models.EnrollmentResponse, error) {
+	var enrollment *models.Enrollment
+	err := s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		var err error
+		enrollment, err = s.completeContentIn(ctx, repo, actorID, userID, contentID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enrollment.ToResponse().SetHijri(models.CalendarFromContext(ctx)), nil
+}
+
+// completeContentIn records the content as done for the learner and
+// completes the enrollment when it was the last one, in repo's transaction.
+func (s *enrollmentService) completeContentIn(ctx context.Context, repo repository.EnrollmentRepository, actorID, userID, contentID uint) (*models.Enrollment, error) {
+	// Only published contents count towards the course's progress.
+	courseID, err := s.contentRepo.GetPublishedCourseID(ctx, contentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContentNotPublished
+		}
+		return nil, err
+	}
+
+	enrollment, err := repo.GetByUserAndCourse(ctx, userID, courseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, err
+	}
+
+	if !enrollment.HasAccess() {
+		return nil, ErrEnrollmentNotActive
+	}
+
+	total, err := s.contentRepo.CountPublishedByCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.CreateProgress(ctx, &models.ContentProgress{
+		EnrollmentID: enrollment.ID,
+		ContentID:    contentID,
+		CompletedAt:  time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	done, err := repo.CountProgress(ctx, enrollment.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	return courseIDs, nil
+	enrollment.ProgressPercent = progressPercent(done, total)
+	if err := s.completeIfDone(ctx, repo, enrollment, actorID); err != nil {
+		return nil, err
+	}
+	return enrollment, nil
 }
 
 func (s *enrollmentService) GetByCouseID(ctx context.Context, id uint) (*models.EnrollmentResponse, error) {
@@ -77,5 +477,322 @@ func (s *enrollmentService) GetByCouseID(ctx context.Context, id uint) (*models.
 		return nil, err
 	}
 
-	return entity.ToResponse(), nil
+	return entity.ToResponse().SetHijri(models.CalendarFromContext(ctx)), nil
+}
+
+func (s *enrollmentService) UpdateDueDate(ctx context.Context, courseID, userID uint, req *models.UpdateDueDateRequest) (*models.EnrollmentResponse, error) {
+	calendar := models.CalendarFromContext(ctx)
+	if err := req.ResolveHijri(calendar); err != nil {
+		return nil, err
+	}
+
+	var enrollment *models.Enrollment
+	err := s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		var err error
+		enrollment, err = repo.GetByUserAndCourse(ctx, userID, courseID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotEnrolled
+			}
+			return err
+		}
+		return updateDueAt(ctx, repo, enrollment, req.DueAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enrollment.ToResponse().SetHijri(calendar), nil
+}
+
+func (s *enrollmentService) GetOverdueEnrollments(ctx context.Context, courseID uint) ([]*models.EnrollmentResponse, error) {
+	enrollments, err := s.repo.ListOverdueByCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := models.CalendarFromContext(ctx)
+	responses := make([]*models.EnrollmentResponse, len(enrollments))
+	for i, enrollment := range enrollments {
+		responses[i] = enrollment.ToResponse().SetHijri(calendar)
+	}
+
+	return responses, nil
+}
+
+func (s *enrollmentService) RefreshCompletion(ctx context.Context, actorID, courseID, userID uint) error {
+	return s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+		enrollment, err := repo.GetByUserAndCourse(ctx, userID, courseID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotEnrolled
+			}
+			return err
+		}
+		if !enrollment.IsActive() || enrollment.ProgressPercent < 100 || enrollment.CompletedAt != nil {
+			return nil
+		}
+
+		return s.completeIfDone(ctx, repo, enrollment, actorID)
+	})
+}
+
+// completeIfDone saves the enrollment's progress and completes it when every
+// content is done and the course's attendance requirement is met.
+func (s *enrollmentService) completeIfDone(ctx context.Context, repo repository.EnrollmentRepository, enrollment *models.Enrollment, actorID uint) error {
+	if enrollment.ProgressPercent < 100 || enrollment.CompletedAt != nil {
+		return repo.Update(ctx, enrollment)
+	}
+
+	met, err := s.attendanceMet(ctx, enrollment)
+	if err != nil {
+		return err
+	}
+	if !met {
+		return repo.Update(ctx, enrollment)
+	}
+
+	now := time.Now()
+	enrollment.CompletedAt = &now
+	return transition(ctx, repo, enrollment, constants.EnrollmentCompleted, constants.EnrollmentEventCompleted, &actorID, nil)
+}
+
+func (s *enrollmentService) attendanceMet(ctx context.Context, enrollment *models.Enrollment) (bool, error) {
+	course, err := s.courseRepo.GetByID(ctx, enrollment.CourseID)
+	if err != nil {
+		return false, err
+	}
+	if course.MinAttendancePercent == nil {
+		return true, nil
+	}
+
+	held, err := s.attendanceRepo.HeldSessionIDs(ctx, course.ID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	records, err := s.attendanceRepo.ListRecordsByCourse(ctx, course.ID, []uint{enrollment.UserID})
+	if err != nil {
+		return false, err
+	}
+	statuses := make(map[uint]string, len(records))
+	for _, record := range records {
+		statuses[record.SessionID] = record.Status
+	}
+
+	return models.SummarizeAttendance(held, statuses).Meets(course.MinAttendancePercent), nil
+}
+
+// transition saves the enrollment under its new status and records the change
+// in the enrollment history.
+func transition(ctx context.Context, repo repository.EnrollmentRepository, enrollment *models.Enrollment, to constants.EnrollmentStatus, action string, actorID *uint, note *string) error {
+	return transitionFrom(ctx, repo, enrollment, enrollment.CurrentStatus(), to, action, actorID, note)
+}
+
+// transitionFrom is transition for callers that changed the enrollment
+// before moving it, and so pass the status it had before.
+func transitionFrom(ctx context.Context, repo repository.EnrollmentRepository, enrollment *models.Enrollment, previous, to constants.EnrollmentStatus, action string, actorID *uint, note *string) error {
+	from := previous.String()
+	setStatus(enrollment, to)
+
+	if err := repo.Update(ctx, enrollment); err != nil {
+		return err
+	}
+
+	return repo.CreateEvent(ctx, &models.EnrollmentEvent{
+		EnrollmentID: enrollment.ID,
+		Action:       action,
+		FromStatus:   &from,
+		ToStatus:     enrollment.Status,
+		ActorID:      actorID,
+		Note:         note,
+	})
+}
+
+// updateDueAt saves a new due date and clears the reminders sent for the old
+// one, so the learner is reminded again before the new deadline.
+func updateDueAt(ctx context.Context, repo repository.EnrollmentRepository, enrollment *models.Enrollment, due *time.Time) error {
+	if !enrollment.SetDueAt(due) {
+		return nil
+	}
+	if err := repo.Update(ctx, enrollment); err != nil {
+		return err
+	}
+	return repo.DeleteReminders(ctx, enrollment.ID)
+}
+
+func progressPercent(done, total int64) int {
+	if total == 0 {
+		return 0
+	}
+	percent := int(done * 100 / total)
+	if percent > 100 {
+		percent = 100
+	}
+	return percent
+}
+
+func (s *enrollmentService) checkPrerequisites(ctx context.Context, repo repository.EnrollmentRepository, userID, courseID uint) error {
+	prerequisites, err := s.courseRepo.GetPrerequisites(ctx, courseID)
+	if err != nil || len(prerequisites) == 0 {
+		return err
+	}
+
+	ids := make([]uint, len(prerequisites))
+	for i, prerequisite := range prerequisites {
+		ids[i] = prerequisite.ID
+	}
+
+	enrollments, err := repo.GetByUserAndCourses(ctx, userID, ids)
+	if err != nil {
+		return err
+	}
+
+	completed := make(map[uint]bool, len(enrollments))
+	for _, enrollment := range enrollments {
+		if enrollment.CompletedAt != nil {
+			completed[enrollment.CourseID] = true
+		}
+	}
+
+	var missing []*models.CourseResponse
+	for _, prerequisite := range prerequisites {
+		if !completed[prerequisite.ID] {
+			missing = append(missing, prerequisite.ToResponse())
+		}
+	}
+
+	if len(missing) > 0 {
+		return &PrerequisitesError{Missing: missing}
+	}
+	return nil
+}
+
+// admissionStatus decides which status a new or returning learner receives
+// under the course's enrollment mode. Callers must hold the course lock.
+func (s *enrollmentService) admissionStatus(ctx context.Context, repo repository.EnrollmentRepository, course *models.Course, invited bool) (constants.EnrollmentStatus, error) {
+	switch constants.EnrollmentMode(course.EnrollmentMode) {
+	case constants.EnrollmentModeClosed:
+		return "", ErrEnrollmentClosed
+	case constants.EnrollmentModeInvite:
+		if !invited {
+			return "", ErrEnrollmentInviteOnly
+		}
+	case constants.EnrollmentModeApproval:
+		if !invited {
+			return constants.EnrollmentPending, nil
+		}
+	}
+
+	return seatStatus(ctx, repo, course)
+}
+
+func seatStatus(ctx context.Context, repo repository.EnrollmentRepository, course *models.Course) (constants.EnrollmentStatus, error) {
+	if course.MaxSeats == nil {
+		return constants.EnrollmentActive, nil
+	}
+
+	taken, err := repo.CountSeatsTaken(ctx, course.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if taken >= int64(*course.MaxSeats) {
+		return constants.EnrollmentWaitlist, nil
+	}
+	return constants.EnrollmentActive, nil
+}
+
+// promoteWaitlist moves waitlisted users into free seats in the order they
+// joined the waitlist. Callers must hold the course lock.
+func (s *enrollmentService) promoteWaitlist(ctx context.Context, repo repository.EnrollmentRepository, course *models.Course) error {
+	for {
+		status, err := seatStatus(ctx, repo, course)
+		if err != nil {
+			return err
+		}
+		if status != constants.EnrollmentActive {
+			return nil
+		}
+
+		next, err := repo.GetQueueHead(ctx, course.ID, constants.EnrollmentWaitlist.String())
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		admit(next, course, constants.EnrollmentActive)
+		if err := transitionFrom(ctx, repo, next, constants.EnrollmentWaitlist, constants.EnrollmentActive, constants.EnrollmentEventPromoted, nil, nil); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *enrollmentService) getPending(ctx context.Context, repo repository.EnrollmentRepository, userID, courseID uint) (*models.Enrollment, error) {
+	enrollment, err := repo.GetByUserAndCourse(ctx, userID, courseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, err
+	}
+
+	if enrollment.CurrentStatus() != constants.EnrollmentPending {
+		return nil, ErrEnrollmentNotPending
+	}
+	return enrollment, nil
+}
+
+func (s *enrollmentService) withQueuePosition(ctx context.Context, enrollment *models.Enrollment) (*models.EnrollmentResponse, error) {
+	resp := enrollment.ToResponse().SetHijri(models.CalendarFromContext(ctx))
+
+	status := enrollment.CurrentStatus()
+	if enrollment.QueuedAt == nil || (status != constants.EnrollmentPending && status != constants.EnrollmentWaitlist) {
+		return resp, nil
+	}
+
+	ahead, err := s.repo.CountQueuedBefore(ctx, enrollment.CourseID, status.String(), *enrollment.QueuedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	position := int(ahead) + 1
+	resp.QueuePosition = &position
+	return resp, nil
+}
+
+// setStatus updates the status and stamps the queue time when the enrollment
+// enters the approval queue or the waitlist.
+func setStatus(enrollment *models.Enrollment, to constants.EnrollmentStatus) {
+	switch to {
+	case constants.EnrollmentPending, constants.EnrollmentWaitlist:
+		current := enrollment.CurrentStatus()
+		if enrollment.QueuedAt == nil || (current != constants.EnrollmentPending && current != constants.EnrollmentWaitlist) {
+			now := time.Now()
+			enrollment.QueuedAt = &now
+		}
+	default:
+		enrollment.QueuedAt = nil
+	}
+	enrollment.Status = to.String()
+}
+
+// admit sets the status an enrollment is admitted with, and starts the
+// course's access window when it becomes active.
+func admit(enrollment *models.Enrollment, course *models.Course, status constants.EnrollmentStatus) {
+	if status == constants.EnrollmentActive {
+		enrollment.ApplyAccessDays(course)
+	}
+	setStatus(enrollment, status)
+}
+
+func admissionAction(status constants.EnrollmentStatus, fallback string) string {
+	switch status {
+	case constants.EnrollmentPending:
+		return constants.EnrollmentEventRequested
+	case constants.EnrollmentWaitlist:
+		return constants.EnrollmentEventWaitlisted
+	}
+	return fallback
 }
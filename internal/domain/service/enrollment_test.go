@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// memoryEnrollments keeps enrollments, their history and content progress.
+type memoryEnrollments struct {
+	repository.EnrollmentRepository
+	course      *models.Course
+	enrollments []*models.Enrollment
+	events      []*models.EnrollmentEvent
+	progress    map[uint][]uint
+	// published lists the contents CountProgress counts.
+	published map[uint]bool
+}
+
+func (r *memoryEnrollments) Transaction(ctx context.Context, fn func(repo repository.EnrollmentRepository) error) error {
+	return fn(r)
+}
+
+func (r *memoryEnrollments) GetByUserAndCourse(ctx context.Context, userID, courseID uint) (*models.Enrollment, error) {
+	for _, enrollment := range r.enrollments {
+		if enrollment.UserID == userID && enrollment.CourseID == courseID {
+			return enrollment, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryEnrollments) LockCourse(ctx context.Context, courseID uint) (*models.Course, error) {
+	return r.course, nil
+}
+
+func (r *memoryEnrollments) Create(ctx context.Context, enrollment *models.Enrollment) error {
+	enrollment.ID = uint(len(r.enrollments) + 1)
+	r.enrollments = append(r.enrollments, enrollment)
+	return nil
+}
+
+func (r *memoryEnrollments) Update(ctx context.Context, enrollment *models.Enrollment) error {
+	return nil
+}
+
+func (r *memoryEnrollments) DeleteReminders(ctx context.Context, enrollmentID uint) error {
+	return nil
+}
+
+func (r *memoryEnrollments) CreateEvent(ctx context.Context, event *models.EnrollmentEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memoryEnrollments) CountQueuedBefore(ctx context.Context, courseID uint, status string, queuedAt time.Time) (int64, error) {
+	var count int64
+	for _, enrollment := range r.enrollments {
+		if enrollment.Status == status && enrollment.QueuedAt.Before(queuedAt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryEnrollments) ListOverdueByCourse(ctx context.Context, courseID uint) ([]*models.Enrollment, error) {
+	var overdue []*models.Enrollment
+	for _, enrollment := range r.enrollments {
+		if enrollment.IsOverdue() {
+			overdue = append(overdue, enrollment)
+		}
+	}
+	return overdue, nil
+}
+
+func (r *memoryEnrollments) CreateProgress(ctx context.Context, progress *models.ContentProgress) error {
+	r.progress[progress.EnrollmentID] = append(r.progress[progress.EnrollmentID], progress.ContentID)
+	return nil
+}
+
+func (r *memoryEnrollments) CountProgress(ctx context.Context, enrollmentID uint) (int64, error) {
+	var count int64
+	for _, contentID := range r.progress[enrollmentID] {
+		if r.published[contentID] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// courseContents serves the contents of course 1, of which the published
+// ones count towards its progress.
+type courseContents struct {
+	repository.CourseContentRepository
+	contents map[uint]*models.CourseContent
+}
+
+func (r *courseContents) GetByID(ctx context.Context, id uint) (*models.CourseContent, error) {
+	if content, ok := r.contents[id]; ok {
+		return content, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *courseContents) GetPublishedCourseID(ctx context.Context, contentID uint) (uint, error) {
+	if content, ok := r.contents[contentID]; ok && content.IsPublished {
+		return 1, nil
+	}
+	return 0, gorm.ErrRecordNotFound
+}
+
+func (r *courseContents) CountPublishedByCourse(ctx context.Context, courseID uint) (int64, error) {
+	var count int64
+	for _, content := range r.contents {
+		if content.IsPublished {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type openCourses struct {
+	repository.CourseRepository
+}
+
+func (openCourses) GetByID(ctx context.Context, id uint) (*models.Course, error) {
+	return &models.Course{ID: id}, nil
+}
+
+func (openCourses) GetPrerequisites(ctx context.Context, courseID uint) ([]*models.Course, error) {
+	return nil, nil
+}
+
+func TestCompleteContentCountsOnlyPublishedContents(t *testing.T) {
+	contents := &courseContents{contents: map[uint]*models.CourseContent{
+		1: {ID: 1, ContentType: "text", IsPublished: true},
+		2: {ID: 2, ContentType: "text", IsPublished: true},
+		3: {ID: 3, ContentType: "text"},
+	}}
+	enrollments := &memoryEnrollments{
+		enrollments: []*models.Enrollment{learner(7, constants.EnrollmentActive)},
+		progress:    map[uint][]uint{},
+		published:   map[uint]bool{1: true, 2: true},
+	}
+	svc := NewEnrollmentService(enrollments, contents, openCourses{}, nil)
+	ctx := context.Background()
+
+	if _, err := svc.CompleteContent(ctx, 7, 3); !errors.Is(err, ErrContentNotPublished) {
+		t.Fatalf("complete a draft = %v, want %v", err, ErrContentNotPublished)
+	}
+	if _, err := svc.CompleteContent(ctx, 7, 4); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("complete a missing content = %v, want not found", err)
+	}
+	if len(enrollments.progress[7]) != 0 {
+		t.Fatalf("recorded progress %v for contents that are not published", enrollments.progress[7])
+	}
+
+	got, err := svc.CompleteContent(ctx, 7, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ProgressPercent != 50 || got.CompletedAt != nil {
+		t.Fatalf("progress %d%%, completed at %v after one of two published contents", got.ProgressPercent, got.CompletedAt)
+	}
+
+	got, err = svc.CompleteContent(ctx, 7, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ProgressPercent != 100 || got.Status != constants.EnrollmentCompleted.String() {
+		t.Fatalf("progress %d%%, status %s after every published content", got.ProgressPercent, got.Status)
+	}
+}
+
+func TestAdmissionStartsTheCourseAccessWindow(t *testing.T) {
+	days := 30
+	enrollments := &memoryEnrollments{course: &models.Course{ID: 1, EnrollmentMode: "open", AccessDays: &days}}
+	svc := NewEnrollmentService(enrollments, &courseContents{}, openCourses{}, nil)
+	ctx := context.Background()
+
+	got, err := svc.Create(ctx, 7, &models.CreateEnrollmentRequest{CourseID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ExpiresAt == nil || got.ExpiresAt.Sub(time.Now().AddDate(0, 0, days)).Abs() > time.Minute {
+		t.Fatalf("access expires at %v, want %d days from now", got.ExpiresAt, days)
+	}
+
+	enrollment := enrollments.enrollments[0]
+	past := time.Now().Add(-time.Hour)
+	enrollment.SetExpiresAt(&past)
+	if enrollment.CurrentStatus() != constants.EnrollmentExpired || enrollment.HasAccess() {
+		t.Fatalf("status %s after the access window, want %s", enrollment.CurrentStatus(), constants.EnrollmentExpired)
+	}
+
+	// Enrolling again opens a new window.
+	got, err = svc.Create(ctx, 7, &models.CreateEnrollmentRequest{CourseID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != constants.EnrollmentActive.String() || !got.ExpiresAt.After(time.Now()) {
+		t.Fatalf("re-enrolled as %s until %v", got.Status, got.ExpiresAt)
+	}
+}
+
+func TestInviteKeepsQueuedUsersInPlace(t *testing.T) {
+	queuedAt := time.Now().Add(-time.Hour)
+	enrollments := &memoryEnrollments{course: &models.Course{ID: 1}}
+	for _, status := range []constants.EnrollmentStatus{constants.EnrollmentPending, constants.EnrollmentWaitlist} {
+		enrollment := learner(uint(len(enrollments.enrollments)+1), status)
+		enrollment.QueuedAt = &queuedAt
+		enrollments.enrollments = append(enrollments.enrollments, enrollment)
+	}
+	svc := NewEnrollmentService(enrollments, &courseContents{}, openCourses{}, nil)
+
+	for _, queued := range enrollments.enrollments {
+		status := queued.Status
+		got, err := svc.Invite(context.Background(), 50, 1, &models.InviteEnrollmentRequest{UserID: queued.UserID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != status || !queued.QueuedAt.Equal(queuedAt) || got.QueuePosition == nil || *got.QueuePosition != 1 {
+			t.Fatalf("inviting a %s user left them %s, queued at %v, position %v", status, got.Status, queued.QueuedAt, got.QueuePosition)
+		}
+	}
+	if len(enrollments.events) != 0 {
+		t.Fatalf("inviting queued users logged %d events", len(enrollments.events))
+	}
+}
+
+func TestOverdueReportCarriesHijriDates(t *testing.T) {
+	late := learner(7, constants.EnrollmentActive)
+	late.EnrolledAt = time.Date(2024, time.March, 11, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2024, time.April, 9, 8, 0, 0, 0, time.UTC)
+	late.DueAt = &due
+	svc := NewEnrollmentService(&memoryEnrollments{enrollments: []*models.Enrollment{late}}, &courseContents{}, openCourses{}, nil)
+
+	report, err := svc.GetOverdueEnrollments(models.ContextWithHijri(context.Background(), true), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 1 || report[0].DueAtHijri == nil || report[0].EnrolledAtHijri == nil {
+		t.Fatalf("overdue report without Hijri dates: %+v", report)
+	}
+	// 1 Ramadan 1445 and 30 Ramadan 1445.
+	if report[0].EnrolledAtHijri.Date != "1445-09-01" || report[0].DueAtHijri.Date != "1445-09-30" {
+		t.Fatalf("enrolled %s, due %s", report[0].EnrolledAtHijri.Date, report[0].DueAtHijri.Date)
+	}
+	if report[0].CompletedAtHijri != nil {
+		t.Fatal("Hijri completion date for an unfinished enrollment")
+	}
+
+	report, err = svc.GetOverdueEnrollments(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report[0].DueAtHijri != nil {
+		t.Fatal("Hijri dates without asking for them")
+	}
+}
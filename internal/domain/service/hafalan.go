@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotHafalanContent     = errors.New("content is not a hafalan activity")
+	ErrHafalanTargetRequired = errors.New("set the hafalan target before publishing the content")
+)
+
+// HafalanService records the Qur'an recitations mentors hear from learners
+// in hafalan contents. A hafalan content is done once every ayah of its
+// target has been recited with a passing grade, and cannot be published
+// without a target.
+type HafalanService interface {
+	// SetTarget sets the content's target and completes the content for the
+	// learners who have already memorized it.
+	SetTarget(ctx context.Context, actorID, contentID uint, req *models.SetHafalanTargetRequest) (*models.HafalanTargetResponse, error)
+	GetTarget(ctx context.Context, contentID uint) (*models.HafalanTargetResponse, error)
+	// Record saves a graded recitation and completes the content for the
+	// learner when it finishes memorizing the target.
+	Record(ctx context.Context, actorID, contentID uint, req *models.CreateHafalanRecordRequest) (*models.HafalanRecordResponse, error)
+	// DeleteRecord removes a recitation recorded by mistake. A content the
+	// learner has already completed stays completed.
+	DeleteRecord(ctx context.Context, id uint) error
+	// GetProgress returns the learner's coverage and timeline over the
+	// hafalan contents of the course.
+	GetProgress(ctx context.Context, courseID, userID uint) (*models.HafalanProgress, error)
+}
+
+type hafalanService struct {
+	repo           repository.HafalanRepository
+	contentRepo    repository.CourseContentRepository
+	courseRepo     repository.CourseRepository
+	enrollmentRepo repository.EnrollmentRepository
+	enrollments    EnrollmentService
+}
+
+func NewHafalanService(repo repository.HafalanRepository, contentRepo repository.CourseContentRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, enrollments EnrollmentService) HafalanService {
+	return &hafalanService{
+		repo:           repo,
+		contentRepo:    contentRepo,
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+		enrollments:    enrollments,
+	}
+}
+
+func (s *hafalanService) SetTarget(ctx context.Context, actorID, contentID uint, req *models.SetHafalanTargetRequest) (*models.HafalanTargetResponse, error) {
+	content, err := s.content(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &models.HafalanTarget{
+		ContentID: content.ID,
+		FromSurah: req.FromSurah,
+		FromAyah:  req.FromAyah,
+		ToSurah:   req.ToSurah,
+		ToAyah:    req.ToAyah,
+	}
+	if _, _, err := target.Range(); err != nil {
+		return nil, err
+	}
+
+	courseID, err := s.contentRepo.GetCourseID(ctx, content.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Learners may already have recited all of a new or narrowed target. The
+	// course lock holds off admissions until the learners are re-evaluated.
+	err = s.repo.Transaction(ctx, func(repo repository.HafalanRepository, enrollmentRepo repository.EnrollmentRepository) error {
+		if _, err := enrollmentRepo.LockCourse(ctx, courseID); err != nil {
+			return err
+		}
+		if err := repo.SaveTarget(ctx, target); err != nil {
+			return err
+		}
+		enrollments, err := enrollmentRepo.ListWithAccess(ctx, courseID)
+		if err != nil {
+			return err
+		}
+		for _, enrollment := range enrollments {
+			if !enrollment.IsActive() {
+				continue
+			}
+			if err := s.completeIfMemorized(ctx, repo, enrollmentRepo, actorID, content.ID, enrollment.UserID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return target.ToResponse(), nil
+}
+
+func (s *hafalanService) GetTarget(ctx context.Context, contentID uint) (*models.HafalanTargetResponse, error) {
+	if _, err := s.content(ctx, contentID); err != nil {
+		return nil, err
+	}
+
+	target, err := s.repo.GetTarget(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return target.ToResponse(), nil
+}
+
+func (s *hafalanService) Record(ctx context.Context, actorID, contentID uint, req *models.CreateHafalanRecordRequest) (*models.HafalanRecordResponse, error) {
+	calendar := models.CalendarFromContext(ctx)
+	if err := req.ResolveHijri(calendar); err != nil {
+		return nil, err
+	}
+
+	content, err := s.content(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+	courseID, err := s.contentRepo.GetCourseID(ctx, content.ID)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.enrollmentRepo.GetByUserAndCourse(ctx, req.UserID, courseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, err
+	}
+	if !enrollment.HasAccess() {
+		return nil, ErrEnrollmentNotActive
+	}
+
+	record := &models.HafalanRecord{
+		ContentID:  content.ID,
+		UserID:     req.UserID,
+		Kind:       req.Kind,
+		Surah:      req.Surah,
+		FromAyah:   req.FromAyah,
+		ToAyah:     req.ToAyah,
+		Grade:      req.Grade,
+		Note:       req.Note,
+		RecordedBy: &actorID,
+		RecordedAt: time.Now(),
+	}
+	if req.RecordedAt != nil {
+		record.RecordedAt = *req.RecordedAt
+	}
+	if _, _, err := record.Range(); err != nil {
+		return nil, err
+	}
+	err = s.repo.Transaction(ctx, func(repo repository.HafalanRepository, enrollmentRepo repository.EnrollmentRepository) error {
+		if err := repo.CreateRecord(ctx, record); err != nil {
+			return err
+		}
+		if !constants.HafalanGrade(record.Grade).Passing() {
+			return nil
+		}
+		return s.completeIfMemorized(ctx, repo, enrollmentRepo, actorID, content.ID, req.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return record.ToResponse().SetHijri(calendar), nil
+}
+
+func (s *hafalanService) DeleteRecord(ctx context.Context, id uint) error {
+	record, err := s.repo.GetRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Records are reached through their content, which belongs to the
+	// tenant.
+	if _, err := s.contentRepo.GetByID(ctx, record.ContentID); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteRecord(ctx, record.ID)
+}
+
+func (s *hafalanService) GetProgress(ctx context.Context, courseID, userID uint) (*models.HafalanProgress, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID); err != nil {
+		return nil, err
+	}
+	if _, err := s.enrollmentRepo.GetByUserAndCourse(ctx, userID, courseID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, err
+	}
+
+	contents, err := s.contentRepo.ListPublishedByCourse(ctx, courseID, constants.ContentTypeHafalan)
+	if err != nil {
+		return nil, err
+	}
+	contentIDs := make([]uint, len(contents))
+	for i, content := range contents {
+		contentIDs[i] = content.ID
+	}
+	targets, err := s.repo.ListTargets(ctx, contentIDs)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.ListRecords(ctx, contentIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := models.CalendarFromContext(ctx)
+	progress := &models.HafalanProgress{
+		CourseID:   courseID,
+		UserID:     userID,
+		Juz:        []models.JuzCoverage{},
+		Activities: make([]*models.HafalanActivityProgress, len(contents)),
+		Timeline:   make([]models.HafalanTimelineEntry, len(records)),
+	}
+
+	// Each content's target is measured against what the learner recited
+	// for that content, and the course totals against everything.
+	var memorized util.AyahSet
+	byContent := make(map[uint]*util.AyahSet, len(contents))
+	for i, record := range records {
+		if from, to, err := record.Range(); err == nil && constants.HafalanGrade(record.Grade).Passing() {
+			memorized.AddRange(from, to)
+			if byContent[record.ContentID] == nil {
+				byContent[record.ContentID] = &util.AyahSet{}
+			}
+			byContent[record.ContentID].AddRange(from, to)
+		}
+		progress.Timeline[i] = models.HafalanTimelineEntry{
+			Record:         record.ToResponse().SetHijri(calendar),
+			MemorizedAyahs: memorized.Len(),
+			JuzCovered:     roundJuz(memorized.JuzCovered()),
+		}
+	}
+
+	targetsByContent := make(map[uint]*models.HafalanTarget, len(targets))
+	for _, target := range targets {
+		targetsByContent[target.ContentID] = target
+	}
+	for i := range contents {
+		content := &contents[i]
+		activity := &models.HafalanActivityProgress{Content: content.ToResponse()}
+		if target := targetsByContent[content.ID]; target != nil {
+			activity.Target = target.ToResponse()
+			if from, to, err := target.Range(); err == nil && byContent[content.ID] != nil {
+				activity.Memorized = byContent[content.ID].CountRange(from, to)
+				activity.Percent = progressPercent(int64(activity.Memorized), int64(to-from+1))
+				activity.Completed = activity.Memorized == to-from+1
+			}
+		}
+		progress.Activities[i] = activity
+	}
+
+	progress.MemorizedAyahs = memorized.Len()
+	progress.JuzCovered = roundJuz(memorized.JuzCovered())
+	for juz := 1; juz <= util.QuranJuzCount; juz++ {
+		count := memorized.JuzCount(juz)
+		if count == 0 {
+			continue
+		}
+		ayahs := util.JuzAyahs(juz)
+		progress.Juz = append(progress.Juz, models.JuzCoverage{
+			Juz:       juz,
+			Memorized: count,
+			Ayahs:     ayahs,
+			Percent:   progressPercent(int64(count), int64(ayahs)),
+		})
+		if count == ayahs {
+			progress.JuzCompleted++
+		}
+	}
+
+	return progress, nil
+}
+
+// completeIfMemorized completes the content for the learner once every ayah
+// of its target has been recited with a passing grade, in the transaction of
+// the repositories.
+func (s *hafalanService) completeIfMemorized(ctx context.Context, repo repository.HafalanRepository, enrollmentRepo repository.EnrollmentRepository, actorID, contentID, userID uint) error {
+	target, err := repo.GetTarget(ctx, contentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	from, to, err := target.Range()
+	if err != nil {
+		return err
+	}
+
+	records, err := repo.ListRecords(ctx, []uint{contentID}, userID)
+	if err != nil {
+		return err
+	}
+	var memorized util.AyahSet
+	for _, record := range records {
+		if recordFrom, recordTo, err := record.Range(); err == nil && constants.HafalanGrade(record.Grade).Passing() {
+			memorized.AddRange(recordFrom, recordTo)
+		}
+	}
+	if memorized.CountRange(from, to) < to-from+1 {
+		return nil
+	}
+
+	// A draft hafalan is recited but does not count towards the course yet.
+	err = s.enrollments.CompleteContentFor(ctx, enrollmentRepo, actorID, userID, contentID)
+	if errors.Is(err, ErrContentNotPublished) {
+		return nil
+	}
+	return err
+}
+
+// content returns the hafalan content with the ID.
+func (s *hafalanService) content(ctx context.Context, id uint) (*models.CourseContent, error) {
+	content, err := s.contentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if content.ContentType != constants.ContentTypeHafalan {
+		return nil, ErrNotHafalanContent
+	}
+	return content, nil
+}
+
+// roundJuz rounds a juz coverage to two decimals.
+func roundJuz(juz float64) float64 {
+	return math.Round(juz*100) / 100
+}
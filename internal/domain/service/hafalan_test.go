@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// memoryHafalan keeps targets and records, and discards the writes of a
+// transaction that fails.
+type memoryHafalan struct {
+	repository.HafalanRepository
+	targets map[uint]*models.HafalanTarget
+	records []*models.HafalanRecord
+	// enrollments are the course's enrollments as the transaction sees them.
+	enrollments hafalanEnrollments
+}
+
+func (r *memoryHafalan) GetTarget(ctx context.Context, contentID uint) (*models.HafalanTarget, error) {
+	if target, ok := r.targets[contentID]; ok {
+		return target, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryHafalan) SaveTarget(ctx context.Context, target *models.HafalanTarget) error {
+	r.targets[target.ContentID] = target
+	return nil
+}
+
+func (r *memoryHafalan) CreateRecord(ctx context.Context, record *models.HafalanRecord) error {
+	record.ID = uint(len(r.records) + 1)
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *memoryHafalan) ListRecords(ctx context.Context, contentIDs []uint, userID uint) ([]*models.HafalanRecord, error) {
+	var records []*models.HafalanRecord
+	for _, record := range r.records {
+		for _, id := range contentIDs {
+			if record.ContentID == id && record.UserID == userID {
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+func (r *memoryHafalan) Transaction(ctx context.Context, fn func(repo repository.HafalanRepository, enrollmentRepo repository.EnrollmentRepository) error) error {
+	targets := make(map[uint]*models.HafalanTarget, len(r.targets))
+	for id, target := range r.targets {
+		targets[id] = target
+	}
+	records := append([]*models.HafalanRecord(nil), r.records...)
+
+	if err := fn(r, r.enrollments); err != nil {
+		r.targets, r.records = targets, records
+		return err
+	}
+	return nil
+}
+
+type hafalanContents struct {
+	repository.CourseContentRepository
+}
+
+func (hafalanContents) GetByID(ctx context.Context, id uint) (*models.CourseContent, error) {
+	return &models.CourseContent{ID: id, ContentType: constants.ContentTypeHafalan, IsPublished: true}, nil
+}
+
+func (hafalanContents) GetCourseID(ctx context.Context, contentID uint) (uint, error) {
+	return 1, nil
+}
+
+type hafalanEnrollments struct {
+	repository.EnrollmentRepository
+	enrollments []*models.Enrollment
+}
+
+func (r hafalanEnrollments) ListWithAccess(ctx context.Context, courseID uint) ([]*models.Enrollment, error) {
+	return r.enrollments, nil
+}
+
+func (r hafalanEnrollments) LockCourse(ctx context.Context, courseID uint) (*models.Course, error) {
+	return &models.Course{ID: courseID}, nil
+}
+
+func (r hafalanEnrollments) GetByUserAndCourse(ctx context.Context, userID, courseID uint) (*models.Enrollment, error) {
+	for _, enrollment := range r.enrollments {
+		if enrollment.UserID == userID {
+			return enrollment, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// completions records the contents completed for learners.
+type completions struct {
+	EnrollmentService
+	completed []uint
+	err       error
+}
+
+func (c *completions) CompleteContentFor(ctx context.Context, repo repository.EnrollmentRepository, actorID, userID, contentID uint) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.completed = append(c.completed, userID)
+	return nil
+}
+
+func learner(userID uint, status constants.EnrollmentStatus) *models.Enrollment {
+	enrollment := &models.Enrollment{UserID: userID, CourseID: 1, Status: status.String()}
+	enrollment.ID = userID
+	return enrollment
+}
+
+func recitation(userID uint, from, to int, grade constants.HafalanGrade) *models.HafalanRecord {
+	return &models.HafalanRecord{ContentID: 9, UserID: userID, Kind: "ziyadah", Surah: 1, FromAyah: from, ToAyah: to, Grade: grade.String()}
+}
+
+var alFatihah = &models.SetHafalanTargetRequest{FromSurah: 1, FromAyah: 1, ToSurah: 1, ToAyah: 7}
+
+func TestSetTargetCompletesLearnersWhoMemorizedIt(t *testing.T) {
+	repo := &memoryHafalan{targets: map[uint]*models.HafalanTarget{}, records: []*models.HafalanRecord{
+		recitation(1, 1, 7, constants.HafalanJayyid),
+		recitation(2, 1, 3, constants.HafalanMumtaz),
+		recitation(2, 4, 7, constants.HafalanRasib),
+		recitation(3, 1, 7, constants.HafalanMumtaz),
+	}}
+	repo.enrollments = hafalanEnrollments{enrollments: []*models.Enrollment{
+		learner(1, constants.EnrollmentActive),
+		learner(2, constants.EnrollmentActive),
+		learner(3, constants.EnrollmentCompleted),
+	}}
+	done := &completions{}
+	// Enrollments are read in the transaction, not before it.
+	hafalan := NewHafalanService(repo, hafalanContents{}, nil, hafalanEnrollments{}, done)
+
+	if _, err := hafalan.SetTarget(context.Background(), 50, 9, alFatihah); err != nil {
+		t.Fatal(err)
+	}
+	if len(done.completed) != 1 || done.completed[0] != 1 {
+		t.Fatalf("completed for %v, want only the learner who memorized the target", done.completed)
+	}
+
+	done.err = errors.New("enrollment update failed")
+	if _, err := hafalan.SetTarget(context.Background(), 50, 9, &models.SetHafalanTargetRequest{FromSurah: 1, FromAyah: 1, ToSurah: 1, ToAyah: 3}); err == nil {
+		t.Fatal("set target succeeded although completing a learner failed")
+	}
+	if to := repo.targets[9].ToAyah; to != 7 {
+		t.Fatalf("target saved up to ayah %d although completing a learner failed", to)
+	}
+}
+
+func TestRecordRollsBackWhenCompletionFails(t *testing.T) {
+	repo := &memoryHafalan{targets: map[uint]*models.HafalanTarget{
+		9: {ContentID: 9, FromSurah: 1, FromAyah: 1, ToSurah: 1, ToAyah: 7},
+	}}
+	done := &completions{err: errors.New("enrollment update failed")}
+	hafalan := NewHafalanService(repo, hafalanContents{}, nil, hafalanEnrollments{enrollments: []*models.Enrollment{learner(1, constants.EnrollmentActive)}}, done)
+
+	req := &models.CreateHafalanRecordRequest{UserID: 1, Kind: "ziyadah", Surah: 1, FromAyah: 1, ToAyah: 7, Grade: constants.HafalanMumtaz.String()}
+	if _, err := hafalan.Record(context.Background(), 50, 9, req); err == nil {
+		t.Fatal("record succeeded although completing the content failed")
+	}
+	if len(repo.records) != 0 {
+		t.Fatalf("kept %d records of a failed recording", len(repo.records))
+	}
+
+	done.err = nil
+	if _, err := hafalan.Record(context.Background(), 50, 9, req); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.records) != 1 || len(done.completed) != 1 {
+		t.Fatalf("records %d, completions %v after recording the whole target", len(repo.records), done.completed)
+	}
+}
+
+type draftContents struct {
+	repository.CourseContentRepository
+	contents map[uint]*models.CourseContent
+}
+
+func (r *draftContents) Create(ctx context.Context, content *models.CourseContent) error {
+	content.ID = uint(len(r.contents) + 1)
+	r.contents[content.ID] = content
+	return nil
+}
+
+func (r *draftContents) GetByID(ctx context.Context, id uint) (*models.CourseContent, error) {
+	if content, ok := r.contents[id]; ok {
+		copied := *content
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *draftContents) Update(ctx context.Context, content *models.CourseContent) error {
+	r.contents[content.ID] = content
+	return nil
+}
+
+func TestHafalanContentNeedsATargetToBePublished(t *testing.T) {
+	targets := &memoryHafalan{targets: map[uint]*models.HafalanTarget{}}
+	contents := NewCourseContentService(&draftContents{contents: map[uint]*models.CourseContent{}}, targets)
+	ctx := context.Background()
+
+	published := &models.CreateCourseContentRequest{ChapterID: 1, Title: "Juz 30", ContentType: constants.ContentTypeHafalan, IsPublished: true}
+	if _, err := contents.CreateContent(ctx, published); !errors.Is(err, ErrHafalanTargetRequired) {
+		t.Fatalf("create published without a target = %v, want %v", err, ErrHafalanTargetRequired)
+	}
+
+	draft := *published
+	draft.IsPublished = false
+	created, err := contents.CreateContent(ctx, &draft)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish := true
+	if _, err := contents.UpdateContent(ctx, created.ID, &models.UpdateCourseContentRequest{IsPublished: &publish}); !errors.Is(err, ErrHafalanTargetRequired) {
+		t.Fatalf("publish without a target = %v, want %v", err, ErrHafalanTargetRequired)
+	}
+
+	targets.targets[created.ID] = &models.HafalanTarget{ContentID: created.ID, FromSurah: 78, FromAyah: 1, ToSurah: 114, ToAyah: 6}
+	if _, err := contents.UpdateContent(ctx, created.ID, &models.UpdateCourseContentRequest{IsPublished: &publish}); err != nil {
+		t.Fatalf("publish with a target: %v", err)
+	}
+}
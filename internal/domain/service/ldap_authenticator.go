@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/ldap"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type ldapAuthenticator struct {
+	userRepo repository.UserRepository
+	cfg      *config.LDAPConfig
+}
+
+// NewLDAPAuthenticator binds as the user found by NIP in the directory and
+// keeps the local account's name, email and, with a role mapping, role in
+// sync with it.
+func NewLDAPAuthenticator(userRepo repository.UserRepository, cfg *config.LDAPConfig) Authenticator {
+	return &ldapAuthenticator{
+		userRepo: userRepo,
+		cfg:      cfg,
+	}
+}
+
+func (a *ldapAuthenticator) Name() string {
+	return constants.AuthSourceLDAP
+}
+
+func (a *ldapAuthenticator) Authenticate(ctx context.Context, user *models.User, nip, password string) (*models.User, error) {
+	if !a.handles(user) {
+		return nil, ErrAuthenticatorSkip
+	}
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := a.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if a.cfg.BindDN != "" {
+		if err := conn.Bind(a.cfg.BindDN, a.cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("service account bind: %w", err)
+		}
+	}
+
+	attributes := []string{a.cfg.NameAttribute, a.cfg.EmailAttribute}
+	if len(a.cfg.RoleMapping) > 0 {
+		attributes = append(attributes, a.cfg.GroupAttribute)
+	}
+
+	entries, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     a.cfg.BaseDN,
+		Filter:     ldap.And(ldap.Equal("objectClass", a.cfg.UserObjectClass), ldap.Equal(a.cfg.NipAttribute, nip)),
+		Attributes: attributes,
+		SizeLimit:  2,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	switch len(entries) {
+	case 0:
+		return nil, ErrAuthenticatorSkip
+	case 1:
+	default:
+		return nil, fmt.Errorf("NIP %s matches %d directory entries", nip, len(entries))
+	}
+	entry := entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		var ldapErr *ldap.Error
+		if errors.As(err, &ldapErr) && ldapErr.Code == ldap.ResultInvalidCredentials {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("user bind: %w", err)
+	}
+
+	name := strings.TrimSpace(entry.Get(a.cfg.NameAttribute))
+	email := strings.TrimSpace(entry.Get(a.cfg.EmailAttribute))
+	role := a.role(entry)
+
+	if user == nil {
+		if !a.cfg.AutoProvision {
+			return nil, ErrInvalidCredentials
+		}
+		if role == "" {
+			role = a.cfg.DefaultRole
+		}
+		return a.provision(ctx, nip, name, email, role)
+	}
+
+	if err := a.sync(ctx, user, name, email, role); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// handles reports whether the account signs in through the directory: new
+// and LDAP accounts do, as do local accounts other than the break-glass
+// accounts in LocalFallbackRoles. Service and OIDC accounts never do.
+func (a *ldapAuthenticator) handles(user *models.User) bool {
+	if user == nil {
+		return true
+	}
+	switch user.AuthSource {
+	case constants.AuthSourceLDAP:
+		return true
+	case constants.AuthSourceLocal:
+		return !slices.Contains(a.cfg.LocalFallbackRoles, user.Role)
+	}
+	return false
+}
+
+func (a *ldapAuthenticator) dial(ctx context.Context) (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.cfg.InsecureSkipVerify}
+
+	dialCtx, cancel := context.WithTimeout(ctx, a.cfg.Timeout)
+	defer cancel()
+
+	conn, err := ldap.Dial(dialCtx, a.cfg.URL, tlsConfig, a.cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.cfg.StartTLS {
+		u, _ := url.Parse(a.cfg.URL)
+		if err := conn.StartTLS(tlsConfig, u.Hostname()); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// role returns the role mapped from the entry's groups, DefaultRole when
+// none matches, or "" when no role mapping is configured.
+func (a *ldapAuthenticator) role(entry *ldap.Entry) string {
+	if len(a.cfg.RoleMapping) == 0 {
+		return ""
+	}
+	if role, ok := mappedRole(entry.GetAll(a.cfg.GroupAttribute), a.cfg.RoleMapping); ok {
+		return role
+	}
+	return a.cfg.DefaultRole
+}
+
+// provision creates the local account for a directory user on first login.
+// Its password is random and unusable; the directory stays the source of
+// truth for credentials.
+func (a *ldapAuthenticator) provision(ctx context.Context, nip, name, email, role string) (*models.User, error) {
+	if email == "" {
+		return nil, fmt.Errorf("directory entry for NIP %s has no %s", nip, a.cfg.EmailAttribute)
+	}
+	if name == "" {
+		name = nip
+	}
+
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	password, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	user := &models.User{
+		Name:            name,
+		Email:           email,
+		Nip:             nip,
+		Role:            role,
+		Password:        string(password),
+		IsActive:        true,
+		AuthSource:      constants.AuthSourceLDAP,
+		EmailVerifiedAt: &now,
+	}
+	if err := a.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// sync copies the directory name, email and mapped role onto the local
+// account. An email already used by another account is left alone, as is the
+// role when role is empty.
+func (a *ldapAuthenticator) sync(ctx context.Context, user *models.User, name, email, role string) error {
+	changed := false
+
+	if role != "" && role != user.Role {
+		user.Role = role
+		changed = true
+	}
+
+	if name != "" && name != user.Name {
+		user.Name = name
+		changed = true
+	}
+
+	if email != "" && !strings.EqualFold(email, user.Email) {
+		existing, err := a.userRepo.GetByEmail(ctx, email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if existing != nil && existing.ID != user.ID {
+			log.Printf("ldap: not syncing email for user %d, %s belongs to user %d", user.ID, email, existing.ID)
+		} else {
+			now := time.Now()
+			user.Email = email
+			user.EmailVerifiedAt = &now
+			user.PendingEmail = nil
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return a.userRepo.Update(ctx, user)
+}
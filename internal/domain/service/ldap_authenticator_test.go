@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/ldap/ldaptest"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	adminsGroup  = "CN=LMS Admins,OU=Groups,DC=example,DC=com"
+	mentorsGroup = "CN=LMS Mentors,OU=Groups,DC=example,DC=com"
+	staffGroup   = "CN=Staff,OU=Groups,DC=example,DC=com"
+)
+
+// memoryUsers implements the user lookups authenticators make; any other
+// method panics through the nil embedded interface.
+type memoryUsers struct {
+	repository.UserRepository
+
+	mu    sync.Mutex
+	users []*models.User
+}
+
+func (r *memoryUsers) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user.ID = uint(len(r.users) + 1)
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *memoryUsers) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUsers) Update(ctx context.Context, user *models.User) error {
+	return nil
+}
+
+func newDirectory(t *testing.T) *ldaptest.Server {
+	t.Helper()
+
+	server, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	server.AddEntry("CN=Svc LMS,OU=Service Accounts,DC=example,DC=com", "svc-password", nil)
+	addPerson(server, "Siti Aminah", "198703152010", "siti@example.com", mentorsGroup, staffGroup)
+	addPerson(server, "Budi Santoso", "199001012020", "budi@example.com", staffGroup)
+	addPerson(server, "Rina Wati", "198512122008", "rina@example.com", mentorsGroup, adminsGroup)
+	return server
+}
+
+func addPerson(server *ldaptest.Server, name, nip, email string, groups ...string) {
+	server.AddEntry("CN="+name+",OU=Staff,DC=example,DC=com", "pw-"+nip, map[string][]string{
+		"objectClass": {"person"},
+		"employeeID":  {nip},
+		"displayName": {name},
+		"mail":        {email},
+		"memberOf":    groups,
+	})
+}
+
+func ldapConfig(url string) *config.LDAPConfig {
+	return &config.LDAPConfig{
+		Enabled:         true,
+		URL:             url,
+		Timeout:         time.Second,
+		BindDN:          "CN=Svc LMS,OU=Service Accounts,DC=example,DC=com",
+		BindPassword:    "svc-password",
+		BaseDN:          "OU=Staff,DC=example,DC=com",
+		UserObjectClass: "person",
+		NipAttribute:    "employeeID",
+		NameAttribute:   "displayName",
+		EmailAttribute:  "mail",
+		GroupAttribute:  "memberOf",
+		AutoProvision:   true,
+		DefaultRole:     constants.RoleUser.String(),
+	}
+}
+
+// withRoleMapping maps the groups the way viper loads them, with lower-cased
+// keys.
+func withRoleMapping(cfg *config.LDAPConfig) *config.LDAPConfig {
+	cfg.RoleMapping = map[string]string{
+		"cn=lms admins,ou=groups,dc=example,dc=com":  constants.RoleAdmin.String(),
+		"cn=lms mentors,ou=groups,dc=example,dc=com": constants.RoleMentor.String(),
+	}
+	return cfg
+}
+
+func localUser(t *testing.T, nip, role, password string) *models.User {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.User{
+		ID:         1,
+		Nip:        nip,
+		Name:       "Local " + nip,
+		Email:      nip + "@local.example.com",
+		Role:       role,
+		Password:   string(hash),
+		IsActive:   true,
+		AuthSource: constants.AuthSourceLocal,
+	}
+}
+
+func TestLDAPBindSuccessSyncsTheAccount(t *testing.T) {
+	server := newDirectory(t)
+	users := &memoryUsers{}
+	authenticator := NewLDAPAuthenticator(users, ldapConfig(server.URL()))
+
+	user := &models.User{ID: 7, Nip: "199001012020", Name: "Budi", Email: "old@example.com", Role: constants.RoleMentor.String(), AuthSource: constants.AuthSourceLDAP}
+	authenticated, err := authenticator.Authenticate(context.Background(), user, "199001012020", "pw-199001012020")
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if authenticated != user || user.Name != "Budi Santoso" || user.Email != "budi@example.com" || user.EmailVerifiedAt == nil {
+		t.Fatalf("account not synced from the directory: %+v", user)
+	}
+	if user.Role != constants.RoleMentor.String() {
+		t.Fatalf("role changed to %q without a role mapping", user.Role)
+	}
+}
+
+func TestLDAPBindFailure(t *testing.T) {
+	server := newDirectory(t)
+	authenticator := NewLDAPAuthenticator(&memoryUsers{}, ldapConfig(server.URL()))
+
+	if _, err := authenticator.Authenticate(context.Background(), nil, "199001012020", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := authenticator.Authenticate(context.Background(), nil, "000000000000", "anything"); !errors.Is(err, ErrAuthenticatorSkip) {
+		t.Fatalf("unknown NIP = %v, want %v", err, ErrAuthenticatorSkip)
+	}
+}
+
+func TestLDAPRejectsEmptyPassword(t *testing.T) {
+	server := newDirectory(t)
+	users := &memoryUsers{}
+	authenticator := NewLDAPAuthenticator(users, ldapConfig(server.URL()))
+
+	if _, err := authenticator.Authenticate(context.Background(), nil, "199001012020", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("empty password = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if len(users.users) != 0 {
+		t.Fatal("an empty password provisioned an account")
+	}
+}
+
+func TestLDAPGroupRoleMapping(t *testing.T) {
+	server := newDirectory(t)
+
+	tests := []struct {
+		name string
+		nip  string
+		want string
+	}{
+		{"mapped group", "198703152010", constants.RoleMentor.String()},
+		{"most privileged group wins", "198512122008", constants.RoleAdmin.String()},
+		{"no mapped group", "199001012020", constants.RoleUser.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &memoryUsers{}
+			authenticator := NewLDAPAuthenticator(users, withRoleMapping(ldapConfig(server.URL())))
+
+			user, err := authenticator.Authenticate(context.Background(), nil, tt.nip, "pw-"+tt.nip)
+			if err != nil {
+				t.Fatalf("authenticate: %v", err)
+			}
+			if user.Role != tt.want {
+				t.Fatalf("provisioned role = %q, want %q", user.Role, tt.want)
+			}
+		})
+	}
+
+	t.Run("role follows the directory on login", func(t *testing.T) {
+		authenticator := NewLDAPAuthenticator(&memoryUsers{}, withRoleMapping(ldapConfig(server.URL())))
+
+		user := &models.User{ID: 3, Nip: "199001012020", Name: "Budi Santoso", Email: "budi@example.com", Role: constants.RoleAdmin.String(), AuthSource: constants.AuthSourceLDAP}
+		if _, err := authenticator.Authenticate(context.Background(), user, user.Nip, "pw-"+user.Nip); err != nil {
+			t.Fatalf("authenticate: %v", err)
+		}
+		if user.Role != constants.RoleUser.String() {
+			t.Fatalf("role = %q after losing the admin group, want %q", user.Role, constants.RoleUser)
+		}
+	})
+}
+
+func TestLocalFallbackRoles(t *testing.T) {
+	server := newDirectory(t)
+
+	// A closed port stands in for a directory outage.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := "ldap://" + listener.Addr().String()
+	listener.Close()
+
+	chain := func(url string) *authService {
+		cfg := withRoleMapping(ldapConfig(url))
+		cfg.LocalFallbackRoles = []string{constants.RoleAdmin.String()}
+		return &authService{authenticators: []Authenticator{
+			NewLDAPAuthenticator(&memoryUsers{}, cfg),
+			NewLocalAuthenticator([]string{constants.RoleAdmin.String()}),
+		}}
+	}
+	login := func(s *authService, user *models.User, password string) error {
+		_, err := s.authenticate(context.Background(), user, &models.LoginRequest{Nip: user.Nip, Password: password})
+		return err
+	}
+
+	admin := localUser(t, "111111111111", constants.RoleAdmin.String(), "break-glass-password")
+	member := localUser(t, "222222222222", constants.RoleUser.String(), "local-password")
+
+	for name, url := range map[string]string{"directory down": down, "not in directory": server.URL()} {
+		t.Run(name, func(t *testing.T) {
+			if err := login(chain(url), admin, "break-glass-password"); err != nil {
+				t.Fatalf("break-glass admin: %v", err)
+			}
+			if err := login(chain(url), admin, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("break-glass admin with a wrong password = %v", err)
+			}
+			if err := login(chain(url), member, "local-password"); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("local user outside the fallback roles = %v, want %v", err, ErrInvalidCredentials)
+			}
+		})
+	}
+
+	t.Run("directory rejection ends the chain", func(t *testing.T) {
+		directoryMember := localUser(t, "199001012020", constants.RoleUser.String(), "local-password")
+		if err := login(chain(server.URL()), directoryMember, "local-password"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("local password for a directory user = %v, want %v", err, ErrInvalidCredentials)
+		}
+	})
+
+	// Budi is in the directory without the admin group, so a directory
+	// login would demote a local admin with the same NIP.
+	t.Run("break-glass admin in the directory", func(t *testing.T) {
+		directoryAdmin := localUser(t, "199001012020", constants.RoleAdmin.String(), "local-password")
+		if err := login(chain(server.URL()), directoryAdmin, "local-password"); err != nil {
+			t.Fatalf("local password for a break-glass admin in the directory: %v", err)
+		}
+		if err := login(chain(server.URL()), directoryAdmin, "pw-199001012020"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("directory password for a break-glass admin = %v, want %v", err, ErrInvalidCredentials)
+		}
+		if directoryAdmin.Role != constants.RoleAdmin.String() || directoryAdmin.AuthSource != constants.AuthSourceLocal {
+			t.Fatalf("break-glass admin changed by the directory: role %q, source %q", directoryAdmin.Role, directoryAdmin.AuthSource)
+		}
+	})
+
+	t.Run("accounts outside the directory's sources", func(t *testing.T) {
+		for _, source := range []string{constants.AuthSourceService, constants.AuthSourceOIDC} {
+			account := localUser(t, "198703152010", constants.RoleUser.String(), "local-password")
+			account.AuthSource = source
+			if _, err := chain(server.URL()).authenticators[0].Authenticate(context.Background(), account, account.Nip, "pw-198703152010"); !errors.Is(err, ErrAuthenticatorSkip) {
+				t.Fatalf("directory login for a %s account = %v, want %v", source, err, ErrAuthenticatorSkip)
+			}
+		}
+	})
+}
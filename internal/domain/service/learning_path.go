@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPathCourse      = errors.New("learning path references a course that does not exist")
+	ErrNotJoinedLearningPath  = errors.New("user has not joined this learning path")
+	ErrLearningPathNotPublish = errors.New("learning path is not published")
+)
+
+type LearningPathService interface {
+	CreatePath(ctx context.Context, req *models.CreateLearningPathRequest) (*models.LearningPathResponse, error)
+	GetPath(ctx context.Context, id uint) (*models.LearningPathResponse, error)
+	GetPaths(ctx context.Context, publishedOnly bool, offset, limit int) ([]*models.LearningPathResponse, error)
+	UpdatePath(ctx context.Context, id uint, req *models.UpdateLearningPathRequest) (*models.LearningPathResponse, error)
+	DeletePath(ctx context.Context, id uint) error
+	SetCourses(ctx context.Context, id uint, req *models.SetLearningPathCoursesRequest) (*models.LearningPathResponse, error)
+	Join(ctx context.Context, userID, pathID uint) (*models.LearningPathProgressResponse, error)
+	GetProgress(ctx context.Context, userID, pathID uint) (*models.LearningPathProgressResponse, error)
+	GetUserPaths(ctx context.Context, userID uint) ([]*models.LearningPathProgressResponse, error)
+}
+
+type learningPathService struct {
+	repo           repository.LearningPathRepository
+	courseRepo     repository.CourseRepository
+	enrollmentRepo repository.EnrollmentRepository
+	certRepo       repository.CertificateRepository
+}
+
+func NewLearningPathService(repo repository.LearningPathRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, certRepo repository.CertificateRepository) LearningPathService {
+	return &learningPathService{
+		repo:           repo,
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+		certRepo:       certRepo,
+	}
+}
+
+func (s *learningPathService) CreatePath(ctx context.Context, req *models.CreateLearningPathRequest) (*models.LearningPathResponse, error) {
+	courseIDs := uniqueIDs(req.CourseIDs)
+	if err := s.validateCourses(ctx, courseIDs); err != nil {
+		return nil, err
+	}
+
+	path := &models.LearningPath{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublished: req.IsPublished,
+	}
+
+	if err := s.repo.Create(ctx, path); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceCourses(ctx, path.ID, courseIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetPath(ctx, path.ID)
+}
+
+func (s *learningPathService) GetPath(ctx context.Context, id uint) (*models.LearningPathResponse, error) {
+	path, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return path.ToResponse(), nil
+}
+
+func (s *learningPathService) GetPaths(ctx context.Context, publishedOnly bool, offset, limit int) ([]*models.LearningPathResponse, error) {
+	paths, err := s.repo.List(ctx, publishedOnly, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.LearningPathResponse, len(paths))
+	for i, path := range paths {
+		responses[i] = path.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *learningPathService) UpdatePath(ctx context.Context, id uint, req *models.UpdateLearningPathRequest) (*models.LearningPathResponse, error) {
+	path, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		path.Name = *req.Name
+	}
+	if req.Description != nil {
+		path.Description = req.Description
+	}
+	if req.IsPublished != nil {
+		path.IsPublished = *req.IsPublished
+	}
+
+	if err := s.repo.Update(ctx, path); err != nil {
+		return nil, err
+	}
+
+	return path.ToResponse(), nil
+}
+
+func (s *learningPathService) DeletePath(ctx context.Context, id uint) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *learningPathService) SetCourses(ctx context.Context, id uint, req *models.SetLearningPathCoursesRequest) (*models.LearningPathResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	courseIDs := uniqueIDs(req.CourseIDs)
+	if err := s.validateCourses(ctx, courseIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceCourses(ctx, id, courseIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetPath(ctx, id)
+}
+
+func (s *learningPathService) Join(ctx context.Context, userID, pathID uint) (*models.LearningPathProgressResponse, error) {
+	path, err := s.repo.GetByID(ctx, pathID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !path.IsPublished {
+		return nil, ErrLearningPathNotPublish
+	}
+
+	enrollment, err := s.repo.GetEnrollment(ctx, pathID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if enrollment == nil {
+		enrollment = &models.LearningPathEnrollment{
+			LearningPathID: pathID,
+			UserID:         userID,
+			EnrolledAt:     time.Now(),
+		}
+		if err := s.repo.CreateEnrollment(ctx, enrollment); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.progress(ctx, path, enrollment)
+}
+
+func (s *learningPathService) GetProgress(ctx context.Context, userID, pathID uint) (*models.LearningPathProgressResponse, error) {
+	path, err := s.repo.GetByID(ctx, pathID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.repo.GetEnrollment(ctx, pathID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotJoinedLearningPath
+		}
+		return nil, err
+	}
+
+	return s.progress(ctx, path, enrollment)
+}
+
+func (s *learningPathService) GetUserPaths(ctx context.Context, userID uint) ([]*models.LearningPathProgressResponse, error) {
+	enrollments, err := s.repo.GetEnrollmentsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.LearningPathProgressResponse, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		path, err := s.repo.GetByID(ctx, enrollment.LearningPathID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		progress, err := s.progress(ctx, path, enrollment)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, progress)
+	}
+
+	return responses, nil
+}
+
+// progress builds the learner's progress through the path from their course
+// enrollments, and marks the path complete and issues its certificate once
+// every course has been completed.
+func (s *learningPathService) progress(ctx context.Context, path *models.LearningPath, enrollment *models.LearningPathEnrollment) (*models.LearningPathProgressResponse, error) {
+	courseIDs := make([]uint, len(path.Courses))
+	for i, pc := range path.Courses {
+		courseIDs[i] = pc.CourseID
+	}
+
+	courseEnrollments, err := s.enrollmentRepo.GetByUserAndCourses(ctx, enrollment.UserID, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byCourse := make(map[uint]*models.Enrollment, len(courseEnrollments))
+	for _, ce := range courseEnrollments {
+		byCourse[ce.CourseID] = ce
+	}
+
+	resp := &models.LearningPathProgressResponse{
+		LearningPath: path.ToResponse(),
+		EnrolledAt:   enrollment.EnrolledAt,
+		TotalCourses: len(path.Courses),
+		Courses:      make([]*models.LearningPathCourseProgress, len(path.Courses)),
+	}
+
+	for i, pc := range path.Courses {
+		item := &models.LearningPathCourseProgress{
+			Position: pc.Position,
+			Course:   pc.Course.ToResponse(),
+		}
+		if ce, ok := byCourse[pc.CourseID]; ok {
+			status := ce.CurrentStatus().String()
+			item.Status = &status
+			item.Completed = ce.CompletedAt != nil
+		}
+		if item.Completed {
+			resp.CompletedCourses++
+		}
+		resp.Courses[i] = item
+	}
+
+	resp.ProgressPercent = progressPercent(int64(resp.CompletedCourses), int64(resp.TotalCourses))
+
+	if resp.TotalCourses > 0 && resp.CompletedCourses == resp.TotalCourses && enrollment.CompletedAt == nil {
+		now := time.Now()
+		enrollment.CompletedAt = &now
+		if err := s.repo.UpdateEnrollment(ctx, enrollment); err != nil {
+			return nil, err
+		}
+	}
+	resp.CompletedAt = enrollment.CompletedAt
+
+	if enrollment.CompletedAt != nil {
+		certificate, err := s.issueCertificate(ctx, enrollment)
+		if err != nil {
+			return nil, err
+		}
+		resp.Certificate = certificate.ToResponse().SetDates(models.CalendarFromContext(ctx))
+	}
+
+	return resp, nil
+}
+
+func (s *learningPathService) issueCertificate(ctx context.Context, enrollment *models.LearningPathEnrollment) (*models.Certificate, error) {
+	certificate, err := s.certRepo.GetByUserAndPath(ctx, enrollment.UserID, enrollment.LearningPathID)
+	if err == nil {
+		return certificate, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	number, err := newCertificateNumber("LP", *enrollment.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	pathID := enrollment.LearningPathID
+	certificate = &models.Certificate{
+		Number:         number,
+		UserID:         enrollment.UserID,
+		LearningPathID: &pathID,
+		IssuedAt:       *enrollment.CompletedAt,
+	}
+	if err := s.certRepo.Create(ctx, certificate); err != nil {
+		return nil, err
+	}
+
+	return certificate, nil
+}
+
+func (s *learningPathService) validateCourses(ctx context.Context, courseIDs []uint) error {
+	courses, err := s.courseRepo.GetByIDs(ctx, courseIDs)
+	if err != nil {
+		return err
+	}
+	if len(courses) != len(courseIDs) {
+		return ErrInvalidPathCourse
+	}
+	return nil
+}
+
+// newCertificateNumber returns an identifier such as LP-20250101-9F3A1C.
+func newCertificateNumber(prefix string, issuedAt time.Time) (string, error) {
+	buf := make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s-%s", prefix, issuedAt.Format("20060102"), strings.ToUpper(hex.EncodeToString(buf))), nil
+}
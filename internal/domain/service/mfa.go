@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	recoveryCodeCount = 10
+	totpSkew          = 1
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFASetupMissing   = errors.New("two-factor setup has not been started")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+	ErrMFARequired       = errors.New("two-factor authentication is required for this account")
+)
+
+type MFAService interface {
+	Setup(ctx context.Context, userID uint) (*models.TOTPSetupResponse, error)
+	Confirm(ctx context.Context, userID uint, code string) ([]string, error)
+	Disable(ctx context.Context, userID uint, req *models.DisableTOTPRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error)
+	// Verify checks a TOTP code, or a recovery code when code is empty.
+	Verify(ctx context.Context, user *models.User, code, recoveryCode string) error
+	// Required reports whether the user's role must use two-factor login.
+	Required(user *models.User) bool
+}
+
+type mfaService struct {
+	repo     repository.MFARepository
+	userRepo repository.UserRepository
+	cfg      *config.MFAConfig
+}
+
+func NewMFAService(repo repository.MFARepository, userRepo repository.UserRepository, cfg *config.MFAConfig) MFAService {
+	return &mfaService{
+		repo:     repo,
+		userRepo: userRepo,
+		cfg:      cfg,
+	}
+}
+
+// Setup generates a new secret for the user. It only takes effect once a
+// code from it is confirmed, so calling Setup again restarts enrollment.
+func (s *mfaService) Setup(ctx context.Context, userID uint) (*models.TOTPSetupResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsMFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = &secret
+	user.TOTPLastStep = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: util.TOTPProvisioningURI(s.cfg.Issuer, user.Nip, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user proves the
+// authenticator works, and returns a fresh set of recovery codes.
+func (s *mfaService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsMFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrMFASetupMissing
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID uint, req *models.DisableTOTPRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsMFAEnabled() {
+		return ErrMFANotEnabled
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return ErrInvalidCurrentPassword
+	}
+
+	if err := s.Verify(ctx, user, req.Code, ""); err != nil {
+		return err
+	}
+
+	if s.Required(user) {
+		return ErrMFARequired
+	}
+
+	user.TOTPSecret = nil
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteRecoveryCodes(ctx, user.ID)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsMFAEnabled() {
+		return nil, ErrMFANotEnabled
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+func (s *mfaService) Verify(ctx context.Context, user *models.User, code, recoveryCode string) error {
+	if !user.IsMFAEnabled() {
+		return ErrMFANotEnabled
+	}
+
+	if code != "" {
+		return s.verifyTOTP(ctx, user, code)
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, user.ID, util.HashToken(normalizeRecoveryCode(recoveryCode)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *mfaService) Required(user *models.User) bool {
+	for _, role := range s.cfg.RequiredRoles {
+		if user.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyTOTP accepts a code once; replaying a code from an already used time
+// step fails.
+func (s *mfaService) verifyTOTP(ctx context.Context, user *models.User, code string) error {
+	if user.TOTPSecret == nil {
+		return ErrMFASetupMissing
+	}
+
+	step, ok := util.ValidateTOTP(*user.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	claimed, err := s.repo.ClaimStep(ctx, user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrInvalidMFACode
+	}
+
+	user.TOTPLastStep = &step
+	return nil
+}
+
+func (s *mfaService) issueRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = util.HashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// newRecoveryCode returns a code such as ABCDE-FGHIJ.
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
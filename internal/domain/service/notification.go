@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+)
+
+type NotificationService interface {
+	Notify(ctx context.Context, userID uint, notificationType, title, body string) error
+	GetUserNotifications(ctx context.Context, userID uint, unreadOnly bool, offset, limit int) ([]*models.NotificationResponse, error)
+	MarkRead(ctx context.Context, userID, id uint) error
+	MarkAllRead(ctx context.Context, userID uint) error
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+}
+
+func NewNotificationService(repo repository.NotificationRepository) NotificationService {
+	return &notificationService{repo: repo}
+}
+
+func (s *notificationService) Notify(ctx context.Context, userID uint, notificationType, title, body string) error {
+	return s.repo.Create(ctx, &models.Notification{
+		UserID: userID,
+		Type:   notificationType,
+		Title:  title,
+		Body:   body,
+	})
+}
+
+func (s *notificationService) GetUserNotifications(ctx context.Context, userID uint, unreadOnly bool, offset, limit int) ([]*models.NotificationResponse, error) {
+	notifications, err := s.repo.ListByUser(ctx, userID, unreadOnly, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		responses[i] = notification.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, id uint) error {
+	return s.repo.MarkRead(ctx, id, userID)
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID uint) error {
+	return s.repo.MarkAllRead(ctx, userID)
+}
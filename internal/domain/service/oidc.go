@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/oidc"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidOIDCState     = errors.New("invalid or expired sign-in request, start again")
+	ErrOIDCProviderError    = errors.New("identity provider rejected the sign-in")
+	ErrOIDCAccountNotFound  = errors.New("no account is linked to this identity")
+	ErrOIDCMissingClaim     = errors.New("identity provider did not supply a required claim")
+	ErrOIDCIdentityLinked   = errors.New("identity is already linked to another account")
+	ErrOIDCIdentityNotFound = errors.New("no identity from this provider is linked")
+	ErrOIDCProviderLinked   = errors.New("account is already linked to a different identity at this provider")
+)
+
+type OIDCService interface {
+	// AuthorizationURL starts a sign-in, or links the provider account to
+	// linkUserID when it is set. It returns the URL and the state parameter,
+	// which the caller binds to the browser so the callback can check that
+	// the same browser started the flow.
+	AuthorizationURL(ctx context.Context, linkUserID *uint) (string, string, error)
+	Callback(ctx context.Context, req *models.OIDCCallbackRequest, client *models.ClientInfo) (*models.OIDCCallbackResponse, error)
+	GetIdentities(ctx context.Context, userID uint) ([]*models.UserIdentity, error)
+	Unlink(ctx context.Context, userID uint) error
+}
+
+type oidcService struct {
+	provider     *oidc.Provider
+	identityRepo repository.UserIdentityRepository
+	userRepo     repository.UserRepository
+	tenantRepo   repository.TenantRepository
+	auth         AuthService
+	security     SecurityService
+	cfg          *config.OIDCConfig
+}
+
+func NewOIDCService(provider *oidc.Provider, identityRepo repository.UserIdentityRepository, userRepo repository.UserRepository, tenantRepo repository.TenantRepository, auth AuthService, security SecurityService, cfg *config.OIDCConfig) OIDCService {
+	return &oidcService{
+		provider:     provider,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		tenantRepo:   tenantRepo,
+		auth:         auth,
+		security:     security,
+		cfg:          cfg,
+	}
+}
+
+func (s *oidcService) AuthorizationURL(ctx context.Context, linkUserID *uint) (string, string, error) {
+	state, err := util.RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := util.RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := util.RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.identityRepo.DeleteExpiredStates(ctx); err != nil {
+		return "", "", err
+	}
+
+	if err := s.identityRepo.CreateState(ctx, &models.OIDCLoginState{
+		StateHash:    util.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(s.cfg.StateExpiry),
+	}); err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// Callback finishes an authorization request. The returning user is found by
+// linked identity first and then, if enabled, by NIP; unknown users are
+// provisioned when auto-provisioning is on. The result goes through the same
+// account checks as a password login, including MFA.
+func (s *oidcService) Callback(ctx context.Context, req *models.OIDCCallbackRequest, client *models.ClientInfo) (*models.OIDCCallbackResponse, error) {
+	if req.State == "" {
+		return nil, ErrInvalidOIDCState
+	}
+
+	state, err := s.identityRepo.ConsumeState(ctx, util.HashToken(req.State))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+	if time.Now().After(state.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	// The provider redirects to one callback URL for every tenant, without
+	// the tenant header the flow may have started with, so the rest of the
+	// callback runs in the tenant that started it.
+	ctx, err = s.restoreTenant(ctx, state.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrOIDCProviderError, req.Error, req.ErrorDescription)
+	}
+	if req.Code == "" {
+		return nil, ErrInvalidOIDCState
+	}
+
+	token, err := s.provider.Exchange(ctx, req.Code, state.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCProviderError, err)
+	}
+
+	claims, err := s.provider.VerifyIDToken(ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCProviderError, err)
+	}
+
+	subject := claims.String("sub")
+	if subject == "" {
+		return nil, fmt.Errorf("%w: sub", ErrOIDCMissingClaim)
+	}
+
+	if state.LinkUserID != nil {
+		identity, err := s.link(ctx, *state.LinkUserID, subject, claims, client)
+		if err != nil {
+			return nil, err
+		}
+		return &models.OIDCCallbackResponse{Identity: identity}, nil
+	}
+
+	user, identity, err := s.resolveUser(ctx, subject, claims, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.security.CheckLogin(ctx, user, client); err != nil {
+		return nil, err
+	}
+
+	if err := s.syncUser(ctx, user, claims); err != nil {
+		return nil, err
+	}
+
+	if err := s.identityRepo.TouchLogin(ctx, identity.ID, optionalString(claims.String(s.cfg.EmailClaim))); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.auth.CompleteLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCCallbackResponse{Tokens: tokens}, nil
+}
+
+// restoreTenant scopes ctx to the tenant a login state belongs to.
+func (s *oidcService) restoreTenant(ctx context.Context, id uint) (context.Context, error) {
+	if id == tenant.ID(ctx) {
+		return ctx, nil
+	}
+
+	t, err := s.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsActive {
+		return nil, ErrTenantInactive
+	}
+	return models.ContextWithTenant(tenant.WithID(ctx, t.ID), t), nil
+}
+
+func (s *oidcService) GetIdentities(ctx context.Context, userID uint) ([]*models.UserIdentity, error) {
+	return s.identityRepo.GetByUserID(ctx, userID)
+}
+
+func (s *oidcService) Unlink(ctx context.Context, userID uint) error {
+	deleted, err := s.identityRepo.Delete(ctx, userID, s.cfg.Provider)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrOIDCIdentityNotFound
+	}
+
+	s.security.LogEvent(ctx, &models.SecurityEvent{
+		UserID:  &userID,
+		ActorID: &userID,
+		Type:    constants.SecurityEventSSOUnlinked,
+		Details: &s.cfg.Provider,
+	})
+
+	return nil
+}
+
+func (s *oidcService) link(ctx context.Context, userID uint, subject string, claims oidc.Claims, client *models.ClientInfo) (*models.UserIdentity, error) {
+	existing, err := s.identityRepo.GetBySubject(ctx, s.cfg.Provider, subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.UserID != userID {
+			return nil, ErrOIDCIdentityLinked
+		}
+		return existing, nil
+	}
+
+	return s.createIdentity(ctx, userID, subject, claims, client)
+}
+
+func (s *oidcService) resolveUser(ctx context.Context, subject string, claims oidc.Claims, client *models.ClientInfo) (*models.User, *models.UserIdentity, error) {
+	identity, err := s.identityRepo.GetBySubject(ctx, s.cfg.Provider, subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return user, identity, nil
+	}
+
+	nip := claims.String(s.cfg.NipClaim)
+
+	var user *models.User
+	if s.cfg.LinkByNip && nip != "" {
+		user, err = s.userRepo.GetByNip(ctx, nip)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+	}
+
+	if user == nil {
+		if !s.cfg.AutoProvision {
+			return nil, nil, ErrOIDCAccountNotFound
+		}
+		if user, err = s.provision(ctx, nip, claims); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	identity, err = s.createIdentity(ctx, user.ID, subject, claims, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, identity, nil
+}
+
+// provision creates a local account for a first-time SSO user. The account
+// gets an unusable random password; a local password can be set later
+// through the password reset flow.
+func (s *oidcService) provision(ctx context.Context, nip string, claims oidc.Claims) (*models.User, error) {
+	email := claims.String(s.cfg.EmailClaim)
+	name := claims.String(s.cfg.NameClaim)
+	if nip == "" {
+		return nil, fmt.Errorf("%w: %s", ErrOIDCMissingClaim, s.cfg.NipClaim)
+	}
+	if email == "" {
+		return nil, fmt.Errorf("%w: %s", ErrOIDCMissingClaim, s.cfg.EmailClaim)
+	}
+	if name == "" {
+		name = nip
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	password, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	role, ok := s.mappedRole(claims)
+	if !ok {
+		role = s.cfg.DefaultRole
+	}
+
+	user := &models.User{
+		Name:       name,
+		Email:      email,
+		Nip:        nip,
+		Role:       role,
+		Password:   string(password),
+		IsActive:   true,
+		AuthSource: constants.AuthSourceOIDC,
+	}
+	if claims.Bool("email_verified") {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// syncUser copies the display name and, when the token carries the groups
+// claim, the mapped role from the provider on every login.
+func (s *oidcService) syncUser(ctx context.Context, user *models.User, claims oidc.Claims) error {
+	changed := false
+
+	if name := claims.String(s.cfg.NameClaim); name != "" && name != user.Name {
+		user.Name = name
+		changed = true
+	}
+
+	if _, present := claims[s.cfg.GroupsClaim]; present && len(s.cfg.RoleMapping) > 0 {
+		role, ok := s.mappedRole(claims)
+		if !ok {
+			role = s.cfg.DefaultRole
+		}
+		if role != user.Role {
+			user.Role = role
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *oidcService) mappedRole(claims oidc.Claims) (string, bool) {
+	return mappedRole(claims.Strings(s.cfg.GroupsClaim), s.cfg.RoleMapping)
+}
+
+// mappedRole returns the most privileged role mapped from the user's groups.
+// Group names are compared case-insensitively since configuration keys are
+// lower-cased when loaded.
+func mappedRole(groups []string, mapping map[string]string) (string, bool) {
+	best := -1
+	for _, group := range groups {
+		role, ok := mapping[strings.ToLower(group)]
+		if !ok || !constants.IsValidRole(role) {
+			continue
+		}
+		if rank := roleRank(role); rank > best {
+			best = rank
+		}
+	}
+	if best < 0 {
+		return "", false
+	}
+	return constants.AllRoles[best].String(), true
+}
+
+// roleRank orders roles by privilege, following constants.AllRoles.
+func roleRank(role string) int {
+	for i, r := range constants.AllRoles {
+		if r.String() == role {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *oidcService) createIdentity(ctx context.Context, userID uint, subject string, claims oidc.Claims, client *models.ClientInfo) (*models.UserIdentity, error) {
+	identities, err := s.identityRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range identities {
+		if existing.Provider == s.cfg.Provider {
+			return nil, ErrOIDCProviderLinked
+		}
+	}
+
+	identity := &models.UserIdentity{
+		UserID:   userID,
+		Provider: s.cfg.Provider,
+		Subject:  subject,
+		Email:    optionalString(claims.String(s.cfg.EmailClaim)),
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	event := &models.SecurityEvent{
+		UserID:  &userID,
+		Type:    constants.SecurityEventSSOLinked,
+		Details: &s.cfg.Provider,
+	}
+	if client != nil {
+		event.IPAddress = optionalString(client.IP)
+		event.UserAgent = optionalString(client.UserAgent)
+	}
+	s.security.LogEvent(ctx, event)
+
+	return identity, nil
+}
@@ -0,0 +1,768 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// mandatoryCourseNotEnrolled is reported for an assigned course the member
+// has no enrollment in, for instance after joining while inactive.
+const mandatoryCourseNotEnrolled = "not_enrolled"
+
+var (
+	ErrOrgUnitForbidden      = errors.New("you do not administer this unit")
+	ErrOrgUnitNotEmpty       = errors.New("unit still has sub-units or members")
+	ErrOrgUnitCycle          = errors.New("a unit cannot be moved below itself")
+	ErrOrgUnitCodeTaken      = errors.New("unit code is already in use")
+	ErrOrgUnitCourseAssigned = errors.New("course is already assigned to this unit")
+	ErrNotUnitMember         = errors.New("user is not a member of this unit")
+	ErrOrgUnitOutsideUser    = errors.New("only administrators can add users who are not in a unit you administer")
+	ErrOrgUnitRestricted     = errors.New("only administrators can assign courses that are not open for enrollment")
+)
+
+// OrgUnitService manages the organization tree. Structural changes and unit
+// admin appointments are for global administrators; the scoped methods take
+// the acting user and also allow admins of the unit or any of its ancestors.
+type OrgUnitService interface {
+	CreateUnit(ctx context.Context, req *models.CreateOrgUnitRequest) (*models.OrgUnitResponse, error)
+	// GetTree returns the top-level units with their sub-units nested.
+	GetTree(ctx context.Context) ([]*models.OrgUnitResponse, error)
+	GetUnit(ctx context.Context, id uint) (*models.OrgUnitResponse, error)
+	UpdateUnit(ctx context.Context, id uint, req *models.UpdateOrgUnitRequest) (*models.OrgUnitResponse, error)
+	MoveUnit(ctx context.Context, id uint, parentID *uint) (*models.OrgUnitResponse, error)
+	// DeleteUnit removes a unit that has no sub-units and no members.
+	DeleteUnit(ctx context.Context, id uint) error
+	GetAdmins(ctx context.Context, unitID uint) ([]*models.UserResponse, error)
+	AddAdmin(ctx context.Context, actorID, unitID, userID uint) error
+	RemoveAdmin(ctx context.Context, actorID, unitID, userID uint) error
+
+	// GetManagedUnits returns the subtrees the user administers, or the whole
+	// tree for a global administrator.
+	GetManagedUnits(ctx context.Context, actorID uint) ([]*models.OrgUnitResponse, error)
+	// GetMembers lists the members of the unit and its sub-units.
+	GetMembers(ctx context.Context, actorID, unitID uint, offset, limit int) ([]*models.UserResponse, int64, error)
+	// AddMember moves a user into the unit and enrolls them in its mandatory
+	// courses. Unit admins can only move users between units they
+	// administer; users without a unit are placed by global administrators.
+	AddMember(ctx context.Context, actorID, unitID, userID uint) (*models.UserResponse, error)
+	RemoveMember(ctx context.Context, actorID, unitID, userID uint) error
+	// GetCourses returns the unit's mandatory courses, including those
+	// assigned to ancestors for their sub-units.
+	GetCourses(ctx context.Context, actorID, unitID uint) ([]*models.OrgUnitCourseResponse, error)
+	// AssignCourse makes a course mandatory for the unit and enrolls its
+	// current active members. Large units are enrolled by a background job.
+	// Unit admins can only assign open courses, since the enrollment skips
+	// the course's approval or invitation.
+	AssignCourse(ctx context.Context, actorID, unitID uint, req *models.AssignUnitCourseRequest) (*models.OrgUnitCourseAssignmentResponse, error)
+	// UnassignCourse stops enrolling new members. Existing enrollments stay.
+	UnassignCourse(ctx context.Context, actorID, unitID, courseID uint) error
+	GetReport(ctx context.Context, actorID, unitID uint) (*models.OrgUnitReport, error)
+}
+
+type orgUnitService struct {
+	repo           repository.OrgUnitRepository
+	userRepo       repository.UserRepository
+	courseRepo     repository.CourseRepository
+	enrollmentRepo repository.EnrollmentRepository
+	bulk           BulkEnrollmentService
+	security       SecurityService
+}
+
+func NewOrgUnitService(repo repository.OrgUnitRepository, userRepo repository.UserRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, bulk BulkEnrollmentService, security SecurityService) OrgUnitService {
+	return &orgUnitService{
+		repo:           repo,
+		userRepo:       userRepo,
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+		bulk:           bulk,
+		security:       security,
+	}
+}
+
+func (s *orgUnitService) CreateUnit(ctx context.Context, req *models.CreateOrgUnitRequest) (*models.OrgUnitResponse, error) {
+	code := strings.TrimSpace(req.Code)
+	if err := s.checkCodeAvailable(ctx, 0, code); err != nil {
+		return nil, err
+	}
+	if req.ParentID != nil {
+		if _, err := s.repo.GetByID(ctx, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	unit := &models.OrgUnit{
+		ParentID: req.ParentID,
+		Name:     strings.TrimSpace(req.Name),
+		Code:     code,
+	}
+	if err := s.repo.Create(ctx, unit); err != nil {
+		return nil, err
+	}
+
+	return unit.ToResponse(), nil
+}
+
+func (s *orgUnitService) GetTree(ctx context.Context) ([]*models.OrgUnitResponse, error) {
+	units, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []uint
+	for _, unit := range units {
+		if unit.ParentID == nil {
+			roots = append(roots, unit.ID)
+		}
+	}
+
+	return buildOrgTree(units, roots), nil
+}
+
+func (s *orgUnitService) GetUnit(ctx context.Context, id uint) (*models.OrgUnitResponse, error) {
+	unit, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return unit.ToResponse(), nil
+}
+
+func (s *orgUnitService) UpdateUnit(ctx context.Context, id uint, req *models.UpdateOrgUnitRequest) (*models.OrgUnitResponse, error) {
+	unit, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		unit.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Code != nil {
+		code := strings.TrimSpace(*req.Code)
+		if err := s.checkCodeAvailable(ctx, unit.ID, code); err != nil {
+			return nil, err
+		}
+		unit.Code = code
+	}
+
+	if err := s.repo.Update(ctx, unit); err != nil {
+		return nil, err
+	}
+
+	return unit.ToResponse(), nil
+}
+
+// MoveUnit changes the unit's parent. Mandatory courses inherited from the
+// new ancestors apply to members who join afterwards; existing members are
+// not enrolled retroactively.
+func (s *orgUnitService) MoveUnit(ctx context.Context, id uint, parentID *uint) (*models.OrgUnitResponse, error) {
+	unit, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		if _, err := s.repo.GetByID(ctx, *parentID); err != nil {
+			return nil, err
+		}
+		subtree, err := s.repo.SubtreeIDs(ctx, unit.ID)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(subtree, *parentID) {
+			return nil, ErrOrgUnitCycle
+		}
+	}
+
+	unit.ParentID = parentID
+	if err := s.repo.Update(ctx, unit); err != nil {
+		return nil, err
+	}
+
+	return unit.ToResponse(), nil
+}
+
+func (s *orgUnitService) DeleteUnit(ctx context.Context, id uint) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	children, err := s.repo.CountChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	members, err := s.repo.CountMembers(ctx, []uint{id})
+	if err != nil {
+		return err
+	}
+	if children > 0 || members > 0 {
+		return ErrOrgUnitNotEmpty
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *orgUnitService) GetAdmins(ctx context.Context, unitID uint) ([]*models.UserResponse, error) {
+	if _, err := s.repo.GetByID(ctx, unitID); err != nil {
+		return nil, err
+	}
+
+	admins, err := s.repo.ListAdmins(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.UserResponse, 0, len(admins))
+	for _, admin := range admins {
+		if admin.User.ID != 0 {
+			responses = append(responses, admin.User.ToResponse())
+		}
+	}
+
+	return responses, nil
+}
+
+func (s *orgUnitService) AddAdmin(ctx context.Context, actorID, unitID, userID uint) error {
+	unit, err := s.repo.GetByID(ctx, unitID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.AuthSource == constants.AuthSourceService {
+		return ErrServiceAccountRole
+	}
+
+	if err := s.repo.AddAdmin(ctx, &models.OrgUnitAdmin{OrgUnitID: unit.ID, UserID: user.ID}); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventUnitAdminAdded, "unit "+unit.Code)
+	return nil
+}
+
+func (s *orgUnitService) RemoveAdmin(ctx context.Context, actorID, unitID, userID uint) error {
+	unit, err := s.repo.GetByID(ctx, unitID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveAdmin(ctx, unit.ID, userID); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, actorID, userID, constants.SecurityEventUnitAdminRemoved, "unit "+unit.Code)
+	return nil
+}
+
+func (s *orgUnitService) GetManagedUnits(ctx context.Context, actorID uint) ([]*models.OrgUnitResponse, error) {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.IsAdmin() {
+		return s.GetTree(ctx)
+	}
+
+	adminOf, err := s.repo.AdminUnitIDs(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if len(adminOf) == 0 {
+		return []*models.OrgUnitResponse{}, nil
+	}
+
+	units, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A unit below another administered unit already appears in that subtree.
+	parents := orgParents(units)
+	administered := make(map[uint]bool, len(adminOf))
+	for _, id := range adminOf {
+		administered[id] = true
+	}
+	var roots []uint
+	for _, id := range adminOf {
+		nested := false
+		for parent := parents[id]; parent != nil; parent = parents[*parent] {
+			if administered[*parent] {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			roots = append(roots, id)
+		}
+	}
+
+	return buildOrgTree(units, roots), nil
+}
+
+func (s *orgUnitService) GetMembers(ctx context.Context, actorID, unitID uint, offset, limit int) ([]*models.UserResponse, int64, error) {
+	if err := s.authorize(ctx, actorID, unitID); err != nil {
+		return nil, 0, err
+	}
+
+	subtree, err := s.repo.SubtreeIDs(ctx, unitID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountMembers(ctx, subtree)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, err := s.repo.ListMembers(ctx, subtree, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*models.UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = user.ToResponse()
+	}
+
+	return responses, total, nil
+}
+
+func (s *orgUnitService) AddMember(ctx context.Context, actorID, unitID, userID uint) (*models.UserResponse, error) {
+	if err := s.authorize(ctx, actorID, unitID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrgUnitID != nil && *user.OrgUnitID == unitID {
+		return user.ToResponse(), nil
+	}
+	if user.OrgUnitID == nil {
+		if err := s.requireGlobalAdmin(ctx, actorID, ErrOrgUnitOutsideUser); err != nil {
+			return nil, err
+		}
+	} else if err := s.authorize(ctx, actorID, *user.OrgUnitID); err != nil {
+		if errors.Is(err, ErrOrgUnitForbidden) {
+			return nil, ErrOrgUnitOutsideUser
+		}
+		return nil, err
+	}
+
+	if err := s.repo.SetUserUnit(ctx, user.ID, &unitID); err != nil {
+		return nil, err
+	}
+	user.OrgUnitID = &unitID
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventOrgUnitChanged, fmt.Sprintf("joined unit %d", unitID))
+
+	if user.IsActive {
+		s.enrollMandatory(ctx, actorID, user, unitID)
+	}
+
+	return user.ToResponse(), nil
+}
+
+func (s *orgUnitService) RemoveMember(ctx context.Context, actorID, unitID, userID uint) error {
+	if err := s.authorize(ctx, actorID, unitID); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.OrgUnitID == nil {
+		return ErrNotUnitMember
+	}
+
+	subtree, err := s.repo.SubtreeIDs(ctx, unitID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(subtree, *user.OrgUnitID) {
+		return ErrNotUnitMember
+	}
+
+	if err := s.repo.SetUserUnit(ctx, user.ID, nil); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventOrgUnitChanged, fmt.Sprintf("left unit %d", *user.OrgUnitID))
+	return nil
+}
+
+func (s *orgUnitService) GetCourses(ctx context.Context, actorID, unitID uint) ([]*models.OrgUnitCourseResponse, error) {
+	if err := s.authorize(ctx, actorID, unitID); err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.applicableCourses(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.OrgUnitCourseResponse, len(assignments))
+	for i, assignment := range assignments {
+		responses[i] = assignment.ToResponse(unitID)
+	}
+
+	return responses, nil
+}
+
+func (s *orgUnitService) AssignCourse(ctx context.Context, actorID, unitID uint, req *models.AssignUnitCourseRequest) (*models.OrgUnitCourseAssignmentResponse, error) {
+	if err := s.authorize(ctx, actorID, unitID); err != nil {
+		return nil, err
+	}
+
+	course, err := s.courseRepo.GetByID(ctx, req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if course.EnrollmentMode != constants.EnrollmentModeOpen.String() {
+		if err := s.requireGlobalAdmin(ctx, actorID, ErrOrgUnitRestricted); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.repo.GetCourse(ctx, unitID, course.ID); err == nil {
+		return nil, ErrOrgUnitCourseAssigned
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	assignment := &models.OrgUnitCourse{
+		OrgUnitID:       unitID,
+		CourseID:        course.ID,
+		IncludeSubUnits: req.IncludeSubUnits == nil || *req.IncludeSubUnits,
+		DueInDays:       req.DueInDays,
+		AssignedBy:      &actorID,
+	}
+	if err := s.repo.CreateCourse(ctx, assignment); err != nil {
+		return nil, err
+	}
+	assignment.Course = *course
+
+	unitIDs := []uint{unitID}
+	if assignment.IncludeSubUnits {
+		if unitIDs, err = s.repo.SubtreeIDs(ctx, unitID); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := s.repo.ListActiveMembers(ctx, unitIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.OrgUnitCourseAssignmentResponse{Assignment: assignment.ToResponse(unitID)}
+	if len(members) == 0 {
+		return response, nil
+	}
+
+	bulkReq := &models.BulkEnrollmentRequest{
+		Nips:  make([]string, len(members)),
+		DueAt: assignment.DueAt(time.Now()),
+	}
+	for i, member := range members {
+		bulkReq.Nips[i] = member.Nip
+	}
+
+	if len(bulkReq.Nips) > BulkEnrollmentSyncLimit {
+		response.Job, err = s.bulk.EnrollAsync(ctx, actorID, course.ID, bulkReq)
+	} else {
+		response.Report, err = s.bulk.Enroll(ctx, actorID, course.ID, bulkReq)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *orgUnitService) UnassignCourse(ctx context.Context, actorID, unitID, courseID uint) error {
+	if err := s.authorize(ctx, actorID, unitID); err != nil {
+		return err
+	}
+
+	assignment, err := s.repo.GetCourse(ctx, unitID, courseID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteCourse(ctx, assignment.ID)
+}
+
+func (s *orgUnitService) GetReport(ctx context.Context, actorID, unitID uint) (*models.OrgUnitReport, error) {
+	if err := s.authorize(ctx, actorID, unitID); err != nil {
+		return nil, err
+	}
+
+	units, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parents := orgParents(units)
+
+	subtree, err := s.repo.SubtreeIDs(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := s.repo.AncestorIDs(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.repo.ListCourses(ctx, append(subtree, ancestors...))
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListActiveMembers(ctx, subtree)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint, len(members))
+	for i, member := range members {
+		userIDs[i] = member.ID
+	}
+	courseIDs := make([]uint, 0, len(assignments))
+	for _, assignment := range assignments {
+		courseIDs = append(courseIDs, assignment.CourseID)
+	}
+
+	enrollments, err := s.enrollmentRepo.ListByUsersAndCourses(ctx, userIDs, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+	type enrollmentKey struct{ userID, courseID uint }
+	byKey := make(map[enrollmentKey]*models.Enrollment, len(enrollments))
+	for _, enrollment := range enrollments {
+		byKey[enrollmentKey{enrollment.UserID, enrollment.CourseID}] = enrollment
+	}
+
+	calendar := models.CalendarFromContext(ctx)
+	report := &models.OrgUnitReport{
+		OrgUnitID: unitID,
+		Members:   len(members),
+		Users:     make([]*models.OrgUnitMemberReport, len(members)),
+	}
+	for i, member := range members {
+		memberReport := &models.OrgUnitMemberReport{
+			User:    member.ToResponse(),
+			Courses: []models.MandatoryCourseStatus{},
+		}
+
+		seen := make(map[uint]bool)
+		for _, assignment := range mandatoryFor(*member.OrgUnitID, parents, assignments) {
+			if seen[assignment.CourseID] {
+				continue
+			}
+			seen[assignment.CourseID] = true
+
+			status := models.MandatoryCourseStatus{
+				CourseID:   assignment.CourseID,
+				CourseName: assignment.Course.Name,
+				Status:     mandatoryCourseNotEnrolled,
+			}
+			if enrollment, ok := byKey[enrollmentKey{member.ID, assignment.CourseID}]; ok {
+				status.Status = enrollment.CurrentStatus().String()
+				status.DueAt = enrollment.DueAt
+				status.CompletedAt = enrollment.CompletedAt
+				status.Overdue = enrollment.IsOverdue()
+			}
+
+			memberReport.Assigned++
+			if status.CompletedAt != nil {
+				memberReport.Completed++
+			}
+			if status.Overdue {
+				memberReport.Overdue++
+			}
+			status.SetHijri(calendar)
+			memberReport.Courses = append(memberReport.Courses, status)
+		}
+
+		report.Assigned += memberReport.Assigned
+		report.Completed += memberReport.Completed
+		report.Overdue += memberReport.Overdue
+		report.Users[i] = memberReport
+	}
+
+	return report, nil
+}
+
+// authorize allows global administrators and admins of the unit or one of
+// its ancestors.
+func (s *orgUnitService) authorize(ctx context.Context, actorID, unitID uint) error {
+	ancestors, err := s.repo.AncestorIDs(ctx, unitID)
+	if err != nil {
+		return err
+	}
+	if len(ancestors) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if actor.IsAdmin() {
+		return nil
+	}
+
+	ok, err := s.repo.IsAdminOf(ctx, actorID, ancestors)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOrgUnitForbidden
+	}
+	return nil
+}
+
+// requireGlobalAdmin returns denied unless the actor is a global
+// administrator.
+func (s *orgUnitService) requireGlobalAdmin(ctx context.Context, actorID uint, denied error) error {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.IsAdmin() {
+		return denied
+	}
+	return nil
+}
+
+// applicableCourses returns the unit's own assignments and those of its
+// ancestors that include sub-units.
+func (s *orgUnitService) applicableCourses(ctx context.Context, unitID uint) ([]*models.OrgUnitCourse, error) {
+	ancestors, err := s.repo.AncestorIDs(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.repo.ListCourses(ctx, ancestors)
+	if err != nil {
+		return nil, err
+	}
+
+	applicable := assignments[:0]
+	for _, assignment := range assignments {
+		if assignment.OrgUnitID == unitID || assignment.IncludeSubUnits {
+			applicable = append(applicable, assignment)
+		}
+	}
+	return applicable, nil
+}
+
+// enrollMandatory enrolls a new member in the unit's mandatory courses.
+// Failures are logged; the membership change itself has already been saved.
+func (s *orgUnitService) enrollMandatory(ctx context.Context, actorID uint, user *models.User, unitID uint) {
+	assignments, err := s.applicableCourses(ctx, unitID)
+	if err != nil {
+		log.Printf("org unit: failed to load mandatory courses of unit %d: %v", unitID, err)
+		return
+	}
+
+	now := time.Now()
+	enrolled := make(map[uint]bool, len(assignments))
+	for _, assignment := range assignments {
+		if enrolled[assignment.CourseID] {
+			continue
+		}
+		enrolled[assignment.CourseID] = true
+
+		req := &models.BulkEnrollmentRequest{Nips: []string{user.Nip}, DueAt: assignment.DueAt(now)}
+		if _, err := s.bulk.Enroll(ctx, actorID, assignment.CourseID, req); err != nil {
+			log.Printf("org unit: failed to enroll user %d in mandatory course %d: %v", user.ID, assignment.CourseID, err)
+		}
+	}
+}
+
+func (s *orgUnitService) checkCodeAvailable(ctx context.Context, exceptID uint, code string) error {
+	existing, err := s.repo.GetByCode(ctx, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != exceptID {
+		return ErrOrgUnitCodeTaken
+	}
+	return nil
+}
+
+func (s *orgUnitService) logEvent(ctx context.Context, actorID, userID uint, eventType, details string) {
+	event := newSecurityEvent(&userID, eventType, models.ClientInfoFromContext(ctx), details)
+	if actorID != 0 {
+		event.ActorID = &actorID
+	}
+	s.security.LogEvent(ctx, event)
+}
+
+func orgParents(units []*models.OrgUnit) map[uint]*uint {
+	parents := make(map[uint]*uint, len(units))
+	for _, unit := range units {
+		parents[unit.ID] = unit.ParentID
+	}
+	return parents
+}
+
+// mandatoryFor picks the assignments that apply to a member of unitID: those
+// on the unit itself and those on an ancestor that include sub-units.
+func mandatoryFor(unitID uint, parents map[uint]*uint, assignments []*models.OrgUnitCourse) []*models.OrgUnitCourse {
+	chain := map[uint]bool{}
+	for parent := parents[unitID]; parent != nil; parent = parents[*parent] {
+		chain[*parent] = true
+	}
+
+	var applicable []*models.OrgUnitCourse
+	for _, assignment := range assignments {
+		if assignment.OrgUnitID == unitID || (chain[assignment.OrgUnitID] && assignment.IncludeSubUnits) {
+			applicable = append(applicable, assignment)
+		}
+	}
+	return applicable
+}
+
+func buildOrgTree(units []*models.OrgUnit, roots []uint) []*models.OrgUnitResponse {
+	children := make(map[uint][]*models.OrgUnit)
+	byID := make(map[uint]*models.OrgUnit, len(units))
+	for _, unit := range units {
+		byID[unit.ID] = unit
+		if unit.ParentID != nil {
+			children[*unit.ParentID] = append(children[*unit.ParentID], unit)
+		}
+	}
+
+	var build func(unit *models.OrgUnit) *models.OrgUnitResponse
+	build = func(unit *models.OrgUnit) *models.OrgUnitResponse {
+		resp := unit.ToResponse()
+		for _, child := range children[unit.ID] {
+			resp.Children = append(resp.Children, build(child))
+		}
+		return resp
+	}
+
+	tree := make([]*models.OrgUnitResponse, 0, len(roots))
+	for _, id := range roots {
+		if unit, ok := byID[id]; ok {
+			tree = append(tree, build(unit))
+		}
+	}
+	return tree
+}
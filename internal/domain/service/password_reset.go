@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/mailer"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidResetToken   = errors.New("invalid or expired reset token")
+	ErrTooManyResetRequest = errors.New("too many password reset requests, try again later")
+)
+
+type PasswordResetService interface {
+	Forgot(ctx context.Context, req *models.ForgotPasswordRequest) error
+	Reset(ctx context.Context, req *models.ResetPasswordRequest) error
+}
+
+type passwordResetService struct {
+	repo     repository.PasswordResetRepository
+	userRepo repository.UserRepository
+	auth     AuthService
+	mailer   mailer.Mailer
+	cfg      *config.PasswordResetConfig
+	limiter  *util.RateLimiter
+}
+
+func NewPasswordResetService(repo repository.PasswordResetRepository, userRepo repository.UserRepository, auth AuthService, mail mailer.Mailer, cfg *config.PasswordResetConfig) PasswordResetService {
+	return &passwordResetService{
+		repo:     repo,
+		userRepo: userRepo,
+		auth:     auth,
+		mailer:   mail,
+		cfg:      cfg,
+		limiter:  util.NewRateLimiter(cfg.MaxRequests, cfg.Window),
+	}
+}
+
+// Forgot emails a reset link to the account matching the email or NIP. It
+// succeeds whether or not an account exists so callers cannot probe for
+// registered users; the rate limit is applied per identifier for the same
+// reason.
+func (s *passwordResetService) Forgot(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	key := "nip:" + strings.TrimSpace(req.Nip)
+	if req.Email != "" {
+		key = "email:" + strings.ToLower(strings.TrimSpace(req.Email))
+	}
+	if !s.limiter.Allow(key) {
+		return ErrTooManyResetRequest
+	}
+
+	var (
+		user *models.User
+		err  error
+	)
+	if req.Email != "" {
+		user, err = s.userRepo.GetByEmail(ctx, strings.TrimSpace(req.Email))
+	} else {
+		user, err = s.userRepo.GetByNip(ctx, strings.TrimSpace(req.Nip))
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	token, err := util.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: util.HashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.TokenExpiry),
+	}); err != nil {
+		return err
+	}
+
+	// A failed send is only logged: answering differently would tell the
+	// caller that the account exists.
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nWe received a request to reset your password. "+
+			"Use the link below within %s to choose a new one:\n\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			user.Name, s.cfg.TokenExpiry, tokenLink(tenantURL(ctx, s.cfg.URL, func(t models.TenantSettings) string { return t.PasswordResetURL }), token)),
+	}); err != nil {
+		log.Printf("password reset: failed to send reset link to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// Reset sets a new password using a reset token. The token is consumed, any
+// other outstanding tokens for the user are invalidated and every refresh
+// token is revoked so existing sessions must sign in again.
+func (s *passwordResetService) Reset(ctx context.Context, req *models.ResetPasswordRequest) error {
+	token, err := s.repo.GetByHash(ctx, util.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err := util.ValidatePassword(req.Password, user.Nip, user.Email, user.Name); err != nil {
+		return err
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// The token is consumed together with the password change, so a failed
+	// update leaves it usable.
+	consumed, err := s.repo.ResetPassword(ctx, token, string(hashPassword))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.auth.RevokeUserTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Body: fmt.Sprintf("Hello %s,\n\nThe password for your account was just reset. "+
+			"If this was not you, contact an administrator immediately.\n", user.Name),
+	}); err != nil {
+		log.Printf("password reset: failed to send confirmation to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// tokenLink appends the token to a frontend URL as the token query parameter.
+func tokenLink(base, token string) string {
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+	ErrEmailTaken             = errors.New("email is already in use")
+)
+
+type ProfileService interface {
+	GetProfile(ctx context.Context, userID uint) (*models.ProfileResponse, error)
+	UpdateProfile(ctx context.Context, userID uint, req *models.UpdateProfileRequest) (*models.ProfileResponse, error)
+	ChangePassword(ctx context.Context, userID uint, req *models.ChangePasswordRequest) error
+}
+
+type profileService struct {
+	userRepo     repository.UserRepository
+	verification EmailVerificationService
+	auth         AuthService
+}
+
+func NewProfileService(userRepo repository.UserRepository, verification EmailVerificationService, auth AuthService) ProfileService {
+	return &profileService{
+		userRepo:     userRepo,
+		verification: verification,
+		auth:         auth,
+	}
+}
+
+func (s *profileService) GetProfile(ctx context.Context, userID uint) (*models.ProfileResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.ToProfileResponse(), nil
+}
+
+// UpdateProfile applies the provided fields. A new email address is kept as
+// pending until the link sent to it is confirmed; empty optional fields are
+// cleared.
+func (s *profileService) UpdateProfile(ctx context.Context, userID uint, req *models.UpdateProfileRequest) (*models.ProfileResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		user.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.AvatarURL != nil {
+		user.AvatarURL = optionalString(*req.AvatarURL)
+	}
+	if req.Phone != nil {
+		user.Phone = optionalString(*req.Phone)
+	}
+	if req.Bio != nil {
+		user.Bio = optionalString(*req.Bio)
+	}
+
+	var newEmail string
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		switch {
+		case email == "" || strings.EqualFold(email, user.Email):
+			user.PendingEmail = nil
+		default:
+			existing, err := s.userRepo.GetByEmail(ctx, email)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if existing != nil {
+				return nil, ErrEmailTaken
+			}
+			user.PendingEmail = &email
+			newEmail = email
+		}
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if newEmail != "" {
+		if err := s.verification.Send(ctx, user, newEmail); err != nil {
+			return nil, err
+		}
+	}
+
+	return user.ToProfileResponse(), nil
+}
+
+// ChangePassword replaces the password after checking the current one and
+// revokes every refresh token issued to the user.
+func (s *profileService) ChangePassword(ctx context.Context, userID uint, req *models.ChangePasswordRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return ErrInvalidCurrentPassword
+	}
+
+	if err := util.ValidatePassword(req.NewPassword, user.Nip, user.Email, user.Name); err != nil {
+		return err
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.Password = string(hashPassword)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return s.auth.RevokeUserTokens(ctx, user.ID)
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
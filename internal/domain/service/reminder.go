@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+)
+
+const reminderKindOverdue = "overdue"
+
+type ReminderService interface {
+	SendDueReminders(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
+
+type reminderService struct {
+	repo          repository.EnrollmentRepository
+	notifications NotificationService
+	cfg           *config.ReminderConfig
+}
+
+func NewReminderService(repo repository.EnrollmentRepository, notifications NotificationService, cfg *config.ReminderConfig) ReminderService {
+	return &reminderService{
+		repo:          repo,
+		notifications: notifications,
+		cfg:           cfg,
+	}
+}
+
+// Run sends due reminders on every tick of the configured interval until the
+// context is cancelled.
+func (s *reminderService) Run(ctx context.Context) {
+	if !s.cfg.Enabled || s.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := s.SendDueReminders(ctx); err != nil {
+			log.Printf("due reminders: %v", err)
+		} else if sent > 0 {
+			log.Printf("due reminders: sent %d", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDueReminders notifies learners whose deadline is within one of the
+// configured offsets or already passed. Only the most urgent reminder that
+// applies is sent, and each kind is sent at most once per enrollment.
+func (s *reminderService) SendDueReminders(ctx context.Context) (int, error) {
+	offsets := append([]time.Duration(nil), s.cfg.Before...)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	var horizon time.Duration
+	if len(offsets) > 0 {
+		horizon = offsets[len(offsets)-1]
+	}
+
+	now := time.Now()
+	enrollments, err := s.repo.ListDueBefore(ctx, now.Add(horizon))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, enrollment := range enrollments {
+		kind, ok := reminderKind(enrollment.DueAt.Sub(now), offsets)
+		if !ok {
+			continue
+		}
+
+		// The reminder is recorded and sent in one transaction, so a failed
+		// send leaves no record and is retried on the next run.
+		var created bool
+		var notifyErr error
+		err := s.repo.Transaction(ctx, func(repo repository.EnrollmentRepository) error {
+			var err error
+			created, err = repo.CreateReminder(ctx, &models.EnrollmentReminder{
+				EnrollmentID: enrollment.ID,
+				Kind:         kind,
+				SentAt:       now,
+			})
+			if err != nil || !created {
+				return err
+			}
+			notifyErr = s.notify(ctx, enrollment, kind)
+			return notifyErr
+		})
+		if notifyErr != nil {
+			log.Printf("due reminders: enrollment %d: %v", enrollment.ID, notifyErr)
+			continue
+		}
+		if err != nil {
+			return sent, err
+		}
+		if created {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+func (s *reminderService) notify(ctx context.Context, enrollment *models.Enrollment, kind string) error {
+	// The scan runs in a system context; the notification belongs to the
+	// enrollment's tenant.
+	ctx = tenant.WithID(ctx, enrollment.TenantID)
+	due := enrollment.DueAt.Format("2006-01-02 15:04 MST")
+
+	if kind == reminderKindOverdue {
+		return s.notifications.Notify(ctx, enrollment.UserID, constants.NotificationEnrollmentOverdue,
+			fmt.Sprintf("%s is overdue", enrollment.Course.Name),
+			fmt.Sprintf("The deadline for %s was %s. Please complete the course as soon as possible.", enrollment.Course.Name, due))
+	}
+
+	return s.notifications.Notify(ctx, enrollment.UserID, constants.NotificationEnrollmentDue,
+		fmt.Sprintf("%s is due soon", enrollment.Course.Name),
+		fmt.Sprintf("Please complete %s before %s.", enrollment.Course.Name, due))
+}
+
+// reminderKind picks the smallest offset the remaining time falls within.
+// offsets must be sorted in ascending order.
+func reminderKind(remaining time.Duration, offsets []time.Duration) (string, bool) {
+	if remaining < 0 {
+		return reminderKindOverdue, true
+	}
+	for _, offset := range offsets {
+		if remaining <= offset {
+			return fmt.Sprintf("due_%dh", int(offset.Hours())), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
+)
+
+var (
+	ErrAccountLocked        = errors.New("account is temporarily locked after too many failed logins")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+)
+
+// LoginThrottledError is returned when a login attempt is refused before the
+// password is checked. RetryAfter tells the client when to try again.
+type LoginThrottledError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return e.Err
+}
+
+type SecurityService interface {
+	CheckLogin(ctx context.Context, user *models.User, client *models.ClientInfo) error
+	RecordLoginFailure(ctx context.Context, user *models.User, client *models.ClientInfo)
+	RecordLoginSuccess(ctx context.Context, user *models.User)
+	Unlock(ctx context.Context, actorID, userID uint) error
+	LogEvent(ctx context.Context, event *models.SecurityEvent)
+	GetEvents(ctx context.Context, filter *models.SecurityEventFilter) ([]*models.SecurityEvent, error)
+}
+
+type securityService struct {
+	repo      repository.SecurityRepository
+	userRepo  repository.UserRepository
+	cfg       *config.SecurityConfig
+	ipLimiter *util.RateLimiter
+}
+
+func NewSecurityService(repo repository.SecurityRepository, userRepo repository.UserRepository, cfg *config.SecurityConfig) SecurityService {
+	return &securityService{
+		repo:      repo,
+		userRepo:  userRepo,
+		cfg:       cfg,
+		ipLimiter: util.NewRateLimiter(cfg.IPMaxFailures, cfg.IPWindow),
+	}
+}
+
+// CheckLogin refuses an attempt from a throttled address, against a locked
+// account, or before the progressive delay after recent failures has passed.
+// user is nil when the NIP does not match an account.
+func (s *securityService) CheckLogin(ctx context.Context, user *models.User, client *models.ClientInfo) error {
+	if client.IP != "" && s.ipLimiter.Exceeded(client.IP) {
+		return &LoginThrottledError{Err: ErrTooManyLoginAttempts, RetryAfter: s.cfg.IPWindow}
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	if user.IsLocked() {
+		return &LoginThrottledError{Err: ErrAccountLocked, RetryAfter: time.Until(*user.LockedUntil)}
+	}
+
+	if delay := s.delay(user.FailedLogins); delay > 0 && user.LastFailedLogin != nil {
+		if wait := time.Until(user.LastFailedLogin.Add(delay)); wait > 0 {
+			return &LoginThrottledError{Err: ErrTooManyLoginAttempts, RetryAfter: wait}
+		}
+	}
+
+	return nil
+}
+
+// RecordLoginFailure counts a failed attempt against the client address and,
+// when known, the account. Reaching the account limit locks it for the
+// configured duration and starts a fresh count.
+func (s *securityService) RecordLoginFailure(ctx context.Context, user *models.User, client *models.ClientInfo) {
+	if client.IP != "" && s.cfg.IPMaxFailures > 0 {
+		if s.ipLimiter.Record(client.IP) == s.cfg.IPMaxFailures {
+			s.LogEvent(ctx, newSecurityEvent(nil, constants.SecurityEventIPThrottled, client,
+				fmt.Sprintf("%d failed logins within %s", s.cfg.IPMaxFailures, s.cfg.IPWindow)))
+		}
+	}
+
+	if user == nil {
+		return
+	}
+
+	failures, err := s.repo.RecordFailedLogin(ctx, user.ID)
+	if err != nil {
+		log.Printf("login throttle: failed to record failure for user %d: %v", user.ID, err)
+		return
+	}
+
+	if s.cfg.MaxFailedLogins <= 0 || failures < s.cfg.MaxFailedLogins {
+		return
+	}
+
+	if err := s.repo.ResetFailedLogins(ctx, user.ID); err != nil {
+		log.Printf("login throttle: failed to reset failures for user %d: %v", user.ID, err)
+	}
+
+	until := time.Now().Add(s.cfg.LockoutDuration)
+	if err := s.repo.LockUser(ctx, user.ID, until); err != nil {
+		log.Printf("login throttle: failed to lock user %d: %v", user.ID, err)
+		return
+	}
+
+	s.LogEvent(ctx, newSecurityEvent(&user.ID, constants.SecurityEventAccountLocked, client,
+		fmt.Sprintf("locked until %s after %d failed logins", until.Format(time.RFC3339), failures)))
+}
+
+func (s *securityService) RecordLoginSuccess(ctx context.Context, user *models.User) {
+	if user.FailedLogins == 0 && user.LockedUntil == nil {
+		return
+	}
+
+	if err := s.repo.ResetFailedLogins(ctx, user.ID); err != nil {
+		log.Printf("login throttle: failed to reset failures for user %d: %v", user.ID, err)
+	}
+}
+
+func (s *securityService) Unlock(ctx context.Context, actorID, userID uint) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.ResetFailedLogins(ctx, userID); err != nil {
+		return err
+	}
+
+	event := newSecurityEvent(&userID, constants.SecurityEventAccountUnlocked, &models.ClientInfo{}, "unlocked by administrator")
+	event.ActorID = &actorID
+	s.LogEvent(ctx, event)
+
+	return nil
+}
+
+// LogEvent stores a security event. Failures are logged rather than returned
+// so auditing never blocks the action being audited.
+func (s *securityService) LogEvent(ctx context.Context, event *models.SecurityEvent) {
+	if err := s.repo.CreateEvent(ctx, event); err != nil {
+		log.Printf("security event %s: %v", event.Type, err)
+	}
+}
+
+func (s *securityService) GetEvents(ctx context.Context, filter *models.SecurityEventFilter) ([]*models.SecurityEvent, error) {
+	return s.repo.ListEvents(ctx, filter)
+}
+
+// delay returns how long to wait after the given number of consecutive
+// failures: nothing up to DelayAfter, then BaseDelay doubling per failure.
+func (s *securityService) delay(failures int) time.Duration {
+	if s.cfg.BaseDelay <= 0 || failures <= s.cfg.DelayAfter {
+		return 0
+	}
+
+	delay := s.cfg.BaseDelay
+	for i := s.cfg.DelayAfter + 1; i < failures; i++ {
+		delay *= 2
+		if s.cfg.MaxDelay > 0 && delay >= s.cfg.MaxDelay {
+			return s.cfg.MaxDelay
+		}
+	}
+
+	return delay
+}
+
+func newSecurityEvent(userID *uint, eventType string, client *models.ClientInfo, details string) *models.SecurityEvent {
+	event := &models.SecurityEvent{
+		UserID:  userID,
+		Type:    eventType,
+		Details: &details,
+	}
+	if client.IP != "" {
+		event.IPAddress = &client.IP
+	}
+	if client.UserAgent != "" {
+		ua := client.UserAgent
+		if len(ua) > 500 {
+			ua = ua[:500]
+		}
+		event.UserAgent = &ua
+	}
+	return event
+}
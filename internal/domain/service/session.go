@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionService manages login sessions, which are backed by refresh tokens.
+// Revoking a session also invalidates the access tokens issued for it.
+type SessionService interface {
+	GetSessions(ctx context.Context, userID, currentID uint) ([]*models.SessionResponse, error)
+	Revoke(ctx context.Context, userID, sessionID uint) error
+	// RevokeAll ends every session of the user except keepID, which may be 0.
+	RevokeAll(ctx context.Context, userID, keepID uint) error
+	ForceLogout(ctx context.Context, actorID, userID uint) error
+}
+
+type sessionService struct {
+	refreshRepo repository.RefreshTokenRepository
+	userRepo    repository.UserRepository
+	security    SecurityService
+}
+
+func NewSessionService(refreshRepo repository.RefreshTokenRepository, userRepo repository.UserRepository, security SecurityService) SessionService {
+	return &sessionService{
+		refreshRepo: refreshRepo,
+		userRepo:    userRepo,
+		security:    security,
+	}
+}
+
+func (s *sessionService) GetSessions(ctx context.Context, userID, currentID uint) ([]*models.SessionResponse, error) {
+	sessions, err := s.refreshRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = session.ToSessionResponse(currentID)
+	}
+
+	return responses, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID, sessionID uint) error {
+	deleted, err := s.refreshRepo.DeleteForUser(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSessionNotFound
+	}
+
+	s.logEvent(ctx, userID, userID, constants.SecurityEventSessionRevoked, fmt.Sprintf("session %d", sessionID))
+	return nil
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID, keepID uint) error {
+	if err := s.refreshRepo.DeleteByUserIDExcept(ctx, userID, keepID); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, userID, userID, constants.SecurityEventSessionRevoked, "all sessions")
+	return nil
+}
+
+func (s *sessionService) ForceLogout(ctx context.Context, actorID, userID uint) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.refreshRepo.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, actorID, userID, constants.SecurityEventForcedLogout, "all sessions ended by administrator")
+	return nil
+}
+
+func (s *sessionService) logEvent(ctx context.Context, actorID, userID uint, eventType, details string) {
+	event := newSecurityEvent(&userID, eventType, models.ClientInfoFromContext(ctx), details)
+	event.ActorID = &actorID
+	s.security.LogEvent(ctx, event)
+}
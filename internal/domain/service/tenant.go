@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownTenant     = errors.New("unknown tenant")
+	ErrTenantInactive    = errors.New("tenant is not active")
+	ErrTenantSlugTaken   = errors.New("tenant slug is already in use")
+	ErrTenantDomainTaken = errors.New("tenant domain is already in use")
+)
+
+type TenantService interface {
+	// Resolve finds the active tenant for a request from the tenant header,
+	// or failing that the host name.
+	Resolve(ctx context.Context, header, host string) (*models.Tenant, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	ListTenants(ctx context.Context) ([]*models.TenantResponse, error)
+	CreateTenant(ctx context.Context, req *models.CreateTenantRequest) (*models.TenantResponse, error)
+	// UpdateBranding changes the branding and settings of the tenant in ctx.
+	UpdateBranding(ctx context.Context, req *models.UpdateTenantBrandingRequest) (*models.TenantResponse, error)
+}
+
+// tenantCacheMaxEntries bounds the resolver cache, which also holds misses
+// for whatever host names clients send.
+const tenantCacheMaxEntries = 1024
+
+type tenantCacheEntry struct {
+	tenant    *models.Tenant
+	expiresAt time.Time
+}
+
+type tenantService struct {
+	repo repository.TenantRepository
+	cfg  *config.TenantConfig
+
+	mu    sync.Mutex
+	cache map[string]tenantCacheEntry
+}
+
+func NewTenantService(repo repository.TenantRepository, cfg *config.TenantConfig) TenantService {
+	return &tenantService{
+		repo:  repo,
+		cfg:   cfg,
+		cache: make(map[string]tenantCacheEntry),
+	}
+}
+
+func (s *tenantService) Resolve(ctx context.Context, header, host string) (*models.Tenant, error) {
+	slug := strings.ToLower(strings.TrimSpace(header))
+	if slug == "" {
+		host = strings.ToLower(host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		tenant, err := s.lookup("domain:"+host, func() (*models.Tenant, error) {
+			return s.repo.GetByDomain(ctx, host)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if tenant != nil {
+			return activeTenant(tenant)
+		}
+
+		if base := strings.ToLower(s.cfg.BaseDomain); base != "" {
+			if sub, ok := strings.CutSuffix(host, "."+base); ok && !strings.Contains(sub, ".") {
+				slug = sub
+			}
+		}
+	}
+	if slug == "" {
+		slug = s.cfg.Default
+	}
+	if slug == "" {
+		return nil, ErrUnknownTenant
+	}
+
+	tenant, err := s.lookup("slug:"+slug, func() (*models.Tenant, error) {
+		return s.repo.GetBySlug(ctx, slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, ErrUnknownTenant
+	}
+	return activeTenant(tenant)
+}
+
+func (s *tenantService) GetBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	return s.repo.GetBySlug(ctx, strings.ToLower(slug))
+}
+
+func (s *tenantService) ListTenants(ctx context.Context) ([]*models.TenantResponse, error) {
+	tenants, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.TenantResponse, len(tenants))
+	for i, tenant := range tenants {
+		responses[i] = tenant.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *tenantService) CreateTenant(ctx context.Context, req *models.CreateTenantRequest) (*models.TenantResponse, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if _, err := s.repo.GetBySlug(ctx, slug); err == nil {
+		return nil, ErrTenantSlugTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	tenant := &models.Tenant{
+		Slug:     slug,
+		Name:     strings.TrimSpace(req.Name),
+		IsActive: true,
+		Settings: "{}",
+	}
+	if req.Domain != nil && *req.Domain != "" {
+		domain := strings.ToLower(strings.TrimSpace(*req.Domain))
+		if _, err := s.repo.GetByDomain(ctx, domain); err == nil {
+			return nil, ErrTenantDomainTaken
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		tenant.Domain = &domain
+	}
+
+	if err := s.repo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+	s.flush()
+
+	return tenant.ToResponse(), nil
+}
+
+func (s *tenantService) UpdateBranding(ctx context.Context, req *models.UpdateTenantBrandingRequest) (*models.TenantResponse, error) {
+	current := models.TenantFromContext(ctx)
+	if current == nil {
+		return nil, ErrUnknownTenant
+	}
+
+	tenant, err := s.repo.GetByID(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		tenant.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.LogoURL != nil {
+		tenant.LogoURL = optionalString(*req.LogoURL)
+	}
+	if req.PrimaryColor != nil {
+		tenant.PrimaryColor = optionalString(strings.ToLower(*req.PrimaryColor))
+	}
+	if req.SupportEmail != nil {
+		tenant.SupportEmail = optionalString(*req.SupportEmail)
+	}
+	if req.Settings != nil {
+		if err := tenant.SetSettings(*req.Settings); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+	s.flush()
+
+	return tenant.ToResponse(), nil
+}
+
+// lookup returns the cached tenant for key, loading it on a miss. Unknown
+// keys are cached as nil too, so requests for unmatched hosts do not query
+// the database every time.
+func (s *tenantService) lookup(key string, load func() (*models.Tenant, error)) (*models.Tenant, error) {
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.tenant, nil
+	}
+
+	tenant, err := load()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tenant, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= tenantCacheMaxEntries {
+		s.cache = make(map[string]tenantCacheEntry)
+	}
+	s.cache[key] = tenantCacheEntry{tenant: tenant, expiresAt: time.Now().Add(s.cfg.CacheTTL)}
+	s.mu.Unlock()
+
+	return tenant, nil
+}
+
+func (s *tenantService) flush() {
+	s.mu.Lock()
+	s.cache = make(map[string]tenantCacheEntry)
+	s.mu.Unlock()
+}
+
+// tenantURL returns the frontend URL the tenant in ctx configured, or
+// fallback.
+func tenantURL(ctx context.Context, fallback string, pick func(models.TenantSettings) string) string {
+	if tenant := models.TenantFromContext(ctx); tenant != nil {
+		if url := pick(tenant.GetSettings()); url != "" {
+			return url
+		}
+	}
+	return fallback
+}
+
+func activeTenant(tenant *models.Tenant) (*models.Tenant, error) {
+	if !tenant.IsActive {
+		return nil, ErrTenantInactive
+	}
+	return tenant, nil
+}
@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/bobchopperz/bahrululum/internal/constants"
 	"github.com/bobchopperz/bahrululum/internal/domain/models"
 	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -13,8 +16,6 @@ type UserService interface {
 	CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.UserResponse, error)
 	GetUser(ctx context.Context, id uint) (*models.UserResponse, error)
 	GetUsers(ctx context.Context, offset, limit int) ([]*models.UserResponse, error)
-	UpdateUser(ctx context.Context, id uint, updates map[string]interface{}) (*models.UserResponse, error)
-	DeleteUser(ctx context.Context, id uint) error
 }
 
 type userService struct {
@@ -26,18 +27,27 @@ func NewUserService(repo repository.UserRepository) UserService {
 }
 
 func (s *userService) CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.UserResponse, error) {
+	if err := util.ValidatePassword(req.Password, req.Nip, req.Email, req.Name); err != nil {
+		return nil, err
+	}
+
 	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	user := &models.User{
-		Name:     req.Name,
-		Email:    req.Email,
-		Nip:      req.Nip,
-		Role:     req.Role,
-		Password: string(hashPassword),
-		IsActive: true,
+		Name:       req.Name,
+		Email:      req.Email,
+		Nip:        req.Nip,
+		Role:       req.Role,
+		Password:   string(hashPassword),
+		IsActive:   true,
+		AuthSource: constants.AuthSourceLocal,
+	}
+	if req.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
@@ -69,27 +79,3 @@ func (s *userService) GetUsers(ctx context.Context, offset, limit int) ([]*model
 
 	return responses, nil
 }
-
-func (s *userService) UpdateUser(ctx context.Context, id uint, updates map[string]interface{}) (*models.UserResponse, error) {
-	user, err := s.repo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	if name, ok := updates["name"]; ok {
-		user.Name = name.(string)
-	}
-
-	if err := s.repo.Update(ctx, user); err != nil {
-		return nil, err
-	}
-
-	return user.ToResponse(), nil
-}
-
-func (s *userService) DeleteUser(ctx context.Context, id uint) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return err
-	}
-	return nil
-}
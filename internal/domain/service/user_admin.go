@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNipTaken           = errors.New("NIP is already in use")
+	ErrCannotModifySelf   = errors.New("administrators cannot change their own role, status or account")
+	ErrLastAdmin          = errors.New("at least one active administrator must remain")
+	ErrExternalPassword   = errors.New("password is managed by the account's identity provider")
+	ErrUserNotDeleted     = errors.New("user is not deleted")
+	ErrInvalidUserStatus  = errors.New("invalid user status")
+	ErrServiceAccountRole = errors.New("service accounts are managed through the service account endpoints")
+)
+
+// UserAdminService is the administrator's view of user accounts. Every
+// change is recorded as a security event with the acting administrator;
+// an actorID of 0 stands for the command line tools.
+type UserAdminService interface {
+	SearchUsers(ctx context.Context, filter *models.UserFilter) ([]*models.UserResponse, int64, error)
+	CreateUser(ctx context.Context, actorID uint, req *models.CreateUserRequest) (*models.UserResponse, error)
+	UpdateUser(ctx context.Context, actorID, userID uint, req *models.AdminUpdateUserRequest) (*models.UserResponse, error)
+	ChangeRole(ctx context.Context, actorID, userID uint, role string) (*models.UserResponse, error)
+	// SetActive activates or deactivates an account. Deactivating ends all
+	// of the user's sessions.
+	SetActive(ctx context.Context, actorID, userID uint, active bool) (*models.UserResponse, error)
+	// ResetPassword sets a new password and ends all of the user's sessions.
+	// Without a password in req a temporary one is generated and returned.
+	ResetPassword(ctx context.Context, actorID, userID uint, req *models.AdminResetPasswordRequest) (*models.AdminResetPasswordResponse, error)
+	DeleteUser(ctx context.Context, actorID, userID uint) error
+	RestoreUser(ctx context.Context, actorID, userID uint) (*models.UserResponse, error)
+}
+
+type userAdminService struct {
+	userRepo     repository.UserRepository
+	users        UserService
+	auth         AuthService
+	verification EmailVerificationService
+	security     SecurityService
+}
+
+func NewUserAdminService(userRepo repository.UserRepository, users UserService, auth AuthService, verification EmailVerificationService, security SecurityService) UserAdminService {
+	return &userAdminService{
+		userRepo:     userRepo,
+		users:        users,
+		auth:         auth,
+		verification: verification,
+		security:     security,
+	}
+}
+
+func (s *userAdminService) SearchUsers(ctx context.Context, filter *models.UserFilter) ([]*models.UserResponse, int64, error) {
+	if filter.Status != "" && !constants.IsValidUserStatus(filter.Status) {
+		return nil, 0, ErrInvalidUserStatus
+	}
+	if filter.Role != "" && !constants.IsValidRole(filter.Role) {
+		return nil, 0, ErrInvalidRole
+	}
+
+	users, total, err := s.userRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*models.UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = user.ToResponse()
+	}
+
+	return responses, total, nil
+}
+
+func (s *userAdminService) CreateUser(ctx context.Context, actorID uint, req *models.CreateUserRequest) (*models.UserResponse, error) {
+	if !constants.IsValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Nip = strings.TrimSpace(req.Nip)
+	req.Email = strings.TrimSpace(req.Email)
+
+	if err := s.checkAvailable(ctx, 0, req.Nip, req.Email); err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.CreateUser(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verification.SendToUser(ctx, user.ID); err != nil {
+		log.Printf("admin create user: failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventUserCreated, "role "+user.Role)
+	return user, nil
+}
+
+func (s *userAdminService) UpdateUser(ctx context.Context, actorID, userID uint, req *models.AdminUpdateUserRequest) (*models.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	if req.Name != nil {
+		user.Name = strings.TrimSpace(*req.Name)
+		changed = append(changed, "name")
+	}
+
+	emailChanged := false
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		if !strings.EqualFold(email, user.Email) {
+			if err := s.checkAvailable(ctx, user.ID, "", email); err != nil {
+				return nil, err
+			}
+			user.Email = email
+			user.EmailVerifiedAt = nil
+			user.PendingEmail = nil
+			emailChanged = true
+			changed = append(changed, "email")
+		}
+	}
+
+	if len(changed) == 0 {
+		return user.ToResponse(), nil
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		if err := s.verification.SendToUser(ctx, user.ID); err != nil {
+			log.Printf("admin update user: failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventUserUpdated, "changed "+strings.Join(changed, ", "))
+	return user.ToResponse(), nil
+}
+
+func (s *userAdminService) ChangeRole(ctx context.Context, actorID, userID uint, role string) (*models.UserResponse, error) {
+	if !constants.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if actorID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.AuthSource == constants.AuthSourceService {
+		return nil, ErrServiceAccountRole
+	}
+	if user.Role == role {
+		return user.ToResponse(), nil
+	}
+	if err := checkNotLastAdmin(ctx, s.userRepo, user); err != nil {
+		return nil, err
+	}
+
+	previous := user.Role
+	user.Role = role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventRoleChanged, fmt.Sprintf("%s to %s", previous, role))
+	return user.ToResponse(), nil
+}
+
+func (s *userAdminService) SetActive(ctx context.Context, actorID, userID uint, active bool) (*models.UserResponse, error) {
+	if actorID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsActive == active {
+		return user.ToResponse(), nil
+	}
+	if !active {
+		if err := checkNotLastAdmin(ctx, s.userRepo, user); err != nil {
+			return nil, err
+		}
+	}
+
+	user.IsActive = active
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	eventType := constants.SecurityEventUserActivated
+	if !active {
+		eventType = constants.SecurityEventUserDeactivated
+		if err := s.auth.RevokeUserTokens(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logEvent(ctx, actorID, user.ID, eventType, "")
+	return user.ToResponse(), nil
+}
+
+func (s *userAdminService) ResetPassword(ctx context.Context, actorID, userID uint, req *models.AdminResetPasswordRequest) (*models.AdminResetPasswordResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.AuthSource != constants.AuthSourceLocal {
+		return nil, ErrExternalPassword
+	}
+
+	response := &models.AdminResetPasswordResponse{}
+	password := req.Password
+	if password == "" {
+		if password, err = util.RandomToken(12); err != nil {
+			return nil, err
+		}
+		response.TemporaryPassword = password
+	} else if err := util.ValidatePassword(password, user.Nip, user.Email, user.Name); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.Password = string(hash)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RevokeUserTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventPasswordReset, "")
+	return response, nil
+}
+
+// DeleteUser soft-deletes the account. The row, and with it the NIP and
+// email, is kept so the account can be restored.
+func (s *userAdminService) DeleteUser(ctx context.Context, actorID, userID uint) error {
+	if actorID == userID {
+		return ErrCannotModifySelf
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := checkNotLastAdmin(ctx, s.userRepo, user); err != nil {
+		return err
+	}
+
+	if err := s.auth.RevokeUserTokens(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventUserDeleted, "")
+	return nil
+}
+
+func (s *userAdminService) RestoreUser(ctx context.Context, actorID, userID uint) (*models.UserResponse, error) {
+	user, err := s.userRepo.GetByIDUnscoped(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.DeletedAt.Valid {
+		return nil, ErrUserNotDeleted
+	}
+
+	if err := s.userRepo.Restore(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+
+	s.logEvent(ctx, actorID, user.ID, constants.SecurityEventUserRestored, "")
+	return user.ToResponse(), nil
+}
+
+// checkAvailable reports whether the NIP or email is held by an account
+// other than exceptID, including deleted accounts.
+func (s *userAdminService) checkAvailable(ctx context.Context, exceptID uint, nip, email string) error {
+	existing, err := s.userRepo.FindByNipOrEmail(ctx, nip, email)
+	if err != nil {
+		return err
+	}
+	for _, user := range existing {
+		if user.ID == exceptID {
+			continue
+		}
+		if nip != "" && user.Nip == nip {
+			return ErrNipTaken
+		}
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// checkNotLastAdmin stops the last active administrator from being demoted,
+// deactivated or deleted.
+func checkNotLastAdmin(ctx context.Context, repo repository.UserRepository, user *models.User) error {
+	if !user.IsAdmin() || !user.IsActive {
+		return nil
+	}
+
+	count, err := repo.CountActiveByRole(ctx, constants.RoleAdmin.String())
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+func (s *userAdminService) logEvent(ctx context.Context, actorID, userID uint, eventType, details string) {
+	event := newSecurityEvent(&userID, eventType, models.ClientInfoFromContext(ctx), details)
+	if actorID != 0 {
+		event.ActorID = &actorID
+	}
+	s.security.LogEvent(ctx, event)
+}
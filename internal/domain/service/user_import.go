@@ -0,0 +1,473 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bobchopperz/bahrululum/internal/constants"
+	"github.com/bobchopperz/bahrululum/internal/domain/models"
+	"github.com/bobchopperz/bahrululum/internal/domain/repository"
+	"github.com/bobchopperz/bahrululum/internal/util"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserImportMaxRows is the largest roster accepted in one import.
+const UserImportMaxRows = 10000
+
+var (
+	ErrImportMissingColumns = errors.New("the file needs a header row with nip, name and email columns")
+	ErrImportEmpty          = errors.New("the file has no user rows")
+	ErrImportTooLarge       = fmt.Errorf("the file has more than %d user rows", UserImportMaxRows)
+)
+
+// errImportDryRun rolls back the import transaction of a dry run.
+var errImportDryRun = errors.New("dry run")
+
+// importRoleChange is a role an import changed, logged once it is saved.
+type importRoleChange struct {
+	userID   uint
+	from, to string
+}
+
+var importValidator = validator.New()
+
+// UserImportService imports a staff roster keyed by NIP. Rows create new
+// users or update the name, email and role of existing ones; invalid rows
+// are reported and skipped. New users get an unusable random password and
+// set their own through the password reset flow or sign in through LDAP or
+// SSO. Imported addresses are unverified unless the import is told to trust
+// them with VerifyEmails.
+type UserImportService interface {
+	// Import processes spreadsheet rows, the first being the header. With
+	// DeactivateMissing the file is treated as the complete roster: users
+	// it lists are reactivated and active users it does not list are
+	// deactivated, except administrators, service accounts and the actor.
+	Import(ctx context.Context, actorID uint, rows [][]string, opts *models.UserImportOptions) (*models.UserImportResponse, error)
+	GetImport(ctx context.Context, id uint) (*models.UserImportResponse, error)
+	GetImports(ctx context.Context, offset, limit int) ([]*models.UserImportResponse, error)
+	// ErrorReport returns the failed rows of an import as CSV.
+	ErrorReport(ctx context.Context, id uint) ([]byte, error)
+}
+
+type userImportService struct {
+	repo     repository.UserImportRepository
+	userRepo repository.UserRepository
+	auth     AuthService
+	security SecurityService
+}
+
+func NewUserImportService(repo repository.UserImportRepository, userRepo repository.UserRepository, auth AuthService, security SecurityService) UserImportService {
+	return &userImportService{
+		repo:     repo,
+		userRepo: userRepo,
+		auth:     auth,
+		security: security,
+	}
+}
+
+func (s *userImportService) Import(ctx context.Context, actorID uint, rows [][]string, opts *models.UserImportOptions) (*models.UserImportResponse, error) {
+	report, present, err := parseImportRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var roleChanges []importRoleChange
+	err = s.userRepo.Transaction(ctx, func(repo repository.UserRepository) error {
+		for i := range report.Rows {
+			if report.Rows[i].Result != "" {
+				continue
+			}
+			if err := s.applyRow(ctx, repo, actorID, &report.Rows[i], opts, &roleChanges); err != nil {
+				return err
+			}
+		}
+
+		if opts.DeactivateMissing {
+			deactivated, err := s.deactivateMissing(ctx, repo, actorID, present)
+			if err != nil {
+				return err
+			}
+			report.Deactivated = deactivated
+		}
+
+		if opts.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return nil, err
+	}
+
+	if !opts.DryRun {
+		for _, change := range roleChanges {
+			s.logEvent(ctx, actorID, change.userID, constants.SecurityEventRoleChanged,
+				fmt.Sprintf("%s to %s by user import", change.from, change.to))
+		}
+		for _, user := range report.Deactivated {
+			if err := s.auth.RevokeUserTokens(ctx, user.UserID); err != nil {
+				return nil, err
+			}
+			s.logEvent(ctx, actorID, user.UserID, constants.SecurityEventUserDeactivated, "absent from user import")
+		}
+	}
+
+	userImport := &models.UserImport{
+		FileName:          opts.FileName,
+		DryRun:            opts.DryRun,
+		DeactivateMissing: opts.DeactivateMissing,
+		Total:             len(report.Rows),
+		Deactivated:       len(report.Deactivated),
+	}
+	if actorID != 0 {
+		userImport.ActorID = &actorID
+	}
+	for _, row := range report.Rows {
+		switch row.Result {
+		case constants.UserImportCreated:
+			userImport.Created++
+		case constants.UserImportUpdated:
+			userImport.Updated++
+		case constants.UserImportUnchanged:
+			userImport.Unchanged++
+		default:
+			userImport.Failed++
+		}
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	reportText := string(encoded)
+	userImport.Report = &reportText
+
+	if err := s.repo.Create(ctx, userImport); err != nil {
+		return nil, err
+	}
+
+	return userImport.ToResponse(), nil
+}
+
+func (s *userImportService) GetImport(ctx context.Context, id uint) (*models.UserImportResponse, error) {
+	userImport, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return userImport.ToResponse(), nil
+}
+
+func (s *userImportService) GetImports(ctx context.Context, offset, limit int) ([]*models.UserImportResponse, error) {
+	imports, err := s.repo.List(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.UserImportResponse, len(imports))
+	for i, userImport := range imports {
+		responses[i] = userImport.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *userImportService) ErrorReport(ctx context.Context, id uint) ([]byte, error) {
+	userImport, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"row", "nip", "name", "email", "role", "errors"}); err != nil {
+		return nil, err
+	}
+
+	if report := userImport.GetReport(); report != nil {
+		for _, row := range report.Rows {
+			if row.Result != constants.UserImportFailed {
+				continue
+			}
+			record := []string{strconv.Itoa(row.Row), row.Nip, row.Name, row.Email, row.Role, strings.Join(row.Errors, "; ")}
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// applyRow creates or updates the user of a validated row and records the
+// outcome on it. Role changes go through the same last administrator check
+// as the user admin service and are added to roleChanges. Only database
+// failures are returned as errors.
+func (s *userImportService) applyRow(ctx context.Context, repo repository.UserRepository, actorID uint, row *models.UserImportRow, opts *models.UserImportOptions, roleChanges *[]importRoleChange) error {
+	matches, err := repo.FindByNipOrEmail(ctx, row.Nip, row.Email)
+	if err != nil {
+		return err
+	}
+
+	var user, emailOwner *models.User
+	for _, match := range matches {
+		if match.Nip == row.Nip {
+			user = match
+		}
+		if strings.EqualFold(match.Email, row.Email) {
+			emailOwner = match
+		}
+	}
+
+	if emailOwner != nil && (user == nil || emailOwner.ID != user.ID) {
+		return failImportRow(row, fmt.Sprintf("email is already used by NIP %s", emailOwner.Nip))
+	}
+
+	if user == nil {
+		return s.createUser(ctx, repo, row, opts.VerifyEmails)
+	}
+
+	row.UserID = &user.ID
+	switch {
+	case user.DeletedAt.Valid:
+		return failImportRow(row, "the user with this NIP is deleted, restore it first")
+	case user.AuthSource == constants.AuthSourceService:
+		return failImportRow(row, "service accounts cannot be imported")
+	case user.ID == actorID && row.Role != "" && row.Role != user.Role:
+		return failImportRow(row, ErrCannotModifySelf.Error())
+	}
+
+	// Checked first and against the transaction, so the row fails before
+	// anything else of it is applied and earlier rows' demotions count.
+	roleChanged := row.Role != "" && row.Role != user.Role
+	if roleChanged {
+		err := checkNotLastAdmin(ctx, repo, user)
+		if errors.Is(err, ErrLastAdmin) {
+			return failImportRow(row, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	changed := false
+	if row.Name != user.Name {
+		user.Name = row.Name
+		changed = true
+	}
+	if row.Email != user.Email {
+		user.Email = row.Email
+		user.EmailVerifiedAt = nil
+		user.PendingEmail = nil
+		changed = true
+	}
+	previousRole := user.Role
+	if roleChanged {
+		user.Role = row.Role
+		changed = true
+	}
+	if opts.VerifyEmails && user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		changed = true
+	}
+	if opts.DeactivateMissing && !user.IsActive {
+		user.IsActive = true
+		changed = true
+	}
+
+	if !changed {
+		row.Result = constants.UserImportUnchanged
+		return nil
+	}
+
+	if err := repo.Update(ctx, user); err != nil {
+		return err
+	}
+	if roleChanged {
+		*roleChanges = append(*roleChanges, importRoleChange{userID: user.ID, from: previousRole, to: user.Role})
+	}
+	row.Result = constants.UserImportUpdated
+	return nil
+}
+
+func (s *userImportService) createUser(ctx context.Context, repo repository.UserRepository, row *models.UserImportRow, verified bool) error {
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	// The password is random and never handed out, so the cheapest cost is
+	// enough and keeps large imports fast.
+	password, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.MinCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	role := row.Role
+	if role == "" {
+		role = constants.RoleUser.String()
+	}
+
+	user := &models.User{
+		Name:       row.Name,
+		Email:      row.Email,
+		Nip:        row.Nip,
+		Role:       role,
+		Password:   string(password),
+		IsActive:   true,
+		AuthSource: constants.AuthSourceLocal,
+	}
+	if verified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := repo.Create(ctx, user); err != nil {
+		return err
+	}
+
+	row.UserID = &user.ID
+	row.Result = constants.UserImportCreated
+	return nil
+}
+
+// deactivateMissing deactivates active users whose NIP is not in present.
+func (s *userImportService) deactivateMissing(ctx context.Context, repo repository.UserRepository, actorID uint, present map[string]bool) ([]models.UserImportDeactivation, error) {
+	users, err := repo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deactivated := []models.UserImportDeactivation{}
+	for _, user := range users {
+		if present[user.Nip] || user.ID == actorID || user.IsAdmin() || user.AuthSource == constants.AuthSourceService {
+			continue
+		}
+
+		user.IsActive = false
+		if err := repo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+		deactivated = append(deactivated, models.UserImportDeactivation{UserID: user.ID, Nip: user.Nip, Name: user.Name})
+	}
+
+	return deactivated, nil
+}
+
+func (s *userImportService) logEvent(ctx context.Context, actorID, userID uint, eventType, details string) {
+	event := newSecurityEvent(&userID, eventType, models.ClientInfoFromContext(ctx), details)
+	if actorID != 0 {
+		event.ActorID = &actorID
+	}
+	s.security.LogEvent(ctx, event)
+}
+
+// parseImportRows reads and validates the rows of an import file. Rows that
+// fail validation are marked failed; the others are left without a result.
+// present holds every NIP in the file, valid or not, so a row with a typo
+// elsewhere does not get its user deactivated.
+func parseImportRows(rows [][]string) (*models.UserImportReport, map[string]bool, error) {
+	if len(rows) == 0 {
+		return nil, nil, ErrImportEmpty
+	}
+
+	header := util.HeaderIndex(rows[0])
+	for _, column := range []string{"nip", "name", "email"} {
+		if _, ok := header[column]; !ok {
+			return nil, nil, ErrImportMissingColumns
+		}
+	}
+
+	cell := func(record []string, column string) string {
+		i, ok := header[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	report := &models.UserImportReport{Rows: []models.UserImportRow{}, Deactivated: []models.UserImportDeactivation{}}
+	present := make(map[string]bool)
+	nipRows := make(map[string]int)
+	emailRows := make(map[string]int)
+
+	for i, record := range rows[1:] {
+		row := models.UserImportRow{
+			Row:   i + 2,
+			Nip:   cell(record, "nip"),
+			Name:  cell(record, "name"),
+			Email: cell(record, "email"),
+			Role:  strings.ToLower(cell(record, "role")),
+		}
+		if row.Nip == "" && row.Name == "" && row.Email == "" && row.Role == "" {
+			continue
+		}
+		if len(report.Rows) == UserImportMaxRows {
+			return nil, nil, ErrImportTooLarge
+		}
+
+		row.Errors = validateImportRow(&row)
+		if row.Nip != "" {
+			present[row.Nip] = true
+			if first, seen := nipRows[row.Nip]; seen {
+				row.Errors = append(row.Errors, fmt.Sprintf("NIP already appears on row %d", first))
+			} else {
+				nipRows[row.Nip] = row.Row
+			}
+		}
+		if row.Email != "" {
+			email := strings.ToLower(row.Email)
+			if first, seen := emailRows[email]; seen {
+				row.Errors = append(row.Errors, fmt.Sprintf("email already appears on row %d", first))
+			} else {
+				emailRows[email] = row.Row
+			}
+		}
+		if len(row.Errors) > 0 {
+			row.Result = constants.UserImportFailed
+		}
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	if len(report.Rows) == 0 {
+		return nil, nil, ErrImportEmpty
+	}
+
+	return report, present, nil
+}
+
+// validateImportRow applies the models.User field rules to a row.
+func validateImportRow(row *models.UserImportRow) []string {
+	var errs []string
+
+	if len(row.Nip) != 12 || strings.Trim(row.Nip, "0123456789") != "" {
+		errs = append(errs, "NIP must be exactly 12 digits")
+	}
+	if n := utf8.RuneCountInString(row.Name); n < 2 || n > 100 {
+		errs = append(errs, "name must be between 2 and 100 characters")
+	}
+	if row.Email == "" || importValidator.Var(row.Email, "email,max=255") != nil {
+		errs = append(errs, "email is not a valid address")
+	}
+	if row.Role != "" && !constants.IsValidRole(row.Role) {
+		errs = append(errs, fmt.Sprintf("role %q is not valid", row.Role))
+	}
+
+	return errs
+}
+
+func failImportRow(row *models.UserImportRow, message string) error {
+	row.Result = constants.UserImportFailed
+	row.Errors = append(row.Errors, message)
+	return nil
+}
@@ -0,0 +1,198 @@
+// Package jwtkeys holds the keys used to sign and verify the API's own JWTs.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/bobchopperz/bahrululum/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// KeySet signs tokens with one key and verifies tokens signed by any of its
+// keys. Keeping retired keys around as verify-only lets tokens they signed
+// stay valid until they expire.
+type KeySet struct {
+	signer *key
+	keys   map[string]*key
+	// hmac is the legacy shared secret. It signs when no signing key is
+	// configured and otherwise still verifies HS256 tokens during migration.
+	hmac []byte
+}
+
+// Load reads the configured PEM keys. Without a signing key the set falls
+// back to HS256 with jwt.secret.
+func Load(cfg *config.JWTConfig) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*key)}
+	if cfg.Secret != "" {
+		ks.hmac = []byte(cfg.Secret)
+	}
+
+	for _, kc := range cfg.Keys {
+		if kc.ID == "" {
+			return nil, errors.New("jwt key without id")
+		}
+		if _, exists := ks.keys[kc.ID]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %q", kc.ID)
+		}
+
+		k, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", kc.ID, err)
+		}
+		ks.keys[kc.ID] = k
+	}
+
+	if cfg.SigningKeyID != "" {
+		signer, ok := ks.keys[cfg.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("signing key %q is not configured", cfg.SigningKeyID)
+		}
+		if signer.private == nil {
+			return nil, fmt.Errorf("signing key %q has no private key", cfg.SigningKeyID)
+		}
+		ks.signer = signer
+	} else if ks.hmac == nil {
+		return nil, errors.New("either jwt.secret or jwt.signing_key_id must be set")
+	}
+
+	return ks, nil
+}
+
+// Sign signs claims with the active key, adding its kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.signer == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.hmac)
+	}
+
+	token := jwt.NewWithClaims(ks.signer.method, claims)
+	token.Header["kid"] = ks.signer.id
+	return token.SignedString(ks.signer.private)
+}
+
+// Keyfunc picks the verification key for a token from its kid and checks
+// that the token's algorithm matches the key.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if ks.hmac == nil {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return ks.hmac, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return k.public, nil
+}
+
+// JWKS returns the public keys for publishing. The HMAC secret is never
+// included.
+func (ks *KeySet) JWKS() (*JWKS, error) {
+	set := &JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		jwk, err := NewJWK(k.id, k.method.Alg(), k.public)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set, nil
+}
+
+// loadKey reads a private key, or only a public key for verify-only keys.
+func loadKey(kc config.JWTKeyConfig) (*key, error) {
+	k := &key{id: kc.ID}
+
+	switch {
+	case kc.PrivateKeyFile != "":
+		block, err := readPEM(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+		k.private = private
+		k.public = private.(crypto.Signer).Public()
+	case kc.PublicKeyFile != "":
+		block, err := readPEM(kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k.public = public
+	default:
+		return nil, errors.New("private_key_file or public_key_file is required")
+	}
+
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		k.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", k.public)
+	}
+
+	return k, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.PrivateKey, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case *rsa.PrivateKey, ed25519.PrivateKey:
+			return key, nil
+		default:
+			return nil, fmt.Errorf("unsupported private key type %T, use RSA or Ed25519", key)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
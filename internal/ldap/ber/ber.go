@@ -0,0 +1,238 @@
+// Package ber implements the subset of ASN.1 Basic Encoding Rules used by
+// LDAP: definite lengths and single byte tags.
+package ber
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	ClassUniversal   byte = 0x00
+	ClassApplication byte = 0x40
+	ClassContext     byte = 0x80
+
+	Constructed byte = 0x20
+
+	TagBoolean     byte = 0x01
+	TagInteger     byte = 0x02
+	TagOctetString byte = 0x04
+	TagNull        byte = 0x05
+	TagEnumerated  byte = 0x0a
+	TagSequence    byte = 0x30 // constructed
+	TagSet         byte = 0x31 // constructed
+)
+
+// maxLength bounds a single element so a hostile peer cannot make us
+// allocate arbitrary amounts of memory.
+const maxLength = 16 << 20
+
+var ErrMalformed = errors.New("ber: malformed packet")
+
+// Packet is a decoded element. Children is populated for constructed
+// elements, Value holds the raw content otherwise.
+type Packet struct {
+	Tag      byte
+	Value    []byte
+	Children []*Packet
+}
+
+func (p *Packet) IsConstructed() bool {
+	return p.Tag&Constructed != 0
+}
+
+// String returns the content as a string, for OCTET STRING elements.
+func (p *Packet) String() string {
+	return string(p.Value)
+}
+
+// Int decodes the content as a two's complement integer, for INTEGER and
+// ENUMERATED elements.
+func (p *Packet) Int() (int64, error) {
+	if len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, ErrMalformed
+	}
+	v := int64(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+func (p *Packet) Bool() bool {
+	return len(p.Value) > 0 && p.Value[0] != 0
+}
+
+// Child returns the i-th child or nil.
+func (p *Packet) Child(i int) *Packet {
+	if i < 0 || i >= len(p.Children) {
+		return nil
+	}
+	return p.Children[i]
+}
+
+// Read reads one element from r.
+func Read(r *bufio.Reader) (*Packet, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if tag&0x1f == 0x1f {
+		return nil, fmt.Errorf("%w: multi-byte tags are not supported", ErrMalformed)
+	}
+
+	length, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+
+	return decode(tag, content)
+}
+
+// Decode parses a single element from b.
+func Decode(b []byte) (*Packet, error) {
+	return Read(bufio.NewReader(bytes.NewReader(b)))
+}
+
+func decode(tag byte, content []byte) (*Packet, error) {
+	p := &Packet{Tag: tag}
+	if tag&Constructed == 0 {
+		p.Value = content
+		return p, nil
+	}
+
+	for len(content) > 0 {
+		if len(content) < 2 {
+			return nil, ErrMalformed
+		}
+		childTag := content[0]
+		length, n, err := parseLength(content[1:])
+		if err != nil {
+			return nil, err
+		}
+		start := 1 + n
+		if length > len(content)-start {
+			return nil, ErrMalformed
+		}
+		child, err := decode(childTag, content[start:start+length])
+		if err != nil {
+			return nil, err
+		}
+		p.Children = append(p.Children, child)
+		content = content[start+length:]
+	}
+	return p, nil
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if first&0x80 == 0 {
+		return int(first), nil
+	}
+
+	n := int(first & 0x7f)
+	if n == 0 || n > 4 {
+		return 0, fmt.Errorf("%w: unsupported length encoding", ErrMalformed)
+	}
+	length := 0
+	for i := 0; i < n; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	if length > maxLength {
+		return 0, fmt.Errorf("%w: element too large", ErrMalformed)
+	}
+	return length, nil
+}
+
+func parseLength(b []byte) (length, consumed int, err error) {
+	if len(b) == 0 {
+		return 0, 0, ErrMalformed
+	}
+	if b[0]&0x80 == 0 {
+		return int(b[0]), 1, nil
+	}
+	n := int(b[0] & 0x7f)
+	if n == 0 || n > 4 || len(b) < 1+n {
+		return 0, 0, ErrMalformed
+	}
+	for _, c := range b[1 : 1+n] {
+		length = length<<8 | int(c)
+	}
+	if length > maxLength {
+		return 0, 0, ErrMalformed
+	}
+	return length, 1 + n, nil
+}
+
+// Encode builds an element from a tag and its already encoded content.
+func Encode(tag byte, content []byte) []byte {
+	out := []byte{tag}
+	out = append(out, encodeLength(len(content))...)
+	return append(out, content...)
+}
+
+// Construct builds a constructed element from encoded children.
+func Construct(tag byte, children ...[]byte) []byte {
+	var content []byte
+	for _, child := range children {
+		content = append(content, child...)
+	}
+	return Encode(tag, content)
+}
+
+func OctetString(s string) []byte {
+	return Encode(TagOctetString, []byte(s))
+}
+
+func Integer(v int64) []byte {
+	return Encode(TagInteger, encodeInt(v))
+}
+
+func Enumerated(v int64) []byte {
+	return Encode(TagEnumerated, encodeInt(v))
+}
+
+func Boolean(v bool) []byte {
+	if v {
+		return Encode(TagBoolean, []byte{0xff})
+	}
+	return Encode(TagBoolean, []byte{0x00})
+}
+
+func encodeInt(v int64) []byte {
+	var out []byte
+	for {
+		out = append([]byte{byte(v)}, out...)
+		v >>= 8
+		if (v == 0 && out[0]&0x80 == 0) || (v == -1 && out[0]&0x80 != 0) {
+			return out
+		}
+	}
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var digits []byte
+	for n > 0 {
+		digits = append([]byte{byte(n)}, digits...)
+		n >>= 8
+	}
+	return append([]byte{0x80 | byte(len(digits))}, digits...)
+}
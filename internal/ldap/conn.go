@@ -0,0 +1,257 @@
+// Package ldap is a small LDAPv3 client covering what directory login needs:
+// simple bind, subtree search with equality filters, and StartTLS.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/ldap/ber"
+)
+
+var ErrEmptyPassword = errors.New("ldap: refusing to bind with an empty password")
+
+// Conn is a connection to a directory server. Requests are sent one at a
+// time; a Conn is not safe for concurrent use.
+type Conn struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+	nextID  int64
+}
+
+// Dial connects to an ldap:// or ldaps:// URL.
+func Dial(ctx context.Context, rawURL string, tlsConfig *tls.Config, timeout time.Duration) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: invalid url: %w", err)
+	}
+
+	host := u.Host
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	case "ldaps":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: serverConfig(tlsConfig, u.Hostname())}).DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("ldap: unsupported scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldap: dial %s: %w", host, err)
+	}
+
+	return &Conn{conn: conn, r: bufio.NewReader(conn), timeout: timeout}, nil
+}
+
+// StartTLS upgrades a plain connection to TLS.
+func (c *Conn) StartTLS(tlsConfig *tls.Config, serverName string) error {
+	op := ber.Construct(TagExtendedRequest, ber.Encode(TagExtendedName, []byte(OIDStartTLS)))
+
+	resp, err := c.roundTrip(op, TagExtendedResponse)
+	if err != nil {
+		return err
+	}
+	if err := result(resp); err != nil {
+		return err
+	}
+
+	tlsConn := tls.Client(c.conn, serverConfig(tlsConfig, serverName))
+	if err := tlsConn.Handshake(); err != nil {
+		return fmt.Errorf("ldap: starttls handshake: %w", err)
+	}
+
+	c.conn = tlsConn
+	c.r = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// Bind authenticates with a DN and password. Empty passwords are rejected
+// because servers treat them as an anonymous bind that always succeeds.
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+
+	op := ber.Construct(TagBindRequest,
+		ber.Integer(3),
+		ber.OctetString(dn),
+		ber.Encode(TagSimpleAuth, []byte(password)),
+	)
+
+	resp, err := c.roundTrip(op, TagBindResponse)
+	if err != nil {
+		return err
+	}
+	return result(resp)
+}
+
+type SearchRequest struct {
+	BaseDN     string
+	Filter     Filter
+	Attributes []string
+	SizeLimit  int
+}
+
+// Search runs a subtree search and collects the returned entries. Referrals
+// are ignored.
+func (c *Conn) Search(req *SearchRequest) ([]*Entry, error) {
+	attrs := make([][]byte, len(req.Attributes))
+	for i, attr := range req.Attributes {
+		attrs[i] = ber.OctetString(attr)
+	}
+
+	op := ber.Construct(TagSearchRequest,
+		ber.OctetString(req.BaseDN),
+		ber.Enumerated(scopeWholeSubtree),
+		ber.Enumerated(derefNever),
+		ber.Integer(int64(req.SizeLimit)),
+		ber.Integer(int64(c.timeout/time.Second)),
+		ber.Boolean(false),
+		req.Filter,
+		ber.Construct(ber.TagSequence, attrs...),
+	)
+
+	id, err := c.send(op)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for {
+		resp, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch resp.Tag {
+		case TagSearchEntry:
+			entry, err := parseEntry(resp)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case TagSearchReference:
+			continue
+		case TagSearchDone:
+			if err := result(resp); err != nil {
+				return nil, err
+			}
+			return entries, nil
+		default:
+			return nil, &Error{Code: ResultProtocolError, Message: fmt.Sprintf("unexpected response tag 0x%x", resp.Tag)}
+		}
+	}
+}
+
+// Close sends an unbind request and closes the connection.
+func (c *Conn) Close() error {
+	c.send(ber.Encode(TagUnbindRequest, nil))
+	return c.conn.Close()
+}
+
+func (c *Conn) roundTrip(op []byte, expectTag byte) (*ber.Packet, error) {
+	id, err := c.send(op)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.receive(id)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Tag != expectTag {
+		return nil, &Error{Code: ResultProtocolError, Message: fmt.Sprintf("unexpected response tag 0x%x", resp.Tag)}
+	}
+	return resp, nil
+}
+
+func (c *Conn) send(op []byte) (int64, error) {
+	c.nextID++
+	id := c.nextID
+
+	if c.timeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	}
+	if _, err := c.conn.Write(Message(id, op)); err != nil {
+		return 0, fmt.Errorf("ldap: write: %w", err)
+	}
+	return id, nil
+}
+
+// receive reads the next response for message id and returns its protocol
+// operation. Unsolicited notifications (message id 0) are skipped.
+func (c *Conn) receive(id int64) (*ber.Packet, error) {
+	for {
+		if c.timeout > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.timeout))
+		}
+
+		msg, err := ber.Read(c.r)
+		if err != nil {
+			return nil, fmt.Errorf("ldap: read: %w", err)
+		}
+		if msg.Tag != ber.TagSequence || len(msg.Children) < 2 {
+			return nil, &Error{Code: ResultProtocolError, Message: "malformed message"}
+		}
+
+		msgID, err := msg.Children[0].Int()
+		if err != nil {
+			return nil, &Error{Code: ResultProtocolError, Message: "malformed message id"}
+		}
+		if msgID == id {
+			return msg.Children[1], nil
+		}
+		if msgID == 0 {
+			if err := result(msg.Children[1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func parseEntry(op *ber.Packet) (*Entry, error) {
+	if len(op.Children) < 2 {
+		return nil, &Error{Code: ResultProtocolError, Message: "malformed search entry"}
+	}
+
+	entry := &Entry{
+		DN:         op.Children[0].String(),
+		Attributes: make(map[string][]string),
+	}
+	for _, attr := range op.Children[1].Children {
+		if len(attr.Children) < 2 {
+			continue
+		}
+		name := attr.Children[0].String()
+		for _, value := range attr.Children[1].Children {
+			entry.Attributes[name] = append(entry.Attributes[name], value.String())
+		}
+	}
+	return entry, nil
+}
+
+func serverConfig(cfg *tls.Config, serverName string) *tls.Config {
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+	cfg = cfg.Clone()
+	if cfg.ServerName == "" {
+		cfg.ServerName = serverName
+	}
+	return cfg
+}
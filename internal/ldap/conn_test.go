@@ -0,0 +1,78 @@
+package ldap_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bobchopperz/bahrululum/internal/ldap"
+	"github.com/bobchopperz/bahrululum/internal/ldap/ldaptest"
+)
+
+const userDN = "CN=Siti Aminah,OU=Staff,DC=example,DC=com"
+
+func dial(t *testing.T) *ldap.Conn {
+	t.Helper()
+
+	server, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	server.AddEntry(userDN, "s3cret-Passw0rd", map[string][]string{
+		"objectClass": {"person"},
+		"employeeID":  {"198703152010"},
+		"memberOf":    {"CN=LMS Mentors,OU=Groups,DC=example,DC=com", "CN=Staff,OU=Groups,DC=example,DC=com"},
+	})
+
+	conn, err := ldap.Dial(context.Background(), server.URL(), nil, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestBind(t *testing.T) {
+	conn := dial(t)
+
+	if err := conn.Bind(userDN, "s3cret-Passw0rd"); err != nil {
+		t.Fatalf("bind with the right password: %v", err)
+	}
+
+	err := conn.Bind(userDN, "wrong")
+	var ldapErr *ldap.Error
+	if !errors.As(err, &ldapErr) || ldapErr.Code != ldap.ResultInvalidCredentials {
+		t.Fatalf("bind with a wrong password = %v, want invalid credentials", err)
+	}
+}
+
+// An empty password would be an unauthenticated bind, which many directories
+// accept for any DN.
+func TestBindRejectsEmptyPassword(t *testing.T) {
+	conn := dial(t)
+
+	if err := conn.Bind(userDN, ""); !errors.Is(err, ldap.ErrEmptyPassword) {
+		t.Fatalf("bind with an empty password = %v, want %v", err, ldap.ErrEmptyPassword)
+	}
+}
+
+func TestSearchReturnsEveryGroup(t *testing.T) {
+	conn := dial(t)
+
+	entries, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     "OU=Staff,DC=example,DC=com",
+		Filter:     ldap.And(ldap.Equal("objectClass", "person"), ldap.Equal("employeeID", "198703152010")),
+		Attributes: []string{"memberOf"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("found %d entries, want 1", len(entries))
+	}
+	if groups := entries[0].GetAll("memberof"); len(groups) != 2 {
+		t.Fatalf("memberOf = %v, want both groups", groups)
+	}
+}
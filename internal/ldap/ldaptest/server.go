@@ -0,0 +1,216 @@
+// Package ldaptest provides an in-process directory server for exercising
+// LDAP login without a real directory. It supports simple bind and subtree
+// search with and/or/equality/presence filters, which is all the ldap
+// package sends.
+package ldaptest
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/bobchopperz/bahrululum/internal/ldap"
+	"github.com/bobchopperz/bahrululum/internal/ldap/ber"
+)
+
+type entry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// Server is a directory listening on a loopback port.
+type Server struct {
+	listener net.Listener
+
+	mu      sync.RWMutex
+	entries []*entry
+	wg      sync.WaitGroup
+}
+
+// NewServer starts a server on 127.0.0.1 with an ephemeral port.
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// URL returns the ldap:// URL clients should dial.
+func (s *Server) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// AddEntry adds an entry that can bind with password, unless password is
+// empty.
+func (s *Server) AddEntry(dn, password string, attributes map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{dn: dn, password: password, attributes: attributes})
+}
+
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		msg, err := ber.Read(r)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, err := msg.Children[0].Int()
+		if err != nil {
+			return
+		}
+		op := msg.Children[1]
+
+		switch op.Tag {
+		case ldap.TagBindRequest:
+			conn.Write(ldap.Message(id, s.bind(op)))
+		case ldap.TagSearchRequest:
+			for _, resp := range s.search(op) {
+				conn.Write(ldap.Message(id, resp))
+			}
+		case ldap.TagUnbindRequest:
+			return
+		case ldap.TagExtendedRequest:
+			conn.Write(ldap.Message(id, ldap.ResultPacket(ldap.TagExtendedResponse, ldap.ResultProtocolError, "extended operations are not supported")))
+		default:
+			return
+		}
+	}
+}
+
+func (s *Server) bind(op *ber.Packet) []byte {
+	if len(op.Children) < 3 || op.Children[2].Tag != ldap.TagSimpleAuth {
+		return ldap.ResultPacket(ldap.TagBindResponse, ldap.ResultProtocolError, "only simple bind is supported")
+	}
+	dn, password := op.Children[1].String(), op.Children[2].String()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, e := range s.entries {
+		if strings.EqualFold(e.dn, dn) && e.password != "" && e.password == password {
+			return ldap.ResultPacket(ldap.TagBindResponse, ldap.ResultSuccess, "")
+		}
+	}
+	return ldap.ResultPacket(ldap.TagBindResponse, ldap.ResultInvalidCredentials, "invalid credentials")
+}
+
+func (s *Server) search(op *ber.Packet) [][]byte {
+	if len(op.Children) < 8 {
+		return [][]byte{ldap.ResultPacket(ldap.TagSearchDone, ldap.ResultProtocolError, "malformed search")}
+	}
+	base := strings.ToLower(op.Children[0].String())
+	filter := op.Children[6]
+
+	var wanted []string
+	for _, attr := range op.Children[7].Children {
+		wanted = append(wanted, attr.String())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out [][]byte
+	for _, e := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(e.dn), base) || !matches(filter, e) {
+			continue
+		}
+		out = append(out, encodeEntry(e, wanted))
+	}
+	return append(out, ldap.ResultPacket(ldap.TagSearchDone, ldap.ResultSuccess, ""))
+}
+
+func matches(filter *ber.Packet, e *entry) bool {
+	switch filter.Tag {
+	case ldap.TagFilterAnd:
+		for _, child := range filter.Children {
+			if !matches(child, e) {
+				return false
+			}
+		}
+		return true
+	case ldap.TagFilterOr:
+		for _, child := range filter.Children {
+			if matches(child, e) {
+				return true
+			}
+		}
+		return false
+	case ldap.TagFilterEqual:
+		if len(filter.Children) < 2 {
+			return false
+		}
+		for _, value := range values(e, filter.Children[0].String()) {
+			if strings.EqualFold(value, filter.Children[1].String()) {
+				return true
+			}
+		}
+		return false
+	case ldap.TagFilterPresent:
+		return len(values(e, filter.String())) > 0
+	default:
+		return false
+	}
+}
+
+func values(e *entry, attr string) []string {
+	for name, vals := range e.attributes {
+		if strings.EqualFold(name, attr) {
+			return vals
+		}
+	}
+	return nil
+}
+
+func encodeEntry(e *entry, wanted []string) []byte {
+	var attrs [][]byte
+	for name, vals := range e.attributes {
+		if len(wanted) > 0 && !contains(wanted, name) {
+			continue
+		}
+		encoded := make([][]byte, len(vals))
+		for i, v := range vals {
+			encoded[i] = ber.OctetString(v)
+		}
+		attrs = append(attrs, ber.Construct(ber.TagSequence, ber.OctetString(name), ber.Construct(ber.TagSet, encoded...)))
+	}
+	return ber.Construct(ldap.TagSearchEntry, ber.OctetString(e.dn), ber.Construct(ber.TagSequence, attrs...))
+}
+
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, name) {
+			return true
+		}
+	}
+	return false
+}
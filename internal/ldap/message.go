@@ -0,0 +1,138 @@
+package ldap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bobchopperz/bahrululum/internal/ldap/ber"
+)
+
+// Protocol operation tags (RFC 4511 section 4.2 onwards).
+const (
+	TagBindRequest      = ber.ClassApplication | ber.Constructed | 0
+	TagBindResponse     = ber.ClassApplication | ber.Constructed | 1
+	TagUnbindRequest    = ber.ClassApplication | 2
+	TagSearchRequest    = ber.ClassApplication | ber.Constructed | 3
+	TagSearchEntry      = ber.ClassApplication | ber.Constructed | 4
+	TagSearchDone       = ber.ClassApplication | ber.Constructed | 5
+	TagSearchReference  = ber.ClassApplication | ber.Constructed | 19
+	TagExtendedRequest  = ber.ClassApplication | ber.Constructed | 23
+	TagExtendedResponse = ber.ClassApplication | ber.Constructed | 24
+
+	TagSimpleAuth    = ber.ClassContext | 0
+	TagExtendedName  = ber.ClassContext | 0
+	TagFilterAnd     = ber.ClassContext | ber.Constructed | 0
+	TagFilterOr      = ber.ClassContext | ber.Constructed | 1
+	TagFilterEqual   = ber.ClassContext | ber.Constructed | 3
+	TagFilterPresent = ber.ClassContext | 7
+)
+
+// Result codes.
+const (
+	ResultSuccess            = 0
+	ResultOperationsError    = 1
+	ResultProtocolError      = 2
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+	ResultUnwillingToPerform = 53
+)
+
+const (
+	OIDStartTLS = "1.3.6.1.4.1.1466.20037"
+
+	scopeWholeSubtree = 2
+	derefNever        = 0
+)
+
+// Error is a non-success LDAP result.
+type Error struct {
+	Code    int64
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.Code)
+	}
+	return fmt.Sprintf("ldap: result code %d: %s", e.Code, e.Message)
+}
+
+// Filter is an encoded search filter. Build filters with Equal, Present,
+// And and Or so values are never spliced into a filter string.
+type Filter []byte
+
+func Equal(attr, value string) Filter {
+	return ber.Construct(TagFilterEqual, ber.OctetString(attr), ber.OctetString(value))
+}
+
+func Present(attr string) Filter {
+	return ber.Encode(TagFilterPresent, []byte(attr))
+}
+
+func And(filters ...Filter) Filter {
+	return ber.Construct(TagFilterAnd, toBytes(filters)...)
+}
+
+func Or(filters ...Filter) Filter {
+	return ber.Construct(TagFilterOr, toBytes(filters)...)
+}
+
+func toBytes(filters []Filter) [][]byte {
+	out := make([][]byte, len(filters))
+	for i, f := range filters {
+		out[i] = f
+	}
+	return out
+}
+
+// Entry is a search result.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Get returns the first value of an attribute. Attribute names are matched
+// case-insensitively, as LDAP does.
+func (e *Entry) Get(attr string) string {
+	for name, values := range e.Attributes {
+		if len(values) > 0 && strings.EqualFold(name, attr) {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// GetAll returns every value of an attribute, such as the groups in memberOf.
+func (e *Entry) GetAll(attr string) []string {
+	for name, values := range e.Attributes {
+		if strings.EqualFold(name, attr) {
+			return values
+		}
+	}
+	return nil
+}
+
+// result decodes an LDAPResult sequence into an error, or nil on success.
+func result(op *ber.Packet) error {
+	if len(op.Children) < 3 {
+		return &Error{Code: ResultProtocolError, Message: "malformed result"}
+	}
+	code, err := op.Children[0].Int()
+	if err != nil {
+		return &Error{Code: ResultProtocolError, Message: "malformed result code"}
+	}
+	if code == ResultSuccess {
+		return nil
+	}
+	return &Error{Code: code, Message: op.Children[2].String()}
+}
+
+// ResultPacket encodes an LDAPResult with the given operation tag.
+func ResultPacket(tag byte, code int64, message string) []byte {
+	return ber.Construct(tag, ber.Enumerated(code), ber.OctetString(""), ber.OctetString(message))
+}
+
+// Message wraps a protocol operation in an LDAPMessage envelope.
+func Message(id int64, op []byte) []byte {
+	return ber.Construct(ber.TagSequence, ber.Integer(id), op)
+}
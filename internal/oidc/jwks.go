@@ -0,0 +1,70 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+
+	"github.com/bobchopperz/bahrululum/internal/jwtkeys"
+)
+
+type keySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// key returns the signing key with the given id, refetching the key set once
+// when the id is unknown so provider key rotation is picked up.
+func (p *Provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	keys := p.keys
+	p.mu.Unlock()
+
+	if keys != nil {
+		if key, ok := keys.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks jwtkeys.JWKS
+	if err := p.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+
+	keys = &keySet{keys: make(map[string]crypto.PublicKey, len(jwks.Keys))}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys.keys[jwk.Kid] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	if key, ok := keys.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc jwks: no key with id %q", kid)
+}
+
+// lookup finds a key by id. Tokens without a kid are accepted only when the
+// set holds a single key.
+func (s *keySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
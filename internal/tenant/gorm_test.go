@@ -0,0 +1,155 @@
+package tenant_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"github.com/bobchopperz/bahrululum/internal/tenant/tenanttest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type note struct {
+	ID       uint
+	TenantID uint
+	Body     string
+}
+
+// setting is not tenant owned.
+type setting struct {
+	ID    uint
+	Value string
+}
+
+func open(t *testing.T) (*gorm.DB, *tenanttest.Recorder) {
+	t.Helper()
+
+	db, recorder, err := tenanttest.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, recorder
+}
+
+// operations runs each callback the plugin hooks on a tenant-owned model.
+var operations = []struct {
+	name string
+	run  func(db *gorm.DB) error
+}{
+	{"query", func(db *gorm.DB) error { return db.Find(&[]note{}).Error }},
+	{"query by key", func(db *gorm.DB) error { return db.Where("body = ?", "x").Limit(1).Find(&note{}).Error }},
+	{"count", func(db *gorm.DB) error { var n int64; return db.Model(&note{}).Count(&n).Error }},
+	{"row", func(db *gorm.DB) error { return db.Model(&note{}).Select("count(*)").Row().Err() }},
+	{"rows", func(db *gorm.DB) error {
+		rows, err := db.Model(&note{}).Select("id").Rows()
+		if err == nil {
+			rows.Close()
+		}
+		return err
+	}},
+	{"update", func(db *gorm.DB) error { return db.Model(&note{ID: 1}).Update("body", "x").Error }},
+	{"update where", func(db *gorm.DB) error {
+		return db.Model(&note{}).Where("body = ?", "x").Updates(map[string]any{"body": "y"}).Error
+	}},
+	{"save", func(db *gorm.DB) error { return db.Save(&note{ID: 1, TenantID: 1, Body: "x"}).Error }},
+	{"delete", func(db *gorm.DB) error { return db.Delete(&note{ID: 1}).Error }},
+	{"delete where", func(db *gorm.DB) error { return db.Where("body = ?", "x").Delete(&note{}).Error }},
+	{"create", func(db *gorm.DB) error { return db.Create(&note{Body: "x"}).Error }},
+	{"create batch", func(db *gorm.DB) error { return db.Create(&[]note{{Body: "x"}, {Body: "y"}}).Error }},
+	{"upsert", func(db *gorm.DB) error {
+		return db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoUpdates: clause.AssignmentColumns([]string{"body"})}).Create(&note{ID: 1, Body: "x"}).Error
+	}},
+}
+
+func TestStatementsStayInTheContextTenant(t *testing.T) {
+	db, recorder := open(t)
+
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			recorder.Reset()
+			if err := op.run(db.WithContext(tenant.WithID(context.Background(), 2))); err != nil {
+				t.Fatal(err)
+			}
+
+			statements := recorder.Statements()
+			if len(statements) == 0 {
+				t.Fatal("no statement sent")
+			}
+			for _, statement := range statements {
+				if err := statement.CheckScoped(2); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateStampsTheContextTenant(t *testing.T) {
+	db, _ := open(t)
+	ctx := tenant.WithID(context.Background(), 2)
+
+	planted := &note{TenantID: 1, Body: "x"}
+	if err := db.WithContext(ctx).Create(planted).Error; err != nil {
+		t.Fatal(err)
+	}
+	if planted.TenantID != 2 {
+		t.Fatalf("created in tenant %d, want 2", planted.TenantID)
+	}
+
+	batch := []*note{{TenantID: 1}, {}}
+	if err := db.WithContext(ctx).Create(&batch).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range batch {
+		if n.TenantID != 2 {
+			t.Fatalf("batch row created in tenant %d, want 2", n.TenantID)
+		}
+	}
+}
+
+// A context without a tenant is a system context and reaches every tenant,
+// so only background work should run in one.
+func TestSystemContextIsUnscoped(t *testing.T) {
+	db, recorder := open(t)
+
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			recorder.Reset()
+			if err := op.run(db.WithContext(context.Background())); err != nil {
+				t.Fatal(err)
+			}
+			for _, statement := range recorder.Statements() {
+				if strings.Contains(statement.SQL, `"tenant_id" =`) {
+					t.Errorf("system context scoped: %s", statement.SQL)
+				}
+			}
+		})
+	}
+
+	kept := &note{TenantID: 3}
+	if err := db.Create(kept).Error; err != nil {
+		t.Fatal(err)
+	}
+	if kept.TenantID != 3 {
+		t.Fatalf("system context changed the tenant to %d", kept.TenantID)
+	}
+}
+
+func TestModelsWithoutTenantAreUnscoped(t *testing.T) {
+	db, recorder := open(t)
+	ctx := tenant.WithID(context.Background(), 2)
+
+	if err := db.WithContext(ctx).Find(&[]setting{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(ctx).Model(&setting{ID: 1}).Update("value", "x").Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range recorder.Statements() {
+		if strings.Contains(statement.SQL, "tenant_id") {
+			t.Errorf("unowned model scoped: %s", statement.SQL)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Package tenant carries the current tenant through request contexts and
+// keeps database access inside it.
+//
+// Every model with a TenantID field is tenant owned. While a context carries
+// a tenant, the GORM plugin adds a tenant_id condition to each query, update
+// and delete on such a model and stamps new rows with the tenant. A context
+// without a tenant is a system context and is not scoped; only background
+// work that spans tenants, such as the reminder scan, should use one.
+package tenant
+
+import "context"
+
+// DefaultID is the tenant created by the migration that introduced tenants.
+// Data from before multi-tenancy belongs to it.
+const DefaultID uint = 1
+
+type contextKey struct{}
+
+// WithID returns a copy of ctx scoped to the tenant.
+func WithID(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx is scoped to.
+func FromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(contextKey{}).(uint)
+	return id, ok && id != 0
+}
+
+// System returns a copy of ctx that is not scoped to any tenant, for lookups
+// that find the tenant a request belongs to.
+func System(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, uint(0))
+}
+
+// ID returns the tenant ctx is scoped to, or 0 in a system context.
+func ID(ctx context.Context) uint {
+	id, _ := FromContext(ctx)
+	return id
+}
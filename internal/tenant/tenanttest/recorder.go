@@ -0,0 +1,210 @@
+// Package tenanttest opens GORM on a driver that records the SQL it is sent
+// instead of running it, for checking how the tenant plugin scopes the
+// statements repositories build. Queries return no rows and statements
+// affect none.
+package tenanttest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bobchopperz/bahrululum/internal/tenant"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement is one statement sent to the database.
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// Recorder collects the statements sent through a connection.
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+}
+
+// Open returns a GORM connection with the tenant plugin installed and the
+// recorder behind it.
+func Open() (*gorm.DB, *Recorder, error) {
+	recorder := &Recorder{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(recorder)}), &gorm.Config{
+		Logger:               logger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		return nil, nil, err
+	}
+	return db, recorder, nil
+}
+
+// Statements returns the statements recorded since the last Reset.
+func (r *Recorder) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.statements...)
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = nil
+}
+
+func (r *Recorder) record(query string, args []driver.NamedValue) {
+	statement := Statement{SQL: query, Args: make([]any, len(args))}
+	for i, arg := range args {
+		statement.Args[i] = arg.Value
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, statement)
+}
+
+var (
+	tenantCondition = regexp.MustCompile(`"tenant_id" = \$(\d+)`)
+	insertColumns   = regexp.MustCompile(`^INSERT INTO "[^"]+" \(([^)]*)\) VALUES `)
+)
+
+// CheckScoped returns why the statement could read or write rows outside
+// the tenant, or nil if it cannot. Selects, updates and deletes need a
+// tenant_id condition, inserts a tenant_id value, and upserts that update on
+// conflict a tenant_id condition on the update too. Every tenant_id bound
+// must be the tenant.
+func (s Statement) CheckScoped(id uint) error {
+	conditions := tenantCondition.FindAllStringSubmatchIndex(s.SQL, -1)
+	for _, condition := range conditions {
+		n, _ := strconv.Atoi(s.SQL[condition[2]:condition[3]])
+		if err := s.checkArg(n, id); err != nil {
+			return err
+		}
+	}
+
+	columns := insertColumns.FindStringSubmatch(s.SQL)
+	if columns == nil {
+		if len(conditions) == 0 {
+			return fmt.Errorf("no tenant_id condition in %s", s.SQL)
+		}
+		return nil
+	}
+
+	names := strings.Split(columns[1], ",")
+	column := -1
+	for i, name := range names {
+		if name == `"tenant_id"` {
+			column = i
+		}
+	}
+	if column < 0 {
+		return fmt.Errorf("no tenant_id column in %s", s.SQL)
+	}
+
+	// The rows' values are bound in order, one parameter per column.
+	values := s.SQL[len(columns[0]):]
+	if end := strings.Index(values, " ON CONFLICT"); end >= 0 {
+		values = values[:end]
+	}
+	if end := strings.Index(values, " RETURNING"); end >= 0 {
+		values = values[:end]
+	}
+	for row := 0; row < strings.Count(values, "("); row++ {
+		if err := s.checkArg(row*len(names)+column+1, id); err != nil {
+			return err
+		}
+	}
+
+	if update := strings.Index(s.SQL, "DO UPDATE"); update >= 0 && !tenantCondition.MatchString(s.SQL[update:]) {
+		return fmt.Errorf("no tenant_id condition on the conflict update in %s", s.SQL)
+	}
+	return nil
+}
+
+func (s Statement) checkArg(n int, id uint) error {
+	if n < 1 || n > len(s.Args) {
+		return fmt.Errorf("parameter $%d not bound in %s", n, s.SQL)
+	}
+	if got := fmt.Sprint(s.Args[n-1]); got != strconv.FormatUint(uint64(id), 10) {
+		return fmt.Errorf("tenant %s bound in %s, want %d", got, s.SQL, id)
+	}
+	return nil
+}
+
+// The recorder is its own connector, driver, connection and transaction.
+
+func (r *Recorder) Connect(context.Context) (driver.Conn, error) {
+	return r, nil
+}
+
+func (r *Recorder) Driver() driver.Driver {
+	return r
+}
+
+func (r *Recorder) Open(string) (driver.Conn, error) {
+	return r, nil
+}
+
+func (r *Recorder) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (r *Recorder) Close() error {
+	return nil
+}
+
+func (r *Recorder) Begin() (driver.Tx, error) {
+	return r, nil
+}
+
+func (r *Recorder) Commit() error {
+	return nil
+}
+
+func (r *Recorder) Rollback() error {
+	return nil
+}
+
+// CheckNamedValue accepts arguments the default converter cannot, such as
+// slices, as they are.
+func (r *Recorder) CheckNamedValue(value *driver.NamedValue) error {
+	if converted, err := driver.DefaultParameterConverter.ConvertValue(value.Value); err == nil {
+		value.Value = converted
+	}
+	return nil
+}
+
+func (r *Recorder) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (r *Recorder) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r.record(query, args)
+	return noRows{}, nil
+}
+
+type noRows struct{}
+
+func (noRows) Columns() []string {
+	return nil
+}
+
+func (noRows) Close() error {
+	return nil
+}
+
+func (noRows) Next([]driver.Value) error {
+	return io.EOF
+}
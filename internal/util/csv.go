@@ -0,0 +1,48 @@
+package util
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ReadCSVColumn returns the non-empty values of the named column. When the
+// first row has no such header, the first column of every row is used.
+func ReadCSVColumn(r io.Reader, column string) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("csv file is empty")
+	}
+
+	index := 0
+	start := 0
+	for i, header := range records[0] {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")), column) {
+			index = i
+			start = 1
+			break
+		}
+	}
+
+	values := make([]string, 0, len(records)-start)
+	for _, record := range records[start:] {
+		if index >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[index])
+		if value == "" {
+			continue
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,139 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidHijriDate = errors.New("invalid Hijri date")
+
+// hijriEpoch is the Julian day number of the day before 1 Muharram 1 AH
+// (16 July 622 in the Julian calendar).
+const hijriEpoch = 1948439
+
+var hijriMonths = [12]string{
+	"Muharram", "Safar", "Rabi' al-Awwal", "Rabi' al-Thani",
+	"Jumada al-Ula", "Jumada al-Akhirah", "Rajab", "Sha'ban",
+	"Ramadan", "Shawwal", "Dhu al-Qa'dah", "Dhu al-Hijjah",
+}
+
+// HijriDate is a date in the tabular Islamic calendar: 30-year cycles with
+// leap years 2, 5, 7, 10, 13, 16, 18, 21, 24, 26 and 29, counted from the
+// civil epoch. The arithmetic is deterministic, so it can differ by a day
+// from calendars that follow the sighting of the moon.
+type HijriDate struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// HijriFromTime converts the calendar date of t, in t's location.
+func HijriFromTime(t time.Time) HijriDate {
+	jdn := gregorianToJDN(t.Year(), int(t.Month()), t.Day())
+
+	year := (30*(jdn-hijriEpoch-1) + 10646) / 10631
+	month := ceilDiv(2*(jdn-29-hijriToJDN(year, 1, 1)), 59) + 1
+	if month > 12 {
+		month = 12
+	}
+	day := jdn - hijriToJDN(year, month, 1) + 1
+
+	return HijriDate{Year: year, Month: month, Day: day}
+}
+
+// Valid reports whether the date exists in the tabular calendar.
+func (d HijriDate) Valid() bool {
+	return d.Year >= 1 && d.Year <= 9999 &&
+		d.Month >= 1 && d.Month <= 12 &&
+		d.Day >= 1 && d.Day <= HijriMonthLength(d.Year, d.Month)
+}
+
+// Time returns the start of the Gregorian day the date falls on.
+func (d HijriDate) Time(loc *time.Location) time.Time {
+	days := hijriToJDN(d.Year, d.Month, d.Day) - gregorianToJDN(1970, 1, 1)
+	return time.Date(1970, time.January, 1+days, 0, 0, 0, 0, loc)
+}
+
+// String returns the date as YYYY-MM-DD.
+func (d HijriDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Format returns the date for display, such as "1 Ramadan 1447 AH".
+func (d HijriDate) Format() string {
+	if d.Month < 1 || d.Month > 12 {
+		return d.String()
+	}
+	return fmt.Sprintf("%d %s %d AH", d.Day, hijriMonths[d.Month-1], d.Year)
+}
+
+// HijriMonthLength returns the number of days, 29 or 30, in a month.
+func HijriMonthLength(year, month int) int {
+	if month == 12 {
+		return hijriToJDN(year+1, 1, 1) - hijriToJDN(year, 12, 1)
+	}
+	return hijriToJDN(year, month+1, 1) - hijriToJDN(year, month, 1)
+}
+
+// ParseHijri parses a Hijri date written as YYYY-MM-DD, optionally followed
+// by a clock time as THH:MM or THH:MM:SS, into the Gregorian time in loc.
+func ParseHijri(value string, loc *time.Location) (time.Time, error) {
+	datePart, clockPart, hasClock := strings.Cut(strings.TrimSpace(value), "T")
+
+	parts := strings.Split(datePart, "-")
+	if len(parts) != 3 || len(parts[0]) != 4 || len(parts[1]) != 2 || len(parts[2]) != 2 {
+		return time.Time{}, ErrInvalidHijriDate
+	}
+	var fields [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, ErrInvalidHijriDate
+		}
+		fields[i] = n
+	}
+	d := HijriDate{Year: fields[0], Month: fields[1], Day: fields[2]}
+	if !d.Valid() {
+		return time.Time{}, ErrInvalidHijriDate
+	}
+
+	t := d.Time(loc)
+	if !hasClock {
+		return t, nil
+	}
+
+	var clock time.Time
+	var err error
+	if strings.Count(clockPart, ":") == 2 {
+		clock, err = time.Parse("15:04:05", clockPart)
+	} else {
+		clock, err = time.Parse("15:04", clockPart)
+	}
+	if err != nil {
+		return time.Time{}, ErrInvalidHijriDate
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, loc), nil
+}
+
+func hijriToJDN(year, month, day int) int {
+	return day + (59*(month-1)+1)/2 + (year-1)*354 + (3+11*year)/30 + hijriEpoch
+}
+
+func gregorianToJDN(year, month, day int) int {
+	a := (14 - month) / 12
+	y := year + 4800 - a
+	m := month + 12*a - 3
+	return day + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+}
+
+func ceilDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) == (b < 0) {
+		q++
+	}
+	return q
+}
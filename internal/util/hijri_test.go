@@ -0,0 +1,142 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Pairs of Hijri and Gregorian dates in the tabular calendar.
+var hijriDates = []struct {
+	hijri     HijriDate
+	gregorian string
+}{
+	{HijriDate{1, 1, 1}, "0622-07-19"},
+	{HijriDate{1400, 1, 1}, "1979-11-21"},
+	{HijriDate{1420, 1, 1}, "1999-04-17"},
+	{HijriDate{1421, 1, 1}, "2000-04-06"},
+	{HijriDate{1444, 12, 29}, "2023-07-18"},
+	{HijriDate{1445, 1, 1}, "2023-07-19"},
+	// Ramadan has 30 days.
+	{HijriDate{1445, 9, 1}, "2024-03-11"},
+	{HijriDate{1445, 9, 30}, "2024-04-09"},
+	{HijriDate{1445, 10, 1}, "2024-04-10"},
+	// 1445 is year 5 of its cycle, a leap year, so Dhu al-Hijjah has 30 days.
+	{HijriDate{1445, 12, 30}, "2024-07-07"},
+	{HijriDate{1446, 1, 1}, "2024-07-08"},
+	// Safar has 29 days.
+	{HijriDate{1446, 2, 29}, "2024-09-04"},
+	{HijriDate{1446, 3, 1}, "2024-09-05"},
+	{HijriDate{1447, 9, 1}, "2026-02-18"},
+	// 1447 is year 7, also a leap year.
+	{HijriDate{1447, 12, 30}, "2026-06-16"},
+	{HijriDate{1448, 1, 1}, "2026-06-17"},
+}
+
+func TestHijriConversion(t *testing.T) {
+	for _, tt := range hijriDates {
+		gregorian, err := time.Parse("2006-01-02", tt.gregorian)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := HijriFromTime(gregorian); got != tt.hijri {
+			t.Errorf("HijriFromTime(%s) = %s, want %s", tt.gregorian, got, tt.hijri)
+		}
+		if got := tt.hijri.Time(time.UTC); !got.Equal(gregorian) {
+			t.Errorf("%s.Time() = %s, want %s", tt.hijri, got.Format("2006-01-02"), tt.gregorian)
+		}
+	}
+}
+
+func TestHijriFromTimeUsesTheLocalDate(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	// 23:00 UTC on 10 March 2024 is already 11 March, 1 Ramadan, in Jakarta.
+	late := time.Date(2024, time.March, 10, 23, 0, 0, 0, time.UTC)
+
+	if got := HijriFromTime(late); got != (HijriDate{1445, 8, 29}) {
+		t.Errorf("UTC: %s", got)
+	}
+	if got := HijriFromTime(late.In(jakarta)); got != (HijriDate{1445, 9, 1}) {
+		t.Errorf("Jakarta: %s", got)
+	}
+}
+
+func TestHijriMonthLength(t *testing.T) {
+	tests := []struct {
+		year, month, want int
+	}{
+		{1445, 1, 30},
+		{1445, 2, 29},
+		{1445, 9, 30},
+		{1445, 12, 30},
+		{1444, 12, 29},
+		{1446, 12, 29},
+		{1447, 12, 30},
+		// Leap years 2 and 29 of the first cycle.
+		{2, 12, 30},
+		{29, 12, 30},
+		{30, 12, 29},
+	}
+	for _, tt := range tests {
+		if got := HijriMonthLength(tt.year, tt.month); got != tt.want {
+			t.Errorf("HijriMonthLength(%d, %d) = %d, want %d", tt.year, tt.month, got, tt.want)
+		}
+	}
+}
+
+func TestParseHijri(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	valid := []struct {
+		value string
+		want  time.Time
+	}{
+		{"1445-09-01", time.Date(2024, time.March, 11, 0, 0, 0, 0, jakarta)},
+		{"1445-12-30", time.Date(2024, time.July, 7, 0, 0, 0, 0, jakarta)},
+		{"1445-09-01T08:30", time.Date(2024, time.March, 11, 8, 30, 0, 0, jakarta)},
+		{" 1445-09-01T08:30:15 ", time.Date(2024, time.March, 11, 8, 30, 15, 0, jakarta)},
+	}
+	for _, tt := range valid {
+		got, err := ParseHijri(tt.value, jakarta)
+		if err != nil {
+			t.Errorf("ParseHijri(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseHijri(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"1445-13-01",
+		"1445-00-10",
+		"1445-09-00",
+		"1445-02-30",
+		// 1444 is a common year, so Dhu al-Hijjah has 29 days.
+		"1444-12-30",
+		"0000-01-01",
+		"1445-9-1",
+		"14450901",
+		"1445-09-01T25:00",
+		"1445-09-01T08",
+		"1445-09-01 08:00",
+		"year-09-01",
+	}
+	for _, value := range invalid {
+		if _, err := ParseHijri(value, time.UTC); !errors.Is(err, ErrInvalidHijriDate) {
+			t.Errorf("ParseHijri(%q) = %v, want %v", value, err, ErrInvalidHijriDate)
+		}
+	}
+}
+
+func TestHijriFormat(t *testing.T) {
+	d := HijriDate{1447, 9, 1}
+	if got := d.String(); got != "1447-09-01" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := d.Format(); got != "1 Ramadan 1447 AH" {
+		t.Errorf("Format() = %q", got)
+	}
+}
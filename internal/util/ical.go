@@ -0,0 +1,115 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const icalProductID = "-//Bahrululum//Calendar Feed//EN"
+
+// ICalEvent is one VEVENT of an iCalendar (RFC 5545) feed. Clients match
+// events by UID and apply a change only when Sequence has grown, so callers
+// keep UIDs stable and raise Sequence whenever an event is rescheduled.
+type ICalEvent struct {
+	UID         string
+	Sequence    int
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	// End may be zero for events that mark a moment, such as a due date.
+	End      time.Time
+	Modified time.Time
+	// Cancelled publishes the event as STATUS:CANCELLED.
+	Cancelled bool
+}
+
+// WriteICalendar writes a published calendar with the given events.
+func WriteICalendar(w io.Writer, name string, stamp time.Time, events []ICalEvent) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICalLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", icalProductID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", icalText(name))
+	line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	line("X-PUBLISHED-TTL", "PT1H")
+
+	for _, event := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", event.UID)
+		line("SEQUENCE", fmt.Sprint(event.Sequence))
+		line("DTSTAMP", icalTime(stamp))
+		line("DTSTART", icalTime(event.Start))
+		if !event.End.IsZero() {
+			line("DTEND", icalTime(event.End))
+		}
+		if !event.Modified.IsZero() {
+			line("LAST-MODIFIED", icalTime(event.Modified))
+		}
+		line("SUMMARY", icalText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", icalText(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION", icalText(event.Location))
+		}
+		if event.URL != "" {
+			line("URL", event.URL)
+		}
+		if event.Cancelled {
+			line("STATUS", "CANCELLED")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func icalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func icalText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(value)
+}
+
+// writeICalLine folds content lines longer than 75 octets without splitting
+// a UTF-8 sequence, as RFC 5545 requires.
+func writeICalLine(w *bufio.Writer, content string) {
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		w.WriteString(content[:cut])
+		w.WriteString("\r\n ")
+		content = content[cut:]
+		// Continuation lines start with a space, which counts toward the
+		// limit.
+		limit = 74
+	}
+	w.WriteString(content)
+	w.WriteString("\r\n")
+}
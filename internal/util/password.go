@@ -0,0 +1,83 @@
+package util
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	PasswordMinLength = 8
+	// PasswordMaxLength is bcrypt's input limit; longer passwords would be
+	// silently truncated.
+	PasswordMaxLength = 72
+)
+
+var ErrPasswordPolicy = errors.New("password does not meet policy")
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = loadCommonPasswords(commonPasswordsFile)
+
+// PasswordPolicyError describes why a password was rejected.
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password " + e.Reason
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrPasswordPolicy
+}
+
+// ValidatePassword checks a new password against the length limits and the
+// bundled list of common passwords. Personal values such as the user's NIP,
+// email or name may be passed to reject passwords equal to them.
+func ValidatePassword(password string, personal ...string) error {
+	length := utf8.RuneCountInString(password)
+	if length < PasswordMinLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("must be at least %d characters", PasswordMinLength)}
+	}
+	if len(password) > PasswordMaxLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("must be at most %d bytes", PasswordMaxLength)}
+	}
+
+	normalized := strings.ToLower(password)
+	if commonPasswords[normalized] {
+		return &PasswordPolicyError{Reason: "is too common"}
+	}
+
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			value = local
+		}
+		if normalized == value {
+			return &PasswordPolicyError{Reason: "must not match your personal details"}
+		}
+	}
+
+	return nil
+}
+
+func loadCommonPasswords(data string) map[string]bool {
+	passwords := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = true
+	}
+	return passwords
+}
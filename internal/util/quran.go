@@ -0,0 +1,206 @@
+package util
+
+import (
+	"errors"
+	"sort"
+)
+
+var ErrInvalidAyahRange = errors.New("invalid surah or ayah range")
+
+const (
+	QuranSurahCount = 114
+	QuranAyahCount  = 6236
+	QuranJuzCount   = 30
+)
+
+// surahAyahs is the number of ayahs in each surah, in the Kufan count used
+// by the Hafs reading.
+var surahAyahs = [QuranSurahCount]int{
+	7, 286, 200, 176, 120, 165, 206, 75, 129, 109,
+	123, 111, 43, 52, 99, 128, 111, 110, 98, 135,
+	112, 78, 118, 64, 77, 227, 93, 88, 69, 60,
+	34, 30, 73, 54, 45, 83, 182, 88, 75, 85,
+	54, 53, 89, 59, 37, 35, 38, 29, 18, 45,
+	60, 49, 62, 55, 78, 96, 29, 22, 24, 13,
+	14, 11, 11, 18, 12, 12, 30, 52, 52, 44,
+	28, 28, 20, 56, 40, 31, 50, 40, 46, 42,
+	29, 19, 36, 25, 22, 17, 19, 26, 30, 20,
+	15, 21, 11, 8, 8, 19, 5, 8, 8, 11,
+	11, 8, 3, 9, 5, 4, 7, 3, 6, 3,
+	5, 4, 5, 6,
+}
+
+var surahNames = [QuranSurahCount]string{
+	"Al-Fatihah", "Al-Baqarah", "Ali 'Imran", "An-Nisa'", "Al-Ma'idah",
+	"Al-An'am", "Al-A'raf", "Al-Anfal", "At-Tawbah", "Yunus",
+	"Hud", "Yusuf", "Ar-Ra'd", "Ibrahim", "Al-Hijr",
+	"An-Nahl", "Al-Isra'", "Al-Kahf", "Maryam", "Ta-Ha",
+	"Al-Anbiya'", "Al-Hajj", "Al-Mu'minun", "An-Nur", "Al-Furqan",
+	"Ash-Shu'ara'", "An-Naml", "Al-Qasas", "Al-'Ankabut", "Ar-Rum",
+	"Luqman", "As-Sajdah", "Al-Ahzab", "Saba'", "Fatir",
+	"Ya-Sin", "As-Saffat", "Sad", "Az-Zumar", "Ghafir",
+	"Fussilat", "Ash-Shura", "Az-Zukhruf", "Ad-Dukhan", "Al-Jathiyah",
+	"Al-Ahqaf", "Muhammad", "Al-Fath", "Al-Hujurat", "Qaf",
+	"Adh-Dhariyat", "At-Tur", "An-Najm", "Al-Qamar", "Ar-Rahman",
+	"Al-Waqi'ah", "Al-Hadid", "Al-Mujadilah", "Al-Hashr", "Al-Mumtahanah",
+	"As-Saff", "Al-Jumu'ah", "Al-Munafiqun", "At-Taghabun", "At-Talaq",
+	"At-Tahrim", "Al-Mulk", "Al-Qalam", "Al-Haqqah", "Al-Ma'arij",
+	"Nuh", "Al-Jinn", "Al-Muzzammil", "Al-Muddaththir", "Al-Qiyamah",
+	"Al-Insan", "Al-Mursalat", "An-Naba'", "An-Nazi'at", "'Abasa",
+	"At-Takwir", "Al-Infitar", "Al-Mutaffifin", "Al-Inshiqaq", "Al-Buruj",
+	"At-Tariq", "Al-A'la", "Al-Ghashiyah", "Al-Fajr", "Al-Balad",
+	"Ash-Shams", "Al-Layl", "Ad-Duha", "Ash-Sharh", "At-Tin",
+	"Al-'Alaq", "Al-Qadr", "Al-Bayyinah", "Az-Zalzalah", "Al-'Adiyat",
+	"Al-Qari'ah", "At-Takathur", "Al-'Asr", "Al-Humazah", "Al-Fil",
+	"Quraysh", "Al-Ma'un", "Al-Kawthar", "Al-Kafirun", "An-Nasr",
+	"Al-Masad", "Al-Ikhlas", "Al-Falaq", "An-Nas",
+}
+
+// juzStarts is the surah and ayah each juz begins with.
+var juzStarts = [QuranJuzCount][2]int{
+	{1, 1}, {2, 142}, {2, 253}, {3, 93}, {4, 24},
+	{4, 148}, {5, 82}, {6, 111}, {7, 88}, {8, 41},
+	{9, 93}, {11, 6}, {12, 53}, {15, 1}, {17, 1},
+	{18, 75}, {21, 1}, {23, 1}, {25, 21}, {27, 56},
+	{29, 46}, {33, 31}, {36, 28}, {39, 32}, {41, 47},
+	{46, 1}, {51, 31}, {58, 1}, {67, 1}, {78, 1},
+}
+
+var (
+	// surahOffsets is the index of each surah's first ayah.
+	surahOffsets [QuranSurahCount]int
+	// juzOffsets is the index of each juz's first ayah.
+	juzOffsets [QuranJuzCount]int
+)
+
+func init() {
+	offset := 0
+	for i, count := range surahAyahs {
+		surahOffsets[i] = offset
+		offset += count
+	}
+	for i, start := range juzStarts {
+		juzOffsets[i] = surahOffsets[start[0]-1] + start[1] - 1
+	}
+}
+
+// SurahAyahs returns the number of ayahs in a surah, or 0 for a number that
+// is not a surah.
+func SurahAyahs(surah int) int {
+	if surah < 1 || surah > QuranSurahCount {
+		return 0
+	}
+	return surahAyahs[surah-1]
+}
+
+// SurahName returns the transliterated name of a surah.
+func SurahName(surah int) string {
+	if surah < 1 || surah > QuranSurahCount {
+		return ""
+	}
+	return surahNames[surah-1]
+}
+
+// AyahIndex returns the position of an ayah in the mushaf, counted from 0,
+// or ErrInvalidAyahRange when the surah has no such ayah.
+func AyahIndex(surah, ayah int) (int, error) {
+	if ayah < 1 || ayah > SurahAyahs(surah) {
+		return 0, ErrInvalidAyahRange
+	}
+	return surahOffsets[surah-1] + ayah - 1, nil
+}
+
+// AyahRange returns the indexes of the first and last ayah of a range, which
+// must not run backwards.
+func AyahRange(fromSurah, fromAyah, toSurah, toAyah int) (int, int, error) {
+	from, err := AyahIndex(fromSurah, fromAyah)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := AyahIndex(toSurah, toAyah)
+	if err != nil {
+		return 0, 0, err
+	}
+	if to < from {
+		return 0, 0, ErrInvalidAyahRange
+	}
+	return from, to, nil
+}
+
+// JuzOf returns the juz, from 1 to 30, the ayah at index is in.
+func JuzOf(index int) int {
+	return sort.Search(QuranJuzCount, func(i int) bool { return juzOffsets[i] > index })
+}
+
+// JuzAyahs returns the number of ayahs in a juz.
+func JuzAyahs(juz int) int {
+	if juz < 1 || juz > QuranJuzCount {
+		return 0
+	}
+	if juz == QuranJuzCount {
+		return QuranAyahCount - juzOffsets[juz-1]
+	}
+	return juzOffsets[juz] - juzOffsets[juz-1]
+}
+
+// AyahSet is a set of ayahs, by index, that also counts its ayahs per juz.
+type AyahSet struct {
+	bits [(QuranAyahCount + 63) / 64]uint64
+	juz  [QuranJuzCount]int
+	len  int
+}
+
+// AddRange adds the ayahs from index from to index to, both included, and
+// returns how many of them were new.
+func (s *AyahSet) AddRange(from, to int) int {
+	added := 0
+	for i := from; i <= to; i++ {
+		if s.Contains(i) {
+			continue
+		}
+		s.bits[i/64] |= 1 << (i % 64)
+		s.juz[JuzOf(i)-1]++
+		added++
+	}
+	s.len += added
+	return added
+}
+
+func (s *AyahSet) Contains(index int) bool {
+	return index >= 0 && index < QuranAyahCount && s.bits[index/64]&(1<<(index%64)) != 0
+}
+
+// CountRange returns how many ayahs from index from to index to are in the
+// set.
+func (s *AyahSet) CountRange(from, to int) int {
+	count := 0
+	for i := from; i <= to; i++ {
+		if s.Contains(i) {
+			count++
+		}
+	}
+	return count
+}
+
+// Len returns the number of ayahs in the set.
+func (s *AyahSet) Len() int {
+	return s.len
+}
+
+// JuzCount returns the number of ayahs of a juz in the set.
+func (s *AyahSet) JuzCount(juz int) int {
+	if juz < 1 || juz > QuranJuzCount {
+		return 0
+	}
+	return s.juz[juz-1]
+}
+
+// JuzCovered returns the number of juz the set covers, counting each juz in
+// proportion to the share of its ayahs in the set.
+func (s *AyahSet) JuzCovered() float64 {
+	covered := 0.0
+	for juz := 1; juz <= QuranJuzCount; juz++ {
+		covered += float64(s.juz[juz-1]) / float64(JuzAyahs(juz))
+	}
+	return covered
+}
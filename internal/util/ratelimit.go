@@ -0,0 +1,114 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter allows at most limit events per key within a sliding window.
+// State is kept in memory, so limits apply per process. Keys whose events
+// have all expired are swept once per window so the map does not grow with
+// every key ever seen.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	events    map[string][]time.Time
+	lastSweep time.Time
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:     limit,
+		window:    window,
+		events:    make(map[string][]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow records an event for key and reports whether it is within the limit.
+// A non-positive limit disables limiting.
+func (l *RateLimiter) Allow(key string) bool {
+	if l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recent := l.prune(key)
+	if len(recent) >= l.limit {
+		return false
+	}
+
+	l.events[key] = append(recent, time.Now())
+	return true
+}
+
+// Exceeded reports whether key has reached the limit without recording an
+// event.
+func (l *RateLimiter) Exceeded(key string) bool {
+	if l.limit <= 0 {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.prune(key)) >= l.limit
+}
+
+// Record adds an event for key regardless of the limit and returns the number
+// of events in the current window.
+func (l *RateLimiter) Record(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recent := append(l.prune(key), time.Now())
+	l.events[key] = recent
+	return len(recent)
+}
+
+// Reset forgets every event recorded for key.
+func (l *RateLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.events, key)
+}
+
+// prune drops events outside the window. The caller must hold the lock.
+func (l *RateLimiter) prune(key string) []time.Time {
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+	if now.Sub(l.lastSweep) >= l.window {
+		l.lastSweep = now
+		l.sweep(cutoff)
+	}
+
+	recent := l.events[key][:0]
+	for _, t := range l.events[key] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) == 0 {
+		delete(l.events, key)
+		return nil
+	}
+
+	l.events[key] = recent
+	return recent
+}
+
+// sweep drops the keys whose latest event is not after cutoff. Events are
+// appended in order, so the last one is the latest. The caller must hold the
+// lock.
+func (l *RateLimiter) sweep(cutoff time.Time) {
+	for key, events := range l.events {
+		if len(events) == 0 || !events[len(events)-1].After(cutoff) {
+			delete(l.events, key)
+		}
+	}
+}
@@ -24,3 +24,12 @@ func ErrorResponse(c echo.Context, statusCode int, message string) error {
 		Error:   message,
 	})
 }
+
+func ErrorResponseWithData(c echo.Context, statusCode int, message string, data interface{}) error {
+	return c.JSON(statusCode, Reponse{
+		Success: false,
+		Message: "Something went wrong",
+		Data:    data,
+		Error:   message,
+	})
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrUnsupportedSpreadsheet = errors.New("unsupported file type, upload a .csv or .xlsx file")
+	ErrSpreadsheetTooLarge    = errors.New("the file has too many rows")
+	ErrSpreadsheetTooWide     = errors.New("the file has too many columns")
+)
+
+// ReadSpreadsheet returns the rows of an uploaded CSV or XLSX file, chosen by
+// the file name's extension. rows[i] is always line or row i+1 of the file.
+// A row or record starting past line maxRows fails with
+// ErrSpreadsheetTooLarge before any padding is allocated for it.
+func ReadSpreadsheet(r io.ReaderAt, size int64, filename string, maxRows int) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(io.NewSectionReader(r, 0, size))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+
+		var rows [][]string
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return rows, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			// Quoted fields may span lines; pad so indexes keep matching
+			// the line a record started on.
+			line, _ := reader.FieldPos(0)
+			if line > maxRows {
+				return nil, ErrSpreadsheetTooLarge
+			}
+			for len(rows) < line-1 {
+				rows = append(rows, nil)
+			}
+			rows = append(rows, record)
+		}
+	case ".xlsx":
+		return ReadXLSX(r, size, maxRows)
+	default:
+		return nil, ErrUnsupportedSpreadsheet
+	}
+}
+
+// HeaderIndex maps the normalized names in a header row to their column.
+func HeaderIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, exists := index[name]; name != "" && !exists {
+			index[name] = i
+		}
+	}
+	return index
+}
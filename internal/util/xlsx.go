@@ -0,0 +1,256 @@
+package util
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// xlsxMaxPartSize caps how much of a single workbook part is
+	// decompressed.
+	xlsxMaxPartSize = 64 << 20
+	// xlsxMaxRows is the number of rows a worksheet can have.
+	xlsxMaxRows = 1 << 20
+	// xlsxMaxColumns is the widest row read, far more than any import
+	// needs. Worksheets may address up to 16384 columns, and each row is
+	// padded up to its last cell.
+	xlsxMaxColumns = 64
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string: plain text or a list of rich text
+// runs.
+type xlsxText struct {
+	T    *string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if t.T != nil {
+		return *t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string   `xml:"r,attr"`
+			T      string   `xml:"t,attr"`
+			V      string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX returns the cell text of the first worksheet in an XLSX workbook.
+// Rows are placed at their sheet position, so rows[i] is spreadsheet row
+// i+1 even when rows in between are empty. Numbers are returned as written
+// without exponent notation, which keeps long numeric IDs intact. Rows past
+// maxRows, or past the worksheet limit, fail with ErrSpreadsheetTooLarge.
+// Empty cells past the 64th column are ignored, others fail with
+// ErrSpreadsheetTooWide.
+func ReadXLSX(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	if maxRows <= 0 || maxRows > xlsxMaxRows {
+		maxRows = xlsxMaxRows
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx file: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := xlsxFirstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := xlsxDecode(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("xlsx worksheet %s is missing", sheetPath)
+	}
+	var sheet xlsxSheet
+	if err := xlsxDecode(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		// The row number comes from the file, so it is checked before the
+		// rows up to it are padded.
+		if row.R > maxRows || len(rows) >= maxRows {
+			return nil, ErrSpreadsheetTooLarge
+		}
+		index := row.R - 1
+		if index < len(rows) {
+			index = len(rows)
+		}
+		for len(rows) < index {
+			rows = append(rows, nil)
+		}
+
+		var record []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.R != "" {
+				if col, err = xlsxColumn(cell.R); err != nil {
+					return nil, err
+				}
+			}
+			// The column comes from the file too, and is checked before
+			// the record is padded up to it.
+			if col >= xlsxMaxColumns {
+				if cell.V == "" && cell.Inline.String() == "" {
+					continue
+				}
+				return nil, ErrSpreadsheetTooWide
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+
+			switch cell.T {
+			case "s":
+				n, err := strconv.Atoi(cell.V)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("xlsx cell %s has an invalid shared string", cell.R)
+				}
+				record[col] = shared.Items[n].String()
+			case "inlineStr":
+				record[col] = cell.Inline.String()
+			case "b":
+				record[col] = "FALSE"
+				if cell.V == "1" {
+					record[col] = "TRUE"
+				}
+			case "", "n":
+				record[col] = xlsxNumber(cell.V)
+			default:
+				record[col] = cell.V
+			}
+		}
+		rows = append(rows, record)
+	}
+
+	return rows, nil
+}
+
+func xlsxFirstSheet(files map[string]*zip.File) (string, error) {
+	workbookFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("not an xlsx file: workbook is missing")
+	}
+	var workbook xlsxWorkbook
+	if err := xlsxDecode(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("xlsx workbook has no sheets")
+	}
+
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return "", errors.New("not an xlsx file: workbook relationships are missing")
+	}
+	var rels xlsxRelationships
+	if err := xlsxDecode(relsFile, &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", errors.New("xlsx worksheet relationship is missing")
+}
+
+func xlsxDecode(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, xlsxMaxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("invalid xlsx part %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// xlsxColumn returns the zero-based column of a cell reference such as "AB12".
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		if col > 16384 {
+			return 0, fmt.Errorf("invalid xlsx cell reference %q", ref)
+		}
+	}
+	if col == 0 {
+		return 0, fmt.Errorf("invalid xlsx cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+// xlsxNumber rewrites whole numbers stored in exponent notation, such as
+// 1.98501012345E+11, as plain digits.
+func xlsxNumber(v string) string {
+	if !strings.ContainsAny(v, "eE") {
+		return v
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f != math.Trunc(f) || math.Abs(f) >= 1e18 {
+		return v
+	}
+	return strconv.FormatFloat(f, 'f', 0, 64)
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// xlsxFile returns a workbook whose only worksheet has the given sheetData.
+func xlsxFile(t *testing.T, sheetData string) *bytes.Reader {
+	t.Helper()
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Users" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadXLSX(t *testing.T) {
+	file := xlsxFile(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>nip</t></is></c><c r="C1" t="inlineStr"><is><t>name</t></is></c></row>`+
+		`<row r="3"><c r="A3"><v>1.98501012345E+11</v></c><c r="C3" t="inlineStr"><is><t>Aisyah</t></is></c></row>`)
+
+	rows, err := ReadXLSX(file, file.Size(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"nip", "", "name"}, nil, {"198501012345", "", "Aisyah"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestReadXLSXLimitsTheColumns(t *testing.T) {
+	// An empty styled cell far to the right is ignored.
+	file := xlsxFile(t, `<row r="1"><c r="A1"><v>1</v></c><c r="XFD1" s="1"/></row>`)
+	rows, err := ReadXLSX(file, file.Size(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 {
+		t.Fatalf("rows = %q, want one cell", rows)
+	}
+
+	// A value there is refused before the row is padded up to it.
+	file = xlsxFile(t, `<row r="1"><c r="A1"><v>1</v></c></row><row r="2"><c r="XFD2"><v>1</v></c></row>`)
+	if _, err := ReadXLSX(file, file.Size(), 0); !errors.Is(err, ErrSpreadsheetTooWide) {
+		t.Fatalf("value in column XFD: %v, want %v", err, ErrSpreadsheetTooWide)
+	}
+
+	// The last column allowed still reads.
+	file = xlsxFile(t, `<row r="1"><c r="BL1"><v>1</v></c></row>`)
+	rows, err = ReadXLSX(file, file.Size(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows[0]) != xlsxMaxColumns {
+		t.Fatalf("row has %d columns, want %d", len(rows[0]), xlsxMaxColumns)
+	}
+}
@@ -0,0 +1,45 @@
+-- +goose Up
+ALTER TABLE enrollments
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active',
+    ADD COLUMN progress_percent INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN enrolled_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN completed_at TIMESTAMP,
+    ADD COLUMN dropped_at TIMESTAMP,
+    ADD COLUMN expires_at TIMESTAMP;
+
+CREATE INDEX idx_enrollments_status ON enrollments(course_id, status);
+
+CREATE TABLE enrollment_events (
+    id SERIAL PRIMARY KEY,
+    enrollment_id INTEGER NOT NULL REFERENCES enrollments(id) ON DELETE CASCADE,
+    action VARCHAR(30) NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_enrollment_events_enrollment_id ON enrollment_events(enrollment_id);
+
+CREATE TABLE content_progress (
+    id SERIAL PRIMARY KEY,
+    enrollment_id INTEGER NOT NULL REFERENCES enrollments(id) ON DELETE CASCADE,
+    content_id INTEGER NOT NULL REFERENCES course_contents(id) ON DELETE CASCADE,
+    completed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_content_progress_enrollment_content UNIQUE (enrollment_id, content_id)
+);
+
+CREATE INDEX idx_content_progress_enrollment_id ON content_progress(enrollment_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS content_progress;
+DROP TABLE IF EXISTS enrollment_events;
+DROP INDEX IF EXISTS idx_enrollments_status;
+ALTER TABLE enrollments
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS progress_percent,
+    DROP COLUMN IF EXISTS enrolled_at,
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS dropped_at,
+    DROP COLUMN IF EXISTS expires_at;
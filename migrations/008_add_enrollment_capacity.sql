@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE courses
+    ADD COLUMN enrollment_mode VARCHAR(20) NOT NULL DEFAULT 'open',
+    ADD COLUMN max_seats INTEGER;
+
+ALTER TABLE enrollments
+    ADD COLUMN queued_at TIMESTAMP;
+
+CREATE INDEX idx_enrollments_queue ON enrollments(course_id, status, queued_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_enrollments_queue;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS queued_at;
+ALTER TABLE courses
+    DROP COLUMN IF EXISTS enrollment_mode,
+    DROP COLUMN IF EXISTS max_seats;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE bulk_enrollment_jobs (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    report TEXT,
+    error TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_enrollment_jobs_course_id ON bulk_enrollment_jobs(course_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS bulk_enrollment_jobs;
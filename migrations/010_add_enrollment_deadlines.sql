@@ -0,0 +1,33 @@
+-- +goose Up
+ALTER TABLE courses ADD COLUMN default_due_days INTEGER;
+
+ALTER TABLE enrollments ADD COLUMN due_at TIMESTAMP;
+
+CREATE INDEX idx_enrollments_due_at ON enrollments(due_at) WHERE due_at IS NOT NULL;
+
+CREATE TABLE enrollment_reminders (
+    id SERIAL PRIMARY KEY,
+    enrollment_id INTEGER NOT NULL REFERENCES enrollments(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_enrollment_reminders_kind UNIQUE (enrollment_id, kind)
+);
+
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS enrollment_reminders;
+DROP INDEX IF EXISTS idx_enrollments_due_at;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS due_at;
+ALTER TABLE courses DROP COLUMN IF EXISTS default_due_days;
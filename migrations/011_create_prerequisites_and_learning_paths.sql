@@ -0,0 +1,62 @@
+-- +goose Up
+CREATE TABLE course_prerequisites (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    prerequisite_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_course_prerequisites UNIQUE (course_id, prerequisite_id),
+    CONSTRAINT chk_course_prerequisites_self CHECK (course_id <> prerequisite_id)
+);
+
+CREATE INDEX idx_course_prerequisites_course_id ON course_prerequisites(course_id);
+
+CREATE TABLE learning_paths (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    is_published BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_learning_paths_deleted_at ON learning_paths(deleted_at);
+
+CREATE TABLE learning_path_courses (
+    id SERIAL PRIMARY KEY,
+    learning_path_id INTEGER NOT NULL REFERENCES learning_paths(id) ON DELETE CASCADE,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 1,
+    CONSTRAINT uq_learning_path_courses UNIQUE (learning_path_id, course_id)
+);
+
+CREATE INDEX idx_learning_path_courses_position ON learning_path_courses(learning_path_id, position);
+
+CREATE TABLE learning_path_enrollments (
+    id SERIAL PRIMARY KEY,
+    learning_path_id INTEGER NOT NULL REFERENCES learning_paths(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enrolled_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    CONSTRAINT uq_learning_path_enrollments UNIQUE (learning_path_id, user_id)
+);
+
+CREATE TABLE certificates (
+    id SERIAL PRIMARY KEY,
+    number VARCHAR(50) NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    course_id INTEGER REFERENCES courses(id) ON DELETE CASCADE,
+    learning_path_id INTEGER REFERENCES learning_paths(id) ON DELETE CASCADE,
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_certificates_number ON certificates(number);
+CREATE UNIQUE INDEX idx_certificates_user_course ON certificates(user_id, course_id) WHERE course_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_certificates_user_path ON certificates(user_id, learning_path_id) WHERE learning_path_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS certificates;
+DROP TABLE IF EXISTS learning_path_enrollments;
+DROP TABLE IF EXISTS learning_path_courses;
+DROP TABLE IF EXISTS learning_paths;
+DROP TABLE IF EXISTS course_prerequisites;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN auth_source VARCHAR(20) NOT NULL DEFAULT 'local';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS auth_source;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE refresh_tokens ADD COLUMN device_name VARCHAR(255);
+ALTER TABLE refresh_tokens ADD COLUMN ip_address VARCHAR(64);
+ALTER TABLE refresh_tokens ADD COLUMN user_agent VARCHAR(500);
+ALTER TABLE refresh_tokens ADD COLUMN last_used_at TIMESTAMP;
+
+UPDATE refresh_tokens SET last_used_at = created_at;
+
+-- +goose Down
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS ip_address;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS device_name;
@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE org_units (
+    id SERIAL PRIMARY KEY,
+    parent_id INTEGER REFERENCES org_units(id) ON DELETE RESTRICT,
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_org_units_parent_self CHECK (parent_id <> id)
+);
+
+CREATE INDEX idx_org_units_parent_id ON org_units(parent_id);
+
+ALTER TABLE users ADD COLUMN org_unit_id INTEGER REFERENCES org_units(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_users_org_unit_id ON users(org_unit_id);
+
+CREATE TABLE org_unit_admins (
+    org_unit_id INTEGER NOT NULL REFERENCES org_units(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_unit_id, user_id)
+);
+
+CREATE INDEX idx_org_unit_admins_user_id ON org_unit_admins(user_id);
+
+CREATE TABLE org_unit_courses (
+    id SERIAL PRIMARY KEY,
+    org_unit_id INTEGER NOT NULL REFERENCES org_units(id) ON DELETE CASCADE,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    include_sub_units BOOLEAN NOT NULL DEFAULT TRUE,
+    due_in_days INTEGER,
+    assigned_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_org_unit_courses UNIQUE (org_unit_id, course_id)
+);
+
+CREATE INDEX idx_org_unit_courses_course_id ON org_unit_courses(course_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS org_unit_courses;
+DROP TABLE IF EXISTS org_unit_admins;
+DROP INDEX IF EXISTS idx_users_org_unit_id;
+ALTER TABLE users DROP COLUMN IF EXISTS org_unit_id;
+DROP TABLE IF EXISTS org_units;
@@ -0,0 +1,119 @@
+-- +goose Up
+CREATE TABLE tenants (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    domain VARCHAR(255) UNIQUE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    logo_url VARCHAR(500),
+    primary_color VARCHAR(7),
+    support_email VARCHAR(255),
+    settings TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing data belongs to the default tenant.
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default');
+SELECT setval('tenants_id_seq', (SELECT MAX(id) FROM tenants));
+
+ALTER TABLE users ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_users_tenant_id ON users(tenant_id);
+
+ALTER TABLE courses ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE courses ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_courses_tenant_id ON courses(tenant_id);
+
+ALTER TABLE course_chapters ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE course_chapters ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_course_chapters_tenant_id ON course_chapters(tenant_id);
+
+ALTER TABLE course_contents ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE course_contents ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_course_contents_tenant_id ON course_contents(tenant_id);
+
+ALTER TABLE enrollments ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE enrollments ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_enrollments_tenant_id ON enrollments(tenant_id);
+
+ALTER TABLE bulk_enrollment_jobs ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE bulk_enrollment_jobs ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_bulk_enrollment_jobs_tenant_id ON bulk_enrollment_jobs(tenant_id);
+
+ALTER TABLE learning_paths ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE learning_paths ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_learning_paths_tenant_id ON learning_paths(tenant_id);
+
+ALTER TABLE certificates ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE certificates ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_certificates_tenant_id ON certificates(tenant_id);
+
+ALTER TABLE notifications ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE notifications ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_notifications_tenant_id ON notifications(tenant_id);
+
+ALTER TABLE security_events ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE security_events ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_security_events_tenant_id ON security_events(tenant_id);
+
+ALTER TABLE user_identities ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE user_identities ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_user_identities_tenant_id ON user_identities(tenant_id);
+
+ALTER TABLE api_keys ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_api_keys_tenant_id ON api_keys(tenant_id);
+
+ALTER TABLE user_imports ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE user_imports ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_user_imports_tenant_id ON user_imports(tenant_id);
+
+ALTER TABLE org_units ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE org_units ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_org_units_tenant_id ON org_units(tenant_id);
+
+ALTER TABLE oidc_login_states ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE oidc_login_states ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_oidc_login_states_tenant_id ON oidc_login_states(tenant_id);
+
+DROP INDEX idx_users_email;
+DROP INDEX idx_users_nip;
+CREATE UNIQUE INDEX idx_users_tenant_email ON users(tenant_id, email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_users_tenant_nip ON users(tenant_id, nip) WHERE deleted_at IS NULL;
+
+ALTER TABLE org_units DROP CONSTRAINT org_units_code_key;
+ALTER TABLE org_units ADD CONSTRAINT uq_org_units_tenant_code UNIQUE (tenant_id, code);
+
+ALTER TABLE user_identities DROP CONSTRAINT user_identities_provider_subject_key;
+ALTER TABLE user_identities ADD CONSTRAINT uq_user_identities_tenant_subject UNIQUE (tenant_id, provider, subject);
+
+-- +goose Down
+ALTER TABLE user_identities DROP CONSTRAINT IF EXISTS uq_user_identities_tenant_subject;
+ALTER TABLE user_identities ADD CONSTRAINT user_identities_provider_subject_key UNIQUE (provider, subject);
+
+ALTER TABLE org_units DROP CONSTRAINT IF EXISTS uq_org_units_tenant_code;
+ALTER TABLE org_units ADD CONSTRAINT org_units_code_key UNIQUE (code);
+
+DROP INDEX IF EXISTS idx_users_tenant_email;
+DROP INDEX IF EXISTS idx_users_tenant_nip;
+CREATE UNIQUE INDEX idx_users_email ON users(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_users_nip ON users(nip) WHERE deleted_at IS NULL;
+
+ALTER TABLE oidc_login_states DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE org_units DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE user_imports DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE user_identities DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE security_events DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE notifications DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE certificates DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE learning_paths DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE bulk_enrollment_jobs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE course_contents DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE course_chapters DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE courses DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
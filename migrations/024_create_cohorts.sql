@@ -0,0 +1,70 @@
+-- +goose Up
+CREATE TABLE cohorts (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE RESTRICT,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    starts_at TIMESTAMP,
+    due_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cohorts_tenant_id ON cohorts(tenant_id);
+CREATE INDEX idx_cohorts_course_id ON cohorts(course_id);
+
+CREATE TABLE cohort_members (
+    cohort_id INTEGER NOT NULL REFERENCES cohorts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cohort_id, user_id),
+    CONSTRAINT uq_cohort_members_course_user UNIQUE (course_id, user_id)
+);
+
+CREATE INDEX idx_cohort_members_user_id ON cohort_members(user_id);
+
+CREATE TABLE cohort_mentors (
+    cohort_id INTEGER NOT NULL REFERENCES cohorts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cohort_id, user_id)
+);
+
+CREATE INDEX idx_cohort_mentors_user_id ON cohort_mentors(user_id);
+
+CREATE TABLE cohort_announcements (
+    id SERIAL PRIMARY KEY,
+    cohort_id INTEGER NOT NULL REFERENCES cohorts(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cohort_announcements_cohort_id ON cohort_announcements(cohort_id, created_at);
+
+CREATE TABLE cohort_posts (
+    id SERIAL PRIMARY KEY,
+    cohort_id INTEGER NOT NULL REFERENCES cohorts(id) ON DELETE CASCADE,
+    parent_id INTEGER REFERENCES cohort_posts(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255),
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CONSTRAINT chk_cohort_posts_title CHECK ((parent_id IS NULL) = (title IS NOT NULL))
+);
+
+CREATE INDEX idx_cohort_posts_cohort_id ON cohort_posts(cohort_id, created_at) WHERE parent_id IS NULL;
+CREATE INDEX idx_cohort_posts_parent_id ON cohort_posts(parent_id);
+CREATE INDEX idx_cohort_posts_deleted_at ON cohort_posts(deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS cohort_posts;
+DROP TABLE IF EXISTS cohort_announcements;
+DROP TABLE IF EXISTS cohort_mentors;
+DROP TABLE IF EXISTS cohort_members;
+DROP TABLE IF EXISTS cohorts;
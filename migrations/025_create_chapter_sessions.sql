@@ -0,0 +1,45 @@
+-- +goose Up
+ALTER TABLE courses ADD COLUMN min_attendance_percent INTEGER;
+
+CREATE TABLE chapter_sessions (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE RESTRICT,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    chapter_id INTEGER NOT NULL REFERENCES course_chapters(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    location VARCHAR(255),
+    meeting_url VARCHAR(500),
+    check_in_secret VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CONSTRAINT chk_chapter_sessions_times CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_chapter_sessions_tenant_id ON chapter_sessions(tenant_id);
+CREATE INDEX idx_chapter_sessions_course_id ON chapter_sessions(course_id, starts_at);
+CREATE INDEX idx_chapter_sessions_chapter_id ON chapter_sessions(chapter_id);
+CREATE INDEX idx_chapter_sessions_deleted_at ON chapter_sessions(deleted_at);
+
+CREATE TABLE attendance_records (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES chapter_sessions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    checked_in_at TIMESTAMP,
+    marked_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    note VARCHAR(500),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_attendance_records_session_user UNIQUE (session_id, user_id),
+    CONSTRAINT chk_attendance_records_status CHECK (status IN ('present', 'late', 'excused', 'absent'))
+);
+
+CREATE INDEX idx_attendance_records_user_id ON attendance_records(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS attendance_records;
+DROP TABLE IF EXISTS chapter_sessions;
+ALTER TABLE courses DROP COLUMN IF EXISTS min_attendance_percent;
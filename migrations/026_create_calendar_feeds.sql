@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE chapter_sessions ALTER COLUMN chapter_id DROP NOT NULL;
+ALTER TABLE chapter_sessions ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE enrollments ADD COLUMN calendar_sequence INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE enrollments ADD COLUMN expiry_sequence INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE calendar_feeds (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE RESTRICT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    last_fetched_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_calendar_feeds_user_id UNIQUE (user_id),
+    CONSTRAINT uq_calendar_feeds_token_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_calendar_feeds_tenant_id ON calendar_feeds(tenant_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS calendar_feeds;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS expiry_sequence;
+ALTER TABLE enrollments DROP COLUMN IF EXISTS calendar_sequence;
+ALTER TABLE chapter_sessions DROP COLUMN IF EXISTS sequence;
+DELETE FROM chapter_sessions WHERE chapter_id IS NULL;
+ALTER TABLE chapter_sessions ALTER COLUMN chapter_id SET NOT NULL;
@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE hafalan_targets (
+    content_id INTEGER PRIMARY KEY REFERENCES course_contents(id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE RESTRICT,
+    from_surah INTEGER NOT NULL,
+    from_ayah INTEGER NOT NULL,
+    to_surah INTEGER NOT NULL,
+    to_ayah INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_hafalan_targets_surahs CHECK (from_surah BETWEEN 1 AND 114 AND to_surah BETWEEN 1 AND 114)
+);
+
+CREATE TABLE hafalan_records (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE RESTRICT,
+    content_id INTEGER NOT NULL REFERENCES course_contents(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    surah INTEGER NOT NULL,
+    from_ayah INTEGER NOT NULL,
+    to_ayah INTEGER NOT NULL,
+    grade VARCHAR(20) NOT NULL,
+    note VARCHAR(500),
+    recorded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    recorded_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_hafalan_records_kind CHECK (kind IN ('ziyadah', 'murajaah')),
+    CONSTRAINT chk_hafalan_records_grade CHECK (grade IN ('mumtaz', 'jayyid_jiddan', 'jayyid', 'maqbul', 'rasib')),
+    CONSTRAINT chk_hafalan_records_ayahs CHECK (surah BETWEEN 1 AND 114 AND from_ayah >= 1 AND to_ayah >= from_ayah)
+);
+
+CREATE INDEX idx_hafalan_targets_tenant_id ON hafalan_targets(tenant_id);
+CREATE INDEX idx_hafalan_records_tenant_id ON hafalan_records(tenant_id);
+CREATE INDEX idx_hafalan_records_user_id ON hafalan_records(user_id, recorded_at);
+CREATE INDEX idx_hafalan_records_content_id ON hafalan_records(content_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS hafalan_records;
+DROP TABLE IF EXISTS hafalan_targets;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE courses ADD COLUMN access_days INTEGER;
+
+-- +goose Down
+ALTER TABLE courses DROP COLUMN IF EXISTS access_days;